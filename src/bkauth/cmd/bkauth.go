@@ -97,6 +97,10 @@ func Start() {
 	initCryptos()
 	initLogin()
 	initRealms()
+	initClientPolicies()
+	initSigningKeys()
+	initTokenHashKeys()
+	initMutualTLS()
 
 	// 2. watch the signal
 	ctx, cancelFunc := context.WithCancel(context.Background())
@@ -104,7 +108,10 @@ func Start() {
 		interrupt(cancelFunc)
 	}()
 
-	// 3. start the server
+	// 3. start the background jobs
+	startTokenCleanup(ctx)
+
+	// 4. start the server
 	httpServer := server.NewServer(globalConfig)
 	httpServer.Run(ctx)
 }
@@ -32,6 +32,10 @@ import (
 var (
 	appCodeParam     string
 	accessKeyIDParam int64
+
+	dryRunParam    bool
+	retentionParam int64
+	batchSizeParam int64
 )
 
 var cliCmd = &cobra.Command{
@@ -67,6 +71,25 @@ var deleteAccessKeyCmd = &cobra.Command{
 	},
 }
 
+var cleanupExpiredTokensCmd = &cobra.Command{
+	Use:   "cleanup_expired_tokens",
+	Short: "delete expired and revoked tokens and codes, example: cleanup_expired_tokens --dry_run",
+	Long:  "",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Parent().Run(cmd, args)
+
+		// fall back to the tokenCleanup config
+		cleanupCfg := globalConfig.OAuth.TokenCleanup
+		if retentionParam <= 0 {
+			retentionParam = cleanupCfg.Retention
+		}
+		if batchSizeParam <= 0 {
+			batchSizeParam = cleanupCfg.BatchSize
+		}
+		cli.CleanupExpiredTokens(retentionParam, batchSizeParam, dryRunParam)
+	},
+}
+
 func init() {
 	cliCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default is config.yml;required)")
 	cliCmd.PersistentFlags().Bool("viper", true, "Use Viper for configuration")
@@ -90,6 +113,18 @@ func init() {
 	_ = deleteAccessKeyCmd.MarkFlagRequired("app_code")
 	_ = deleteAccessKeyCmd.MarkFlagRequired("access_key_id")
 	cliCmd.AddCommand(deleteAccessKeyCmd)
+
+	// Cleanup Expired Tokens
+	cleanupExpiredTokensCmd.Flags().BoolVarP(
+		&dryRunParam, "dry_run", "d", false, "only count the rows which would be deleted",
+	)
+	cleanupExpiredTokensCmd.Flags().Int64VarP(
+		&retentionParam, "retention", "r", 0, "seconds to keep rows after expired or revoked (default from config)",
+	)
+	cleanupExpiredTokensCmd.Flags().Int64VarP(
+		&batchSizeParam, "batch_size", "b", 0, "max rows deleted by one statement (default from config)",
+	)
+	cliCmd.AddCommand(cleanupExpiredTokensCmd)
 }
 
 func cliStart() {
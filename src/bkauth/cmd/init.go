@@ -19,8 +19,10 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"regexp"
+	"time"
 
 	"github.com/getsentry/sentry-go"
 	"github.com/spf13/viper"
@@ -28,11 +30,13 @@ import (
 
 	"bkauth/pkg/api/common"
 	"bkauth/pkg/cache/impls"
+	"bkauth/pkg/cache/invalidation"
 	"bkauth/pkg/config"
 	"bkauth/pkg/cryptography"
 	"bkauth/pkg/database"
 	"bkauth/pkg/errorx"
 	"bkauth/pkg/external/bkapigateway"
+	"bkauth/pkg/janitor"
 	"bkauth/pkg/logging"
 	"bkauth/pkg/login"
 	"bkauth/pkg/metric"
@@ -133,6 +137,7 @@ func initLogger() {
 
 func initCaches() {
 	impls.InitCaches(false)
+	invalidation.RegisterEvictor(invalidation.KindAccessToken, impls.DeleteAccessTokenCaches)
 }
 
 func initCryptos() {
@@ -173,6 +178,71 @@ func initRealms() {
 	oauth.RegisterRealm(gpu.New())
 }
 
+func initSigningKeys() {
+	// a retired key stays published until every token it may have signed has expired
+	maxAccessTokenTTL := time.Duration(globalConfig.OAuth.MaxAccessTokenTTL()) * time.Second
+
+	for _, k := range globalConfig.OAuth.SigningKeys {
+		privateKey, err := oauth.ParsePrivateKeyPEM(k.PrivateKey)
+		if err != nil {
+			panic(fmt.Sprintf("invalid oauth signing key kid=%s: %v", k.KID, err))
+		}
+
+		key := oauth.SigningKey{KID: k.KID, Algorithm: k.Algorithm, PrivateKey: privateKey}
+		if k.RetiredAt != "" {
+			retiredAt, err := time.Parse(time.RFC3339, k.RetiredAt)
+			if err != nil {
+				panic(fmt.Sprintf("invalid oauth signing key kid=%s retiredAt: %v", k.KID, err))
+			}
+			key.RetiredAt = retiredAt
+			key.PublishUntil = retiredAt.Add(maxAccessTokenTTL)
+		}
+
+		if err := oauth.RegisterSigningKey(key); err != nil {
+			panic(fmt.Sprintf("register oauth signing key kid=%s fail: %v", k.KID, err))
+		}
+	}
+
+	if len(globalConfig.OAuth.JWTAccessTokenRealms) > 0 {
+		if _, ok := oauth.ActiveSigningKey(); !ok {
+			panic("oauth jwtAccessTokenRealms requires at least one active signing key")
+		}
+	}
+	zap.S().Infof("init OAuth signing keys success, %d key(s) registered", len(globalConfig.OAuth.SigningKeys))
+}
+
+func initTokenHashKeys() {
+	for _, k := range globalConfig.OAuth.TokenHashKeys {
+		key := oauth.TokenHashKey{ID: k.ID, Secret: []byte(k.Secret)}
+		if err := oauth.RegisterTokenHashKey(key); err != nil {
+			panic(fmt.Sprintf("register oauth token hash key id=%s fail: %v", k.ID, err))
+		}
+	}
+	zap.S().Infof("init OAuth token hash keys success, %d key(s) registered", len(globalConfig.OAuth.TokenHashKeys))
+}
+
+func initMutualTLS() {
+	if globalConfig.OAuth.MutualTLS.TrustedCAs == "" {
+		return
+	}
+
+	if err := oauth.RegisterTrustedClientCAs(globalConfig.OAuth.MutualTLS.TrustedCAs); err != nil {
+		panic(fmt.Sprintf("invalid oauth mutualTLS trustedCAs: %v", err))
+	}
+	zap.S().Info("init OAuth mutual-TLS trusted CAs success")
+}
+
+func initClientPolicies() {
+	for _, p := range globalConfig.OAuth.PublicClientPolicies {
+		if !oauth.IsValidRealm(p.RealmName) {
+			panic(fmt.Sprintf("invalid oauth publicClientPolicies realmName=%s: realm not registered", p.RealmName))
+		}
+		if err := oauth.RegisterPublicClientPolicy(p.RealmName, p.AllowedResources); err != nil {
+			panic(fmt.Sprintf("invalid oauth publicClientPolicies realmName=%s: %v", p.RealmName, err))
+		}
+	}
+}
+
 func initAPIAllowList() {
 	common.InitAPIAllowList(globalConfig.APIAllowLists)
 }
@@ -211,3 +281,12 @@ func initProfiling() {
 
 	zap.S().Info("init Profiling success")
 }
+
+func startTokenCleanup(ctx context.Context) {
+	if !globalConfig.OAuth.TokenCleanup.Enabled {
+		zap.S().Info("Token cleanup is not enabled, will not start it")
+		return
+	}
+
+	go janitor.New(globalConfig.OAuth.TokenCleanup).Run(ctx)
+}
@@ -0,0 +1,264 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+
+	"bkauth/pkg/api/common"
+	"bkauth/pkg/errorx"
+	"bkauth/pkg/service"
+	svctypes "bkauth/pkg/service/types"
+	"bkauth/pkg/util"
+)
+
+// CreateOAuthClient godoc
+// @Summary oauth client create
+// @Description registers the confidential OAuth client of an app, the client_id is the bk_app_code
+// @ID api-app-oauth-client-create
+// @Tags oauth-client
+// @Accept  json
+// @Produce  json
+// @Param X-BK-APP-CODE header string true "app_code"
+// @Param X-BK-APP-SECRET header string true "app_secret"
+// @Param bk_app_code path string true "App Code"
+// @Param data body oauthClientSerializer true "OAuth Client Info"
+// @Success 200 {object} util.Response{data=oauthClientResponse}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Router /api/v1/apps/{bk_app_code}/oauth-client [post]
+func CreateOAuthClient(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "CreateOAuthClient")
+
+	var uriParams common.AppCodeSerializer
+	if err := c.ShouldBindUri(&uriParams); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	appCode := uriParams.AppCode
+
+	var body oauthClientSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	if err := body.validate(); err != nil {
+		util.BadRequestErrorJSONResponse(c, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	svc := service.NewOAuthClientService()
+
+	exists, err := svc.Exists(ctx, appCode)
+	if err != nil {
+		util.SystemErrorJSONResponse(c, errorWrapf(err, "svc.Exists appCode=`%s`", appCode))
+		return
+	}
+	if exists {
+		util.ConflictJSONResponse(c, "oauth client of the app already exists")
+		return
+	}
+
+	client, err := svc.RegisterConfidential(ctx, appCode, svctypes.OAuthConfidentialClientInput{
+		Name:         body.Name,
+		RedirectURIs: body.RedirectURIs,
+		GrantTypes:   body.GrantTypes,
+		LogoURI:      body.LogoURI,
+
+		TokenEndpointAuthMethod: body.TokenEndpointAuthMethod,
+		TLSClientAuthSubjectDN:  body.TLSClientAuthSubjectDN,
+		TLSClientAuthSAN:        body.TLSClientAuthSAN,
+		JWKS:                    body.jwks(),
+		JWKSURI:                 body.JWKSURI,
+
+		AllowedRealms:    body.AllowedRealms,
+		AllowedResources: body.AllowedResources,
+	})
+	if err != nil {
+		util.SystemErrorJSONResponse(c, errorWrapf(err, "svc.RegisterConfidential appCode=`%s`", appCode))
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", newOAuthClientResponse(client))
+}
+
+// GetOAuthClient godoc
+// @Summary get oauth client
+// @Description gets the confidential OAuth client of an app
+// @ID api-app-oauth-client-get
+// @Tags oauth-client
+// @Accept  json
+// @Produce  json
+// @Param X-BK-APP-CODE header string true "app_code"
+// @Param X-BK-APP-SECRET header string true "app_secret"
+// @Param bk_app_code path string true "App Code"
+// @Success 200 {object} util.Response{data=oauthClientResponse}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Router /api/v1/apps/{bk_app_code}/oauth-client [get]
+func GetOAuthClient(c *gin.Context) {
+	var uriParams common.AppCodeSerializer
+	if err := c.ShouldBindUri(&uriParams); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	appCode := uriParams.AppCode
+
+	ctx := c.Request.Context()
+	svc := service.NewOAuthClientService()
+	client, err := svc.Get(ctx, appCode)
+	if err != nil {
+		err = errorx.Wrapf(err, "Handler", "GetOAuthClient", "svc.Get appCode=`%s` fail", appCode)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+	if client.ID == "" {
+		util.NotFoundJSONResponse(c, "oauth client of the app not found")
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", newOAuthClientResponse(client))
+}
+
+// UpdateOAuthClient godoc
+// @Summary update oauth client
+// @Description replaces the metadata of the confidential OAuth client of an app
+// @ID api-app-oauth-client-update
+// @Tags oauth-client
+// @Accept  json
+// @Produce  json
+// @Param X-BK-APP-CODE header string true "app_code"
+// @Param X-BK-APP-SECRET header string true "app_secret"
+// @Param bk_app_code path string true "App Code"
+// @Param data body oauthClientSerializer true "OAuth Client Info"
+// @Success 200 {object} util.Response{data=oauthClientResponse}
+// @Header 200 {string} X-Request-Id "the request id"
+// @Router /api/v1/apps/{bk_app_code}/oauth-client [put]
+func UpdateOAuthClient(c *gin.Context) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf("Handler", "UpdateOAuthClient")
+
+	var uriParams common.AppCodeSerializer
+	if err := c.ShouldBindUri(&uriParams); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	appCode := uriParams.AppCode
+
+	var body oauthClientSerializer
+	if err := c.ShouldBindJSON(&body); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	if err := body.validate(); err != nil {
+		util.BadRequestErrorJSONResponse(c, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	svc := service.NewOAuthClientService()
+
+	exists, err := svc.Exists(ctx, appCode)
+	if err != nil {
+		util.SystemErrorJSONResponse(c, errorWrapf(err, "svc.Exists appCode=`%s`", appCode))
+		return
+	}
+	if !exists {
+		util.NotFoundJSONResponse(c, "oauth client of the app not found")
+		return
+	}
+
+	client, err := svc.UpdateConfidential(ctx, appCode, svctypes.OAuthConfidentialClientInput{
+		Name:         body.Name,
+		RedirectURIs: body.RedirectURIs,
+		GrantTypes:   body.GrantTypes,
+		LogoURI:      body.LogoURI,
+
+		TokenEndpointAuthMethod: body.TokenEndpointAuthMethod,
+		TLSClientAuthSubjectDN:  body.TLSClientAuthSubjectDN,
+		TLSClientAuthSAN:        body.TLSClientAuthSAN,
+		JWKS:                    body.jwks(),
+		JWKSURI:                 body.JWKSURI,
+
+		AllowedRealms:    body.AllowedRealms,
+		AllowedResources: body.AllowedResources,
+	})
+	if err != nil {
+		util.SystemErrorJSONResponse(c, errorWrapf(err, "svc.UpdateConfidential appCode=`%s`", appCode))
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", newOAuthClientResponse(client))
+}
+
+// DeleteOAuthClient godoc
+// @Summary delete oauth client
+// @Description deletes the confidential OAuth client of an app
+// @ID api-app-oauth-client-delete
+// @Tags oauth-client
+// @Accept  json
+// @Produce  json
+// @Param X-BK-APP-CODE header string true "app_code"
+// @Param X-BK-APP-SECRET header string true "app_secret"
+// @Param bk_app_code path string true "App Code"
+// @Success 200 {object} util.Response
+// @Header 200 {string} X-Request-Id "the request id"
+// @Router /api/v1/apps/{bk_app_code}/oauth-client [delete]
+func DeleteOAuthClient(c *gin.Context) {
+	var uriParams common.AppCodeSerializer
+	if err := c.ShouldBindUri(&uriParams); err != nil {
+		util.BadRequestErrorJSONResponse(c, util.ValidationErrorMessage(err))
+		return
+	}
+	appCode := uriParams.AppCode
+
+	ctx := c.Request.Context()
+	svc := service.NewOAuthClientService()
+	if err := svc.Delete(ctx, appCode); err != nil {
+		err = errorx.Wrapf(err, "Handler", "DeleteOAuthClient", "svc.Delete appCode=`%s` fail", appCode)
+		util.SystemErrorJSONResponse(c, err)
+		return
+	}
+
+	util.SuccessJSONResponse(c, "ok", nil)
+}
+
+func newOAuthClientResponse(client svctypes.OAuthClient) oauthClientResponse {
+	response := oauthClientResponse{
+		ClientID:     client.ID,
+		Name:         client.Name,
+		Type:         client.Type,
+		RedirectURIs: client.RedirectURIs,
+		GrantTypes:   client.GrantTypes,
+		LogoURI:      client.LogoURI,
+		CreatedAt:    client.CreatedAt,
+
+		TokenEndpointAuthMethod: client.TokenEndpointAuthMethod(),
+		TLSClientAuthSubjectDN:  client.TLSClientAuthSubjectDN,
+		TLSClientAuthSAN:        client.TLSClientAuthSAN,
+		JWKSURI:                 client.JWKSURI,
+
+		AllowedRealms:    client.AllowedRealms,
+		AllowedResources: client.AllowedResources,
+	}
+	if client.JWKS != "" {
+		response.JWKS = json.RawMessage(client.JWKS)
+	}
+	return response
+}
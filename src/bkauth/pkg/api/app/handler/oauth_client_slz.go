@@ -0,0 +1,166 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"bkauth/pkg/oauth"
+	"bkauth/pkg/util"
+)
+
+// oauthClientSerializer is the metadata of the confidential OAuth client of an app,
+// the client_id is always the bk_app_code
+type oauthClientSerializer struct {
+	Name         string   `json:"name" binding:"required,max=128" example:"BK PaaS"`
+	RedirectURIs []string `json:"redirect_uris" binding:"omitempty"`
+	GrantTypes   []string `json:"grant_types" binding:"required,min=1" example:"authorization_code,refresh_token"`
+	LogoURI      string   `json:"logo_uri" binding:"omitempty,max=512" example:"https://example.com/logo.png"`
+	// RFC 8705 / RFC 7523: empty means client_secret_basic with the app secrets;
+	// tls_client_auth needs one of tls_client_auth_subject_dn / tls_client_auth_san,
+	// self_signed_tls_client_auth needs jwks and private_key_jwt needs one of jwks / jwks_uri
+	TokenEndpointAuthMethod string          `json:"token_endpoint_auth_method" example:"tls_client_auth"`
+	TLSClientAuthSubjectDN  string          `json:"tls_client_auth_subject_dn" binding:"omitempty,max=512"`
+	TLSClientAuthSAN        string          `json:"tls_client_auth_san" binding:"omitempty,max=512"`
+	JWKS                    json.RawMessage `json:"jwks"`
+	JWKSURI                 string          `json:"jwks_uri" binding:"omitempty,max=512"`
+	// the client policy, empty means not restricted; "*" in a resource pattern matches any sequence
+	AllowedRealms    []string `json:"allowed_realms" binding:"omitempty" example:"blueking"`
+	AllowedResources []string `json:"allowed_resources" binding:"omitempty" example:"gateway:bk_paas:api:*"`
+}
+
+// validate performs the validation beyond struct tags and normalizes the fields in place
+func (s *oauthClientSerializer) validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return errors.New("name cannot be blank")
+	}
+
+	if err := oauth.ValidateGrantTypes(s.GrantTypes); err != nil {
+		return err
+	}
+	s.GrantTypes = util.Deduplicate(s.GrantTypes)
+
+	for _, uri := range s.RedirectURIs {
+		if err := oauth.ValidateRedirectURI(uri); err != nil {
+			return err
+		}
+	}
+	s.RedirectURIs = util.Deduplicate(s.RedirectURIs)
+
+	// the authorization code flow redirects back to the client, so it needs at least one redirect uri
+	for _, gt := range s.GrantTypes {
+		if gt == oauth.GrantTypeAuthorizationCode && len(s.RedirectURIs) == 0 {
+			return errors.New("redirect_uris is required for grant_type authorization_code")
+		}
+	}
+
+	if s.LogoURI != "" {
+		if err := oauth.ValidateLogoURI(s.LogoURI); err != nil {
+			return err
+		}
+	}
+
+	for _, realmName := range s.AllowedRealms {
+		if !oauth.IsValidRealm(realmName) {
+			return fmt.Errorf("allowed_realms contains an unknown realm: %s", realmName)
+		}
+	}
+	s.AllowedRealms = util.Deduplicate(s.AllowedRealms)
+
+	for _, pattern := range s.AllowedResources {
+		if err := oauth.ValidateResourcePattern(pattern); err != nil {
+			return err
+		}
+	}
+	s.AllowedResources = util.Deduplicate(s.AllowedResources)
+
+	return s.validateAuthMethod()
+}
+
+func (s *oauthClientSerializer) validateAuthMethod() error {
+	hasSubject := s.TLSClientAuthSubjectDN != "" || s.TLSClientAuthSAN != ""
+	hasJWKS := s.jwks() != ""
+
+	switch s.TokenEndpointAuthMethod {
+	case "", oauth.AuthMethodClientSecretBasic, oauth.AuthMethodClientSecretPost:
+		if hasSubject || hasJWKS || s.JWKSURI != "" {
+			return errors.New("tls_client_auth_subject_dn, tls_client_auth_san, jwks and jwks_uri " +
+				"are not allowed for client secret authentication")
+		}
+		return nil
+	case oauth.AuthMethodTLSClientAuth:
+		if hasJWKS || s.JWKSURI != "" {
+			return errors.New("jwks and jwks_uri are not allowed for tls_client_auth")
+		}
+		return oauth.ValidateTLSClientAuthSubject(s.TLSClientAuthSubjectDN, s.TLSClientAuthSAN)
+	case oauth.AuthMethodSelfSignedTLSClientAuth:
+		// the certificates are registered in jwks (RFC 8705 §2.2)
+		if hasSubject || s.JWKSURI != "" {
+			return errors.New("only jwks is allowed for self_signed_tls_client_auth")
+		}
+		if !hasJWKS {
+			return errors.New("jwks is required for self_signed_tls_client_auth")
+		}
+		return oauth.ValidateSelfSignedJWKS(s.jwks())
+	case oauth.AuthMethodPrivateKeyJWT:
+		if hasSubject {
+			return errors.New("tls_client_auth_subject_dn and tls_client_auth_san are not allowed for private_key_jwt")
+		}
+		if hasJWKS == (s.JWKSURI != "") {
+			return errors.New("exactly one of jwks and jwks_uri is required for private_key_jwt")
+		}
+		if s.JWKSURI != "" {
+			return oauth.ValidateJWKSURI(s.JWKSURI)
+		}
+		return oauth.ValidateJWKS(s.jwks())
+	default:
+		return errors.New("unsupported token_endpoint_auth_method: " + s.TokenEndpointAuthMethod)
+	}
+}
+
+// jwks returns the registered JWK Set as a string, empty when none is registered
+func (s *oauthClientSerializer) jwks() string {
+	if len(s.JWKS) == 0 || string(s.JWKS) == "null" {
+		return ""
+	}
+	return string(s.JWKS)
+}
+
+type oauthClientResponse struct {
+	ClientID     string   `json:"client_id" example:"bk_paas"`
+	Name         string   `json:"name" example:"BK PaaS"`
+	Type         string   `json:"type" example:"confidential"`
+	RedirectURIs []string `json:"redirect_uris"`
+	GrantTypes   []string `json:"grant_types"`
+	LogoURI      string   `json:"logo_uri"`
+	CreatedAt    int64    `json:"created_at" example:"1700000000"`
+
+	TokenEndpointAuthMethod string          `json:"token_endpoint_auth_method" example:"client_secret_basic"`
+	TLSClientAuthSubjectDN  string          `json:"tls_client_auth_subject_dn"`
+	TLSClientAuthSAN        string          `json:"tls_client_auth_san"`
+	JWKS                    json.RawMessage `json:"jwks,omitempty"`
+	JWKSURI                 string          `json:"jwks_uri"`
+
+	AllowedRealms    []string `json:"allowed_realms"`
+	AllowedResources []string `json:"allowed_resources"`
+}
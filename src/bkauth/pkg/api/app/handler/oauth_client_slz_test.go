@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+
+	"bkauth/pkg/oauth"
+)
+
+const testPublicJWKS = `{"keys":[{"kty":"EC","crv":"P-256",` +
+	`"x":"f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU",` +
+	`"y":"x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0"}]}`
+
+func TestOAuthClientSerializer_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		serializer oauthClientSerializer
+		wantErr    bool
+		errMsg     string
+	}{
+		{
+			name: "blank name",
+			serializer: oauthClientSerializer{
+				Name:       "  ",
+				GrantTypes: []string{oauth.GrantTypeClientCredentials},
+			},
+			wantErr: true,
+			errMsg:  "name cannot be blank",
+		},
+		{
+			name: "unsupported grant_type",
+			serializer: oauthClientSerializer{
+				Name:       "demo",
+				GrantTypes: []string{"password"},
+			},
+			wantErr: true,
+			errMsg:  "unsupported grant_type: password",
+		},
+		{
+			name: "authorization_code without redirect_uris",
+			serializer: oauthClientSerializer{
+				Name:       "demo",
+				GrantTypes: []string{oauth.GrantTypeAuthorizationCode},
+			},
+			wantErr: true,
+			errMsg:  "redirect_uris is required for grant_type authorization_code",
+		},
+		{
+			name: "invalid redirect_uri",
+			serializer: oauthClientSerializer{
+				Name:         "demo",
+				RedirectURIs: []string{"https://example.com/cb#fragment"},
+				GrantTypes:   []string{oauth.GrantTypeAuthorizationCode},
+			},
+			wantErr: true,
+			errMsg:  "redirect_uri must not contain a fragment (#): https://example.com/cb#fragment",
+		},
+		{
+			name: "invalid logo_uri",
+			serializer: oauthClientSerializer{
+				Name:       "demo",
+				GrantTypes: []string{oauth.GrantTypeClientCredentials},
+				LogoURI:    "ftp://example.com/logo.png",
+			},
+			wantErr: true,
+			errMsg:  "logo_uri must use http or https scheme: ftp://example.com/logo.png",
+		},
+		{
+			name: "unknown allowed realm",
+			serializer: oauthClientSerializer{
+				Name:          "demo",
+				GrantTypes:    []string{oauth.GrantTypeClientCredentials},
+				AllowedRealms: []string{"not-a-realm"},
+			},
+			wantErr: true,
+			errMsg:  "allowed_realms contains an unknown realm: not-a-realm",
+		},
+		{
+			name: "invalid allowed resource pattern",
+			serializer: oauthClientSerializer{
+				Name:             "demo",
+				GrantTypes:       []string{oauth.GrantTypeClientCredentials},
+				AllowedResources: []string{"mcp:foo,mcp:bar"},
+			},
+			wantErr: true,
+			errMsg:  "resource pattern must not contain a comma: mcp:foo,mcp:bar",
+		},
+		{
+			name: "unsupported token_endpoint_auth_method",
+			serializer: oauthClientSerializer{
+				Name:                    "demo",
+				GrantTypes:              []string{oauth.GrantTypeClientCredentials},
+				TokenEndpointAuthMethod: oauth.AuthMethodNone,
+			},
+			wantErr: true,
+			errMsg:  "unsupported token_endpoint_auth_method: none",
+		},
+		{
+			name: "client secret with a certificate subject",
+			serializer: oauthClientSerializer{
+				Name:             "demo",
+				GrantTypes:       []string{oauth.GrantTypeClientCredentials},
+				TLSClientAuthSAN: "demo.example.com",
+			},
+			wantErr: true,
+			errMsg: "tls_client_auth_subject_dn, tls_client_auth_san, jwks and jwks_uri " +
+				"are not allowed for client secret authentication",
+		},
+		{
+			name: "tls_client_auth without subject",
+			serializer: oauthClientSerializer{
+				Name:                    "demo",
+				GrantTypes:              []string{oauth.GrantTypeClientCredentials},
+				TokenEndpointAuthMethod: oauth.AuthMethodTLSClientAuth,
+			},
+			wantErr: true,
+			errMsg:  "exactly one of tls_client_auth_subject_dn and tls_client_auth_san is required",
+		},
+		{
+			name: "tls_client_auth with jwks",
+			serializer: oauthClientSerializer{
+				Name:                    "demo",
+				GrantTypes:              []string{oauth.GrantTypeClientCredentials},
+				TokenEndpointAuthMethod: oauth.AuthMethodTLSClientAuth,
+				TLSClientAuthSubjectDN:  "CN=demo,O=BlueKing",
+				JWKS:                    json.RawMessage(testPublicJWKS),
+			},
+			wantErr: true,
+			errMsg:  "jwks and jwks_uri are not allowed for tls_client_auth",
+		},
+		{
+			name: "self_signed_tls_client_auth without jwks",
+			serializer: oauthClientSerializer{
+				Name:                    "demo",
+				GrantTypes:              []string{oauth.GrantTypeClientCredentials},
+				TokenEndpointAuthMethod: oauth.AuthMethodSelfSignedTLSClientAuth,
+			},
+			wantErr: true,
+			errMsg:  "jwks is required for self_signed_tls_client_auth",
+		},
+		{
+			name: "self_signed_tls_client_auth without certificate",
+			serializer: oauthClientSerializer{
+				Name:                    "demo",
+				GrantTypes:              []string{oauth.GrantTypeClientCredentials},
+				TokenEndpointAuthMethod: oauth.AuthMethodSelfSignedTLSClientAuth,
+				JWKS:                    json.RawMessage(testPublicJWKS),
+			},
+			wantErr: true,
+			errMsg:  "jwks must contain a key with an x5c certificate",
+		},
+		{
+			name: "private_key_jwt with both jwks and jwks_uri",
+			serializer: oauthClientSerializer{
+				Name:                    "demo",
+				GrantTypes:              []string{oauth.GrantTypeClientCredentials},
+				TokenEndpointAuthMethod: oauth.AuthMethodPrivateKeyJWT,
+				JWKS:                    json.RawMessage(testPublicJWKS),
+				JWKSURI:                 "https://example.com/jwks.json",
+			},
+			wantErr: true,
+			errMsg:  "exactly one of jwks and jwks_uri is required for private_key_jwt",
+		},
+		{
+			name: "tls_client_auth with san",
+			serializer: oauthClientSerializer{
+				Name:                    "demo",
+				GrantTypes:              []string{oauth.GrantTypeClientCredentials},
+				TokenEndpointAuthMethod: oauth.AuthMethodTLSClientAuth,
+				TLSClientAuthSAN:        "demo.example.com",
+			},
+			wantErr: false,
+		},
+		{
+			name: "private_key_jwt with jwks",
+			serializer: oauthClientSerializer{
+				Name:                    "demo",
+				GrantTypes:              []string{oauth.GrantTypeClientCredentials},
+				TokenEndpointAuthMethod: oauth.AuthMethodPrivateKeyJWT,
+				JWKS:                    json.RawMessage(testPublicJWKS),
+			},
+			wantErr: false,
+		},
+		{
+			name: "client_credentials without redirect_uris",
+			serializer: oauthClientSerializer{
+				Name:       "demo",
+				GrantTypes: []string{oauth.GrantTypeClientCredentials},
+			},
+			wantErr: false,
+		},
+		{
+			name: "all valid",
+			serializer: oauthClientSerializer{
+				Name:         "demo",
+				RedirectURIs: []string{"https://example.com/cb"},
+				GrantTypes:   []string{oauth.GrantTypeAuthorizationCode, oauth.GrantTypeRefreshToken},
+				LogoURI:      "https://example.com/logo.png",
+
+				AllowedResources: []string{"gateway:demo:api:*"},
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.serializer.validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.errMsg, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestOAuthClientSerializer_ValidateNormalizes(t *testing.T) {
+	s := oauthClientSerializer{
+		Name:         " demo ",
+		RedirectURIs: []string{"https://example.com/cb", "https://example.com/cb"},
+		GrantTypes:   []string{oauth.GrantTypeAuthorizationCode, oauth.GrantTypeAuthorizationCode},
+	}
+
+	assert.NoError(t, s.validate())
+	assert.Equal(t, "demo", s.Name)
+	assert.Equal(t, []string{"https://example.com/cb"}, s.RedirectURIs)
+	assert.Equal(t, []string{oauth.GrantTypeAuthorizationCode}, s.GrantTypes)
+}
+
+func TestOAuthClientSerializer_ValidateSelfSignedTLSClientAuth(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "demo"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:          &key.PublicKey,
+		Certificates: []*x509.Certificate{cert},
+	}}})
+	assert.NoError(t, err)
+
+	s := oauthClientSerializer{
+		Name:                    "demo",
+		GrantTypes:              []string{oauth.GrantTypeClientCredentials},
+		TokenEndpointAuthMethod: oauth.AuthMethodSelfSignedTLSClientAuth,
+		JWKS:                    jwks,
+	}
+
+	assert.NoError(t, s.validate())
+	assert.Equal(t, string(jwks), s.jwks())
+}
@@ -68,4 +68,15 @@ func Register(r *gin.RouterGroup) {
 		// Verify for PaaS/APIGateway/IAM/SSM
 		accessKey.POST("/verify", common.NewAPIAllowMiddleware(common.VerifySecretAPI), handler.VerifyAccessKey)
 	}
+
+	// Confidential OAuth client of the app, the client_id is the bk_app_code
+	oauthClient := r.Group("/:bk_app_code/oauth-client")
+	oauthClient.Use(common.AppCodeExists())
+	oauthClient.Use(common.NewAPIAllowMiddleware(common.ManageOAuthClientAPI))
+	{
+		oauthClient.POST("", handler.CreateOAuthClient)
+		oauthClient.GET("", handler.GetOAuthClient)
+		oauthClient.PUT("", handler.UpdateOAuthClient)
+		oauthClient.DELETE("", handler.DeleteOAuthClient)
+	}
 }
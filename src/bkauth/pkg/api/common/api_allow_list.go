@@ -26,11 +26,12 @@ import (
 )
 
 const (
-	ManageAppAPI       = "manage_app"
-	ReadAppAPI         = "read_app"
-	ManageAccessKeyAPI = "manage_access_key"
-	ReadAccessKeyAPI   = "read_access_key"
-	VerifySecretAPI    = "verify_secret"
+	ManageAppAPI         = "manage_app"
+	ReadAppAPI           = "read_app"
+	ManageAccessKeyAPI   = "manage_access_key"
+	ReadAccessKeyAPI     = "read_access_key"
+	VerifySecretAPI      = "verify_secret"
+	ManageOAuthClientAPI = "manage_oauth_client"
 )
 
 var apiAllowLists = make(map[string]*util.StringSet)
@@ -40,9 +40,9 @@ var (
 	// app_code (exact match) or as the start of an app_code followed by '_' or '-'.
 	//
 	//   "public"  – used as PublicAppCode for dcr / cimd
-	//   "private" – personal token
+	//   "private" – used as PrivateAppCode for personal access tokens
 	//   "dcr"     – dynamic client registration prefix
-	//   "cimd"    – reserved for future Client Instance Metadata Document functionality
+	//   "cimd"    – Client ID Metadata Document clients (their client_id is an https URL)
 	reservedAppCodes = []string{"public", "private", "dcr", "cimd"}
 
 	ErrReservedAppCode = errors.New(
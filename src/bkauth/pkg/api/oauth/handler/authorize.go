@@ -19,6 +19,9 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 
@@ -26,8 +29,10 @@ import (
 
 	"bkauth/pkg/cache/impls"
 	"bkauth/pkg/config"
+	"bkauth/pkg/login"
 	"bkauth/pkg/oauth"
 	"bkauth/pkg/service"
+	"bkauth/pkg/service/types"
 	"bkauth/pkg/util"
 )
 
@@ -36,13 +41,18 @@ import (
 // Required/optional validation is handled by Validate (two-phase, RFC 6749 §4.1.2.1),
 // not by binding tags, because error delivery depends on whether redirect_uri is trusted.
 type AuthorizeRequest struct {
-	ClientID            string `form:"client_id"`             // required
-	RedirectURI         string `form:"redirect_uri"`          // required
-	ResponseType        string `form:"response_type"`         // required ("code")
-	State               string `form:"state"`                 // optional for public clients (PKCE); required otherwise
-	CodeChallenge       string `form:"code_challenge"`        // required (RFC 7636)
-	CodeChallengeMethod string `form:"code_challenge_method"` // required ("S256" or "plain")
-	Resource            string `form:"resource"`              // required
+	ClientID             string `form:"client_id"`             // required
+	RedirectURI          string `form:"redirect_uri"`          // required
+	ResponseType         string `form:"response_type"`         // required ("code")
+	State                string `form:"state"`                 // optional for public clients (PKCE); required otherwise
+	CodeChallenge        string `form:"code_challenge"`        // required (RFC 7636)
+	CodeChallengeMethod  string `form:"code_challenge_method"` // required ("S256" or "plain")
+	Resource             string `form:"resource"`              // required unless authorization_details is present
+	AuthorizationDetails string `form:"authorization_details"` // optional, RFC 9396; types are defined by the realm
+	Scope                string `form:"scope"`                 // optional, must be in the realm's scope catalog
+	Nonce                string `form:"nonce"`                 // optional, OpenID Connect; echoed in the id_token
+	RequestURI           string `form:"request_uri"`           // optional, RFC 9126 pushed authorization request
+	Prompt               string `form:"prompt"`                // optional, OpenID Connect: "none" or "consent"
 }
 
 // Validate validates the OAuth authorize request parameters in RFC 6749 order.
@@ -50,7 +60,7 @@ type AuthorizeRequest struct {
 // Validation is split into two phases based on how errors must be reported:
 //   - Phase 1 (client_id, redirect_uri): errors are returned directly to the user-agent
 //     because the redirect_uri is not yet trusted (RFC 6749 §4.1.2.1).
-//   - Phase 2 (response_type, PKCE, resource): errors can be redirected to the
+//   - Phase 2 (response_type, PKCE, authorization_details, resource, scope): errors can be redirected to the
 //     validated redirect_uri with error/error_description/state query params.
 //
 // The returned canRedirect indicates whether redirect_uri has been validated:
@@ -94,7 +104,7 @@ func (r *AuthorizeRequest) Validate(
 		return true, oauth.NewUnsupportedResponseTypeError("Only 'code' response type is supported")
 	}
 
-	if r.State == "" && !oauth.IsPublicClient(r.ClientID) {
+	if r.State == "" && !flowSpec.IsPublic() {
 		return true, oauth.NewInvalidRequestError("state is required")
 	}
 
@@ -110,22 +120,96 @@ func (r *AuthorizeRequest) Validate(
 		return true, oauth.NewInvalidRequestError("code_challenge_method must be 'S256' or 'plain'")
 	}
 
+	realmName := util.GetRealmName(c)
+	realm := oauth.GetRealm(realmName)
+	r.Resource, r.AuthorizationDetails, err = resolveAuthorizationDetails(ctx, realm, r.Resource, r.AuthorizationDetails)
+	if err != nil {
+		return true, err
+	}
+	if err := validateResourceLength(r.Resource); err != nil {
+		return true, err
+	}
+
 	if r.Resource == "" {
 		return true, oauth.NewInvalidRequestError("resource is required")
 	}
-
-	realmName := util.GetRealmName(c)
-	realm := oauth.GetRealm(realmName)
 	if err := realm.ValidateResource(c.Request.Context(), r.Resource); err != nil {
 		return true, oauth.NewInvalidRequestError("Invalid resource parameter: " + err.Error())
 	}
+	if err := checkClientPolicy(flowSpec, realmName, r.Resource); err != nil {
+		return true, err
+	}
+
+	scope, err := oauth.ValidateScope(realm, r.Scope)
+	if err != nil {
+		return true, oauth.NewInvalidScopeError(err.Error())
+	}
+	r.Scope = scope
+
+	if err := oauth.ValidatePrompt(r.Prompt); err != nil {
+		return true, oauth.NewInvalidRequestError(err.Error())
+	}
+
+	if err := r.validateNonce(); err != nil {
+		return true, err
+	}
 
 	return true, nil
 }
 
+// validateNonce checks the length of the nonce against the column it is stored in.
+func (r *AuthorizeRequest) validateNonce() error {
+	if len(r.Nonce) > oauth.MaxNonceLength {
+		return oauth.NewInvalidRequestError(fmt.Sprintf("nonce must not exceed %d bytes", oauth.MaxNonceLength))
+	}
+	return nil
+}
+
+// validateResourceLength checks the length of the resolved resource against the column it is stored in.
+func validateResourceLength(resource string) error {
+	if len(resource) > oauth.MaxResourceLength {
+		return oauth.NewInvalidRequestError(fmt.Sprintf("resource must not exceed %d bytes", oauth.MaxResourceLength))
+	}
+	return nil
+}
+
+// resolveAuthorizationDetails validates the authorization_details parameter against the realm
+// and merges the resource items the details grant access to into resource, so that the policy
+// check and the audiences cover them. It returns the merged resource and the normalized details.
+func resolveAuthorizationDetails(
+	ctx context.Context, realm oauth.Realm, resource, authorizationDetails string,
+) (string, string, error) {
+	details, detailsResource, err := oauth.ValidateAuthorizationDetails(ctx, realm, authorizationDetails)
+	if err != nil {
+		return "", "", oauth.NewInvalidAuthorizationDetailsError(err.Error())
+	}
+	return oauth.MergeResource(resource, detailsResource), oauth.FormatAuthorizationDetails(details), nil
+}
+
+// checkClientPolicy checks the realm and resource of a request against the effective
+// policy of the client, returning the OAuth error to respond with when not allowed.
+func checkClientPolicy(flowSpec types.OAuthClientFlowSpec, realmName, resource string) error {
+	err := flowSpec.ResolvePolicy(realmName).Check(realmName, resource)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, oauth.ErrRealmNotAllowed):
+		return oauth.NewUnauthorizedClientError("Client is not allowed to use this realm")
+	case errors.Is(err, oauth.ErrResourceNotAllowed):
+		// RFC 8707 §2: the requested resource is not allowed for the client
+		return oauth.NewInvalidTargetError(err.Error())
+	default:
+		return err
+	}
+}
+
 // NewAuthorizeHandler creates a handler for the authorization endpoint (GET /authorize).
 // It validates all OAuth parameters before storing the consent session in Redis,
 // then redirects to the frontend consent page.
+//
+// A logged-in user who has already approved the client for the requested resources
+// is redirected back with an authorization code directly, unless prompt=consent.
+// With prompt=none, the consent page is never shown (OIDC Core §3.1.2.1).
 func NewAuthorizeHandler(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req AuthorizeRequest
@@ -134,7 +218,23 @@ func NewAuthorizeHandler(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		clientSvc := service.NewOAuthClientService()
+		// RFC 9126 §4: with request_uri, the authorization request parameters are
+		// taken from the pushed request only; parameters on the query string are ignored.
+		if req.RequestURI != "" {
+			pushedReq, err := loadPushedAuthorizationRequest(c, req.ClientID, req.RequestURI)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, err)
+				return
+			}
+			req = pushedReq
+		} else if cfg.OAuth.IsPARRequired(util.GetRealmName(c), req.ClientID) {
+			c.JSON(http.StatusBadRequest, oauth.NewInvalidRequestError(
+				"Pushed authorization request is required for this client",
+			))
+			return
+		}
+
+		clientSvc := impls.WrapOAuthClientService(service.NewOAuthClientService())
 		canRedirect, err := req.Validate(c, clientSvc)
 		if err != nil {
 			// Normalize: wrap unexpected internal errors as OAuth "server_error".
@@ -163,14 +263,34 @@ func NewAuthorizeHandler(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		if !oauth.HasPrompt(req.Prompt, oauth.PromptConsent) {
+			redirectURL, err := authorizeWithGrantedConsent(c, cfg, req)
+			if err != nil {
+				oauthErr, ok := oauth.AsOAuthError(err)
+				if !ok {
+					oauthErr = oauth.NewServerError(err.Error())
+				}
+				redirectURL = oauth.BuildErrorRedirectURL(
+					req.RedirectURI, req.State, oauthErr.Code, oauthErr.Description,
+				)
+			}
+			if redirectURL != "" {
+				c.Redirect(http.StatusFound, redirectURL)
+				return
+			}
+		}
+
 		consent := impls.Consent{
-			RealmName:           util.GetRealmName(c),
-			ClientID:            req.ClientID,
-			RedirectURI:         req.RedirectURI,
-			State:               req.State,
-			CodeChallenge:       req.CodeChallenge,
-			CodeChallengeMethod: req.CodeChallengeMethod,
-			Resource:            req.Resource,
+			RealmName:            util.GetRealmName(c),
+			ClientID:             req.ClientID,
+			RedirectURI:          req.RedirectURI,
+			State:                req.State,
+			CodeChallenge:        req.CodeChallenge,
+			CodeChallengeMethod:  req.CodeChallengeMethod,
+			Resource:             req.Resource,
+			AuthorizationDetails: req.AuthorizationDetails,
+			Scope:                req.Scope,
+			Nonce:                req.Nonce,
 		}
 
 		consentChallenge, err := impls.CreateConsent(c.Request.Context(), consent)
@@ -190,3 +310,128 @@ func NewAuthorizeHandler(cfg *config.Config) gin.HandlerFunc {
 		c.Redirect(http.StatusFound, redirectURL)
 	}
 }
+
+// authorizeWithGrantedConsent issues an authorization code without showing the consent page
+// when the logged-in user has a persisted consent covering the request, and returns the
+// redirect URL carrying the code. It returns "" when the consent page must be shown, or
+// login_required / consent_required for prompt=none.
+//
+// A persisted consent is recorded per audience, which does not cover the API-level
+// authorization_details, so a request carrying them always needs the user's approval.
+//
+// The user-client tenant check is not repeated here: a consent is only recorded after
+// the check passed on the consent page, and it is keyed by the user's tenant.
+func authorizeWithGrantedConsent(c *gin.Context, cfg *config.Config, req AuthorizeRequest) (string, error) {
+	promptNone := oauth.HasPrompt(req.Prompt, oauth.PromptNone)
+
+	user, ok := checkLoginUser(c)
+	if !ok {
+		if promptNone {
+			return "", oauth.NewLoginRequiredError("User is not logged in")
+		}
+		return "", nil
+	}
+
+	ctx := c.Request.Context()
+	realmName := util.GetRealmName(c)
+	audience, err := oauth.GetRealm(realmName).ExtractAudiences(ctx, req.Resource)
+	if err != nil {
+		return "", err
+	}
+
+	granted := false
+	if cfg.OAuth.ConsentTTL > 0 && req.AuthorizationDetails == "" {
+		consentSvc := service.NewOAuthConsentService()
+		granted, err = consentSvc.IsGranted(ctx, types.ConsentGrant{
+			TenantID:  user.TenantID,
+			Sub:       user.Sub,
+			RealmName: realmName,
+			ClientID:  req.ClientID,
+			Audience:  audience,
+			Scope:     req.Scope,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	if !granted {
+		if promptNone {
+			return "", oauth.NewConsentRequiredError("User consent is required")
+		}
+		return "", nil
+	}
+
+	code, err := oauth.GenerateAuthorizationCode()
+	if err != nil {
+		return "", err
+	}
+
+	authCodeSvc := service.NewOAuthAuthorizationCodeService()
+	err = authCodeSvc.CreateAuthorizationCode(ctx, types.CreateAuthorizationCodeInput{
+		Code:                code,
+		ClientID:            req.ClientID,
+		TenantID:            user.TenantID,
+		RealmName:           realmName,
+		Sub:                 user.Sub,
+		Username:            user.Username,
+		RedirectURI:         req.RedirectURI,
+		Audience:            audience,
+		Resource:            req.Resource,
+		Scope:               req.Scope,
+		Nonce:               req.Nonce,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return oauth.BuildAuthorizationRedirectURL(req.RedirectURI, req.State, code), nil
+}
+
+// checkLoginUser verifies the user's login cookie, as LoginRequired does for the web API.
+func checkLoginUser(c *gin.Context) (login.AuthResult, bool) {
+	authenticator := login.GetAuthenticator()
+
+	token, err := c.Cookie(authenticator.CookieName())
+	if err != nil || token == "" {
+		return login.AuthResult{}, false
+	}
+
+	result, err := authenticator.CheckLogin(c.Request.Context(), token)
+	if err != nil || !result.Success {
+		return login.AuthResult{}, false
+	}
+	return result, true
+}
+
+// loadPushedAuthorizationRequest consumes the pushed authorization request referenced by
+// request_uri and rebuilds the AuthorizeRequest from it. The request_uri is one-time use,
+// and it must have been pushed by the same client to the same realm (RFC 9126 §4).
+func loadPushedAuthorizationRequest(c *gin.Context, clientID, requestURI string) (AuthorizeRequest, *oauth.OAuthError) {
+	if clientID == "" {
+		return AuthorizeRequest{}, oauth.NewInvalidRequestError("client_id is required")
+	}
+
+	par, err := impls.ConsumePushedAuthorizationRequest(c.Request.Context(), requestURI)
+	if err != nil {
+		return AuthorizeRequest{}, oauth.NewInvalidRequestURIError("request_uri is invalid or expired")
+	}
+	if par.RealmName != util.GetRealmName(c) || par.ClientID != clientID {
+		return AuthorizeRequest{}, oauth.NewInvalidRequestURIError("request_uri was not issued to this client")
+	}
+
+	return AuthorizeRequest{
+		ClientID:             par.ClientID,
+		RedirectURI:          par.RedirectURI,
+		ResponseType:         par.ResponseType,
+		State:                par.State,
+		CodeChallenge:        par.CodeChallenge,
+		CodeChallengeMethod:  par.CodeChallengeMethod,
+		Resource:             par.Resource,
+		AuthorizationDetails: par.AuthorizationDetails,
+		Scope:                par.Scope,
+		Nonce:                par.Nonce,
+		Prompt:               par.Prompt,
+	}, nil
+}
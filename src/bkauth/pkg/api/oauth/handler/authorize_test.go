@@ -21,6 +21,7 @@ package handler
 import (
 	"errors"
 	"net/http/httptest"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/mock/gomock"
@@ -185,6 +186,7 @@ var _ = Describe("AuthorizeRequest.Validate", func() {
 		publicClientID := "dcr_abc123def456"
 		publicFlowSpec := types.OAuthClientFlowSpec{
 			ID:           publicClientID,
+			Type:         oauth.ClientTypePublic,
 			GrantTypes:   []string{oauth.GrantTypeAuthorizationCode},
 			RedirectURIs: []string{"https://example.com/callback"},
 		}
@@ -199,6 +201,47 @@ var _ = Describe("AuthorizeRequest.Validate", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	It("should reject a resource not allowed by the client policy", func() {
+		spec := validFlowSpec
+		spec.Policy = oauth.ClientPolicy{AllowedResources: []string{"gateway:bk-paas:api:list_*"}}
+		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), "test-client").Return(spec, nil)
+
+		canRedirect, err := validReq.Validate(c, clientSvc)
+
+		Expect(canRedirect).To(BeTrue())
+		oauthErr, ok := oauth.AsOAuthError(err)
+		Expect(ok).To(BeTrue())
+		Expect(oauthErr.Code).To(Equal(oauth.ErrorCodeInvalidTarget))
+		Expect(oauthErr.Description).To(ContainSubstring("gateway:bk-paas:api:get_users"))
+	})
+
+	It("should reject a realm not allowed by the client policy", func() {
+		spec := validFlowSpec
+		spec.Policy = oauth.ClientPolicy{AllowedRealms: []string{"bk-devops"}}
+		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), "test-client").Return(spec, nil)
+
+		canRedirect, err := validReq.Validate(c, clientSvc)
+
+		Expect(canRedirect).To(BeTrue())
+		oauthErr, ok := oauth.AsOAuthError(err)
+		Expect(ok).To(BeTrue())
+		Expect(oauthErr.Code).To(Equal(oauth.ErrorCodeUnauthorizedClient))
+	})
+
+	It("should accept a resource allowed by the client policy", func() {
+		spec := validFlowSpec
+		spec.Policy = oauth.ClientPolicy{
+			AllowedRealms:    []string{blueking.Name},
+			AllowedResources: []string{"gateway:bk-paas:api:*"},
+		}
+		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), "test-client").Return(spec, nil)
+
+		canRedirect, err := validReq.Validate(c, clientSvc)
+
+		Expect(canRedirect).To(BeTrue())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
 	It("should reject empty code_challenge", func() {
 		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), "test-client").Return(validFlowSpec, nil)
 		validReq.CodeChallenge = ""
@@ -279,6 +322,115 @@ var _ = Describe("AuthorizeRequest.Validate", func() {
 		Expect(oauthErr.Description).To(ContainSubstring("resource"))
 	})
 
+	It("should merge the resources of authorization_details", func() {
+		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), "test-client").Return(validFlowSpec, nil)
+		validReq.Resource = ""
+		validReq.AuthorizationDetails = ` [{"type": "bk_gateway_api", "identifier": "bk-paas",
+			"actions": ["get_users"]}, {"type": "bk_mcp_server", "identifier": "bk-log"}]`
+
+		canRedirect, err := validReq.Validate(c, clientSvc)
+
+		Expect(canRedirect).To(BeTrue())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(validReq.Resource).To(Equal("gateway:bk-paas:api:get_users,mcp:bk-log"))
+		Expect(validReq.AuthorizationDetails).To(Equal(`[{"type":"bk_gateway_api","identifier":"bk-paas",` +
+			`"actions":["get_users"]},{"type":"bk_mcp_server","identifier":"bk-log"}]`))
+	})
+
+	It("should reject an unsupported authorization_details type", func() {
+		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), "test-client").Return(validFlowSpec, nil)
+		validReq.AuthorizationDetails = `[{"type": "payment_initiation"}]`
+
+		canRedirect, err := validReq.Validate(c, clientSvc)
+
+		Expect(canRedirect).To(BeTrue())
+		oauthErr, ok := oauth.AsOAuthError(err)
+		Expect(ok).To(BeTrue())
+		Expect(oauthErr.Code).To(Equal(oauth.ErrorCodeInvalidAuthorizationDetails))
+	})
+
+	It("should reject unsupported scope", func() {
+		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), "test-client").Return(validFlowSpec, nil)
+		validReq.Scope = "read admin"
+
+		canRedirect, err := validReq.Validate(c, clientSvc)
+
+		Expect(canRedirect).To(BeTrue())
+		Expect(err).To(HaveOccurred())
+		oauthErr, ok := oauth.AsOAuthError(err)
+		Expect(ok).To(BeTrue())
+		Expect(oauthErr.Code).To(Equal(oauth.ErrorCodeInvalidScope))
+		Expect(oauthErr.Description).To(ContainSubstring("admin"))
+	})
+
+	It("should normalize a valid scope", func() {
+		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), "test-client").Return(validFlowSpec, nil)
+		validReq.Scope = " write read  write"
+
+		canRedirect, err := validReq.Validate(c, clientSvc)
+
+		Expect(canRedirect).To(BeTrue())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(validReq.Scope).To(Equal("write read"))
+	})
+
+	It("should reject prompt=none combined with other values", func() {
+		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), "test-client").Return(validFlowSpec, nil)
+		validReq.Prompt = "none consent"
+
+		canRedirect, err := validReq.Validate(c, clientSvc)
+
+		Expect(canRedirect).To(BeTrue())
+		oauthErr, ok := oauth.AsOAuthError(err)
+		Expect(ok).To(BeTrue())
+		Expect(oauthErr.Code).To(Equal(oauth.ErrorCodeInvalidRequest))
+	})
+
+	It("should accept prompt=consent", func() {
+		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), "test-client").Return(validFlowSpec, nil)
+		validReq.Prompt = "consent"
+
+		canRedirect, err := validReq.Validate(c, clientSvc)
+
+		Expect(canRedirect).To(BeTrue())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject a nonce longer than the stored column", func() {
+		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), "test-client").Return(validFlowSpec, nil)
+		validReq.Nonce = strings.Repeat("n", oauth.MaxNonceLength+1)
+
+		canRedirect, err := validReq.Validate(c, clientSvc)
+
+		Expect(canRedirect).To(BeTrue())
+		oauthErr, ok := oauth.AsOAuthError(err)
+		Expect(ok).To(BeTrue())
+		Expect(oauthErr.Code).To(Equal(oauth.ErrorCodeInvalidRequest))
+	})
+
+	It("should accept a nonce of the maximum length", func() {
+		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), "test-client").Return(validFlowSpec, nil)
+		validReq.Nonce = strings.Repeat("n", oauth.MaxNonceLength)
+
+		canRedirect, err := validReq.Validate(c, clientSvc)
+
+		Expect(canRedirect).To(BeTrue())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject a resource longer than the stored column", func() {
+		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), "test-client").Return(validFlowSpec, nil)
+		validReq.Resource = "gateway:bk-paas:api:" + strings.Repeat("r", oauth.MaxResourceLength)
+
+		canRedirect, err := validReq.Validate(c, clientSvc)
+
+		Expect(canRedirect).To(BeTrue())
+		oauthErr, ok := oauth.AsOAuthError(err)
+		Expect(ok).To(BeTrue())
+		Expect(oauthErr.Code).To(Equal(oauth.ErrorCodeInvalidRequest))
+		Expect(oauthErr.Description).To(ContainSubstring("must not exceed"))
+	})
+
 	It("should pass with all valid parameters", func() {
 		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), "test-client").Return(validFlowSpec, nil)
 
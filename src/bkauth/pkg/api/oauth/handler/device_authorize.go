@@ -23,6 +23,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"bkauth/pkg/cache/impls"
 	"bkauth/pkg/config"
 	"bkauth/pkg/oauth"
 	"bkauth/pkg/service"
@@ -40,15 +41,21 @@ type DeviceAuthorizationResponse struct {
 
 // DeviceAuthorizeRequest represents the device authorization request (RFC 8628 Section 3.1)
 type DeviceAuthorizeRequest struct {
-	ClientID string `form:"client_id"`
-	Resource string `form:"resource"`
+	ClientID             string `form:"client_id"`
+	Resource             string `form:"resource"`
+	AuthorizationDetails string `form:"authorization_details"`
+	Scope                string `form:"scope"`
 }
 
 // Validate validates the device authorization request parameters.
 //
 // It checks:
 //   - The client exists and supports the device_code grant type.
+//   - The optional authorization_details parameter is valid for the given realm;
+//     the resource items it grants access to are merged into resource.
 //   - The resource parameter is present and valid for the given realm.
+//   - The realm and resource are allowed by the client policy.
+//   - The optional scope parameter is within the realm's scope catalog.
 //
 // clientSvc is injected by the caller so that Validate owns the full
 // validation flow while remaining testable via mock.
@@ -71,15 +78,31 @@ func (r *DeviceAuthorizeRequest) Validate(
 		)
 	}
 
+	realmName := util.GetRealmName(c)
+	realm := oauth.GetRealm(realmName)
+	r.Resource, r.AuthorizationDetails, err = resolveAuthorizationDetails(ctx, realm, r.Resource, r.AuthorizationDetails)
+	if err != nil {
+		return err
+	}
+	if err := validateResourceLength(r.Resource); err != nil {
+		return err
+	}
+
 	if r.Resource == "" {
 		return oauth.NewInvalidRequestError("resource is required")
 	}
-
-	realmName := util.GetRealmName(c)
-	realm := oauth.GetRealm(realmName)
 	if err := realm.ValidateResource(c.Request.Context(), r.Resource); err != nil {
 		return oauth.NewInvalidRequestError("Invalid resource parameter: " + err.Error())
 	}
+	if err := checkClientPolicy(flowSpec, realmName, r.Resource); err != nil {
+		return err
+	}
+
+	scope, err := oauth.ValidateScope(realm, r.Scope)
+	if err != nil {
+		return oauth.NewInvalidScopeError(err.Error())
+	}
+	r.Scope = scope
 
 	return nil
 }
@@ -95,7 +118,7 @@ func NewDeviceAuthorizeHandler(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		clientSvc := service.NewOAuthClientService()
+		clientSvc := impls.WrapOAuthClientService(service.NewOAuthClientService())
 		if err := req.Validate(c, clientSvc); err != nil {
 			oauthErr, ok := oauth.AsOAuthError(err)
 			if !ok {
@@ -110,8 +133,11 @@ func NewDeviceAuthorizeHandler(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		deviceCodeSvc := service.NewOAuthDeviceCodeService()
+		// Validate has normalized the details, parsing them back cannot fail
+		authorizationDetails, _ := oauth.ParseAuthorizationDetails(req.AuthorizationDetails)
 		dc, err := deviceCodeSvc.CreateDeviceCode(
-			c.Request.Context(), util.GetRealmName(c), util.GetClientID(c), req.Resource,
+			c.Request.Context(), util.GetRealmName(c), util.GetClientID(c), req.Resource, req.Scope,
+			authorizationDetails,
 		)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, oauth.NewServerError(
@@ -21,6 +21,7 @@ package handler
 import (
 	"errors"
 	"net/http/httptest"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/mock/gomock"
@@ -120,6 +121,18 @@ var _ = Describe("DeviceAuthorizeRequest.Validate", func() {
 		Expect(oauthErr.Description).To(ContainSubstring("resource"))
 	})
 
+	It("should reject a resource longer than the stored column", func() {
+		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), clientID).Return(validFlowSpec, nil)
+		validReq.Resource = "gateway:bk-paas:api:" + strings.Repeat("r", oauth.MaxResourceLength)
+
+		err := validReq.Validate(c, clientSvc)
+
+		oauthErr, ok := oauth.AsOAuthError(err)
+		Expect(ok).To(BeTrue())
+		Expect(oauthErr.Code).To(Equal(oauth.ErrorCodeInvalidRequest))
+		Expect(oauthErr.Description).To(ContainSubstring("must not exceed"))
+	})
+
 	It("should reject invalid resource format", func() {
 		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), clientID).Return(validFlowSpec, nil)
 		validReq.Resource = ":::invalid"
@@ -133,6 +146,46 @@ var _ = Describe("DeviceAuthorizeRequest.Validate", func() {
 		Expect(oauthErr.Description).To(ContainSubstring("resource"))
 	})
 
+	It("should reject a resource not allowed by the client policy", func() {
+		spec := validFlowSpec
+		spec.Policy = oauth.ClientPolicy{AllowedResources: []string{"mcp:*"}}
+		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), clientID).Return(spec, nil)
+
+		err := validReq.Validate(c, clientSvc)
+
+		Expect(err).To(HaveOccurred())
+		oauthErr, ok := oauth.AsOAuthError(err)
+		Expect(ok).To(BeTrue())
+		Expect(oauthErr.Code).To(Equal(oauth.ErrorCodeInvalidTarget))
+	})
+
+	It("should reject authorization_details not allowed by the client policy", func() {
+		spec := validFlowSpec
+		spec.Policy = oauth.ClientPolicy{AllowedResources: []string{"gateway:*"}}
+		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), clientID).Return(spec, nil)
+		validReq.Resource = ""
+		validReq.AuthorizationDetails = `[{"type":"bk_mcp_server","identifier":"bk-log"}]`
+
+		err := validReq.Validate(c, clientSvc)
+
+		Expect(err).To(HaveOccurred())
+		oauthErr, ok := oauth.AsOAuthError(err)
+		Expect(ok).To(BeTrue())
+		Expect(oauthErr.Code).To(Equal(oauth.ErrorCodeInvalidTarget))
+	})
+
+	It("should reject unsupported scope", func() {
+		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), clientID).Return(validFlowSpec, nil)
+		validReq.Scope = "admin"
+
+		err := validReq.Validate(c, clientSvc)
+
+		Expect(err).To(HaveOccurred())
+		oauthErr, ok := oauth.AsOAuthError(err)
+		Expect(ok).To(BeTrue())
+		Expect(oauthErr.Code).To(Equal(oauth.ErrorCodeInvalidScope))
+	})
+
 	It("should pass with all valid parameters", func() {
 		clientSvc.EXPECT().GetFlowSpec(gomock.Any(), clientID).Return(validFlowSpec, nil)
 
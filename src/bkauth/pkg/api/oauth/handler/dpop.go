@@ -0,0 +1,70 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"bkauth/pkg/cache/impls"
+	"bkauth/pkg/config"
+	"bkauth/pkg/oauth"
+	"bkauth/pkg/util"
+)
+
+// verifyDPoPRequest verifies the DPoP proof of a request to htu (RFC 9449 §4.3)
+// and records its jti so that the proof cannot be replayed. accessToken is the
+// token presented to a protected resource, empty at the token endpoint.
+// An invalid or replayed proof is an invalid_dpop_proof error; a failure to
+// record the jti is returned unwrapped.
+func verifyDPoPRequest(c *gin.Context, htu, accessToken string) (oauth.DPoPProof, error) {
+	proofs := c.Request.Header.Values(oauth.DPoPHeader)
+	if len(proofs) != 1 {
+		return oauth.DPoPProof{}, oauth.NewInvalidDPoPProofError("Exactly one DPoP proof is required")
+	}
+
+	proof, err := oauth.VerifyDPoPProof(proofs[0], c.Request.Method, htu, accessToken)
+	if err != nil {
+		return oauth.DPoPProof{}, oauth.NewInvalidDPoPProofError(err.Error())
+	}
+
+	firstUse, err := impls.ClaimDPoPProof(c.Request.Context(), proof.JKT, proof.JTI, 2*oauth.DPoPProofMaxAge)
+	if err != nil {
+		return oauth.DPoPProof{}, err
+	}
+	if !firstUse {
+		return oauth.DPoPProof{}, oauth.NewInvalidDPoPProofError("DPoP proof has already been used")
+	}
+
+	return proof, nil
+}
+
+// resolveDPoPConfirmation verifies the DPoP proof of a token request, if any,
+// and returns the key binding of the tokens to issue (zero for bearer tokens),
+// failing as verifyDPoPRequest does.
+func resolveDPoPConfirmation(c *gin.Context, cfg *config.Config) (oauth.Confirmation, error) {
+	if c.GetHeader(oauth.DPoPHeader) == "" {
+		return oauth.Confirmation{}, nil
+	}
+
+	proof, err := verifyDPoPRequest(c, oauth.TokenEndpointURL(cfg.BKAuthURL, util.GetRealmName(c)), "")
+	if err != nil {
+		return oauth.Confirmation{}, err
+	}
+	return oauth.Confirmation{JKT: proof.JKT}, nil
+}
@@ -20,11 +20,14 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"bkauth/pkg/cache/impls"
+	"bkauth/pkg/config"
 	"bkauth/pkg/oauth"
+	"bkauth/pkg/service"
 	"bkauth/pkg/service/types"
 	"bkauth/pkg/util"
 )
@@ -32,10 +35,9 @@ import (
 // IntrospectRequest represents a token introspection request
 type IntrospectRequest struct {
 	Token string `form:"token" json:"token" binding:"required"`
-	// NOTE: token_type_hint (RFC 7662 §2.1) is intentionally omitted for now.
-	// Currently only access_token introspection is supported; add this field
-	// when refresh_token introspection is needed to optimize lookup dispatch.
-	// TokenTypeHint string `form:"token_type_hint" json:"token_type_hint"`
+	// TokenTypeHint (RFC 7662 §2.1) only decides which token type is looked up first;
+	// the other type is still looked up when the token is not found, unknown hints are ignored.
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint"`
 }
 
 // IntrospectionError represents an error in introspection response
@@ -54,66 +56,200 @@ type IntrospectionResponse struct {
 	// Iat       int64              `json:"iat"`
 	// Nbf       int64              `json:"nbf"`
 	// Iss       string             `json:"iss"`
-	// JTI       string             `json:"jti"`
-	// Scope     string             `json:"scope"`
-	ClientID  string             `json:"client_id"`
-	BkAppCode string             `json:"bk_app_code"`
-	TenantID  string             `json:"tenant_id"`
-	Error     IntrospectionError `json:"error"`
+	// JTI matches the jti of the revocation events (see the revocations endpoint)
+	JTI   string `json:"jti,omitempty"`
+	Scope string `json:"scope,omitempty"`
+	// AuthorizationDetails lets the gateway enforce the approved APIs (RFC 9396 §9.2)
+	AuthorizationDetails []oauth.AuthorizationDetail `json:"authorization_details,omitempty"`
+	ClientID             string                      `json:"client_id"`
+	BkAppCode            string                      `json:"bk_app_code"`
+	TenantID             string                      `json:"tenant_id"`
+	GrantType            string                      `json:"grant_type,omitempty"`
+	Act                  *oauth.Actor                `json:"act,omitempty"`
+	Cnf                  *oauth.Confirmation         `json:"cnf,omitempty"`
+	Error                IntrospectionError          `json:"error"`
+}
+
+// RefreshTokenIntrospectionResponse represents the introspection response of a refresh token
+type RefreshTokenIntrospectionResponse struct {
+	Active        bool   `json:"active"`
+	TokenType     string `json:"token_type"`
+	Exp           int64  `json:"exp"`
+	ClientID      string `json:"client_id"`
+	GrantID       string `json:"grant_id"`
+	RotationCount int64  `json:"rotation_count"`
 }
 
 // NewIntrospectHandler creates a handler for the token introspection endpoint.
-// Authentication and per-realm authorization are handled by RealmAuthMiddleware.
-func NewIntrospectHandler() gin.HandlerFunc {
+// Authentication and per-realm authorization are handled by RealmAuthMiddleware;
+// each token type is only looked up when the app is allowed to introspect it.
+//
+// With Accept: application/token-introspection+jwt, the response is returned as a JWT
+// signed with the active signing key (RFC 9701), verifiable with the published JWKS.
+func NewIntrospectHandler(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx := c.Request.Context()
-
 		var req IntrospectRequest
 		if err := c.ShouldBind(&req); err != nil {
 			c.JSON(http.StatusBadRequest, oauth.NewInvalidRequestError("token is required"))
 			return
 		}
 
-		tokenHash := oauth.HashToken(req.Token)
-
-		token, err := impls.GetAccessTokenByTokenHash(ctx, tokenHash)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, oauth.NewServerError("failed to introspect token: "+err.Error()))
+		// JSON stays the default, also for Accept: */*
+		signed := c.NegotiateFormat(gin.MIMEJSON, oauth.ContentTypeTokenIntrospectionJWT) ==
+			oauth.ContentTypeTokenIntrospectionJWT
+		if _, ok := oauth.ActiveSigningKey(); signed && !ok {
+			c.JSON(http.StatusNotAcceptable, oauth.NewInvalidRequestError(
+				"Signed introspection responses are not enabled",
+			))
 			return
 		}
 
-		// not found (zero-value) or revoked/expired
-		if token.ClientID == "" || !token.IsActive() {
-			c.JSON(http.StatusOK, newInactiveIntrospectionResponse())
-			return
+		realmName := util.GetRealmName(c)
+		appCode := util.GetAccessAppCode(c)
+		tokenHashes := oauth.TokenHashCandidates(req.Token)
+
+		lookupOrder := []string{oauth.TokenTypeAccessToken, oauth.TokenTypeRefreshToken}
+		if req.TokenTypeHint == oauth.TokenTypeRefreshToken {
+			lookupOrder = []string{oauth.TokenTypeRefreshToken, oauth.TokenTypeAccessToken}
 		}
 
-		// RFC 7662: tokens not belonging to the requested realm are invisible
-		realmName := util.GetRealmName(c)
-		if token.RealmName != realmName {
-			c.JSON(http.StatusOK, newInactiveIntrospectionResponse())
-			return
+		for _, tokenType := range lookupOrder {
+			var (
+				resp  any
+				found bool
+				err   error
+			)
+			switch tokenType {
+			case oauth.TokenTypeAccessToken:
+				if !cfg.OAuth.IsIntrospectAllowed(realmName, appCode) {
+					continue
+				}
+				resp, found, err = introspectAccessToken(c, tokenHashes)
+			case oauth.TokenTypeRefreshToken:
+				if !cfg.OAuth.IsRefreshTokenIntrospectAllowed(realmName, appCode) {
+					continue
+				}
+				resp, found, err = introspectRefreshToken(c, cfg, tokenHashes)
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, oauth.NewServerError("failed to introspect token: "+err.Error()))
+				return
+			}
+			if found {
+				renderIntrospectionResponse(c, cfg, signed, resp)
+				return
+			}
 		}
 
-		c.JSON(http.StatusOK, newActiveIntrospectionResponse(token))
+		renderIntrospectionResponse(c, cfg, signed, newInactiveIntrospectionResponse())
 	}
 }
 
+// renderIntrospectionResponse writes the introspection response as JSON, or, when signed,
+// wrapped in a JWT issued by the realm to the calling app (RFC 9701 §5). The JWT is signed
+// with the active signing key, which is shared by every realm (see NewJWKSHandler).
+func renderIntrospectionResponse(c *gin.Context, cfg *config.Config, signed bool, resp any) {
+	if !signed {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	jwt, err := oauth.SignJWT(oauth.JWTTypeTokenIntrospection, oauth.TokenIntrospectionClaims{
+		Issuer:             oauth.IssuerURL(cfg.BKAuthURL, util.GetRealmName(c)),
+		Audience:           util.GetAccessAppCode(c),
+		IssuedAt:           time.Now().Unix(),
+		TokenIntrospection: resp,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, oauth.NewServerError("failed to sign introspection response"))
+		return
+	}
+	c.Data(http.StatusOK, oauth.ContentTypeTokenIntrospectionJWT, []byte(jwt))
+}
+
+// introspectAccessToken looks up the token as an access token and returns its
+// introspection response, or found=false when there is no such access token.
+func introspectAccessToken(c *gin.Context, tokenHashes []oauth.TokenHash) (any, bool, error) {
+	token, err := impls.GetAccessTokenByTokenHashes(c.Request.Context(), tokenHashes)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// not found (zero-value)
+	if token.ClientID == "" {
+		return nil, false, nil
+	}
+
+	// revoked/expired, or, per RFC 7662, invisible as it does not belong to the requested realm
+	if !token.IsActive() || token.RealmName != util.GetRealmName(c) {
+		return newInactiveIntrospectionResponse(), true, nil
+	}
+
+	return newActiveIntrospectionResponse(token), true, nil
+}
+
+// introspectRefreshToken looks up the token as a refresh token and returns its
+// introspection response, or found=false when there is no such refresh token.
+func introspectRefreshToken(c *gin.Context, cfg *config.Config, tokenHashes []oauth.TokenHash) (any, bool, error) {
+	tokenSvc := service.NewOAuthTokenService()
+	token, err := tokenSvc.GetRefreshTokenByTokenHashes(c.Request.Context(), tokenHashes)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if token.ClientID == "" {
+		return nil, false, nil
+	}
+
+	idleTimeout := cfg.OAuth.ResolveRefreshTokenIdleTimeout(token.RealmName, token.ClientID)
+	if !token.IsActive(idleTimeout) || token.RealmName != util.GetRealmName(c) {
+		return newInactiveIntrospectionResponse(), true, nil
+	}
+
+	return newRefreshTokenIntrospectionResponse(token, idleTimeout), true, nil
+}
+
 func newActiveIntrospectionResponse(token types.ResolvedAccessToken) IntrospectionResponse {
 	aud := token.Audience
 	if aud == nil {
 		aud = []string{}
 	}
 
-	return IntrospectionResponse{
+	resp := IntrospectionResponse{
 		Active:    true,
 		Username:  token.Username,
 		Sub:       token.Sub,
 		Exp:       token.ExpiresAt,
 		Aud:       aud,
+		JTI:       token.JTI,
+		Scope:     token.Scope,
 		ClientID:  token.ClientID,
 		BkAppCode: oauth.ResolveAppCode(token.ClientID),
 		TenantID:  token.TenantID,
+		GrantType: token.GrantType,
+		Act:       token.Act,
+
+		AuthorizationDetails: token.AuthorizationDetails,
+	}
+	// RFC 9449 §6.2: expose the key binding so resource servers can verify DPoP proofs
+	if !token.Cnf.IsZero() {
+		cnf := token.Cnf
+		resp.Cnf = &cnf
+	}
+
+	return resp
+}
+
+func newRefreshTokenIntrospectionResponse(
+	token types.ResolvedRefreshToken, idleTimeout int64,
+) RefreshTokenIntrospectionResponse {
+	return RefreshTokenIntrospectionResponse{
+		Active:        true,
+		TokenType:     oauth.TokenTypeRefreshToken,
+		Exp:           token.EffectiveExpiresAt(idleTimeout),
+		ClientID:      token.ClientID,
+		GrantID:       token.GrantID,
+		RotationCount: token.RotationCount,
 	}
 }
 
@@ -19,33 +19,117 @@
 package handler
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"bkauth/pkg/config"
 	"bkauth/pkg/oauth"
+	"bkauth/pkg/realm/blueking"
 	"bkauth/pkg/service/types"
+	"bkauth/pkg/util"
 )
 
 var _ = Describe("newActiveIntrospectionResponse", func() {
 	It("should map all fields from ResolvedAccessToken", func() {
 		token := types.ResolvedAccessToken{
+			JTI:       "jti-1",
 			ClientID:  "my-app",
 			RealmName: "blueking",
 			Sub:       "sub-1",
 			Username:  "admin",
 			Audience:  []string{"aud-1", "aud-2"},
+			Scope:     "read write",
 			ExpiresAt: 1700000000,
 		}
 
 		resp := newActiveIntrospectionResponse(token)
 
 		Expect(resp.Active).To(BeTrue())
+		Expect(resp.JTI).To(Equal("jti-1"))
 		Expect(resp.Username).To(Equal("admin"))
 		Expect(resp.Sub).To(Equal("sub-1"))
 		Expect(resp.Exp).To(Equal(int64(1700000000)))
 		Expect(resp.Aud).To(Equal([]string{"aud-1", "aud-2"}))
+		Expect(resp.Scope).To(Equal("read write"))
 		Expect(resp.ClientID).To(Equal("my-app"))
 		Expect(resp.BkAppCode).To(Equal("my-app"))
+		Expect(resp.GrantType).To(BeEmpty())
+		Expect(resp.Cnf).To(BeNil())
+	})
+
+	It("should mark client_credentials tokens with no subject", func() {
+		token := types.ResolvedAccessToken{
+			ClientID:  "my-app",
+			Audience:  []string{"aud-1"},
+			GrantType: oauth.GrantTypeClientCredentials,
+		}
+
+		resp := newActiveIntrospectionResponse(token)
+
+		Expect(resp.GrantType).To(Equal(oauth.GrantTypeClientCredentials))
+		Expect(resp.Sub).To(BeEmpty())
+		Expect(resp.Username).To(BeEmpty())
+	})
+
+	It("should expose the actor chain of exchanged tokens", func() {
+		token := types.ResolvedAccessToken{
+			ClientID:  "mcp-1",
+			Sub:       "sub-1",
+			GrantType: oauth.GrantTypeTokenExchange,
+			Act:       oauth.NewActor("mcp-1", nil),
+		}
+
+		resp := newActiveIntrospectionResponse(token)
+
+		Expect(resp.GrantType).To(Equal(oauth.GrantTypeTokenExchange))
+		Expect(resp.Act).To(Equal(&oauth.Actor{ClientID: "mcp-1"}))
+	})
+
+	It("should expose the approved authorization_details", func() {
+		details := []oauth.AuthorizationDetail{
+			{Type: "bk_gateway_api", Identifier: "bk-paas", Actions: []string{"get_users"}},
+		}
+		token := types.ResolvedAccessToken{
+			ClientID:             "my-app",
+			Audience:             []string{"gateway:bk-paas"},
+			AuthorizationDetails: details,
+		}
+
+		resp := newActiveIntrospectionResponse(token)
+
+		Expect(resp.AuthorizationDetails).To(Equal(details))
+	})
+
+	It("should expose the confirmation of DPoP-bound tokens", func() {
+		token := types.ResolvedAccessToken{
+			ClientID: "dcr_abc123",
+			Cnf:      oauth.Confirmation{JKT: "jkt-1"},
+		}
+
+		resp := newActiveIntrospectionResponse(token)
+
+		Expect(resp.Cnf).To(Equal(&oauth.Confirmation{JKT: "jkt-1"}))
+	})
+
+	It("should expose the confirmation of certificate-bound tokens", func() {
+		token := types.ResolvedAccessToken{
+			ClientID: "my-app",
+			Cnf:      oauth.Confirmation{X5TS256: "x5t-1"},
+		}
+
+		resp := newActiveIntrospectionResponse(token)
+
+		Expect(resp.Cnf).To(Equal(&oauth.Confirmation{X5TS256: "x5t-1"}))
 	})
 
 	It("should resolve BkAppCode to 'public' for DCR clients", func() {
@@ -59,6 +143,19 @@ var _ = Describe("newActiveIntrospectionResponse", func() {
 		Expect(resp.BkAppCode).To(Equal(oauth.PublicAppCode))
 	})
 
+	It("should resolve BkAppCode to 'private' for personal access tokens", func() {
+		token := types.ResolvedAccessToken{
+			ClientID:  oauth.PrivateAppCode,
+			GrantType: oauth.GrantTypePersonalAccessToken,
+			Audience:  []string{"mcp:foo"},
+		}
+
+		resp := newActiveIntrospectionResponse(token)
+
+		Expect(resp.BkAppCode).To(Equal("private"))
+		Expect(resp.GrantType).To(Equal(oauth.GrantTypePersonalAccessToken))
+	})
+
 	It("should default nil audience to empty slice", func() {
 		token := types.ResolvedAccessToken{
 			ClientID: "my-app",
@@ -70,6 +167,37 @@ var _ = Describe("newActiveIntrospectionResponse", func() {
 	})
 })
 
+var _ = Describe("newRefreshTokenIntrospectionResponse", func() {
+	It("should map the refresh token fields", func() {
+		token := types.ResolvedRefreshToken{
+			GrantID:         "grant-1",
+			ClientID:        "my-app",
+			RotationCount:   2,
+			ExpiresAt:       10000,
+			LastRefreshedAt: 1000,
+		}
+
+		resp := newRefreshTokenIntrospectionResponse(token, 0)
+
+		Expect(resp).To(Equal(RefreshTokenIntrospectionResponse{
+			Active:        true,
+			TokenType:     oauth.TokenTypeRefreshToken,
+			Exp:           10000,
+			ClientID:      "my-app",
+			GrantID:       "grant-1",
+			RotationCount: 2,
+		}))
+	})
+
+	It("should expire at the end of the idle timeout", func() {
+		token := types.ResolvedRefreshToken{ExpiresAt: 10000, LastRefreshedAt: 1000}
+
+		resp := newRefreshTokenIntrospectionResponse(token, 600)
+
+		Expect(resp.Exp).To(Equal(int64(1600)))
+	})
+})
+
 var _ = Describe("newInactiveIntrospectionResponse", func() {
 	It("should return inactive response with error details", func() {
 		resp := newInactiveIntrospectionResponse()
@@ -80,3 +208,70 @@ var _ = Describe("newInactiveIntrospectionResponse", func() {
 		Expect(resp.Error.Message).NotTo(BeEmpty())
 	})
 })
+
+var _ = Describe("NewIntrospectHandler", func() {
+	var w *httptest.ResponseRecorder
+
+	// the app is allowed no token type, so that no token is looked up
+	serve := func(accept string) {
+		gin.SetMode(gin.TestMode)
+		w = httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/introspect", strings.NewReader("token=abc"))
+		c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		c.Request.Header.Set("Accept", accept)
+		util.SetRealmName(c, blueking.Name)
+		util.SetAccessAppCode(c, "bk_apigateway")
+		NewIntrospectHandler(&config.Config{BKAuthURL: "https://bkauth.example.com"})(c)
+	}
+
+	BeforeEach(func() {
+		oauth.ResetSigningKeys()
+	})
+
+	AfterEach(func() {
+		oauth.ResetSigningKeys()
+	})
+
+	It("should respond with JSON by default", func() {
+		serve("*/*")
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(HavePrefix(gin.MIMEJSON))
+		Expect(w.Body.String()).To(ContainSubstring(`"active":false`))
+	})
+
+	It("should not accept a signed response without a signing key", func() {
+		serve(oauth.ContentTypeTokenIntrospectionJWT)
+
+		Expect(w.Code).To(Equal(http.StatusNotAcceptable))
+	})
+
+	It("should sign the response for the calling app with a key of the published JWKS", func() {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(oauth.RegisterSigningKey(oauth.SigningKey{KID: "introspect-test", PrivateKey: key})).To(Succeed())
+
+		serve(oauth.ContentTypeTokenIntrospectionJWT)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(HavePrefix(oauth.ContentTypeTokenIntrospectionJWT))
+
+		parsed, err := jwt.ParseSigned(w.Body.String(), []jose.SignatureAlgorithm{jose.ES256})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed.Headers[0].ExtraHeaders[jose.HeaderType]).To(Equal(oauth.JWTTypeTokenIntrospection))
+		jwks := oauth.PublishedJWKS()
+		publishedKeys := jwks.Key(parsed.Headers[0].KeyID)
+		Expect(publishedKeys).To(HaveLen(1))
+
+		var claims struct {
+			Issuer             string                `json:"iss"`
+			Audience           string                `json:"aud"`
+			TokenIntrospection IntrospectionResponse `json:"token_introspection"`
+		}
+		Expect(parsed.Claims(publishedKeys[0].Key, &claims)).To(Succeed())
+		Expect(claims.Issuer).To(Equal(oauth.IssuerURL("https://bkauth.example.com", blueking.Name)))
+		Expect(claims.Audience).To(Equal("bk_apigateway"))
+		Expect(claims.TokenIntrospection.Active).To(BeFalse())
+	})
+})
@@ -0,0 +1,45 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"bkauth/pkg/oauth"
+)
+
+// jwksCacheControl lets resource servers cache the key set briefly; a verifier
+// that meets an unknown kid is expected to refetch (RFC 7517 key rotation).
+const jwksCacheControl = "public, max-age=300"
+
+// NewJWKSHandler creates a handler for the JSON Web Key Set endpoint.
+// It publishes the public half of every signing key that may still have
+// unexpired tokens outstanding, including retired keys.
+//
+// There is no per-realm key: the JWT access tokens, id_tokens and signed introspection
+// responses of every realm are signed by the same keys, so every realm publishes the same
+// key set and verifiers tell the realms apart by the iss claim.
+func NewJWKSHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", jwksCacheControl)
+		c.JSON(http.StatusOK, oauth.PublishedJWKS())
+	}
+}
@@ -37,11 +37,45 @@ type AuthorizationServerMetadata struct {
 	IntrospectionEndpoint             string   `json:"introspection_endpoint,omitempty"`
 	RevocationEndpoint                string   `json:"revocation_endpoint,omitempty"`
 	RegistrationEndpoint              string   `json:"registration_endpoint,omitempty"`
+	JWKSURI                           string   `json:"jwks_uri,omitempty"`
+	ScopesSupported                   []string `json:"scopes_supported,omitempty"`
 	ResponseTypesSupported            []string `json:"response_types_supported"`
 	ResponseModesSupported            []string `json:"response_modes_supported"`
 	GrantTypesSupported               []string `json:"grant_types_supported"`
 	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
 	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+
+	// Pushed Authorization Requests (RFC 9126 §5)
+	PushedAuthorizationRequestEndpoint string `json:"pushed_authorization_request_endpoint,omitempty"`
+	RequirePushedAuthorizationRequests bool   `json:"require_pushed_authorization_requests,omitempty"`
+
+	// Rich Authorization Requests (RFC 9396 §10)
+	AuthorizationDetailsTypesSupported []string `json:"authorization_details_types_supported,omitempty"`
+
+	// DPoP (RFC 9449 §5.1)
+	DPoPSigningAlgValuesSupported []string `json:"dpop_signing_alg_values_supported,omitempty"`
+
+	// Signed introspection responses (RFC 9701 §7)
+	IntrospectionSigningAlgValuesSupported []string `json:"introspection_signing_alg_values_supported,omitempty"`
+
+	// Mutual-TLS (RFC 8705 §3.3)
+	TLSClientCertificateBoundAccessTokens bool `json:"tls_client_certificate_bound_access_tokens"`
+
+	// private_key_jwt (RFC 8414 §2)
+	TokenEndpointAuthSigningAlgValuesSupported []string `json:"token_endpoint_auth_signing_alg_values_supported,omitempty"`
+
+	// draft-ietf-oauth-client-id-metadata-document §5
+	ClientIDMetadataDocumentSupported bool `json:"client_id_metadata_document_supported"`
+}
+
+// OpenIDProviderMetadata represents OpenID Connect Discovery metadata
+// (OpenID Connect Discovery 1.0 §3), a superset of the RFC 8414 metadata.
+type OpenIDProviderMetadata struct {
+	AuthorizationServerMetadata
+	UserInfoEndpoint                 string   `json:"userinfo_endpoint"`
+	SubjectTypesSupported            []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+	ClaimsSupported                  []string `json:"claims_supported"`
 }
 
 // NewMetadataHandler creates a handler for authorization server metadata.
@@ -60,7 +94,47 @@ func NewDefaultRealmMetadataHandler(cfg *config.Config) gin.HandlerFunc {
 	}
 }
 
+// NewOpenIDConfigurationHandler creates a handler for OpenID Connect discovery.
+// Reads the realm from gin context (set by RealmMiddleware).
+func NewOpenIDConfigurationHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		renderOpenIDConfiguration(c, cfg, util.GetRealmName(c))
+	}
+}
+
+// NewDefaultRealmOpenIDConfigurationHandler creates an OpenID Connect discovery
+// handler that always uses the configured default realm.
+func NewDefaultRealmOpenIDConfigurationHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		renderOpenIDConfiguration(c, cfg, cfg.OAuth.DefaultRealmName)
+	}
+}
+
 func renderMetadata(c *gin.Context, cfg *config.Config, realm string) {
+	c.JSON(http.StatusOK, buildMetadata(cfg, realm))
+}
+
+// renderOpenIDConfiguration mirrors renderMetadata with the OpenID Connect additions.
+// OpenID Connect is only available once a signing key for id_tokens is configured.
+func renderOpenIDConfiguration(c *gin.Context, cfg *config.Config, realm string) {
+	key, ok := oauth.ActiveSigningKey()
+	if !ok {
+		c.JSON(http.StatusNotFound, oauth.NewInvalidRequestError("OpenID Connect is not enabled"))
+		return
+	}
+
+	c.JSON(http.StatusOK, OpenIDProviderMetadata{
+		AuthorizationServerMetadata:      buildMetadata(cfg, realm),
+		UserInfoEndpoint:                 oauth.UserInfoEndpointURL(cfg.BKAuthURL, realm),
+		SubjectTypesSupported:            []string{"public"},
+		IDTokenSigningAlgValuesSupported: []string{key.Algorithm},
+		ClaimsSupported: []string{
+			"iss", "sub", "aud", "exp", "iat", "nonce", "preferred_username", "tenant_id",
+		},
+	})
+}
+
+func buildMetadata(cfg *config.Config, realm string) AuthorizationServerMetadata {
 	base := cfg.BKAuthURL
 
 	metadata := AuthorizationServerMetadata{
@@ -75,6 +149,8 @@ func renderMetadata(c *gin.Context, cfg *config.Config, realm string) {
 		GrantTypesSupported: []string{
 			oauth.GrantTypeAuthorizationCode,
 			oauth.GrantTypeRefreshToken,
+			oauth.GrantTypeClientCredentials,
+			oauth.GrantTypeTokenExchange,
 			// NOTE: device_code grant type is not exposed in well-known metadata for now
 			// oauth.GrantTypeDeviceCode,
 		},
@@ -82,11 +158,50 @@ func renderMetadata(c *gin.Context, cfg *config.Config, realm string) {
 		TokenEndpointAuthMethodsSupported: []string{
 			oauth.AuthMethodNone, oauth.AuthMethodClientSecretBasic, oauth.AuthMethodClientSecretPost,
 		},
+		PushedAuthorizationRequestEndpoint: oauth.PushedAuthorizationRequestEndpointURL(base, realm),
+		// Realm-wide requirement only, per-client requirements are enforced by /authorize.
+		RequirePushedAuthorizationRequests: cfg.OAuth.IsPARRequired(realm, "*"),
+		DPoPSigningAlgValuesSupported:      oauth.DPoPSigningAlgorithms,
 	}
 
+	// RFC 8705: client certificates are only seen on a TLS connection to BKAuth itself or in the
+	// header of the TLS-terminating ingress; tls_client_auth also needs trusted CAs, self-signed
+	// certificates are trusted by registration
+	if cfg.Server.TLS.Enabled || cfg.OAuth.MutualTLS.ClientCertificateHeader != "" {
+		metadata.TLSClientCertificateBoundAccessTokens = true
+		if cfg.OAuth.MutualTLS.TrustedCAs != "" {
+			metadata.TokenEndpointAuthMethodsSupported = append(
+				metadata.TokenEndpointAuthMethodsSupported, oauth.AuthMethodTLSClientAuth,
+			)
+		}
+		metadata.TokenEndpointAuthMethodsSupported = append(
+			metadata.TokenEndpointAuthMethodsSupported, oauth.AuthMethodSelfSignedTLSClientAuth,
+		)
+	}
+
+	metadata.TokenEndpointAuthMethodsSupported = append(
+		metadata.TokenEndpointAuthMethodsSupported, oauth.AuthMethodPrivateKeyJWT,
+	)
+	metadata.TokenEndpointAuthSigningAlgValuesSupported = oauth.ClientAssertionSigningAlgorithms
+
+	metadata.ClientIDMetadataDocumentSupported = true
+
 	if cfg.OAuth.DCREnabled {
 		metadata.RegistrationEndpoint = oauth.RegistrationEndpointURL(base, realm)
 	}
 
-	c.JSON(http.StatusOK, metadata)
+	if r := oauth.GetRealm(realm); r != nil {
+		metadata.ScopesSupported = oauth.ScopeNames(r)
+		metadata.AuthorizationDetailsTypesSupported = r.AuthorizationDetailsTypes()
+	}
+
+	// the key set is shared by every realm, see NewJWKSHandler
+	if len(oauth.PublishedJWKS().Keys) > 0 {
+		metadata.JWKSURI = oauth.JWKSURL(base, realm)
+	}
+	if key, ok := oauth.ActiveSigningKey(); ok {
+		metadata.IntrospectionSigningAlgValuesSupported = []string{key.Algorithm}
+	}
+
+	return metadata
 }
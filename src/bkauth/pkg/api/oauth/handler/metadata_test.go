@@ -52,21 +52,34 @@ var _ = Describe("renderMetadata", func() {
 		Expect(m.DeviceAuthorizationEndpoint).To(Equal(oauth.DeviceAuthorizationEndpointURL(base, realmName)))
 		Expect(m.IntrospectionEndpoint).To(Equal(oauth.IntrospectionEndpointURL(base, realmName)))
 		Expect(m.RevocationEndpoint).To(Equal(oauth.RevocationEndpointURL(base, realmName)))
+		Expect(m.PushedAuthorizationRequestEndpoint).To(Equal(
+			oauth.PushedAuthorizationRequestEndpointURL(base, realmName),
+		))
+		Expect(m.RequirePushedAuthorizationRequests).To(BeFalse())
+		Expect(m.DPoPSigningAlgValuesSupported).To(Equal(oauth.DPoPSigningAlgorithms))
 
 		Expect(m.ResponseTypesSupported).To(Equal([]string{oauth.ResponseTypeCode}))
 		Expect(m.ResponseModesSupported).To(Equal([]string{oauth.ResponseModeQuery}))
 		Expect(m.GrantTypesSupported).To(Equal([]string{
 			oauth.GrantTypeAuthorizationCode,
 			oauth.GrantTypeRefreshToken,
+			oauth.GrantTypeClientCredentials,
+			oauth.GrantTypeTokenExchange,
 		}))
 		Expect(m.CodeChallengeMethodsSupported).To(Equal([]string{oauth.CodeChallengeMethodS256}))
 		Expect(m.TokenEndpointAuthMethodsSupported).To(Equal([]string{
 			oauth.AuthMethodNone,
 			oauth.AuthMethodClientSecretBasic,
 			oauth.AuthMethodClientSecretPost,
+			oauth.AuthMethodPrivateKeyJWT,
 		}))
+		Expect(m.TokenEndpointAuthSigningAlgValuesSupported).To(Equal(oauth.ClientAssertionSigningAlgorithms))
+		Expect(m.ClientIDMetadataDocumentSupported).To(BeTrue())
+		Expect(m.TLSClientCertificateBoundAccessTokens).To(BeFalse())
 
 		Expect(m.RegistrationEndpoint).To(BeEmpty())
+		Expect(m.JWKSURI).To(BeEmpty())
+		Expect(m.ScopesSupported).To(Equal([]string{"read", "write"}))
 	})
 
 	It("should include registration endpoint when DCR is enabled", func() {
@@ -81,6 +94,38 @@ var _ = Describe("renderMetadata", func() {
 		))
 	})
 
+	It("should advertise mutual-TLS when the ingress forwards the client certificate", func() {
+		cfg.OAuth.MutualTLS.ClientCertificateHeader = "X-Client-Cert"
+		renderMetadata(c, cfg, "blueking")
+
+		m := parseBody()
+		Expect(m.TLSClientCertificateBoundAccessTokens).To(BeTrue())
+		Expect(m.TokenEndpointAuthMethodsSupported).To(ContainElement(oauth.AuthMethodSelfSignedTLSClientAuth))
+		Expect(m.TokenEndpointAuthMethodsSupported).NotTo(ContainElement(oauth.AuthMethodTLSClientAuth))
+	})
+
+	It("should advertise tls_client_auth when TLS is enabled and trusted CAs are configured", func() {
+		cfg.Server.TLS.Enabled = true
+		cfg.OAuth.MutualTLS.TrustedCAs = "-----BEGIN CERTIFICATE-----"
+		renderMetadata(c, cfg, "blueking")
+
+		m := parseBody()
+		Expect(m.TLSClientCertificateBoundAccessTokens).To(BeTrue())
+		Expect(m.TokenEndpointAuthMethodsSupported).To(ContainElements(
+			oauth.AuthMethodTLSClientAuth, oauth.AuthMethodSelfSignedTLSClientAuth,
+		))
+	})
+
+	It("should not advertise tls_client_auth without a client certificate source", func() {
+		cfg.OAuth.MutualTLS.TrustedCAs = "-----BEGIN CERTIFICATE-----"
+		renderMetadata(c, cfg, "blueking")
+
+		m := parseBody()
+		Expect(m.TLSClientCertificateBoundAccessTokens).To(BeFalse())
+		Expect(m.TokenEndpointAuthMethodsSupported).NotTo(ContainElement(oauth.AuthMethodTLSClientAuth))
+		Expect(m.TokenEndpointAuthMethodsSupported).NotTo(ContainElement(oauth.AuthMethodSelfSignedTLSClientAuth))
+	})
+
 	It("should build URLs for a different realm", func() {
 		renderMetadata(c, cfg, "devops")
 
@@ -88,4 +133,10 @@ var _ = Describe("renderMetadata", func() {
 		Expect(m.Issuer).To(Equal(oauth.IssuerURL("https://bkauth.example.com", "devops")))
 		Expect(m.TokenEndpoint).To(Equal(oauth.TokenEndpointURL("https://bkauth.example.com", "devops")))
 	})
+
+	It("should not serve openid-configuration without a signing key", func() {
+		renderOpenIDConfiguration(c, cfg, "blueking")
+
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
 })
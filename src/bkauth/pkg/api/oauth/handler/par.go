@@ -0,0 +1,113 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"bkauth/pkg/cache/impls"
+	"bkauth/pkg/oauth"
+	"bkauth/pkg/service"
+	"bkauth/pkg/util"
+)
+
+// PushedAuthorizationResponse is the response for a pushed authorization request (RFC 9126 §2.2)
+type PushedAuthorizationResponse struct {
+	RequestURI string `json:"request_uri"`
+	ExpiresIn  int64  `json:"expires_in"`
+}
+
+// NewPushedAuthorizationRequestHandler creates a handler for the pushed authorization
+// request endpoint (RFC 9126). Client authentication is handled by ClientAuthMiddleware;
+// the authenticated client_id is available via util.GetClientID(c).
+//
+// The request body carries the same parameters as /authorize and is validated by
+// AuthorizeRequest.Validate. Since the client calls this endpoint directly, all
+// errors are returned as JSON, the redirect_uri is never used for error delivery.
+func NewPushedAuthorizationRequestHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req AuthorizeRequest
+		if err := c.ShouldBind(&req); err != nil {
+			c.JSON(http.StatusBadRequest, oauth.NewInvalidRequestError(util.ValidationErrorMessage(err)))
+			return
+		}
+
+		// RFC 9126 §2.1: request_uri MUST NOT be provided in a pushed authorization request.
+		if req.RequestURI != "" {
+			c.JSON(http.StatusBadRequest, oauth.NewInvalidRequestError(
+				"request_uri is not allowed in a pushed authorization request",
+			))
+			return
+		}
+		// client_id may be presented via Basic Auth only; use the authenticated one.
+		req.ClientID = util.GetClientID(c)
+
+		clientSvc := impls.WrapOAuthClientService(service.NewOAuthClientService())
+		if _, err := req.Validate(c, clientSvc); err != nil {
+			oauthErr, ok := oauth.AsOAuthError(err)
+			if !ok {
+				oauthErr = oauth.NewServerError(err.Error())
+			}
+			status := http.StatusBadRequest
+			if !ok {
+				status = http.StatusInternalServerError
+			}
+			c.JSON(status, oauthErr)
+			return
+		}
+
+		requestURI, err := oauth.GenerateRequestURI()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, oauth.NewServerError("Failed to generate request_uri"))
+			return
+		}
+
+		par := impls.PushedAuthorizationRequest{
+			RealmName:            util.GetRealmName(c),
+			ClientID:             req.ClientID,
+			RedirectURI:          req.RedirectURI,
+			ResponseType:         req.ResponseType,
+			State:                req.State,
+			CodeChallenge:        req.CodeChallenge,
+			CodeChallengeMethod:  req.CodeChallengeMethod,
+			Resource:             req.Resource,
+			AuthorizationDetails: req.AuthorizationDetails,
+			Scope:                req.Scope,
+			Nonce:                req.Nonce,
+			Prompt:               req.Prompt,
+		}
+		err = impls.CreatePushedAuthorizationRequest(
+			c.Request.Context(), requestURI, par, oauth.PushedAuthorizationRequestTTL,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, oauth.NewServerError(
+				"Failed to store pushed authorization request",
+			))
+			return
+		}
+
+		// RFC 9126 §2.2: successful response uses HTTP 201 Created.
+		c.JSON(http.StatusCreated, PushedAuthorizationResponse{
+			RequestURI: requestURI,
+			ExpiresIn:  oauth.PushedAuthorizationRequestTTL,
+		})
+	}
+}
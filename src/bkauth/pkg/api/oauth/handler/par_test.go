@@ -0,0 +1,112 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package handler
+
+import (
+	"context"
+	"net/http/httptest"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/gin-gonic/gin"
+	goredis "github.com/go-redis/redis/v8"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"bkauth/pkg/cache/impls"
+	"bkauth/pkg/cache/redis"
+	"bkauth/pkg/oauth"
+	"bkauth/pkg/realm/blueking"
+	"bkauth/pkg/util"
+)
+
+var _ = Describe("loadPushedAuthorizationRequest", func() {
+	var c *gin.Context
+
+	requestURI := "urn:ietf:params:oauth:request_uri:abc"
+	pushed := impls.PushedAuthorizationRequest{
+		RealmName:           blueking.Name,
+		ClientID:            "test-client",
+		RedirectURI:         "https://example.com/callback",
+		ResponseType:        oauth.ResponseTypeCode,
+		State:               "random-state",
+		CodeChallenge:       "challenge123",
+		CodeChallengeMethod: oauth.CodeChallengeMethodS256,
+		Resource:            "gateway:bk-paas:api:get_users",
+		Scope:               "read",
+	}
+
+	BeforeEach(func() {
+		mr, err := miniredis.Run()
+		Expect(err).NotTo(HaveOccurred())
+		impls.PushedAuthorizationRequestCache = redis.NewMockCache(
+			goredis.NewClient(&goredis.Options{Addr: mr.Addr()}), "oauth_par", 5*time.Minute,
+		)
+
+		w := httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/authorize", nil)
+		util.SetRealmName(c, blueking.Name)
+
+		err = impls.CreatePushedAuthorizationRequest(context.Background(), requestURI, pushed, 60)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should require client_id", func() {
+		_, err := loadPushedAuthorizationRequest(c, "", requestURI)
+
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code).To(Equal(oauth.ErrorCodeInvalidRequest))
+	})
+
+	It("should reject an unknown request_uri", func() {
+		_, err := loadPushedAuthorizationRequest(c, "test-client", "urn:ietf:params:oauth:request_uri:none")
+
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code).To(Equal(oauth.ErrorCodeInvalidRequestURI))
+	})
+
+	It("should reject a request_uri pushed by another client", func() {
+		_, err := loadPushedAuthorizationRequest(c, "other-client", requestURI)
+
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code).To(Equal(oauth.ErrorCodeInvalidRequestURI))
+	})
+
+	It("should rebuild the authorize request and consume the request_uri", func() {
+		req, err := loadPushedAuthorizationRequest(c, "test-client", requestURI)
+
+		Expect(err).To(BeNil())
+		Expect(req).To(Equal(AuthorizeRequest{
+			ClientID:            "test-client",
+			RedirectURI:         "https://example.com/callback",
+			ResponseType:        oauth.ResponseTypeCode,
+			State:               "random-state",
+			CodeChallenge:       "challenge123",
+			CodeChallengeMethod: oauth.CodeChallengeMethodS256,
+			Resource:            "gateway:bk-paas:api:get_users",
+			Scope:               "read",
+		}))
+
+		_, err = loadPushedAuthorizationRequest(c, "test-client", requestURI)
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code).To(Equal(oauth.ErrorCodeInvalidRequestURI))
+	})
+})
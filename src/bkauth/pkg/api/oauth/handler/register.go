@@ -19,6 +19,7 @@
 package handler
 
 import (
+	"encoding/json"
 	"net/http"
 	"strings"
 
@@ -38,6 +39,11 @@ type ClientRegistrationRequest struct {
 	RedirectURIs []string `json:"redirect_uris" binding:"required,min=1"`
 	GrantTypes   []string `json:"grant_types,omitempty"`
 	LogoURI      string   `json:"logo_uri,omitempty" binding:"omitempty,max=512"`
+
+	// RFC 7591 §2: none (default) or private_key_jwt with one of jwks / jwks_uri
+	TokenEndpointAuthMethod string          `json:"token_endpoint_auth_method,omitempty"`
+	JWKS                    json.RawMessage `json:"jwks,omitempty"`
+	JWKSURI                 string          `json:"jwks_uri,omitempty" binding:"omitempty,max=512"`
 }
 
 // Validate performs business-level validation beyond struct tags
@@ -57,6 +63,13 @@ func (r *ClientRegistrationRequest) Validate() error {
 	if err := oauth.ValidateGrantTypes(r.GrantTypes); err != nil {
 		return oauth.NewInvalidClientMetadataError(err.Error())
 	}
+	// dynamically registered clients are public and cannot authenticate,
+	// so they must not be granted confidential-only grants (e.g. RFC 6749 §4.4)
+	for _, gt := range r.GrantTypes {
+		if _, ok := oauth.ConfidentialGrantTypes[gt]; ok {
+			return oauth.NewInvalidClientMetadataError(gt + " is not allowed for public clients")
+		}
+	}
 	r.GrantTypes = util.Deduplicate(r.GrantTypes)
 
 	if r.LogoURI != "" {
@@ -72,6 +85,36 @@ func (r *ClientRegistrationRequest) Validate() error {
 	}
 	r.RedirectURIs = util.Deduplicate(r.RedirectURIs)
 
+	return r.validateAuthMethod()
+}
+
+func (r *ClientRegistrationRequest) validateAuthMethod() error {
+	switch r.TokenEndpointAuthMethod {
+	case "", oauth.AuthMethodNone:
+		if len(r.JWKS) > 0 || r.JWKSURI != "" {
+			return oauth.NewInvalidClientMetadataError("jwks and jwks_uri require token_endpoint_auth_method=private_key_jwt")
+		}
+		return nil
+	case oauth.AuthMethodPrivateKeyJWT:
+	default:
+		return oauth.NewInvalidClientMetadataError(
+			"unsupported token_endpoint_auth_method: " + r.TokenEndpointAuthMethod,
+		)
+	}
+
+	// RFC 7591 §2: jwks and jwks_uri MUST NOT both be present
+	if (len(r.JWKS) > 0) == (r.JWKSURI != "") {
+		return oauth.NewInvalidClientMetadataError("exactly one of jwks and jwks_uri is required for private_key_jwt")
+	}
+	if r.JWKSURI != "" {
+		if err := oauth.ValidateJWKSURI(r.JWKSURI); err != nil {
+			return oauth.NewInvalidClientMetadataError(err.Error())
+		}
+		return nil
+	}
+	if err := oauth.ValidateJWKS(string(r.JWKS)); err != nil {
+		return oauth.NewInvalidClientMetadataError(err.Error())
+	}
 	return nil
 }
 
@@ -84,13 +127,66 @@ type ClientRegistrationResponse struct {
 	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
 	LogoURI                 string   `json:"logo_uri,omitempty"`
 	ClientIDIssuedAt        int64    `json:"client_id_issued_at"`
+
+	JWKS    json.RawMessage `json:"jwks,omitempty"`
+	JWKSURI string          `json:"jwks_uri,omitempty"`
+
+	// RFC 7592 §3: the registration access token is only returned on registration
+	RegistrationAccessToken string `json:"registration_access_token,omitempty"`
+	RegistrationClientURI   string `json:"registration_client_uri"`
+}
+
+func newClientRegistrationResponse(client types.OAuthClient, registrationClientURI string) ClientRegistrationResponse {
+	resp := ClientRegistrationResponse{
+		ClientID:                client.ID,
+		ClientName:              client.Name,
+		RedirectURIs:            client.RedirectURIs,
+		GrantTypes:              client.GrantTypes,
+		TokenEndpointAuthMethod: client.TokenEndpointAuthMethod(),
+		LogoURI:                 client.LogoURI,
+		ClientIDIssuedAt:        client.CreatedAt,
+		JWKSURI:                 client.JWKSURI,
+		RegistrationAccessToken: client.RegistrationAccessToken,
+		RegistrationClientURI:   registrationClientURI,
+	}
+	if client.JWKS != "" {
+		resp.JWKS = json.RawMessage(client.JWKS)
+	}
+	return resp
+}
+
+// ClientUpdateRequest represents a client update request (RFC 7592 §2.2): the full
+// registration replaces the current one, and client_id must be the one being updated.
+type ClientUpdateRequest struct {
+	ClientID string `json:"client_id" binding:"required"`
+	ClientRegistrationRequest
+}
+
+func (r ClientRegistrationRequest) toInput() types.OAuthClientDynamicRegistrationInput {
+	return types.OAuthClientDynamicRegistrationInput{
+		Name:         r.ClientName,
+		RedirectURIs: r.RedirectURIs,
+		GrantTypes:   r.GrantTypes,
+		LogoURI:      r.LogoURI,
+
+		TokenEndpointAuthMethod: r.TokenEndpointAuthMethod,
+		JWKS:                    string(r.JWKS),
+		JWKSURI:                 r.JWKSURI,
+	}
+}
+
+func rejectIfDCRDisabled(c *gin.Context, cfg *config.Config) bool {
+	if cfg.OAuth.DCREnabled {
+		return false
+	}
+	c.JSON(http.StatusForbidden, oauth.NewInvalidRequestError("Dynamic Client Registration is disabled"))
+	return true
 }
 
 // NewRegisterHandler creates a handler for Dynamic Client Registration
 func NewRegisterHandler(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if !cfg.OAuth.DCREnabled {
-			c.JSON(http.StatusForbidden, oauth.NewInvalidRequestError("Dynamic Client Registration is disabled"))
+		if rejectIfDCRDisabled(c, cfg) {
 			return
 		}
 
@@ -111,28 +207,100 @@ func NewRegisterHandler(cfg *config.Config) gin.HandlerFunc {
 
 		ctx := c.Request.Context()
 		svc := service.NewOAuthClientService()
-		input := types.OAuthClientDynamicRegistrationInput{
-			Name:         req.ClientName,
-			RedirectURIs: req.RedirectURIs,
-			GrantTypes:   req.GrantTypes,
-			LogoURI:      req.LogoURI,
+		registeredClient, err := svc.DynamicRegister(ctx, req.toInput())
+		if err != nil {
+			err = errorx.Wrapf(err, "Handler", "DynamicRegister", "svc.DynamicRegister fail")
+			c.JSON(http.StatusInternalServerError, oauth.NewServerError(err.Error()))
+			return
 		}
 
-		registeredClient, err := svc.DynamicRegister(ctx, input)
+		registrationClientURI := oauth.RegistrationClientURL(cfg.BKAuthURL, util.GetRealmName(c), registeredClient.ID)
+		c.JSON(http.StatusCreated, newClientRegistrationResponse(registeredClient, registrationClientURI))
+	}
+}
+
+// NewGetRegistrationHandler creates a handler that reads the current registration
+// of the client authenticated by RegistrationAuthMiddleware (RFC 7592 §2.1)
+func NewGetRegistrationHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rejectIfDCRDisabled(c, cfg) {
+			return
+		}
+
+		clientID := util.GetClientID(c)
+		client, err := service.NewOAuthClientService().Get(c.Request.Context(), clientID)
 		if err != nil {
-			err = errorx.Wrapf(err, "Handler", "DynamicRegister", "svc.DynamicRegister fail")
+			err = errorx.Wrapf(err, "Handler", "GetRegistration", "svc.Get fail")
+			c.JSON(http.StatusInternalServerError, oauth.NewServerError(err.Error()))
+			return
+		}
+		if client.ID == "" {
+			c.JSON(http.StatusUnauthorized, oauth.NewInvalidTokenError("Client not found"))
+			return
+		}
+
+		registrationClientURI := oauth.RegistrationClientURL(cfg.BKAuthURL, util.GetRealmName(c), clientID)
+		c.JSON(http.StatusOK, newClientRegistrationResponse(client, registrationClientURI))
+	}
+}
+
+// NewUpdateRegistrationHandler creates a handler that replaces the registration
+// of the client authenticated by RegistrationAuthMiddleware (RFC 7592 §2.2)
+func NewUpdateRegistrationHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rejectIfDCRDisabled(c, cfg) {
+			return
+		}
+
+		var req ClientUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, oauth.NewInvalidRequestError(util.ValidationErrorMessage(err)))
+			return
+		}
+
+		clientID := util.GetClientID(c)
+		if req.ClientID != clientID {
+			c.JSON(http.StatusBadRequest, oauth.NewInvalidRequestError("client_id does not match the registration"))
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			if oauthErr, ok := oauth.AsOAuthError(err); ok {
+				c.JSON(http.StatusBadRequest, oauthErr)
+				return
+			}
+			c.JSON(http.StatusInternalServerError, oauth.NewServerError(err.Error()))
+			return
+		}
+
+		svc := service.NewOAuthClientService()
+		updatedClient, err := svc.DynamicUpdate(c.Request.Context(), clientID, req.toInput())
+		if err != nil {
+			err = errorx.Wrapf(err, "Handler", "UpdateRegistration", "svc.DynamicUpdate fail")
+			c.JSON(http.StatusInternalServerError, oauth.NewServerError(err.Error()))
+			return
+		}
+
+		registrationClientURI := oauth.RegistrationClientURL(cfg.BKAuthURL, util.GetRealmName(c), clientID)
+		c.JSON(http.StatusOK, newClientRegistrationResponse(updatedClient, registrationClientURI))
+	}
+}
+
+// NewDeleteRegistrationHandler creates a handler that deletes the client authenticated
+// by RegistrationAuthMiddleware and revokes all of its tokens (RFC 7592 §2.3)
+func NewDeleteRegistrationHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rejectIfDCRDisabled(c, cfg) {
+			return
+		}
+
+		svc := service.NewOAuthClientService()
+		if err := svc.Delete(c.Request.Context(), util.GetClientID(c)); err != nil {
+			err = errorx.Wrapf(err, "Handler", "DeleteRegistration", "svc.Delete fail")
 			c.JSON(http.StatusInternalServerError, oauth.NewServerError(err.Error()))
 			return
 		}
 
-		c.JSON(http.StatusCreated, ClientRegistrationResponse{
-			ClientID:                registeredClient.ID,
-			ClientName:              registeredClient.Name,
-			RedirectURIs:            registeredClient.RedirectURIs,
-			GrantTypes:              registeredClient.GrantTypes,
-			TokenEndpointAuthMethod: registeredClient.TokenEndpointAuthMethod(),
-			LogoURI:                 registeredClient.LogoURI,
-			ClientIDIssuedAt:        registeredClient.CreatedAt,
-		})
+		c.Status(http.StatusNoContent)
 	}
 }
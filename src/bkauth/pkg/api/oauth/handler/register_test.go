@@ -23,6 +23,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"bkauth/pkg/oauth"
+	"bkauth/pkg/service/types"
 )
 
 var _ = Describe("ClientRegistrationRequest.Validate", func() {
@@ -70,6 +71,21 @@ var _ = Describe("ClientRegistrationRequest.Validate", func() {
 		Expect(oauthErr.Code).To(Equal(oauth.ErrorCodeInvalidClientMetadata))
 	})
 
+	It("should reject client_credentials for public clients", func() {
+		req := ClientRegistrationRequest{
+			ClientName:   "My App",
+			RedirectURIs: []string{"https://example.com/cb"},
+			GrantTypes:   []string{oauth.GrantTypeAuthorizationCode, oauth.GrantTypeClientCredentials},
+		}
+
+		err := req.Validate()
+
+		Expect(err).To(HaveOccurred())
+		oauthErr, ok := oauth.AsOAuthError(err)
+		Expect(ok).To(BeTrue())
+		Expect(oauthErr.Code).To(Equal(oauth.ErrorCodeInvalidClientMetadata))
+	})
+
 	It("should deduplicate grant_types", func() {
 		req := ClientRegistrationRequest{
 			ClientName:   "My App",
@@ -124,6 +140,75 @@ var _ = Describe("ClientRegistrationRequest.Validate", func() {
 		Expect(req.RedirectURIs).To(Equal([]string{"https://example.com/cb"}))
 	})
 
+	It("should accept private_key_jwt with jwks_uri", func() {
+		req := ClientRegistrationRequest{
+			ClientName:              "My App",
+			RedirectURIs:            []string{"https://example.com/cb"},
+			TokenEndpointAuthMethod: oauth.AuthMethodPrivateKeyJWT,
+			JWKSURI:                 "https://example.com/jwks.json",
+		}
+
+		err := req.Validate()
+
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject private_key_jwt without jwks or jwks_uri", func() {
+		req := ClientRegistrationRequest{
+			ClientName:              "My App",
+			RedirectURIs:            []string{"https://example.com/cb"},
+			TokenEndpointAuthMethod: oauth.AuthMethodPrivateKeyJWT,
+		}
+
+		err := req.Validate()
+
+		Expect(err).To(HaveOccurred())
+		oauthErr, ok := oauth.AsOAuthError(err)
+		Expect(ok).To(BeTrue())
+		Expect(oauthErr.Code).To(Equal(oauth.ErrorCodeInvalidClientMetadata))
+	})
+
+	It("should reject private_key_jwt with both jwks and jwks_uri", func() {
+		req := ClientRegistrationRequest{
+			ClientName:              "My App",
+			RedirectURIs:            []string{"https://example.com/cb"},
+			TokenEndpointAuthMethod: oauth.AuthMethodPrivateKeyJWT,
+			JWKS:                    []byte(`{"keys":[]}`),
+			JWKSURI:                 "https://example.com/jwks.json",
+		}
+
+		err := req.Validate()
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject jwks_uri without private_key_jwt", func() {
+		req := ClientRegistrationRequest{
+			ClientName:   "My App",
+			RedirectURIs: []string{"https://example.com/cb"},
+			JWKSURI:      "https://example.com/jwks.json",
+		}
+
+		err := req.Validate()
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject unsupported token_endpoint_auth_method", func() {
+		req := ClientRegistrationRequest{
+			ClientName:              "My App",
+			RedirectURIs:            []string{"https://example.com/cb"},
+			TokenEndpointAuthMethod: oauth.AuthMethodClientSecretBasic,
+		}
+
+		err := req.Validate()
+
+		Expect(err).To(HaveOccurred())
+		oauthErr, ok := oauth.AsOAuthError(err)
+		Expect(ok).To(BeTrue())
+		Expect(oauthErr.Code).To(Equal(oauth.ErrorCodeInvalidClientMetadata))
+	})
+
 	It("should pass with valid input", func() {
 		req := ClientRegistrationRequest{
 			ClientName:   "My App",
@@ -137,3 +222,24 @@ var _ = Describe("ClientRegistrationRequest.Validate", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 })
+
+var _ = Describe("newClientRegistrationResponse", func() {
+	It("should echo the registered keys and the client configuration endpoint", func() {
+		client := types.OAuthClient{
+			ID:           "dcr_abc",
+			Name:         "My App",
+			Type:         oauth.ClientTypePublic,
+			RedirectURIs: []string{"https://example.com/cb"},
+			GrantTypes:   []string{oauth.GrantTypeAuthorizationCode},
+			AuthMethod:   oauth.AuthMethodPrivateKeyJWT,
+			JWKS:         `{"keys":[]}`,
+		}
+
+		resp := newClientRegistrationResponse(client, "https://bkauth.example.com/register/dcr_abc")
+
+		Expect(resp.TokenEndpointAuthMethod).To(Equal(oauth.AuthMethodPrivateKeyJWT))
+		Expect(string(resp.JWKS)).To(Equal(`{"keys":[]}`))
+		Expect(resp.RegistrationClientURI).To(Equal("https://bkauth.example.com/register/dcr_abc"))
+		Expect(resp.RegistrationAccessToken).To(BeEmpty())
+	})
+})
@@ -0,0 +1,111 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"bkauth/pkg/config"
+	"bkauth/pkg/oauth"
+	"bkauth/pkg/util"
+)
+
+// ProtectedResourceMetadata represents OAuth 2.0 Protected Resource Metadata (RFC 9728 §2)
+type ProtectedResourceMetadata struct {
+	Resource                           string   `json:"resource"`
+	AuthorizationServers               []string `json:"authorization_servers"`
+	ScopesSupported                    []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported             []string `json:"bearer_methods_supported"`
+	ResourceName                       string   `json:"resource_name,omitempty"`
+	AuthorizationDetailsTypesSupported []string `json:"authorization_details_types_supported,omitempty"`
+	DPoPSigningAlgValuesSupported      []string `json:"dpop_signing_alg_values_supported,omitempty"`
+}
+
+// NewResourceMetadataHandler creates a handler that builds the protected resource metadata
+// for any resource the realm accepts, so that MCP servers and gateways can proxy or redirect
+// their /.well-known/oauth-protected-resource to it instead of hand-writing the document.
+// Reads the realm from gin context (set by RealmMiddleware).
+func NewResourceMetadataHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resource := c.Query("resource")
+		if resource == "" {
+			c.JSON(http.StatusBadRequest, oauth.NewInvalidRequestError("resource is required"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		realmName := util.GetRealmName(c)
+		realm := oauth.GetRealm(realmName)
+		if err := realm.ValidateResource(ctx, resource); err != nil {
+			c.JSON(http.StatusBadRequest, oauth.NewInvalidRequestError("Invalid resource parameter: "+err.Error()))
+			return
+		}
+
+		metadata := ProtectedResourceMetadata{
+			Resource:                           resource,
+			AuthorizationServers:               []string{oauth.IssuerURL(cfg.BKAuthURL, realmName)},
+			ScopesSupported:                    oauth.ScopeNames(realm),
+			BearerMethodsSupported:             []string{"header"},
+			AuthorizationDetailsTypesSupported: realm.AuthorizationDetailsTypes(),
+			DPoPSigningAlgValuesSupported:      oauth.DPoPSigningAlgorithms,
+		}
+		// the display only names the resource, the metadata is still usable without it
+		if display, err := realm.ResolveResourceDisplay(ctx, resource); err == nil {
+			metadata.ResourceName = resourceDisplayName(display)
+		}
+
+		c.JSON(http.StatusOK, metadata)
+	}
+}
+
+// resourceDisplayName joins the display names of the resource items in the display
+// returned by ResolveResourceDisplay. Every realm renders it as groups of
+// {type, display_name, items: [{name, display_name}]} for the consent page.
+func resourceDisplayName(display any) string {
+	data, err := json.Marshal(display)
+	if err != nil {
+		return ""
+	}
+
+	var groups []struct {
+		DisplayName string `json:"display_name"`
+		Items       []struct {
+			DisplayName string `json:"display_name"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return ""
+	}
+
+	var names []string
+	for _, group := range groups {
+		if len(group.Items) == 0 {
+			names = append(names, group.DisplayName)
+			continue
+		}
+		for _, item := range group.Items {
+			names = append(names, item.DisplayName)
+		}
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,116 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"bkauth/pkg/config"
+	"bkauth/pkg/oauth"
+	"bkauth/pkg/realm/blueking"
+	"bkauth/pkg/realm/devops"
+	"bkauth/pkg/util"
+)
+
+var _ = Describe("NewResourceMetadataHandler", func() {
+	var (
+		w   *httptest.ResponseRecorder
+		cfg *config.Config
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		w = httptest.NewRecorder()
+		cfg = &config.Config{BKAuthURL: "https://bkauth.example.com"}
+	})
+
+	serve := func(realmName, target string) {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		util.SetRealmName(c, realmName)
+		NewResourceMetadataHandler(cfg)(c)
+	}
+
+	parseBody := func() ProtectedResourceMetadata {
+		var m ProtectedResourceMetadata
+		Expect(json.Unmarshal(w.Body.Bytes(), &m)).To(Succeed())
+		return m
+	}
+
+	It("should build the metadata of a resource of the realm", func() {
+		serve(blueking.Name, "/realms/blueking/resource-metadata?resource=mcp:bk-log,gateway:bk-paas:api:get_users")
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		m := parseBody()
+		Expect(m.Resource).To(Equal("mcp:bk-log,gateway:bk-paas:api:get_users"))
+		Expect(m.AuthorizationServers).To(Equal([]string{
+			oauth.IssuerURL("https://bkauth.example.com", blueking.Name),
+		}))
+		Expect(m.ScopesSupported).To(Equal([]string{"read", "write"}))
+		Expect(m.BearerMethodsSupported).To(Equal([]string{"header"}))
+		Expect(m.ResourceName).To(Equal("bk-log, bk-paas"))
+		Expect(m.AuthorizationDetailsTypesSupported).To(Equal([]string{
+			blueking.AuthorizationDetailsTypeGatewayAPI, blueking.AuthorizationDetailsTypeMCPServer,
+		}))
+		Expect(m.DPoPSigningAlgValuesSupported).To(Equal(oauth.DPoPSigningAlgorithms))
+	})
+
+	It("should use the issuer of the requested realm", func() {
+		serve(devops.Name, "/realms/bk-devops/resource-metadata?resource=service:pipeline")
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		m := parseBody()
+		Expect(m.AuthorizationServers).To(Equal([]string{
+			oauth.IssuerURL("https://bkauth.example.com", devops.Name),
+		}))
+		Expect(m.AuthorizationDetailsTypesSupported).To(BeEmpty())
+	})
+
+	It("should reject a missing resource", func() {
+		serve(blueking.Name, "/realms/blueking/resource-metadata")
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Body.String()).To(ContainSubstring(oauth.ErrorCodeInvalidRequest))
+	})
+
+	It("should reject a resource the realm does not accept", func() {
+		serve(blueking.Name, "/realms/blueking/resource-metadata?resource=:::invalid")
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Body.String()).To(ContainSubstring("Invalid resource parameter"))
+	})
+})
+
+var _ = Describe("resourceDisplayName", func() {
+	It("should fall back to the group name when it has no items", func() {
+		display := []map[string]any{{"type": "resource", "display_name": "GPU"}}
+
+		Expect(resourceDisplayName(display)).To(Equal("GPU"))
+	})
+
+	It("should return empty for an unexpected display", func() {
+		Expect(resourceDisplayName("bk-log")).To(BeEmpty())
+	})
+})
@@ -0,0 +1,203 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"bkauth/pkg/config"
+	"bkauth/pkg/oauth"
+	"bkauth/pkg/revocation"
+	"bkauth/pkg/util"
+)
+
+const (
+	mimeEventStream = "text/event-stream"
+
+	defaultRevocationsLimit = 100
+	maxRevocationsLimit     = 1000
+
+	// maxRevocationsWait bounds a long-poll, and an event stream session after which
+	// the client reconnects with Last-Event-ID; see revocationsWaitLimit.
+	maxRevocationsWait = 30 * time.Second
+	// revocationsStreamBlock is how long an event stream waits for events before a keepalive
+	revocationsStreamBlock = 5 * time.Second
+	// revocationsWriteMargin is kept below the server write timeout for the last read of an
+	// event stream session, which may start right before the session ends, and the response
+	revocationsWriteMargin = revocationsStreamBlock + time.Second
+)
+
+// RevocationsRequest represents a request of the revocation events
+type RevocationsRequest struct {
+	// LastEventID resumes after the given event, the Last-Event-ID header takes precedence;
+	// when both are absent only the upcoming events are returned
+	LastEventID string `form:"last_event_id"`
+	// Wait is how many seconds a long-poll waits for the first event, 30 at most and by default
+	Wait  *int  `form:"wait"`
+	Limit int64 `form:"limit"`
+}
+
+// RevocationsResponse represents a long-poll response of the revocation events
+type RevocationsResponse struct {
+	Events []revocation.Event `json:"events"`
+	// LastEventID is the id to resume from, it advances past the events the app cannot see too
+	LastEventID string `json:"last_event_id"`
+}
+
+// NewRevocationsHandler creates a handler for the revocation events of a realm, so that the
+// resource servers can drop the cached introspection results of the revoked tokens.
+// Authentication and per-realm authorization are handled by RealmAuthMiddleware;
+// the app only receives the events of the token types it is allowed to introspect.
+//
+// With Accept: text/event-stream, the events are streamed as server-sent events for a session
+// of at most 30 seconds, otherwise the request long-polls for the next batch of events; both
+// end earlier when the server write timeout is shorter.
+func NewRevocationsHandler(cfg *config.Config) gin.HandlerFunc {
+	waitLimit := revocationsWaitLimit(cfg)
+	return func(c *gin.Context) {
+		var req RevocationsRequest
+		if err := c.ShouldBindQuery(&req); err != nil {
+			c.JSON(http.StatusBadRequest, oauth.NewInvalidRequestError("invalid parameters: "+err.Error()))
+			return
+		}
+		if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+			req.LastEventID = lastEventID
+		}
+		if req.LastEventID != "" && !revocation.IsValidEventID(req.LastEventID) {
+			c.JSON(http.StatusBadRequest, oauth.NewInvalidRequestError("Invalid last event id"))
+			return
+		}
+		if req.Limit <= 0 || req.Limit > maxRevocationsLimit {
+			req.Limit = defaultRevocationsLimit
+		}
+		wait := waitLimit
+		if req.Wait != nil && *req.Wait >= 0 && time.Duration(*req.Wait)*time.Second < waitLimit {
+			wait = time.Duration(*req.Wait) * time.Second
+		}
+
+		ctx := c.Request.Context()
+		realmName := util.GetRealmName(c)
+		if req.LastEventID == "" {
+			lastEventID, err := revocation.LastEventID(ctx, realmName)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, oauth.NewServerError("failed to read revocations: "+err.Error()))
+				return
+			}
+			req.LastEventID = lastEventID
+		}
+
+		visible := newRevocationEventFilter(cfg, realmName, util.GetAccessAppCode(c))
+
+		if c.NegotiateFormat(gin.MIMEJSON, mimeEventStream) == mimeEventStream {
+			streamRevocations(c, realmName, req.LastEventID, req.Limit, waitLimit, visible)
+			return
+		}
+
+		events, err := revocation.Read(ctx, realmName, req.LastEventID, req.Limit, wait)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, oauth.NewServerError("failed to read revocations: "+err.Error()))
+			return
+		}
+
+		resp := RevocationsResponse{Events: []revocation.Event{}, LastEventID: req.LastEventID}
+		for _, e := range events {
+			if visible(e) {
+				resp.Events = append(resp.Events, e)
+			}
+			resp.LastEventID = e.ID
+		}
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// revocationsWaitLimit returns how long a long-poll or an event stream session may last, so that
+// the response is written before the server write timeout closes the connection
+func revocationsWaitLimit(cfg *config.Config) time.Duration {
+	if cfg.Server.WriteTimeout <= 0 {
+		return maxRevocationsWait
+	}
+	limit := time.Duration(cfg.Server.WriteTimeout)*time.Second - revocationsWriteMargin
+	return max(min(limit, maxRevocationsWait), 0)
+}
+
+// newRevocationEventFilter returns whether an event is visible to the app, i.e. it is
+// allowed to introspect the type of the revoked token
+func newRevocationEventFilter(cfg *config.Config, realmName, appCode string) func(revocation.Event) bool {
+	allowed := map[string]bool{
+		oauth.TokenTypeAccessToken:  cfg.OAuth.IsIntrospectAllowed(realmName, appCode),
+		oauth.TokenTypeRefreshToken: cfg.OAuth.IsRefreshTokenIntrospectAllowed(realmName, appCode),
+	}
+	return func(e revocation.Event) bool {
+		return allowed[e.TokenType]
+	}
+}
+
+// streamRevocations streams the events as server-sent events until the session ends, the client
+// goes away or the stream cannot be read; the client then reconnects with the last event id.
+func streamRevocations(
+	c *gin.Context, realmName, lastEventID string, limit int64, session time.Duration,
+	visible func(revocation.Event) bool,
+) {
+	c.Header("Content-Type", mimeEventStream)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	deadline := time.Now().Add(session)
+	c.Stream(func(w io.Writer) bool {
+		if time.Now().After(deadline) {
+			return false
+		}
+
+		events, err := revocation.Read(c.Request.Context(), realmName, lastEventID, limit, revocationsStreamBlock)
+		if err != nil {
+			return false
+		}
+		if len(events) == 0 {
+			_, err = io.WriteString(w, ": keepalive\n\n")
+			return err == nil
+		}
+		for _, e := range events {
+			lastEventID = e.ID
+			if !visible(e) {
+				continue
+			}
+			if err := writeRevocationEvent(w, e); err != nil {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// writeRevocationEvent writes the event as a server-sent event, whose id is the event id
+func writeRevocationEvent(w io.Writer, e revocation.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: revocation\ndata: %s\n\n", e.ID, data)
+	return err
+}
@@ -0,0 +1,114 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"bkauth/pkg/config"
+	"bkauth/pkg/oauth"
+	"bkauth/pkg/realm/blueking"
+	"bkauth/pkg/revocation"
+	"bkauth/pkg/util"
+)
+
+var _ = Describe("NewRevocationsHandler", func() {
+	var w *httptest.ResponseRecorder
+
+	serve := func(target, lastEventID string) {
+		gin.SetMode(gin.TestMode)
+		w = httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		if lastEventID != "" {
+			c.Request.Header.Set("Last-Event-ID", lastEventID)
+		}
+		util.SetRealmName(c, blueking.Name)
+		util.SetAccessAppCode(c, "bk_apigateway")
+		NewRevocationsHandler(&config.Config{})(c)
+	}
+
+	It("should reject an invalid last_event_id", func() {
+		serve("/revocations?last_event_id=$", "")
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Body.String()).To(ContainSubstring("Invalid last event id"))
+	})
+
+	It("should prefer the Last-Event-ID header", func() {
+		serve("/revocations?last_event_id=1700000000000-0", "invalid")
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should fail without redis", func() {
+		serve("/revocations?last_event_id=1700000000000-0&wait=0", "")
+
+		Expect(w.Code).To(Equal(http.StatusInternalServerError))
+	})
+})
+
+var _ = Describe("revocationsWaitLimit", func() {
+	DescribeTable("should stay within the server write timeout",
+		func(writeTimeout int, want time.Duration) {
+			cfg := &config.Config{}
+			cfg.Server.WriteTimeout = writeTimeout
+
+			Expect(revocationsWaitLimit(cfg)).To(Equal(want))
+		},
+		Entry("default write timeout", 0, maxRevocationsWait),
+		Entry("long write timeout", 120, maxRevocationsWait),
+		Entry("short write timeout", 20, 20*time.Second-revocationsWriteMargin),
+		Entry("write timeout below the margin", 3, time.Duration(0)),
+	)
+})
+
+var _ = Describe("newRevocationEventFilter", func() {
+	It("should hide the events of the token types the app is not allowed to introspect", func() {
+		visible := newRevocationEventFilter(&config.Config{}, blueking.Name, "bk_apigateway")
+
+		Expect(visible(revocation.Event{TokenType: oauth.TokenTypeAccessToken})).To(BeFalse())
+		Expect(visible(revocation.Event{TokenType: oauth.TokenTypeRefreshToken})).To(BeFalse())
+	})
+})
+
+var _ = Describe("writeRevocationEvent", func() {
+	It("should write a server-sent event identified by the event id", func() {
+		var buf bytes.Buffer
+		err := writeRevocationEvent(&buf, revocation.Event{
+			ID:        "1700000000000-0",
+			TokenType: oauth.TokenTypeAccessToken,
+			JTI:       "jti-1",
+			RealmName: blueking.Name,
+			Reason:    revocation.ReasonRevoked,
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buf.String()).To(HavePrefix("id: 1700000000000-0\nevent: revocation\ndata: {"))
+		Expect(buf.String()).To(ContainSubstring(`"jti":"jti-1"`))
+		Expect(buf.String()).To(HaveSuffix("}\n\n"))
+	})
+})
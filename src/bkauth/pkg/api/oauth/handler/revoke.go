@@ -23,7 +23,6 @@ import (
 
 	"github.com/gin-gonic/gin"
 
-	"bkauth/pkg/cache/impls"
 	"bkauth/pkg/oauth"
 	"bkauth/pkg/service"
 	"bkauth/pkg/util"
@@ -55,17 +54,15 @@ func NewRevokeHandler() gin.HandlerFunc {
 
 		ctx := c.Request.Context()
 
-		tokenHash := oauth.HashToken(req.Token)
+		tokenHashes := oauth.TokenHashCandidates(req.Token)
 
 		tokenSvc := service.NewOAuthTokenService()
-		if err := tokenSvc.RevokeToken(ctx, tokenHash, clientID); err != nil {
+		if err := tokenSvc.RevokeToken(ctx, tokenHashes, clientID); err != nil {
 			// Per RFC 7009, server errors should still return 200
 			c.Status(http.StatusOK)
 			return
 		}
 
-		_ = impls.DeleteAccessTokenCache(ctx, tokenHash)
-
 		c.Status(http.StatusOK)
 	}
 }
@@ -19,9 +19,12 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"time"
 
+	"bkauth/pkg/cache/impls"
 	"bkauth/pkg/config"
 	"bkauth/pkg/oauth"
 	"bkauth/pkg/service"
@@ -38,6 +41,9 @@ import (
 //   - authorization_code: Code, RedirectURI, CodeVerifier (required)
 //   - refresh_token:      RefreshToken (required)
 //   - device_code:        DeviceCode (required)
+//   - client_credentials: Resource (required), Scope (optional)
+//   - token-exchange:     SubjectToken, SubjectTokenType, Resource (required),
+//     Scope, RequestedTokenType (optional)
 type TokenRequest struct {
 	GrantType string `form:"grant_type" binding:"required"`
 	ClientID  string `form:"client_id" binding:"required"`
@@ -56,6 +62,15 @@ type TokenRequest struct {
 
 	// device_code
 	DeviceCode string `form:"device_code"`
+
+	// client_credentials, token-exchange
+	Resource string `form:"resource"`
+	Scope    string `form:"scope"`
+
+	// token-exchange (RFC 8693 §2.1)
+	SubjectToken       string `form:"subject_token"`
+	SubjectTokenType   string `form:"subject_token_type"`
+	RequestedTokenType string `form:"requested_token_type"`
 }
 
 // TokenResponse represents a successful token response
@@ -65,6 +80,12 @@ type TokenResponse struct {
 	ExpiresIn    int64  `json:"expires_in"`
 	RefreshToken string `json:"refresh_token,omitempty"`
 	Scope        string `json:"scope,omitempty"`
+	// IssuedTokenType is only set for token exchange (RFC 8693 §2.2.1).
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
+	// IDToken is only set for OpenID Connect requests (scope contains openid).
+	IDToken string `json:"id_token,omitempty"`
+	// AuthorizationDetails is only set when the grant carries them (RFC 9396 §7).
+	AuthorizationDetails []oauth.AuthorizationDetail `json:"authorization_details,omitempty"`
 }
 
 // NewTokenHandler creates a handler for the token endpoint.
@@ -80,7 +101,7 @@ func NewTokenHandler(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		clientSvc := service.NewOAuthClientService()
+		clientSvc := impls.WrapOAuthClientService(service.NewOAuthClientService())
 		flowSpec, err := clientSvc.GetFlowSpec(c.Request.Context(), clientID)
 		if err == nil && flowSpec.ID != "" && !flowSpec.SupportsGrantType(req.GrantType) {
 			c.JSON(http.StatusBadRequest, oauth.NewUnauthorizedClientError(
@@ -89,13 +110,30 @@ func NewTokenHandler(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		// RFC 9449: a DPoP proof binds the issued tokens to the client's key
+		cnf, err := resolveDPoPConfirmation(c, cfg)
+		if err != nil {
+			if oauthErr, ok := oauth.AsOAuthError(err); ok {
+				c.JSON(http.StatusBadRequest, oauthErr)
+				return
+			}
+			c.JSON(http.StatusInternalServerError, oauth.NewServerError("Failed to verify DPoP proof"))
+			return
+		}
+		// RFC 8705 §3: the client certificate, if any, binds the issued access token
+		cnf.X5TS256 = util.GetClientCertThumbprint(c)
+
 		switch req.GrantType {
 		case oauth.GrantTypeAuthorizationCode:
-			handleAuthorizationCodeGrant(c, cfg, req)
+			handleAuthorizationCodeGrant(c, cfg, req, cnf)
 		case oauth.GrantTypeRefreshToken:
-			handleRefreshTokenGrant(c, cfg, req)
+			handleRefreshTokenGrant(c, cfg, req, cnf)
 		case oauth.GrantTypeDeviceCode:
-			handleDeviceCodeGrant(c, cfg, req)
+			handleDeviceCodeGrant(c, cfg, req, cnf)
+		case oauth.GrantTypeClientCredentials:
+			handleClientCredentialsGrant(c, cfg, req, cnf)
+		case oauth.GrantTypeTokenExchange:
+			handleTokenExchangeGrant(c, cfg, req, cnf)
 		default:
 			c.JSON(http.StatusBadRequest, oauth.NewUnsupportedGrantTypeError("Grant type not supported"))
 		}
@@ -106,24 +144,38 @@ func resolveTokenIssuancePolicy(c *gin.Context, cfg *config.Config) types.TokenI
 	realmName := util.GetRealmName(c)
 	clientID := util.GetClientID(c)
 	accessTokenTTL, refreshTokenTTL := cfg.OAuth.ResolveTokenTTL(realmName, clientID)
+	accessTokenFormat := oauth.AccessTokenFormatOpaque
+	if cfg.OAuth.IsJWTAccessTokenEnabled(realmName) {
+		accessTokenFormat = oauth.AccessTokenFormatJWT
+	}
 	return types.TokenIssuancePolicy{
-		Prefix:          oauth.GetRealm(realmName).TokenPrefix(),
-		AccessTokenTTL:  accessTokenTTL,
-		RefreshTokenTTL: refreshTokenTTL,
+		Prefix:                  oauth.GetRealm(realmName).TokenPrefix(),
+		AccessTokenTTL:          accessTokenTTL,
+		RefreshTokenTTL:         refreshTokenTTL,
+		RefreshTokenIdleTimeout: cfg.OAuth.ResolveRefreshTokenIdleTimeout(realmName, clientID),
+		AccessTokenFormat:       accessTokenFormat,
+		Issuer:                  oauth.IssuerURL(cfg.BKAuthURL, realmName),
+		PublicClient:            util.GetClientType(c) == oauth.ClientTypePublic,
 	}
 }
 
-func makeTokenResponse(pair types.TokenPair) TokenResponse {
+func makeTokenResponse(pair types.TokenPair, cnf oauth.Confirmation) TokenResponse {
+	tokenType := oauth.TokenTypeBearer
+	if cnf.JKT != "" {
+		tokenType = oauth.TokenTypeDPoP
+	}
 	return TokenResponse{
 		AccessToken:  pair.AccessToken,
-		TokenType:    oauth.TokenTypeBearer,
+		TokenType:    tokenType,
 		ExpiresIn:    pair.ExpiresIn,
 		RefreshToken: pair.RefreshToken,
 		Scope:        pair.Scope,
+
+		AuthorizationDetails: pair.AuthorizationDetails,
 	}
 }
 
-func handleAuthorizationCodeGrant(c *gin.Context, cfg *config.Config, req TokenRequest) {
+func handleAuthorizationCodeGrant(c *gin.Context, cfg *config.Config, req TokenRequest, cnf oauth.Confirmation) {
 	ctx := c.Request.Context()
 	clientID := util.GetClientID(c)
 	if req.Code == "" {
@@ -157,20 +209,55 @@ func handleAuthorizationCodeGrant(c *gin.Context, cfg *config.Config, req TokenR
 
 	policy := resolveTokenIssuancePolicy(c, cfg)
 	tokenSvc := service.NewOAuthTokenService()
-	tokenPair, err := tokenSvc.IssueTokensForAuthorizationCode(
-		ctx, realmName, clientID,
-		authCode.TenantID, authCode.Sub, authCode.Username,
-		authCode.Audience, policy,
-	)
+	grant := types.TokenGrant{
+		TenantID:  authCode.TenantID,
+		Sub:       authCode.Sub,
+		Username:  authCode.Username,
+		Audience:  authCode.Audience,
+		Resource:  authCode.Resource,
+		Scope:     authCode.Scope,
+		GrantType: oauth.GrantTypeAuthorizationCode,
+		Cnf:       cnf,
+
+		AuthorizationDetails: authCode.AuthorizationDetails,
+	}
+	tokenPair, err := tokenSvc.IssueTokensForAuthorizationCode(ctx, realmName, clientID, grant, policy)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, oauth.NewServerError("Failed to issue tokens"))
 		return
 	}
 
-	c.JSON(http.StatusOK, makeTokenResponse(tokenPair))
+	resp := makeTokenResponse(tokenPair, cnf)
+	if oauth.HasScope(authCode.Scope, oauth.ScopeOpenID) {
+		resp.IDToken, err = signIDToken(policy, clientID, authCode)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, oauth.NewServerError("Failed to issue id_token"))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
 }
 
-func handleRefreshTokenGrant(c *gin.Context, cfg *config.Config, req TokenRequest) {
+// signIDToken builds and signs the OpenID Connect id_token for an authorization
+// code grant. It shares the access token's issuer and lifetime.
+func signIDToken(
+	policy types.TokenIssuancePolicy, clientID string, authCode types.ConsumedAuthorizationCode,
+) (string, error) {
+	now := time.Now()
+	return oauth.SignJWT(oauth.JWTTypeIDToken, oauth.IDTokenClaims{
+		Issuer:            policy.Issuer,
+		Subject:           authCode.Sub,
+		Audience:          clientID,
+		ExpiresAt:         now.Add(time.Duration(policy.AccessTokenTTL) * time.Second).Unix(),
+		IssuedAt:          now.Unix(),
+		Nonce:             authCode.Nonce,
+		PreferredUsername: authCode.Username,
+		TenantID:          authCode.TenantID,
+	})
+}
+
+func handleRefreshTokenGrant(c *gin.Context, cfg *config.Config, req TokenRequest, cnf oauth.Confirmation) {
 	ctx := c.Request.Context()
 	clientID := util.GetClientID(c)
 	realmName := util.GetRealmName(c)
@@ -179,18 +266,26 @@ func handleRefreshTokenGrant(c *gin.Context, cfg *config.Config, req TokenReques
 		return
 	}
 
+	flowSpec, err := getClientFlowSpec(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, oauth.NewServerError("Failed to resolve client policy"))
+		return
+	}
+
+	// the client policy may have been narrowed since the grant was issued, so it is checked again
 	policy := resolveTokenIssuancePolicy(c, cfg)
+	policy.ClientPolicy = flowSpec.ResolvePolicy(realmName)
 	tokenSvc := service.NewOAuthTokenService()
-	tokenPair, err := tokenSvc.RefreshAccessToken(ctx, realmName, req.RefreshToken, clientID, policy)
+	tokenPair, err := tokenSvc.RefreshAccessToken(ctx, realmName, req.RefreshToken, clientID, cnf, policy)
 	if err != nil {
 		handleTokenError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, makeTokenResponse(tokenPair))
+	c.JSON(http.StatusOK, makeTokenResponse(tokenPair, cnf))
 }
 
-func handleDeviceCodeGrant(c *gin.Context, cfg *config.Config, req TokenRequest) {
+func handleDeviceCodeGrant(c *gin.Context, cfg *config.Config, req TokenRequest, cnf oauth.Confirmation) {
 	ctx := c.Request.Context()
 	clientID := util.GetClientID(c)
 	realmName := util.GetRealmName(c)
@@ -214,17 +309,300 @@ func handleDeviceCodeGrant(c *gin.Context, cfg *config.Config, req TokenRequest)
 
 	policy := resolveTokenIssuancePolicy(c, cfg)
 	tokenSvc := service.NewOAuthTokenService()
-	tokenPair, err := tokenSvc.IssueTokensForDeviceCode(
-		ctx, realmName, clientID,
-		dc.TenantID, dc.Sub, dc.Username,
-		dc.Audience, policy,
+	grant := types.TokenGrant{
+		TenantID:  dc.TenantID,
+		Sub:       dc.Sub,
+		Username:  dc.Username,
+		Audience:  dc.Audience,
+		Resource:  dc.Resource,
+		Scope:     dc.Scope,
+		GrantType: oauth.GrantTypeDeviceCode,
+		Cnf:       cnf,
+
+		AuthorizationDetails: dc.AuthorizationDetails,
+	}
+	tokenPair, err := tokenSvc.IssueTokensForDeviceCode(ctx, realmName, clientID, grant, policy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, oauth.NewServerError("Failed to issue tokens"))
+		return
+	}
+
+	c.JSON(http.StatusOK, makeTokenResponse(tokenPair, cnf))
+}
+
+func handleClientCredentialsGrant(c *gin.Context, cfg *config.Config, req TokenRequest, cnf oauth.Confirmation) {
+	ctx := c.Request.Context()
+	clientID := util.GetClientID(c)
+	realmName := util.GetRealmName(c)
+
+	// RFC 6749 §4.4: the client_credentials grant MUST only be used by confidential clients.
+	if !requireConfidentialClient(c, req.GrantType) {
+		return
+	}
+
+	grant, err := resolveClientCredentialsGrant(ctx, oauth.GetRealm(realmName), req)
+	if err != nil {
+		if oauthErr, ok := oauth.AsOAuthError(err); ok {
+			c.JSON(http.StatusBadRequest, oauthErr)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, oauth.NewServerError("Failed to process resource parameter"))
+		return
+	}
+	if !requireClientPolicy(c, realmName, req.Resource) {
+		return
+	}
+
+	// the token acts on behalf of the app, so it is bound to the app's tenant
+	app, err := impls.GetApp(ctx, clientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, oauth.NewServerError("Failed to resolve client tenant"))
+		return
+	}
+	grant.TenantID = app.TenantID
+	grant.Cnf = cnf
+
+	policy := resolveTokenIssuancePolicy(c, cfg)
+	tokenSvc := service.NewOAuthTokenService()
+	tokenPair, err := tokenSvc.IssueAccessTokenForClientCredentials(ctx, realmName, clientID, grant, policy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, oauth.NewServerError("Failed to issue tokens"))
+		return
+	}
+
+	c.JSON(http.StatusOK, makeTokenResponse(tokenPair, cnf))
+}
+
+func handleTokenExchangeGrant(c *gin.Context, cfg *config.Config, req TokenRequest, cnf oauth.Confirmation) {
+	ctx := c.Request.Context()
+	clientID := util.GetClientID(c)
+	realmName := util.GetRealmName(c)
+
+	// the exchanging client is recorded as the actor, so it must be authenticated
+	if !requireConfidentialClient(c, req.GrantType) {
+		return
+	}
+
+	if req.SubjectToken == "" {
+		c.JSON(http.StatusBadRequest, oauth.NewInvalidRequestError("subject_token is required"))
+		return
+	}
+	// only access tokens issued by bkauth can be exchanged
+	if req.SubjectTokenType != oauth.TokenTypeURIAccessToken {
+		c.JSON(http.StatusBadRequest, oauth.NewInvalidRequestError(
+			"subject_token_type must be "+oauth.TokenTypeURIAccessToken,
+		))
+		return
+	}
+	if req.RequestedTokenType != "" && req.RequestedTokenType != oauth.TokenTypeURIAccessToken {
+		c.JSON(http.StatusBadRequest, oauth.NewInvalidRequestError(
+			"requested_token_type must be "+oauth.TokenTypeURIAccessToken,
+		))
+		return
+	}
+
+	subject, err := impls.GetAccessTokenByTokenHashes(ctx, oauth.TokenHashCandidates(req.SubjectToken))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, oauth.NewServerError("Failed to resolve subject token"))
+		return
+	}
+	// tokens of another realm are invisible, same as introspection
+	if subject.ClientID == "" || !subject.IsActive() || subject.RealmName != realmName {
+		c.JSON(http.StatusBadRequest, oauth.NewInvalidGrantError("Invalid subject token"))
+		return
+	}
+	if err := checkSubjectTokenBinding(subject.Cnf, cnf); err != nil {
+		c.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	grant, err := resolveTokenExchangeGrant(ctx, oauth.GetRealm(realmName), clientID, subject, req)
+	if err != nil {
+		if oauthErr, ok := oauth.AsOAuthError(err); ok {
+			c.JSON(http.StatusBadRequest, oauthErr)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, oauth.NewServerError("Failed to process resource parameter"))
+		return
+	}
+	if !requireClientPolicy(c, realmName, req.Resource) {
+		return
+	}
+
+	grant.Cnf = cnf
+
+	// the exchanged token must not outlive its subject token
+	policy := resolveTokenIssuancePolicy(c, cfg)
+	if remaining := subject.ExpiresAt - time.Now().Unix(); remaining < policy.AccessTokenTTL {
+		policy.AccessTokenTTL = remaining
+	}
+
+	tokenSvc := service.NewOAuthTokenService()
+	tokenPair, err := tokenSvc.IssueAccessTokenForTokenExchange(
+		ctx, realmName, subject.GrantID, clientID, grant, policy,
 	)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, oauth.NewServerError("Failed to issue tokens"))
 		return
 	}
 
-	c.JSON(http.StatusOK, makeTokenResponse(tokenPair))
+	resp := makeTokenResponse(tokenPair, cnf)
+	resp.IssuedTokenType = oauth.TokenTypeURIAccessToken
+	c.JSON(http.StatusOK, resp)
+}
+
+// checkSubjectTokenBinding requires the exchanging client to prove possession of the key a
+// sender-constrained subject token is bound to, as a resource server would (RFC 9449 §7.1,
+// RFC 8705 §3); cnf is the DPoP key and client certificate of the token request.
+func checkSubjectTokenBinding(subject, cnf oauth.Confirmation) *oauth.OAuthError {
+	if subject.JKT != "" && cnf.JKT != subject.JKT {
+		return oauth.NewInvalidGrantError("Subject token is DPoP-bound, a DPoP proof of its key is required")
+	}
+	if subject.X5TS256 != "" && cnf.X5TS256 != subject.X5TS256 {
+		return oauth.NewInvalidGrantError("Subject token is bound to a different client certificate")
+	}
+	return nil
+}
+
+// resolveTokenExchangeGrant validates the requested resource and scope against
+// the subject token and builds the grant of the exchanged token.
+// The exchanged token keeps the subject's identity, may only narrow its
+// audience and scope, and records clientID as the current actor. A request beyond
+// the subject token is rejected with invalid_target or invalid_scope, and a delegation
+// chain beyond oauth.ValidateActor with invalid_request, while a failure of the realm
+// to resolve the audiences is returned unwrapped.
+func resolveTokenExchangeGrant(
+	ctx context.Context, realm oauth.Realm, clientID string,
+	subject types.ResolvedAccessToken, req TokenRequest,
+) (types.TokenGrant, error) {
+	if req.Resource == "" {
+		return types.TokenGrant{}, oauth.NewInvalidRequestError("resource is required")
+	}
+	if err := realm.ValidateResource(ctx, req.Resource); err != nil {
+		return types.TokenGrant{}, oauth.NewInvalidRequestError("Invalid resource parameter: " + err.Error())
+	}
+
+	act := oauth.NewActor(clientID, subject.Act)
+	if err := oauth.ValidateActor(act); err != nil {
+		return types.TokenGrant{}, oauth.NewInvalidRequestError(err.Error())
+	}
+
+	audience, err := realm.ExtractAudiences(ctx, req.Resource)
+	if err != nil {
+		return types.TokenGrant{}, err
+	}
+	if !oauth.IsAudienceSubset(audience, subject.Audience) {
+		return types.TokenGrant{}, oauth.NewInvalidTargetError(
+			"Requested resource is not within the subject token's audience",
+		)
+	}
+
+	scope := subject.Scope
+	if req.Scope != "" {
+		scope, err = oauth.ValidateScope(realm, req.Scope)
+		if err != nil {
+			return types.TokenGrant{}, oauth.NewInvalidScopeError(err.Error())
+		}
+		if !oauth.IsScopeSubset(scope, subject.Scope) {
+			return types.TokenGrant{}, oauth.NewInvalidScopeError(
+				"Requested scope is not within the subject token's scope",
+			)
+		}
+	}
+
+	return types.TokenGrant{
+		TenantID:  subject.TenantID,
+		Sub:       subject.Sub,
+		Username:  subject.Username,
+		Audience:  audience,
+		Scope:     scope,
+		GrantType: oauth.GrantTypeTokenExchange,
+		Act:       act,
+
+		// the API-level permissions of the subject token are kept as is, so that
+		// exchanging it for its gateway does not widen them to every API
+		AuthorizationDetails: subject.AuthorizationDetails,
+	}, nil
+}
+
+// resolveClientCredentialsGrant validates the resource and scope of a
+// client_credentials request against the realm and builds the grant.
+// Validation failures are returned as OAuth errors; other errors are internal.
+func resolveClientCredentialsGrant(
+	ctx context.Context, realm oauth.Realm, req TokenRequest,
+) (types.TokenGrant, error) {
+	if req.Resource == "" {
+		return types.TokenGrant{}, oauth.NewInvalidRequestError("resource is required")
+	}
+	if err := realm.ValidateResource(ctx, req.Resource); err != nil {
+		return types.TokenGrant{}, oauth.NewInvalidRequestError("Invalid resource parameter: " + err.Error())
+	}
+
+	scope, err := oauth.ValidateScope(realm, req.Scope)
+	if err != nil {
+		return types.TokenGrant{}, oauth.NewInvalidScopeError(err.Error())
+	}
+
+	audience, err := realm.ExtractAudiences(ctx, req.Resource)
+	if err != nil {
+		return types.TokenGrant{}, err
+	}
+
+	return types.TokenGrant{
+		Audience:  audience,
+		Scope:     scope,
+		GrantType: oauth.GrantTypeClientCredentials,
+	}, nil
+}
+
+// getClientFlowSpec returns the flow spec of the authenticated client.
+func getClientFlowSpec(c *gin.Context) (types.OAuthClientFlowSpec, error) {
+	clientSvc := impls.WrapOAuthClientService(service.NewOAuthClientService())
+	return clientSvc.GetFlowSpec(c.Request.Context(), util.GetClientID(c))
+}
+
+// requireClientPolicy rejects a resource request that the policy of the authenticated
+// client does not allow, writing the error response. It reports whether to proceed.
+func requireClientPolicy(c *gin.Context, realmName, resource string) bool {
+	flowSpec, err := getClientFlowSpec(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, oauth.NewServerError("Failed to resolve client policy"))
+		return false
+	}
+	if err := checkClientPolicy(flowSpec, realmName, resource); err != nil {
+		if oauthErr, ok := oauth.AsOAuthError(err); ok {
+			c.JSON(http.StatusBadRequest, oauthErr)
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, oauth.NewServerError("Failed to check client policy"))
+		return false
+	}
+	return true
+}
+
+// requireConfidentialClient rejects public clients and secret-exempt
+// confidential clients for grants in which the client acts on its own
+// credentials, writing the error response. It reports whether to proceed.
+//
+// A secret-exempt client passes ClientAuthMiddleware with its client_id alone,
+// which is not enough here: the method the middleware actually verified must be
+// a client credential. A client_secret merely present in the request is not.
+func requireConfidentialClient(c *gin.Context, grantType string) bool {
+	if util.GetClientType(c) != oauth.ClientTypeConfidential {
+		c.JSON(http.StatusBadRequest, oauth.NewUnauthorizedClientError(
+			"Public clients cannot use the "+grantType+" grant",
+		))
+		return false
+	}
+	switch util.GetClientAuthMethod(c) {
+	case oauth.AuthMethodClientSecretBasic, oauth.AuthMethodClientSecretPost, oauth.AuthMethodPrivateKeyJWT,
+		oauth.AuthMethodTLSClientAuth, oauth.AuthMethodSelfSignedTLSClientAuth:
+		return true
+	}
+	c.JSON(http.StatusUnauthorized, oauth.NewInvalidClientError(
+		"Client authentication is required for the "+grantType+" grant",
+	))
+	return false
 }
 
 // handleDeviceCodeError maps device-code-specific errors to OAuth error responses.
@@ -285,8 +663,24 @@ func handleTokenError(c *gin.Context, err error) {
 		c.JSON(http.StatusBadRequest, oauth.NewInvalidGrantError("Invalid refresh token"))
 	case errors.Is(err, oauth.ErrRefreshTokenExpired):
 		c.JSON(http.StatusBadRequest, oauth.NewInvalidGrantError("Refresh token has expired"))
+	case errors.Is(err, oauth.ErrRefreshTokenIdleExpired):
+		c.JSON(http.StatusBadRequest, oauth.NewInvalidGrantError(
+			"Refresh token has expired due to inactivity",
+		))
 	case errors.Is(err, oauth.ErrRefreshTokenRevoked):
 		c.JSON(http.StatusBadRequest, oauth.NewInvalidGrantError("Refresh token has been revoked"))
+	case errors.Is(err, oauth.ErrDPoPKeyMismatch):
+		c.JSON(http.StatusBadRequest, oauth.NewInvalidDPoPProofError(
+			"Refresh token is bound to a different DPoP key",
+		))
+	case errors.Is(err, oauth.ErrClientCertificateMismatch):
+		c.JSON(http.StatusBadRequest, oauth.NewInvalidGrantError(
+			"Refresh token is bound to a different client certificate",
+		))
+	case errors.Is(err, oauth.ErrRealmNotAllowed), errors.Is(err, oauth.ErrResourceNotAllowed):
+		c.JSON(http.StatusBadRequest, oauth.NewInvalidGrantError(
+			"Grant is no longer allowed by the client policy",
+		))
 	default:
 		c.JSON(http.StatusInternalServerError, oauth.NewServerError("An unexpected error occurred"))
 	}
@@ -19,18 +19,27 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
+	"github.com/alicebob/miniredis"
+	goredis "github.com/go-redis/redis/v8"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	"github.com/gin-gonic/gin"
 
+	"bkauth/pkg/cache/impls"
+	"bkauth/pkg/cache/redis"
+	"bkauth/pkg/config"
 	"bkauth/pkg/oauth"
+	"bkauth/pkg/realm/blueking"
 	"bkauth/pkg/service/types"
+	"bkauth/pkg/util"
 )
 
 var _ = Describe("makeTokenResponse", func() {
@@ -42,7 +51,7 @@ var _ = Describe("makeTokenResponse", func() {
 			Scope:        "openid",
 		}
 
-		resp := makeTokenResponse(pair)
+		resp := makeTokenResponse(pair, oauth.Confirmation{})
 
 		Expect(resp.AccessToken).To(Equal("bk_abc123"))
 		Expect(resp.TokenType).To(Equal(oauth.TokenTypeBearer))
@@ -57,10 +66,21 @@ var _ = Describe("makeTokenResponse", func() {
 			ExpiresIn:   300,
 		}
 
-		resp := makeTokenResponse(pair)
+		resp := makeTokenResponse(pair, oauth.Confirmation{})
 
 		Expect(resp.RefreshToken).To(BeEmpty())
 	})
+
+	It("should use the DPoP token type for DPoP-bound tokens", func() {
+		pair := types.TokenPair{
+			AccessToken: "bk_abc123",
+			ExpiresIn:   300,
+		}
+
+		resp := makeTokenResponse(pair, oauth.Confirmation{JKT: "jkt-1"})
+
+		Expect(resp.TokenType).To(Equal(oauth.TokenTypeDPoP))
+	})
 })
 
 var _ = Describe("handleTokenError", func() {
@@ -105,6 +125,8 @@ var _ = Describe("handleTokenError", func() {
 			errorCase{oauth.ErrRefreshTokenExpired, oauth.ErrorCodeInvalidGrant, http.StatusBadRequest}),
 		Entry("refresh token revoked",
 			errorCase{oauth.ErrRefreshTokenRevoked, oauth.ErrorCodeInvalidGrant, http.StatusBadRequest}),
+		Entry("DPoP key mismatch",
+			errorCase{oauth.ErrDPoPKeyMismatch, oauth.ErrorCodeInvalidDPoPProof, http.StatusBadRequest}),
 		Entry("unexpected error",
 			errorCase{errors.New("something went wrong"), oauth.ErrorCodeServerError, http.StatusInternalServerError}),
 	)
@@ -152,3 +174,238 @@ var _ = Describe("handleDeviceCodeError", func() {
 			errorCase{errors.New("something went wrong"), oauth.ErrorCodeServerError, http.StatusInternalServerError}),
 	)
 })
+
+var _ = Describe("requireConfidentialClient", func() {
+	handlers := map[string]func(*gin.Context, *config.Config, TokenRequest, oauth.Confirmation){
+		oauth.GrantTypeClientCredentials: handleClientCredentialsGrant,
+		oauth.GrantTypeTokenExchange:     handleTokenExchangeGrant,
+	}
+
+	// the request is otherwise empty, so a client allowed to use the grant gets invalid_request
+	serve := func(grantType, clientType, authMethod string) *httptest.ResponseRecorder {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/token", nil)
+		// a secret that ClientAuthMiddleware did not verify
+		c.Request.SetBasicAuth("my-app", "made-up-secret")
+		util.SetRealmName(c, blueking.Name)
+		util.SetClientID(c, "my-app")
+		util.SetClientType(c, clientType)
+		util.SetClientAuthMethod(c, authMethod)
+		handlers[grantType](c, &config.Config{}, TokenRequest{GrantType: grantType}, oauth.Confirmation{})
+		return w
+	}
+
+	errorCode := func(w *httptest.ResponseRecorder) string {
+		var oauthErr oauth.OAuthError
+		Expect(json.Unmarshal(w.Body.Bytes(), &oauthErr)).To(Succeed())
+		return oauthErr.Code
+	}
+
+	for _, grantType := range []string{oauth.GrantTypeClientCredentials, oauth.GrantTypeTokenExchange} {
+		DescribeTable("should accept the client credentials verified by the middleware for "+grantType,
+			func(authMethod string) {
+				w := serve(grantType, oauth.ClientTypeConfidential, authMethod)
+
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+				Expect(errorCode(w)).To(Equal(oauth.ErrorCodeInvalidRequest))
+			},
+			Entry("client_secret_basic", oauth.AuthMethodClientSecretBasic),
+			Entry("client_secret_post", oauth.AuthMethodClientSecretPost),
+			Entry("private_key_jwt", oauth.AuthMethodPrivateKeyJWT),
+			Entry("tls_client_auth", oauth.AuthMethodTLSClientAuth),
+			Entry("self_signed_tls_client_auth", oauth.AuthMethodSelfSignedTLSClientAuth),
+		)
+
+		It("should reject a secret-exempt client presenting an unverified secret for "+grantType, func() {
+			w := serve(grantType, oauth.ClientTypeConfidential, oauth.AuthMethodNone)
+
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+			Expect(errorCode(w)).To(Equal(oauth.ErrorCodeInvalidClient))
+		})
+
+		It("should reject a public client for "+grantType, func() {
+			w := serve(grantType, oauth.ClientTypePublic, oauth.AuthMethodNone)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(errorCode(w)).To(Equal(oauth.ErrorCodeUnauthorizedClient))
+		})
+	}
+})
+
+var _ = Describe("handleTokenExchangeGrant", func() {
+	const subjectToken = "subject-token"
+
+	// the subject token is bound to the DPoP key "jkt-1" and the certificate "x5t-1"
+	BeforeEach(func() {
+		mr, err := miniredis.Run()
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(mr.Close)
+
+		orig := impls.AccessTokenCache
+		impls.AccessTokenCache = redis.NewMockCache(
+			goredis.NewClient(&goredis.Options{Addr: mr.Addr()}), "oauth_access_token", 5*time.Minute,
+		)
+		DeferCleanup(func() { impls.AccessTokenCache = orig })
+
+		key := impls.AccessTokenHashKey{TokenHashes: oauth.TokenHashCandidates(subjectToken)}
+		Expect(impls.AccessTokenCache.Set(context.Background(), key, types.ResolvedAccessToken{
+			ClientID:  "my-app",
+			RealmName: blueking.Name,
+			Sub:       "sub-1",
+			Cnf:       oauth.Confirmation{JKT: "jkt-1", X5TS256: "x5t-1"},
+			ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		}, 5*time.Minute)).To(Succeed())
+	})
+
+	serve := func(cnf oauth.Confirmation) oauth.OAuthError {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/token", nil)
+		util.SetRealmName(c, blueking.Name)
+		util.SetClientID(c, "my-app")
+		util.SetClientType(c, oauth.ClientTypeConfidential)
+		util.SetClientAuthMethod(c, oauth.AuthMethodTLSClientAuth)
+		handleTokenExchangeGrant(c, &config.Config{}, TokenRequest{
+			GrantType:        oauth.GrantTypeTokenExchange,
+			SubjectToken:     subjectToken,
+			SubjectTokenType: oauth.TokenTypeURIAccessToken,
+		}, cnf)
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		var oauthErr oauth.OAuthError
+		Expect(json.Unmarshal(w.Body.Bytes(), &oauthErr)).To(Succeed())
+		return oauthErr
+	}
+
+	DescribeTable("should reject a bound subject token without proof of its key",
+		func(cnf oauth.Confirmation) {
+			Expect(serve(cnf).Code).To(Equal(oauth.ErrorCodeInvalidGrant))
+		},
+		Entry("without DPoP proof", oauth.Confirmation{X5TS256: "x5t-1"}),
+		Entry("with the DPoP proof of another key", oauth.Confirmation{JKT: "jkt-2", X5TS256: "x5t-1"}),
+		Entry("without client certificate", oauth.Confirmation{JKT: "jkt-1"}),
+		Entry("with another client certificate", oauth.Confirmation{JKT: "jkt-1", X5TS256: "x5t-2"}),
+	)
+
+	It("should accept a bound subject token presented with its key", func() {
+		// the request has no resource, so it only fails after the binding check
+		oauthErr := serve(oauth.Confirmation{JKT: "jkt-1", X5TS256: "x5t-1"})
+
+		Expect(oauthErr.Code).To(Equal(oauth.ErrorCodeInvalidRequest))
+		Expect(oauthErr.Description).To(Equal("resource is required"))
+	})
+})
+
+var _ = Describe("resolveClientCredentialsGrant", func() {
+	ctx := context.Background()
+	realm := blueking.New()
+
+	It("should build an app grant without subject", func() {
+		grant, err := resolveClientCredentialsGrant(ctx, realm, TokenRequest{
+			Resource: "gateway:bk-paas:api:get_users",
+			Scope:    "read read",
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(grant.Sub).To(BeEmpty())
+		Expect(grant.Username).To(BeEmpty())
+		Expect(grant.Audience).NotTo(BeEmpty())
+		Expect(grant.Scope).To(Equal("read"))
+		Expect(grant.GrantType).To(Equal(oauth.GrantTypeClientCredentials))
+	})
+
+	DescribeTable("should reject invalid requests",
+		func(req TokenRequest, wantCode string) {
+			_, err := resolveClientCredentialsGrant(ctx, realm, req)
+
+			oauthErr, ok := oauth.AsOAuthError(err)
+			Expect(ok).To(BeTrue())
+			Expect(oauthErr.Code).To(Equal(wantCode))
+		},
+		Entry("missing resource",
+			TokenRequest{}, oauth.ErrorCodeInvalidRequest),
+		Entry("invalid resource",
+			TokenRequest{Resource: ":::invalid"}, oauth.ErrorCodeInvalidRequest),
+		Entry("unsupported scope",
+			TokenRequest{Resource: "gateway:bk-paas:api:get_users", Scope: "admin"}, oauth.ErrorCodeInvalidScope),
+	)
+})
+
+var _ = Describe("resolveTokenExchangeGrant", func() {
+	ctx := context.Background()
+	realm := blueking.New()
+	resource := "gateway:bk-paas:api:get_users"
+
+	var subject types.ResolvedAccessToken
+
+	BeforeEach(func() {
+		audience, err := realm.ExtractAudiences(ctx, resource)
+		Expect(err).NotTo(HaveOccurred())
+		subject = types.ResolvedAccessToken{
+			GrantID:  "grant-1",
+			ClientID: "my-app",
+			TenantID: "default",
+			Sub:      "sub-1",
+			Username: "user-1",
+			Audience: append(audience, "mcp_server:other"),
+			Scope:    "read write",
+			Act:      oauth.NewActor("mcp-0", nil),
+		}
+	})
+
+	It("should keep the subject and narrow the audience", func() {
+		grant, err := resolveTokenExchangeGrant(ctx, realm, "mcp-1", subject, TokenRequest{
+			Resource: resource,
+			Scope:    "read",
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(grant.TenantID).To(Equal("default"))
+		Expect(grant.Sub).To(Equal("sub-1"))
+		Expect(grant.Username).To(Equal("user-1"))
+		Expect(grant.Audience).To(HaveLen(len(subject.Audience) - 1))
+		Expect(grant.Scope).To(Equal("read"))
+		Expect(grant.GrantType).To(Equal(oauth.GrantTypeTokenExchange))
+		Expect(grant.Act).To(Equal(oauth.NewActor("mcp-1", oauth.NewActor("mcp-0", nil))))
+	})
+
+	It("should inherit the subject scope when scope is omitted", func() {
+		grant, err := resolveTokenExchangeGrant(ctx, realm, "mcp-1", subject, TokenRequest{Resource: resource})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(grant.Scope).To(Equal("read write"))
+	})
+
+	DescribeTable("should reject invalid requests",
+		func(mutate func(*types.ResolvedAccessToken), req TokenRequest, wantCode string) {
+			mutate(&subject)
+
+			_, err := resolveTokenExchangeGrant(ctx, realm, "mcp-1", subject, req)
+
+			oauthErr, ok := oauth.AsOAuthError(err)
+			Expect(ok).To(BeTrue())
+			Expect(oauthErr.Code).To(Equal(wantCode))
+		},
+		Entry("missing resource",
+			func(*types.ResolvedAccessToken) {}, TokenRequest{}, oauth.ErrorCodeInvalidRequest),
+		Entry("invalid resource",
+			func(*types.ResolvedAccessToken) {}, TokenRequest{Resource: ":::invalid"}, oauth.ErrorCodeInvalidRequest),
+		Entry("audience outside the subject token",
+			func(s *types.ResolvedAccessToken) { s.Audience = []string{"mcp_server:other"} },
+			TokenRequest{Resource: resource}, oauth.ErrorCodeInvalidTarget),
+		Entry("scope outside the subject token",
+			func(s *types.ResolvedAccessToken) { s.Scope = "read" },
+			TokenRequest{Resource: resource, Scope: "write"}, oauth.ErrorCodeInvalidScope),
+		Entry("delegation chain at the maximum depth",
+			func(s *types.ResolvedAccessToken) {
+				s.Act = nil
+				for i := 0; i < oauth.MaxActorChainDepth; i++ {
+					s.Act = oauth.NewActor("mcp-0", s.Act)
+				}
+			},
+			TokenRequest{Resource: resource}, oauth.ErrorCodeInvalidRequest),
+	)
+})
@@ -0,0 +1,149 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"bkauth/pkg/cache/impls"
+	"bkauth/pkg/config"
+	"bkauth/pkg/oauth"
+	"bkauth/pkg/service/types"
+	"bkauth/pkg/util"
+)
+
+// UserInfoResponse represents the OpenID Connect UserInfo response (OIDC Core §5.3.2).
+type UserInfoResponse struct {
+	Sub               string `json:"sub"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	TenantID          string `json:"tenant_id"`
+}
+
+// NewUserInfoHandler creates a handler for the OpenID Connect UserInfo endpoint.
+// The caller presents a bkauth access token granted with the openid scope;
+// the token itself is the authentication, no client credentials are needed.
+func NewUserInfoHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accessToken, scheme := extractAccessToken(c)
+		if accessToken == "" {
+			// RFC 6750 §3.1: no error code when the request lacks authentication
+			c.Header("WWW-Authenticate", `Bearer`)
+			c.JSON(http.StatusUnauthorized, oauth.NewInvalidRequestError("access token is required"))
+			return
+		}
+
+		token, err := impls.GetAccessTokenByTokenHashes(c.Request.Context(), oauth.TokenHashCandidates(accessToken))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, oauth.NewServerError("failed to resolve access token"))
+			return
+		}
+
+		// tokens of another realm are invisible, same as introspection
+		if token.ClientID == "" || !token.IsActive() || token.RealmName != util.GetRealmName(c) {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.JSON(http.StatusUnauthorized, oauth.NewInvalidTokenError(
+				"the access token is not found, expired or revoked",
+			))
+			return
+		}
+
+		// RFC 9449 §7.1: DPoP-bound tokens must be presented with a proof of the bound key
+		if token.Cnf.JKT != "" {
+			if scheme != oauth.TokenTypeDPoP {
+				c.Header("WWW-Authenticate", `DPoP error="invalid_token"`)
+				c.JSON(http.StatusUnauthorized, oauth.NewInvalidTokenError(
+					"the access token is DPoP-bound and must use the DPoP scheme",
+				))
+				return
+			}
+
+			htu := oauth.UserInfoEndpointURL(cfg.BKAuthURL, token.RealmName)
+			proof, err := verifyDPoPRequest(c, htu, accessToken)
+			if err != nil {
+				oauthErr, ok := oauth.AsOAuthError(err)
+				if !ok {
+					c.JSON(http.StatusInternalServerError, oauth.NewServerError("failed to verify DPoP proof"))
+					return
+				}
+				c.Header("WWW-Authenticate", `DPoP error="invalid_dpop_proof"`)
+				c.JSON(http.StatusUnauthorized, oauthErr)
+				return
+			}
+			if proof.JKT != token.Cnf.JKT {
+				c.Header("WWW-Authenticate", `DPoP error="invalid_dpop_proof"`)
+				c.JSON(http.StatusUnauthorized, oauth.NewInvalidDPoPProofError(
+					"the DPoP proof is not signed by the key the access token is bound to",
+				))
+				return
+			}
+		}
+
+		// RFC 8705 §3: certificate-bound tokens must be presented over a connection
+		// authenticated with the same client certificate
+		if token.Cnf.X5TS256 != "" {
+			cert, _, err := oauth.ClientCertificateFromRequest(c.Request, cfg.OAuth.MutualTLS.ClientCertificateHeader)
+			if err != nil || cert == nil || oauth.CertificateThumbprint(cert) != token.Cnf.X5TS256 {
+				c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+				c.JSON(http.StatusUnauthorized, oauth.NewInvalidTokenError(
+					"the access token is bound to a different client certificate",
+				))
+				return
+			}
+		}
+
+		if !oauth.HasScope(token.Scope, oauth.ScopeOpenID) || token.Sub == "" {
+			c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+			c.JSON(http.StatusForbidden, oauth.NewInsufficientScopeError(
+				"the access token was not granted the openid scope",
+			))
+			return
+		}
+
+		c.JSON(http.StatusOK, newUserInfoResponse(token))
+	}
+}
+
+func newUserInfoResponse(token types.ResolvedAccessToken) UserInfoResponse {
+	return UserInfoResponse{
+		Sub:               token.Sub,
+		PreferredUsername: token.Username,
+		TenantID:          token.TenantID,
+	}
+}
+
+// extractAccessToken reads the access token and its scheme from the Authorization
+// header (RFC 6750 §2.1, RFC 9449 §7.1), falling back to the form-encoded body
+// parameter (RFC 6750 §2.2) which only carries bearer tokens.
+func extractAccessToken(c *gin.Context) (token, scheme string) {
+	if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+		for _, s := range []string{oauth.TokenTypeBearer, oauth.TokenTypeDPoP} {
+			if token, ok := strings.CutPrefix(authHeader, s+" "); ok {
+				return token, s
+			}
+		}
+		return "", ""
+	}
+	if c.Request.Method == http.MethodPost {
+		return c.PostForm("access_token"), oauth.TokenTypeBearer
+	}
+	return "", ""
+}
@@ -0,0 +1,96 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"bkauth/pkg/oauth"
+	"bkauth/pkg/service/types"
+)
+
+var _ = Describe("newUserInfoResponse", func() {
+	It("should map identity claims from the access token", func() {
+		resp := newUserInfoResponse(types.ResolvedAccessToken{
+			ClientID: "my-app",
+			TenantID: "default",
+			Sub:      "sub-1",
+			Username: "admin",
+		})
+
+		Expect(resp).To(Equal(UserInfoResponse{
+			Sub:               "sub-1",
+			PreferredUsername: "admin",
+			TenantID:          "default",
+		}))
+	})
+})
+
+var _ = Describe("extractAccessToken", func() {
+	newContext := func(method, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(method, "/userinfo", strings.NewReader(body))
+		if body != "" {
+			c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		return c
+	}
+
+	It("should read the Authorization header", func() {
+		c := newContext(http.MethodGet, "")
+		c.Request.Header.Set("Authorization", "Bearer bk_abc")
+		token, scheme := extractAccessToken(c)
+		Expect(token).To(Equal("bk_abc"))
+		Expect(scheme).To(Equal(oauth.TokenTypeBearer))
+	})
+
+	It("should read the DPoP scheme", func() {
+		c := newContext(http.MethodGet, "")
+		c.Request.Header.Set("Authorization", "DPoP bk_abc")
+		token, scheme := extractAccessToken(c)
+		Expect(token).To(Equal("bk_abc"))
+		Expect(scheme).To(Equal(oauth.TokenTypeDPoP))
+	})
+
+	It("should ignore non-bearer schemes", func() {
+		c := newContext(http.MethodGet, "")
+		c.Request.Header.Set("Authorization", "Basic YWJj")
+		token, _ := extractAccessToken(c)
+		Expect(token).To(BeEmpty())
+	})
+
+	It("should fall back to the form body for POST", func() {
+		c := newContext(http.MethodPost, "access_token=bk_abc")
+		token, scheme := extractAccessToken(c)
+		Expect(token).To(Equal("bk_abc"))
+		Expect(scheme).To(Equal(oauth.TokenTypeBearer))
+	})
+
+	It("should return empty when no token is presented", func() {
+		c := newContext(http.MethodGet, "")
+		token, _ := extractAccessToken(c)
+		Expect(token).To(BeEmpty())
+	})
+})
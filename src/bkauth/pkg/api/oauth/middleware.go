@@ -20,7 +20,10 @@ package oauth
 
 import (
 	"context"
+	"crypto/x509"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
@@ -28,6 +31,7 @@ import (
 	"bkauth/pkg/config"
 	pkgoauth "bkauth/pkg/oauth"
 	"bkauth/pkg/service"
+	"bkauth/pkg/service/types"
 	"bkauth/pkg/util"
 )
 
@@ -49,14 +53,17 @@ func RealmMiddleware() gin.HandlerFunc {
 }
 
 // ClientAuthMiddleware authenticates the OAuth client for endpoints that
-// require it (/token, /device/authorize, /revoke).
+// require it (/token, /device/authorize, /par, /revoke).
 //
 // It enforces the full authentication chain:
-//  1. Extract credentials (HTTP Basic Auth > POST body)
+//  1. Extract credentials (HTTP Basic Auth > POST body > client_assertion) and the client certificate, if any
 //  2. Require client_id, otherwise 400
 //  3. Look up the client, 401 if not registered
-//  4. If confidential, verify client_secret (with realm-level exemptions), 401 on failure
-//  5. Store the authenticated client_id in gin context
+//  4. Verify the client_assertion for private_key_jwt clients (RFC 7523), public or not;
+//     if confidential, verify the client certificate for mutual-TLS clients (RFC 8705),
+//     otherwise the client_secret (with realm-level exemptions), 401 on failure
+//  5. Store the authenticated client_id, the authentication method actually verified
+//     ("none" for public and secret-exempt clients) and the certificate thumbprint in gin context
 func ClientAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
@@ -79,6 +86,34 @@ func ClientAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			clientSecret = c.PostForm("client_secret")
 		}
 
+		// RFC 7521 §4.2: private_key_jwt carries the client credentials as a signed assertion
+		clientAssertion := c.PostForm("client_assertion")
+		if clientAssertionType := c.PostForm("client_assertion_type"); clientAssertionType != "" || clientAssertion != "" {
+			if clientAssertionType != pkgoauth.ClientAssertionTypeJWTBearer || clientAssertion == "" {
+				c.AbortWithStatusJSON(http.StatusBadRequest, pkgoauth.NewInvalidRequestError(
+					"client_assertion_type must be "+pkgoauth.ClientAssertionTypeJWTBearer+" with a client_assertion",
+				))
+				return
+			}
+			if hasBasicAuth || clientSecret != "" {
+				c.AbortWithStatusJSON(http.StatusBadRequest, pkgoauth.NewInvalidRequestError(
+					"client_assertion cannot be combined with client_secret",
+				))
+				return
+			}
+			// RFC 7523 §3: client_id is OPTIONAL, the "sub" of the assertion identifies the client
+			if clientID == "" {
+				sub, err := pkgoauth.ClientAssertionSubject(clientAssertion)
+				if err != nil {
+					c.AbortWithStatusJSON(http.StatusUnauthorized, pkgoauth.NewInvalidClientError(
+						"Client authentication failed",
+					))
+					return
+				}
+				clientID = sub
+			}
+		}
+
 		if clientID == "" {
 			c.AbortWithStatusJSON(http.StatusBadRequest, pkgoauth.OAuthError{
 				Code:        "invalid_request",
@@ -87,10 +122,21 @@ func ClientAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		clientSvc := service.NewOAuthClientService()
-		// TODO: consider adding cache for client existence check to reduce DB queries
-		exists, err := clientSvc.Exists(ctx, clientID)
-		if err != nil || !exists {
+		// RFC 8705: the certificate authenticates mutual-TLS clients and binds the issued tokens
+		cert, intermediates, err := pkgoauth.ClientCertificateFromRequest(
+			c.Request, cfg.OAuth.MutualTLS.ClientCertificateHeader,
+		)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, pkgoauth.NewInvalidClientError(
+				"Invalid client certificate",
+			))
+			return
+		}
+
+		clientSvc := impls.WrapOAuthClientService(service.NewOAuthClientService())
+		// TODO: consider adding cache for client lookup to reduce DB queries
+		authSpec, err := clientSvc.GetAuthSpec(ctx, clientID)
+		if err != nil || authSpec.ID == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, pkgoauth.OAuthError{
 				Code:        "invalid_client",
 				Description: "Client not found",
@@ -98,20 +144,78 @@ func ClientAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		// FIXME(nan): security decision is bound to a naming convention (IsPublicClient);
-		//  should be determined by a persistent client_type attribute from the database instead.
-		//  Mitigation: AppCode registration will reject IDs that match the dynamic client ID
-		//  prefix (e.g. "dcr_"), preventing confidential clients from being misclassified as public.
-		if !pkgoauth.IsPublicClient(clientID) {
-			if authErr := authenticateConfidentialClient(
-				ctx, clientID, clientSecret, &cfg.OAuth, realmName,
-			); authErr != nil {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, pkgoauth.OAuthError{
-					Code:        "invalid_client",
-					Description: "Client authentication failed",
-				})
-				return
+		// The client type is the persisted one, not derived from the client_id naming convention,
+		// so that a confidential client can never be authenticated as a public one.
+		var authErr error
+		authMethod := pkgoauth.AuthMethodNone
+		switch {
+		case authSpec.TokenEndpointAuthMethod == pkgoauth.AuthMethodPrivateKeyJWT || clientAssertion != "":
+			// RFC 7523 §3: the assertion may be addressed to the token endpoint, the issuer
+			// or the endpoint actually called (e.g. /par)
+			audiences := []string{
+				pkgoauth.TokenEndpointURL(cfg.BKAuthURL, realmName),
+				pkgoauth.IssuerURL(cfg.BKAuthURL, realmName),
+				util.URLJoin(cfg.BKAuthURL, c.Request.URL.Path),
 			}
+			authErr = authenticateJWTClient(ctx, authSpec, clientAssertion, audiences)
+			authMethod = pkgoauth.AuthMethodPrivateKeyJWT
+		case authSpec.IsPublic():
+		case authSpec.IsTLSClientAuth():
+			// a client_secret sent along is not verified, so it does not count as a credential
+			authErr = authenticateTLSClient(authSpec, cert, intermediates)
+			authMethod = authSpec.TokenEndpointAuthMethod
+		default:
+			authErr = authenticateConfidentialClient(ctx, clientID, clientSecret, &cfg.OAuth, realmName)
+			authMethod = clientSecretAuthMethod(clientSecret, hasBasicAuth)
+		}
+		if authErr != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, pkgoauth.OAuthError{
+				Code:        "invalid_client",
+				Description: "Client authentication failed",
+			})
+			return
+		}
+
+		util.SetClientID(c, clientID)
+		util.SetClientType(c, authSpec.Type)
+		util.SetClientAuthMethod(c, authMethod)
+		if cert != nil {
+			util.SetClientCertThumbprint(c, pkgoauth.CertificateThumbprint(cert))
+		}
+		c.Next()
+	}
+}
+
+// RegistrationAuthMiddleware authenticates requests to the client configuration endpoint
+// (RFC 7592 §2) with the registration access token issued when the client was registered,
+// and stores the :client_id path parameter into the gin context.
+//
+// An unknown client_id is reported exactly like a wrong token (RFC 7592 §2.1: 401),
+// so that callers cannot probe which clients exist.
+func RegistrationAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientID := c.Param("client_id")
+		registrationAccessToken, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || registrationAccessToken == "" {
+			c.Header("WWW-Authenticate", `Bearer`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, pkgoauth.NewInvalidTokenError(
+				"Registration access token is required",
+			))
+			return
+		}
+
+		clientSvc := service.NewOAuthClientService()
+		valid, err := clientSvc.VerifyRegistrationAccessToken(c.Request.Context(), clientID, registrationAccessToken)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, pkgoauth.NewServerError(err.Error()))
+			return
+		}
+		if !valid {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, pkgoauth.NewInvalidTokenError(
+				"Invalid registration access token",
+			))
+			return
 		}
 
 		util.SetClientID(c, clientID)
@@ -131,7 +235,8 @@ type accessAppHeader struct {
 // Chain:
 //  1. Bind and verify app credentials
 //  2. Authenticate: verify app secret
-//  3. Authorize: check per-realm introspect access via config
+//  3. Authorize: check per-realm introspect access via config; an app allowed for either
+//     token type passes, the handler only looks up the token types the app is allowed
 //
 // SECURITY: authenticate before authorize — do NOT reorder for performance.
 // Checking the allowlist before verifying credentials exposes an oracle
@@ -160,12 +265,14 @@ func RealmAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		realmName := util.GetRealmName(c)
-		if !cfg.OAuth.IsIntrospectAllowed(realmName, header.AppCode) {
+		if !cfg.OAuth.IsIntrospectAllowed(realmName, header.AppCode) &&
+			!cfg.OAuth.IsRefreshTokenIntrospectAllowed(realmName, header.AppCode) {
 			c.AbortWithStatusJSON(http.StatusForbidden, pkgoauth.NewAccessDeniedError(
 				"App code is not allowed to call this endpoint for realm: "+realmName,
 			))
 			return
 		}
+		util.SetAccessAppCode(c, header.AppCode)
 
 		c.Next()
 	}
@@ -201,3 +308,76 @@ func authenticateConfidentialClient(
 
 	return nil
 }
+
+// clientSecretAuthMethod returns the method of a client authenticated by authenticateConfidentialClient,
+// "none" for a secret-exempt client that presented no secret.
+func clientSecretAuthMethod(clientSecret string, hasBasicAuth bool) string {
+	switch {
+	case clientSecret == "":
+		return pkgoauth.AuthMethodNone
+	case hasBasicAuth:
+		return pkgoauth.AuthMethodClientSecretBasic
+	default:
+		return pkgoauth.AuthMethodClientSecretPost
+	}
+}
+
+// authenticateTLSClient verifies the client certificate of a mutual-TLS client (RFC 8705 §2):
+//   - tls_client_auth: the certificate chains to a trusted CA and carries the registered subject.
+//   - self_signed_tls_client_auth: the certificate is registered in the client's JWK Set.
+func authenticateTLSClient(
+	authSpec types.OAuthClientAuthSpec,
+	cert *x509.Certificate,
+	intermediates []*x509.Certificate,
+) error {
+	if cert == nil {
+		return pkgoauth.ErrMissingClientCertificate
+	}
+
+	if authSpec.TokenEndpointAuthMethod == pkgoauth.AuthMethodSelfSignedTLSClientAuth {
+		return pkgoauth.VerifySelfSignedClientCertificate(cert, authSpec.JWKS)
+	}
+	return pkgoauth.VerifyPKIClientCertificate(
+		cert, intermediates, authSpec.TLSClientAuthSubjectDN, authSpec.TLSClientAuthSAN,
+	)
+}
+
+// authenticateJWTClient verifies the client_assertion of a private_key_jwt client (RFC 7523 §3)
+// against its registered jwks, or the one published at its jwks_uri, and claims the jti
+// so that the assertion cannot be replayed.
+func authenticateJWTClient(
+	ctx context.Context,
+	authSpec types.OAuthClientAuthSpec,
+	assertion string,
+	audiences []string,
+) error {
+	if authSpec.TokenEndpointAuthMethod != pkgoauth.AuthMethodPrivateKeyJWT {
+		return fmt.Errorf("%w: client is not registered for private_key_jwt", pkgoauth.ErrInvalidClientAssertion)
+	}
+	if assertion == "" {
+		return pkgoauth.ErrMissingClientAssertion
+	}
+
+	jwks := authSpec.JWKS
+	if jwks == "" {
+		var err error
+		jwks, err = impls.GetClientJWKS(ctx, authSpec.JWKSURI)
+		if err != nil {
+			return err
+		}
+	}
+
+	verified, err := pkgoauth.VerifyClientAssertion(assertion, jwks, authSpec.ID, audiences)
+	if err != nil {
+		return err
+	}
+
+	claimed, err := impls.ClaimClientAssertion(ctx, authSpec.ID, verified.JTI, verified.ReplayWindow())
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return pkgoauth.ErrClientAssertionReplay
+	}
+	return nil
+}
@@ -19,14 +19,18 @@
 package oauth
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/stretchr/testify/assert"
 
 	"bkauth/pkg/config"
 	pkgoauth "bkauth/pkg/oauth"
+	"bkauth/pkg/service/types"
 )
 
 func TestMiddleware(t *testing.T) {
@@ -49,3 +53,65 @@ var _ = Describe("authenticateConfidentialClient", func() {
 		assert.ErrorIs(GinkgoT(), err, pkgoauth.ErrMissingClientSecret)
 	})
 })
+
+var _ = Describe("clientSecretAuthMethod", func() {
+	It("should report the secret method actually used", func() {
+		Expect(clientSecretAuthMethod("secret", true)).To(Equal(pkgoauth.AuthMethodClientSecretBasic))
+		Expect(clientSecretAuthMethod("secret", false)).To(Equal(pkgoauth.AuthMethodClientSecretPost))
+	})
+
+	It("should report none for a secret-exempt client", func() {
+		Expect(clientSecretAuthMethod("", false)).To(Equal(pkgoauth.AuthMethodNone))
+	})
+})
+
+var _ = Describe("authenticateTLSClient", func() {
+	// Certificate verification itself is tested in pkg/oauth/mtls_test.go.
+	It("should fail when no client certificate is presented", func() {
+		authSpec := types.OAuthClientAuthSpec{
+			ID:                      "mtls_app",
+			TokenEndpointAuthMethod: pkgoauth.AuthMethodTLSClientAuth,
+			TLSClientAuthSubjectDN:  "CN=mtls_app",
+		}
+		err := authenticateTLSClient(authSpec, nil, nil)
+		assert.ErrorIs(GinkgoT(), err, pkgoauth.ErrMissingClientCertificate)
+	})
+})
+
+var _ = Describe("authenticateJWTClient", func() {
+	// Assertion verification itself is tested in pkg/oauth/client_assertion_test.go.
+	It("should fail when the client is not registered for private_key_jwt", func() {
+		authSpec := types.OAuthClientAuthSpec{
+			ID:                      "dcr_abc",
+			TokenEndpointAuthMethod: pkgoauth.AuthMethodNone,
+		}
+		err := authenticateJWTClient(nil, authSpec, "assertion", nil)
+		assert.ErrorIs(GinkgoT(), err, pkgoauth.ErrInvalidClientAssertion)
+	})
+
+	It("should fail when no client assertion is presented", func() {
+		authSpec := types.OAuthClientAuthSpec{
+			ID:                      "dcr_abc",
+			TokenEndpointAuthMethod: pkgoauth.AuthMethodPrivateKeyJWT,
+			JWKSURI:                 "https://client.example.com/jwks.json",
+		}
+		err := authenticateJWTClient(nil, authSpec, "", nil)
+		assert.ErrorIs(GinkgoT(), err, pkgoauth.ErrMissingClientAssertion)
+	})
+})
+
+var _ = Describe("RegistrationAuthMiddleware", func() {
+	It("should reject requests without a registration access token", func() {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/register/dcr_abc", nil)
+		c.Params = gin.Params{{Key: "client_id", Value: "dcr_abc"}}
+
+		RegistrationAuthMiddleware()(c)
+
+		assert.True(GinkgoT(), c.IsAborted())
+		assert.Equal(GinkgoT(), http.StatusUnauthorized, w.Code)
+		assert.Equal(GinkgoT(), "Bearer", w.Header().Get("WWW-Authenticate"))
+	})
+})
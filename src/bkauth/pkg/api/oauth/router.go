@@ -36,10 +36,28 @@ func Register(cfg *config.Config, r *gin.RouterGroup) {
 	// Authorization Server Metadata (RFC 8414)
 	r.GET("/.well-known/oauth-authorization-server", handler.NewMetadataHandler(cfg))
 
+	// OpenID Connect Discovery 1.0
+	r.GET("/.well-known/openid-configuration", handler.NewOpenIDConfigurationHandler(cfg))
+
+	// JSON Web Key Set for verifying JWTs issued by this server (RFC 7517)
+	r.GET("/jwks.json", handler.NewJWKSHandler())
+
+	// OpenID Connect UserInfo — authenticated by the bearer access token itself
+	r.GET("/userinfo", handler.NewUserInfoHandler(cfg))
+	r.POST("/userinfo", handler.NewUserInfoHandler(cfg))
+
 	// Dynamic Client Registration (RFC 7591)
 	r.POST("/register", handler.NewRegisterHandler(cfg))
 
-	// Authorization Endpoint — validates params, creates consent, 302 to frontend
+	// Dynamic Client Registration Management (RFC 7592) — authenticated by the registration access token
+	registrationAuth := r.Group("/register/:client_id", RegistrationAuthMiddleware())
+	{
+		registrationAuth.GET("", handler.NewGetRegistrationHandler(cfg))
+		registrationAuth.PUT("", handler.NewUpdateRegistrationHandler(cfg))
+		registrationAuth.DELETE("", handler.NewDeleteRegistrationHandler(cfg))
+	}
+
+	// Authorization Endpoint — validates params (or loads them via request_uri), creates consent, 302 to frontend
 	r.GET("/authorize", handler.NewAuthorizeHandler(cfg))
 
 	// Device page redirect (no client auth needed)
@@ -48,7 +66,9 @@ func Register(cfg *config.Config, r *gin.RouterGroup) {
 	// Token Introspection (RFC 7662) — X-Bk-App-Code/Secret auth with per-realm access control
 	realmAuth := r.Group("", RealmAuthMiddleware(cfg))
 	{
-		realmAuth.POST("/introspect", handler.NewIntrospectHandler())
+		realmAuth.POST("/introspect", handler.NewIntrospectHandler(cfg))
+		// Revocation events, long-polled or streamed (SSE) and resumable by event id
+		realmAuth.GET("/revocations", handler.NewRevocationsHandler(cfg))
 	}
 
 	// Endpoints requiring OAuth client authentication
@@ -56,6 +76,8 @@ func Register(cfg *config.Config, r *gin.RouterGroup) {
 	{
 		// Device Authorization Grant (RFC 8628)
 		clientAuth.POST("/device/authorize", handler.NewDeviceAuthorizeHandler(cfg))
+		// Pushed Authorization Requests (RFC 9126)
+		clientAuth.POST("/par", handler.NewPushedAuthorizationRequestHandler())
 		// Token Endpoint
 		clientAuth.POST("/token", handler.NewTokenHandler(cfg))
 		// Token Revocation (RFC 7009)
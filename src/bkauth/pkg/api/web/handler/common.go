@@ -24,6 +24,7 @@ import (
 
 	"bkauth/pkg/cache/impls"
 	"bkauth/pkg/oauth"
+	"bkauth/pkg/service"
 	"bkauth/pkg/util"
 )
 
@@ -32,11 +33,16 @@ var errTenantMismatch = errors.New("user tenant does not match client tenant")
 // checkUserClientTenant resolves the client's tenant constraint and validates
 // it against the user's tenant.
 //
-// Public clients (DCR) are global and accept any user tenant.
+// Public clients (DCR / CIMD) are global and accept any user tenant.
 // Confidential clients inherit tenant_mode / tenant_id from the App record;
 // when tenant_mode is "single", the user's tenant_id must match exactly.
 func checkUserClientTenant(ctx context.Context, clientID, userTenantID string) error {
-	if oauth.IsPublicClient(clientID) {
+	clientSvc := impls.WrapOAuthClientService(service.NewOAuthClientService())
+	profile, err := clientSvc.GetProfile(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	if profile.Type == oauth.ClientTypePublic {
 		return nil
 	}
 
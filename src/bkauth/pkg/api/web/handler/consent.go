@@ -23,6 +23,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 
 	"bkauth/pkg/cache/impls"
 	"bkauth/pkg/config"
@@ -38,11 +39,14 @@ const (
 )
 
 type consentInfoResponse struct {
-	ClientName    string `json:"client_name"`
-	ClientType    string `json:"client_type"`
-	ClientLogoURI string `json:"client_logo_uri"`
-	RealmName     string `json:"realm_name"`
-	Resources     any    `json:"resources"`
+	ClientName    string        `json:"client_name"`
+	ClientType    string        `json:"client_type"`
+	ClientLogoURI string        `json:"client_logo_uri"`
+	RealmName     string        `json:"realm_name"`
+	Resources     any           `json:"resources"`
+	Scopes        []oauth.Scope `json:"scopes"`
+	// AuthorizationDetails lists the API-level permissions requested, if any
+	AuthorizationDetails []oauth.AuthorizationDetail `json:"authorization_details,omitempty"`
 }
 
 type consentConfirmRequest struct {
@@ -77,7 +81,7 @@ func NewConsentInfoHandler() gin.HandlerFunc {
 			return
 		}
 
-		clientSvc := service.NewOAuthClientService()
+		clientSvc := impls.WrapOAuthClientService(service.NewOAuthClientService())
 		profile, err := clientSvc.GetProfile(ctx, consent.ClientID)
 		if err != nil || profile.ID == "" {
 			webJSONError(c, http.StatusInternalServerError, webErrCodeInternal,
@@ -86,10 +90,13 @@ func NewConsentInfoHandler() gin.HandlerFunc {
 		}
 
 		var resources any
+		scopes := []oauth.Scope{}
 		realm := oauth.GetRealm(consent.RealmName)
 		if realm != nil {
 			resources, _ = realm.ResolveResourceDisplay(ctx, consent.Resource)
+			scopes = oauth.ResolveScopeDisplay(realm, consent.Scope)
 		}
+		authorizationDetails, _ := oauth.ParseAuthorizationDetails(consent.AuthorizationDetails)
 
 		webJSONSuccess(c, consentInfoResponse{
 			ClientName:    profile.Name,
@@ -97,6 +104,9 @@ func NewConsentInfoHandler() gin.HandlerFunc {
 			ClientLogoURI: profile.LogoURI,
 			RealmName:     consent.RealmName,
 			Resources:     resources,
+			Scopes:        scopes,
+
+			AuthorizationDetails: authorizationDetails,
 		})
 	}
 }
@@ -168,6 +178,13 @@ func NewConsentConfirmHandler(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		authorizationDetails, err := oauth.ParseAuthorizationDetails(consent.AuthorizationDetails)
+		if err != nil {
+			webJSONError(c, http.StatusInternalServerError, webErrCodeInternal,
+				"Failed to process authorization_details parameter")
+			return
+		}
+
 		code, err := oauth.GenerateAuthorizationCode()
 		if err != nil {
 			webJSONError(c, http.StatusInternalServerError, webErrCodeInternal,
@@ -185,8 +202,13 @@ func NewConsentConfirmHandler(cfg *config.Config) gin.HandlerFunc {
 			Username:            username,
 			RedirectURI:         consent.RedirectURI,
 			Audience:            audience,
+			Resource:            consent.Resource,
+			Scope:               consent.Scope,
+			Nonce:               consent.Nonce,
 			CodeChallenge:       consent.CodeChallenge,
 			CodeChallengeMethod: consent.CodeChallengeMethod,
+
+			AuthorizationDetails: authorizationDetails,
 		}
 
 		if err := authCodeSvc.CreateAuthorizationCode(ctx, authCode); err != nil {
@@ -195,6 +217,24 @@ func NewConsentConfirmHandler(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		// Remember the approval so that /authorize can skip the consent page next time;
+		// failing to do so only means the user will be asked again. An approval of
+		// authorization_details is not remembered, as it is narrower than the audience.
+		if cfg.OAuth.ConsentTTL > 0 && len(authorizationDetails) == 0 {
+			consentSvc := service.NewOAuthConsentService()
+			err = consentSvc.Grant(ctx, types.ConsentGrant{
+				TenantID:  userTenantID,
+				Sub:       sub,
+				RealmName: consent.RealmName,
+				ClientID:  consent.ClientID,
+				Audience:  audience,
+				Scope:     consent.Scope,
+			}, cfg.OAuth.ConsentTTL)
+			if err != nil {
+				zap.S().Warnf("record oauth consent fail, clientID=%s, err=%v", consent.ClientID, err)
+			}
+		}
+
 		redirectURL := oauth.BuildAuthorizationRedirectURL(consent.RedirectURI, consent.State, code)
 
 		webJSONSuccess(c, consentConfirmResponse{RedirectURL: redirectURL})
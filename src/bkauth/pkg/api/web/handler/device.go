@@ -24,6 +24,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"bkauth/pkg/cache/impls"
 	"bkauth/pkg/config"
 	"bkauth/pkg/oauth"
 	"bkauth/pkg/service"
@@ -40,11 +41,14 @@ type deviceVerifyRequest struct {
 }
 
 type deviceVerifyResponse struct {
-	ClientName    string `json:"client_name"`
-	ClientType    string `json:"client_type"`
-	ClientLogoURI string `json:"client_logo_uri"`
-	RealmName     string `json:"realm_name"`
-	Resources     any    `json:"resources"`
+	ClientName    string        `json:"client_name"`
+	ClientType    string        `json:"client_type"`
+	ClientLogoURI string        `json:"client_logo_uri"`
+	RealmName     string        `json:"realm_name"`
+	Resources     any           `json:"resources"`
+	Scopes        []oauth.Scope `json:"scopes"`
+	// AuthorizationDetails lists the API-level permissions requested, if any
+	AuthorizationDetails []oauth.AuthorizationDetail `json:"authorization_details,omitempty"`
 }
 
 type deviceConfirmRequest struct {
@@ -94,7 +98,7 @@ func NewDeviceVerifyHandler(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		clientSvc := service.NewOAuthClientService()
+		clientSvc := impls.WrapOAuthClientService(service.NewOAuthClientService())
 		profile, err := clientSvc.GetProfile(ctx, dc.ClientID)
 		clientName := dc.ClientID
 		clientType := ""
@@ -106,12 +110,16 @@ func NewDeviceVerifyHandler(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		var resources any
+		scopes := []oauth.Scope{}
 		realmName := dc.RealmName
 		if dc.Resource != "" && oauth.IsValidRealm(dc.RealmName) {
 			if display, err := oauth.GetRealm(dc.RealmName).ResolveResourceDisplay(ctx, dc.Resource); err == nil {
 				resources = display
 			}
 		}
+		if oauth.IsValidRealm(dc.RealmName) {
+			scopes = oauth.ResolveScopeDisplay(oauth.GetRealm(dc.RealmName), dc.Scope)
+		}
 
 		webJSONSuccess(c, deviceVerifyResponse{
 			ClientName:    clientName,
@@ -119,6 +127,9 @@ func NewDeviceVerifyHandler(cfg *config.Config) gin.HandlerFunc {
 			ClientLogoURI: clientLogoURI,
 			RealmName:     realmName,
 			Resources:     resources,
+			Scopes:        scopes,
+
+			AuthorizationDetails: dc.AuthorizationDetails,
 		})
 	}
 }
@@ -0,0 +1,179 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"bkauth/pkg/cache/impls"
+	"bkauth/pkg/config"
+	"bkauth/pkg/oauth"
+	"bkauth/pkg/service"
+	"bkauth/pkg/service/types"
+	"bkauth/pkg/util"
+)
+
+type grantRevokeRequest struct {
+	GrantID string `uri:"grant_id" binding:"required"`
+}
+
+type grantClientResponse struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	LogoURI string `json:"logo_uri"`
+}
+
+type grantResponse struct {
+	GrantID         string              `json:"grant_id"`
+	Client          grantClientResponse `json:"client"`
+	RealmName       string              `json:"realm_name"`
+	Resources       any                 `json:"resources"`
+	Scopes          []oauth.Scope       `json:"scopes"`
+	FirstIssuedAt   int64               `json:"first_issued_at"`
+	LastRefreshedAt int64               `json:"last_refreshed_at"`
+	ExpiresAt       int64               `json:"expires_at"`
+	// IdleExpiresAt is when the grant expires unless it is refreshed, 0 without an idle timeout
+	IdleExpiresAt int64 `json:"idle_expires_at"`
+}
+
+// NewGrantListHandler creates a handler for GET /oauth2/grants
+//
+// It lists the grant families of the logged-in user that can still be refreshed,
+// i.e. the clients the user has authorized and not revoked yet.
+// Grants that have gone idle for longer than the refresh token idle timeout are left out.
+func NewGrantListHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		tokenSvc := service.NewOAuthTokenService()
+		grants, err := tokenSvc.ListActiveGrants(ctx, util.GetTenantID(c), util.GetSub(c))
+		if err != nil {
+			webJSONError(c, http.StatusInternalServerError, webErrCodeInternal,
+				"failed to list grants")
+			return
+		}
+
+		clientSvc := impls.WrapOAuthClientService(service.NewOAuthClientService())
+		now := time.Now().Unix()
+		resp := make([]grantResponse, 0, len(grants))
+		for _, g := range grants {
+			var idleExpiresAt int64
+			if idleTimeout := cfg.OAuth.ResolveRefreshTokenIdleTimeout(g.RealmName, g.ClientID); idleTimeout > 0 {
+				idleExpiresAt = g.LastRefreshedAt + idleTimeout
+				if idleExpiresAt < now {
+					continue
+				}
+			}
+
+			// the client id is still displayed if the client profile cannot be resolved
+			client := grantClientResponse{ID: g.ClientID}
+			profile, err := clientSvc.GetProfile(ctx, g.ClientID)
+			if err != nil {
+				zap.S().Warnf("get oauth client profile fail, clientID=%s, err=%v", g.ClientID, err)
+			} else if profile.ID != "" {
+				client = grantClientResponse{
+					ID:      profile.ID,
+					Name:    profile.Name,
+					Type:    profile.Type,
+					LogoURI: profile.LogoURI,
+				}
+			}
+
+			grant := newGrantResponse(ctx, g, client)
+			grant.IdleExpiresAt = idleExpiresAt
+			resp = append(resp, grant)
+		}
+		webJSONSuccess(c, resp)
+	}
+}
+
+func newGrantResponse(ctx context.Context, g types.UserGrant, client grantClientResponse) grantResponse {
+	var resources any
+	scopes := []oauth.Scope{}
+	realm := oauth.GetRealm(g.RealmName)
+	if realm != nil {
+		if g.Resource != "" {
+			resources, _ = realm.ResolveResourceDisplay(ctx, g.Resource)
+		}
+		scopes = oauth.ResolveScopeDisplay(realm, g.Scope)
+	}
+
+	return grantResponse{
+		GrantID:         g.GrantID,
+		Client:          client,
+		RealmName:       g.RealmName,
+		Resources:       resources,
+		Scopes:          scopes,
+		FirstIssuedAt:   g.FirstIssuedAt,
+		LastRefreshedAt: g.LastRefreshedAt,
+		ExpiresAt:       g.ExpiresAt,
+	}
+}
+
+// NewGrantRevokeHandler creates a handler for DELETE /oauth2/grants/:grant_id
+//
+// Revoking a grant revokes every token of the family and forgets the consent,
+// so that the client has to ask the user again.
+func NewGrantRevokeHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req grantRevokeRequest
+		if err := c.ShouldBindUri(&req); err != nil {
+			webJSONErrorWithDetails(c, http.StatusBadRequest, webErrCodeInvalidArgument,
+				"invalid request",
+				[]webErrorDetail{{Field: "grant_id", Message: "grant_id is required"}})
+			return
+		}
+
+		ctx := c.Request.Context()
+		tenantID := util.GetTenantID(c)
+		sub := util.GetSub(c)
+
+		tokenSvc := service.NewOAuthTokenService()
+		grant, err := tokenSvc.RevokeGrantOfUser(ctx, tenantID, sub, req.GrantID)
+		if err != nil {
+			if errors.Is(err, oauth.ErrGrantNotFound) {
+				webJSONError(c, http.StatusNotFound, webErrCodeNotFound, "grant not found")
+				return
+			}
+			webJSONError(c, http.StatusInternalServerError, webErrCodeInternal,
+				"failed to revoke grant")
+			return
+		}
+
+		consentSvc := service.NewOAuthConsentService()
+		err = consentSvc.Revoke(ctx, types.ConsentGrant{
+			TenantID:  tenantID,
+			Sub:       sub,
+			RealmName: grant.RealmName,
+			ClientID:  grant.ClientID,
+		})
+		if err != nil {
+			zap.S().Warnf("revoke oauth consent fail, clientID=%s, err=%v", grant.ClientID, err)
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"bkauth/pkg/config"
+	"bkauth/pkg/oauth"
+	"bkauth/pkg/service"
+	"bkauth/pkg/service/types"
+	"bkauth/pkg/util"
+)
+
+type personalAccessTokenCreateRequest struct {
+	Name      string `json:"name" binding:"required,max=64"`
+	RealmName string `json:"realm_name" binding:"required"`
+	Resource  string `json:"resource" binding:"required,max=2048"`
+	// ExpiresIn is the lifetime in seconds, capped per realm
+	ExpiresIn int64 `json:"expires_in" binding:"required,min=1"`
+}
+
+type personalAccessTokenRevokeRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type personalAccessTokenResponse struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	RealmName string `json:"realm_name"`
+	Resource  string `json:"resource"`
+	TokenMask string `json:"token_mask"`
+	ExpiresAt int64  `json:"expires_at"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type personalAccessTokenCreateResponse struct {
+	personalAccessTokenResponse
+	// Token is shown only once, it cannot be retrieved later
+	Token string `json:"token"`
+}
+
+func newPersonalAccessTokenResponse(t types.PersonalAccessToken) personalAccessTokenResponse {
+	return personalAccessTokenResponse{
+		ID:        t.ID,
+		Name:      t.Name,
+		RealmName: t.RealmName,
+		Resource:  t.Resource,
+		TokenMask: t.TokenMask,
+		ExpiresAt: t.ExpiresAt,
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+// NewPersonalAccessTokenListHandler creates a handler for GET /personal-access-tokens
+func NewPersonalAccessTokenListHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		patSvc := service.NewOAuthPersonalAccessTokenService()
+		tokens, err := patSvc.ListBySub(c.Request.Context(), util.GetTenantID(c), util.GetSub(c))
+		if err != nil {
+			webJSONError(c, http.StatusInternalServerError, webErrCodeInternal,
+				"failed to list personal access tokens")
+			return
+		}
+
+		resp := make([]personalAccessTokenResponse, 0, len(tokens))
+		for _, t := range tokens {
+			resp = append(resp, newPersonalAccessTokenResponse(t))
+		}
+		webJSONSuccess(c, resp)
+	}
+}
+
+// NewPersonalAccessTokenCreateHandler creates a handler for POST /personal-access-tokens
+func NewPersonalAccessTokenCreateHandler(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req personalAccessTokenCreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			webJSONErrorWithDetails(c, http.StatusBadRequest, webErrCodeInvalidArgument,
+				"invalid request body",
+				[]webErrorDetail{
+					{Field: "name", Message: "name is required, at most 64 characters"},
+					{Field: "realm_name", Message: "realm_name is required"},
+					{Field: "resource", Message: "resource is required"},
+					{Field: "expires_in", Message: "expires_in is required, must be a positive number of seconds"},
+				})
+			return
+		}
+
+		if !oauth.IsValidRealm(req.RealmName) {
+			webJSONErrorWithDetails(c, http.StatusBadRequest, webErrCodeInvalidArgument,
+				"invalid realm",
+				[]webErrorDetail{{Field: "realm_name", Message: "realm not found"}})
+			return
+		}
+
+		maxTTL := cfg.OAuth.ResolvePersonalAccessTokenMaxTTL(req.RealmName)
+		if req.ExpiresIn > maxTTL {
+			webJSONErrorWithDetails(c, http.StatusBadRequest, webErrCodeInvalidArgument,
+				"expires_in exceeds the limit of the realm",
+				[]webErrorDetail{{Field: "expires_in", Message: fmt.Sprintf("must not exceed %d seconds", maxTTL)}})
+			return
+		}
+
+		ctx := c.Request.Context()
+		realm := oauth.GetRealm(req.RealmName)
+		if err := realm.ValidateResource(ctx, req.Resource); err != nil {
+			webJSONErrorWithDetails(c, http.StatusBadRequest, webErrCodeInvalidArgument,
+				"invalid resource",
+				[]webErrorDetail{{Field: "resource", Message: err.Error()}})
+			return
+		}
+		audience, err := realm.ExtractAudiences(ctx, req.Resource)
+		if err != nil {
+			webJSONErrorWithDetails(c, http.StatusBadRequest, webErrCodeInvalidArgument,
+				"invalid resource",
+				[]webErrorDetail{{Field: "resource", Message: err.Error()}})
+			return
+		}
+
+		patSvc := service.NewOAuthPersonalAccessTokenService()
+		token, err := patSvc.Create(ctx, types.PersonalAccessTokenInput{
+			TenantID:  util.GetTenantID(c),
+			Sub:       util.GetSub(c),
+			Username:  util.GetUsername(c),
+			RealmName: req.RealmName,
+			Name:      req.Name,
+			Resource:  req.Resource,
+			Audience:  audience,
+			Prefix:    realm.TokenPrefix(),
+			TTL:       req.ExpiresIn,
+		})
+		if err != nil {
+			webJSONError(c, http.StatusInternalServerError, webErrCodeInternal,
+				"failed to create personal access token")
+			return
+		}
+
+		webJSONSuccess(c, personalAccessTokenCreateResponse{
+			personalAccessTokenResponse: newPersonalAccessTokenResponse(token),
+			Token:                       token.Token,
+		})
+	}
+}
+
+// NewPersonalAccessTokenRevokeHandler creates a handler for DELETE /personal-access-tokens/:id
+func NewPersonalAccessTokenRevokeHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req personalAccessTokenRevokeRequest
+		if err := c.ShouldBindUri(&req); err != nil {
+			webJSONErrorWithDetails(c, http.StatusBadRequest, webErrCodeInvalidArgument,
+				"invalid request",
+				[]webErrorDetail{{Field: "id", Message: "id must be a positive integer"}})
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		patSvc := service.NewOAuthPersonalAccessTokenService()
+		if err := patSvc.Revoke(ctx, util.GetTenantID(c), util.GetSub(c), req.ID); err != nil {
+			if errors.Is(err, oauth.ErrPersonalAccessTokenNotFound) {
+				webJSONError(c, http.StatusNotFound, webErrCodeNotFound,
+					"personal access token not found")
+				return
+			}
+			webJSONError(c, http.StatusInternalServerError, webErrCodeInternal,
+				"failed to revoke personal access token")
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
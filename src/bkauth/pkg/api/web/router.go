@@ -43,5 +43,14 @@ func Register(cfg *config.Config, r *gin.RouterGroup) {
 		oauthGroup.POST("/consent", handler.NewConsentConfirmHandler(cfg))
 		oauthGroup.POST("/device/verify", handler.NewDeviceVerifyHandler(cfg))
 		oauthGroup.POST("/device/confirm", handler.NewDeviceConfirmHandler(cfg))
+		oauthGroup.GET("/grants", handler.NewGrantListHandler(cfg))
+		oauthGroup.DELETE("/grants/:grant_id", handler.NewGrantRevokeHandler())
+	}
+
+	patGroup := r.Group("/personal-access-tokens")
+	{
+		patGroup.GET("", handler.NewPersonalAccessTokenListHandler())
+		patGroup.POST("", handler.NewPersonalAccessTokenCreateHandler(cfg))
+		patGroup.DELETE("/:id", handler.NewPersonalAccessTokenRevokeHandler())
 	}
 }
@@ -0,0 +1,84 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package impls
+
+import (
+	"context"
+	"time"
+
+	"bkauth/pkg/cache"
+	"bkauth/pkg/errorx"
+	"bkauth/pkg/external/clientdoc"
+)
+
+type clientJWKSKey struct {
+	jwksURI string
+}
+
+func (k clientJWKSKey) Key() string {
+	return k.jwksURI
+}
+
+var retrieveClientJWKS = func(ctx context.Context, key cache.Key) (interface{}, error) {
+	k := key.(clientJWKSKey)
+
+	return clientdoc.FetchJWKS(ctx, k.jwksURI)
+}
+
+// GetClientJWKS returns the JWK Set document a client publishes at its jwks_uri.
+// The document is cached for a few minutes, so a client rotating its keys should
+// publish the new key ahead of using it.
+func GetClientJWKS(ctx context.Context, jwksURI string) (doc string, err error) {
+	key := clientJWKSKey{
+		jwksURI: jwksURI,
+	}
+
+	err = ClientJWKSCache.GetInto(ctx, key, &doc, retrieveClientJWKS)
+	if err != nil {
+		err = errorx.Wrapf(err, CacheLayer, "GetClientJWKS",
+			"ClientJWKSCache.GetInto jwksURI=`%s` fail", jwksURI)
+		return doc, err
+	}
+
+	return doc, nil
+}
+
+type clientAssertionKey struct {
+	clientID string
+	jti      string
+}
+
+func (k clientAssertionKey) Key() string {
+	return k.clientID + ":" + k.jti
+}
+
+// ClaimClientAssertion records the jti of a client assertion issued by clientID and
+// reports whether it is the first use, so that an assertion cannot be replayed
+// (RFC 7523 §3). ttl must cover the remaining lifetime of the assertion.
+func ClaimClientAssertion(ctx context.Context, clientID, jti string, ttl time.Duration) (bool, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(CacheLayer, "ClaimClientAssertion")
+
+	key := clientAssertionKey{clientID: clientID, jti: jti}
+	ok, err := ClientAssertionCache.SetNX(ctx, key, true, ttl)
+	if err != nil {
+		return false, errorWrapf(err, "ClientAssertionCache.SetNX jti=`%s` fail", jti)
+	}
+
+	return ok, nil
+}
@@ -0,0 +1,94 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth服务(BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package impls
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+
+	"bkauth/pkg/cache"
+	"bkauth/pkg/cache/redis"
+)
+
+var _ = Describe("GetClientJWKS", func() {
+	var (
+		ctx      context.Context
+		retrieve func(ctx context.Context, key cache.Key) (interface{}, error)
+		fetches  int
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ClientJWKSCache = redis.NewMockCache(newTestRedisClient(), "oauth_client_jwks", 5*time.Minute)
+
+		fetches = 0
+		retrieve = retrieveClientJWKS
+		retrieveClientJWKS = func(_ context.Context, key cache.Key) (interface{}, error) {
+			fetches++
+			return `{"keys":[]}`, nil
+		}
+	})
+
+	AfterEach(func() {
+		retrieveClientJWKS = retrieve
+	})
+
+	It("should fetch the document once and serve it from cache", func() {
+		doc, err := GetClientJWKS(ctx, "https://client.example.com/jwks.json")
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), `{"keys":[]}`, doc)
+
+		doc, err = GetClientJWKS(ctx, "https://client.example.com/jwks.json")
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), `{"keys":[]}`, doc)
+		assert.Equal(GinkgoT(), 1, fetches)
+	})
+})
+
+var _ = Describe("ClaimClientAssertion", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ClientAssertionCache = redis.NewMockCache(newTestRedisClient(), "oauth_client_assertion", 5*time.Minute)
+	})
+
+	It("should accept the first use and reject a replay", func() {
+		ok, err := ClaimClientAssertion(ctx, "my-app", "jti-1", time.Minute)
+		assert.NoError(GinkgoT(), err)
+		assert.True(GinkgoT(), ok)
+
+		ok, err = ClaimClientAssertion(ctx, "my-app", "jti-1", time.Minute)
+		assert.NoError(GinkgoT(), err)
+		assert.False(GinkgoT(), ok)
+	})
+
+	It("should scope jti to the client", func() {
+		ok, err := ClaimClientAssertion(ctx, "my-app", "jti-1", time.Minute)
+		assert.NoError(GinkgoT(), err)
+		assert.True(GinkgoT(), ok)
+
+		ok, err = ClaimClientAssertion(ctx, "other-app", "jti-1", time.Minute)
+		assert.NoError(GinkgoT(), err)
+		assert.True(GinkgoT(), ok)
+	})
+})
@@ -0,0 +1,162 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package impls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"bkauth/pkg/cache"
+	"bkauth/pkg/errorx"
+	"bkauth/pkg/external/clientdoc"
+	"bkauth/pkg/oauth"
+	"bkauth/pkg/service"
+	"bkauth/pkg/service/types"
+)
+
+type clientMetadataDocumentKey struct {
+	clientID string
+}
+
+func (k clientMetadataDocumentKey) Key() string {
+	return k.clientID
+}
+
+// only valid documents are returned, so that an invalid one is not cached
+var retrieveClientMetadataDocument = func(ctx context.Context, key cache.Key) (interface{}, error) {
+	k := key.(clientMetadataDocumentKey)
+
+	if err := oauth.ValidateClientIDMetadataDocumentURL(k.clientID); err != nil {
+		return nil, err
+	}
+
+	doc, err := clientdoc.FetchClientMetadata(ctx, k.clientID)
+	if err != nil {
+		// the document is hosted by the client: an unreachable one is the client's fault
+		return nil, fmt.Errorf("%w: %s", oauth.ErrInvalidClientMetadataDocument, err.Error())
+	}
+
+	return oauth.ParseClientMetadataDocument(k.clientID, doc)
+}
+
+// GetClientMetadataDocument returns the validated Client ID Metadata Document published
+// at a URL-formatted client_id. The document is cached for a few minutes, so changes
+// made by the client take effect with a delay.
+func GetClientMetadataDocument(ctx context.Context, clientID string) (doc oauth.ClientMetadataDocument, err error) {
+	key := clientMetadataDocumentKey{
+		clientID: clientID,
+	}
+
+	err = ClientMetadataDocumentCache.GetInto(ctx, key, &doc, retrieveClientMetadataDocument)
+	if err != nil {
+		err = errorx.Wrapf(err, CacheLayer, "GetClientMetadataDocument",
+			"ClientMetadataDocumentCache.GetInto clientID=`%s` fail", clientID)
+		return doc, err
+	}
+
+	return doc, nil
+}
+
+// getValidClientMetadataDocument resolves the document of a URL-formatted client_id,
+// reporting ok=false when the client_id does not point at a valid document, which
+// callers treat like an unregistered client.
+func getValidClientMetadataDocument(ctx context.Context, clientID string) (
+	doc oauth.ClientMetadataDocument, ok bool, err error,
+) {
+	doc, err = GetClientMetadataDocument(ctx, clientID)
+	if errors.Is(err, oauth.ErrInvalidClientMetadataDocument) {
+		zap.S().Warnf("invalid client metadata document, clientID=%s, err=%v", clientID, err)
+		return doc, false, nil
+	}
+	if err != nil {
+		return doc, false, err
+	}
+	return doc, true, nil
+}
+
+// clientMetadataDocumentAwareService resolves the clients with a URL-formatted client_id
+// from their Client ID Metadata Document, and delegates the others to the wrapped service.
+type clientMetadataDocumentAwareService struct {
+	service.OAuthClientService
+}
+
+// WrapOAuthClientService makes the flow spec, profile and auth spec lookups of svc
+// accept URL-formatted client_ids; such clients are public and never stored in oauth_client.
+func WrapOAuthClientService(svc service.OAuthClientService) service.OAuthClientService {
+	return clientMetadataDocumentAwareService{OAuthClientService: svc}
+}
+
+func (s clientMetadataDocumentAwareService) GetFlowSpec(
+	ctx context.Context, clientID string,
+) (types.OAuthClientFlowSpec, error) {
+	if !oauth.IsClientIDMetadataDocumentURL(clientID) {
+		return s.OAuthClientService.GetFlowSpec(ctx, clientID)
+	}
+
+	doc, ok, err := getValidClientMetadataDocument(ctx, clientID)
+	if err != nil || !ok {
+		return types.OAuthClientFlowSpec{}, err
+	}
+	return types.OAuthClientFlowSpec{
+		ID:           doc.ClientID,
+		Type:         oauth.ClientTypePublic,
+		GrantTypes:   doc.GrantTypes,
+		RedirectURIs: doc.RedirectURIs,
+	}, nil
+}
+
+func (s clientMetadataDocumentAwareService) GetProfile(
+	ctx context.Context, clientID string,
+) (types.OAuthClientProfile, error) {
+	if !oauth.IsClientIDMetadataDocumentURL(clientID) {
+		return s.OAuthClientService.GetProfile(ctx, clientID)
+	}
+
+	doc, ok, err := getValidClientMetadataDocument(ctx, clientID)
+	if err != nil || !ok {
+		return types.OAuthClientProfile{}, err
+	}
+	return types.OAuthClientProfile{
+		ID:      doc.ClientID,
+		Name:    doc.ClientName,
+		Type:    oauth.ClientTypePublic,
+		LogoURI: doc.LogoURI,
+	}, nil
+}
+
+func (s clientMetadataDocumentAwareService) GetAuthSpec(
+	ctx context.Context, clientID string,
+) (types.OAuthClientAuthSpec, error) {
+	if !oauth.IsClientIDMetadataDocumentURL(clientID) {
+		return s.OAuthClientService.GetAuthSpec(ctx, clientID)
+	}
+
+	doc, ok, err := getValidClientMetadataDocument(ctx, clientID)
+	if err != nil || !ok {
+		return types.OAuthClientAuthSpec{}, err
+	}
+	return types.OAuthClientAuthSpec{
+		ID:                      doc.ClientID,
+		Type:                    oauth.ClientTypePublic,
+		TokenEndpointAuthMethod: oauth.AuthMethodNone,
+	}, nil
+}
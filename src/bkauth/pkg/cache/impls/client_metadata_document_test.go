@@ -0,0 +1,123 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth服务(BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package impls
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"bkauth/pkg/cache"
+	"bkauth/pkg/cache/redis"
+	"bkauth/pkg/oauth"
+	"bkauth/pkg/service/mock"
+	"bkauth/pkg/service/types"
+)
+
+var _ = Describe("ClientMetadataDocument", func() {
+	const clientID = "https://client.example.com/oauth/client.json"
+
+	var (
+		ctx      context.Context
+		ctl      *gomock.Controller
+		retrieve func(ctx context.Context, key cache.Key) (interface{}, error)
+		fetches  int
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctl = gomock.NewController(GinkgoT())
+		ClientMetadataDocumentCache = redis.NewMockCache(
+			newTestRedisClient(), "oauth_client_metadata_document", 5*time.Minute,
+		)
+
+		fetches = 0
+		retrieve = retrieveClientMetadataDocument
+		retrieveClientMetadataDocument = func(_ context.Context, key cache.Key) (interface{}, error) {
+			fetches++
+			k := key.(clientMetadataDocumentKey)
+			if k.clientID != clientID {
+				return nil, fmt.Errorf("%w: unexpected status 404", oauth.ErrInvalidClientMetadataDocument)
+			}
+			return oauth.ClientMetadataDocument{
+				ClientID:     clientID,
+				ClientName:   "MCP Client",
+				RedirectURIs: []string{"http://127.0.0.1/callback"},
+				GrantTypes:   []string{oauth.GrantTypeAuthorizationCode},
+			}, nil
+		}
+	})
+
+	AfterEach(func() {
+		retrieveClientMetadataDocument = retrieve
+		ctl.Finish()
+	})
+
+	It("should fetch the document once and serve it from cache", func() {
+		doc, err := GetClientMetadataDocument(ctx, clientID)
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), "MCP Client", doc.ClientName)
+
+		_, err = GetClientMetadataDocument(ctx, clientID)
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), 1, fetches)
+	})
+
+	Describe("WrapOAuthClientService", func() {
+		It("should resolve a URL-formatted client_id as a public client", func() {
+			svc := WrapOAuthClientService(mock.NewMockOAuthClientService(ctl))
+
+			flowSpec, err := svc.GetFlowSpec(ctx, clientID)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []string{"http://127.0.0.1/callback"}, flowSpec.RedirectURIs)
+
+			profile, err := svc.GetProfile(ctx, clientID)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), oauth.ClientTypePublic, profile.Type)
+
+			authSpec, err := svc.GetAuthSpec(ctx, clientID)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), oauth.AuthMethodNone, authSpec.TokenEndpointAuthMethod)
+		})
+
+		It("should treat an invalid document like an unregistered client", func() {
+			svc := WrapOAuthClientService(mock.NewMockOAuthClientService(ctl))
+
+			flowSpec, err := svc.GetFlowSpec(ctx, "https://client.example.com/missing.json")
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), flowSpec.ID)
+		})
+
+		It("should delegate other client_ids to the wrapped service", func() {
+			mockService := mock.NewMockOAuthClientService(ctl)
+			mockService.EXPECT().GetFlowSpec(gomock.Any(), "dcr_abc").
+				Return(types.OAuthClientFlowSpec{ID: "dcr_abc"}, nil)
+			svc := WrapOAuthClientService(mockService)
+
+			flowSpec, err := svc.GetFlowSpec(ctx, "dcr_abc")
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "dcr_abc", flowSpec.ID)
+			assert.Equal(GinkgoT(), 0, fetches)
+		})
+	})
+})
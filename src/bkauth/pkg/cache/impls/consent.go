@@ -41,6 +41,10 @@ type Consent struct {
 	CodeChallenge       string `msgpack:"code_challenge"`
 	CodeChallengeMethod string `msgpack:"code_challenge_method,omitempty"`
 	Resource            string `msgpack:"resource"`
+	// AuthorizationDetails is the normalized RFC 9396 JSON, empty unless requested
+	AuthorizationDetails string `msgpack:"authorization_details,omitempty"`
+	Scope                string `msgpack:"scope,omitempty"`
+	Nonce                string `msgpack:"nonce,omitempty"`
 }
 
 type consentKey struct {
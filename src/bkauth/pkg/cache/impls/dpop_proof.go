@@ -0,0 +1,50 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package impls
+
+import (
+	"context"
+	"time"
+
+	"bkauth/pkg/errorx"
+)
+
+type dpopProofKey struct {
+	jkt string
+	jti string
+}
+
+func (k dpopProofKey) Key() string {
+	return k.jkt + ":" + k.jti
+}
+
+// ClaimDPoPProof records the jti of a DPoP proof signed by the key jkt and reports
+// whether it is the first use, so that a proof cannot be replayed (RFC 9449 §11.1).
+// ttl must cover the window in which the proof's iat is still accepted.
+func ClaimDPoPProof(ctx context.Context, jkt, jti string, ttl time.Duration) (bool, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(CacheLayer, "ClaimDPoPProof")
+
+	key := dpopProofKey{jkt: jkt, jti: jti}
+	ok, err := DPoPProofCache.SetNX(ctx, key, true, ttl)
+	if err != nil {
+		return false, errorWrapf(err, "DPoPProofCache.SetNX jti=`%s` fail", jti)
+	}
+
+	return ok, nil
+}
@@ -0,0 +1,58 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth服务(BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package impls
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+
+	"bkauth/pkg/cache/redis"
+)
+
+var _ = Describe("ClaimDPoPProof", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		DPoPProofCache = redis.NewMockCache(newTestRedisClient(), "oauth_dpop", 5*time.Minute)
+	})
+
+	It("should accept the first use and reject a replay", func() {
+		ok, err := ClaimDPoPProof(ctx, "jkt-1", "jti-1", time.Minute)
+		assert.NoError(GinkgoT(), err)
+		assert.True(GinkgoT(), ok)
+
+		ok, err = ClaimDPoPProof(ctx, "jkt-1", "jti-1", time.Minute)
+		assert.NoError(GinkgoT(), err)
+		assert.False(GinkgoT(), ok)
+	})
+
+	It("should scope jti to the key", func() {
+		ok, err := ClaimDPoPProof(ctx, "jkt-1", "jti-1", time.Minute)
+		assert.NoError(GinkgoT(), err)
+		assert.True(GinkgoT(), ok)
+
+		ok, err = ClaimDPoPProof(ctx, "jkt-2", "jti-1", time.Minute)
+		assert.NoError(GinkgoT(), err)
+		assert.True(GinkgoT(), ok)
+	})
+})
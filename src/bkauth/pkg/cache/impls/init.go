@@ -36,6 +36,12 @@ var (
 	AccessKeysCache  *redis.Cache
 	ConsentCache     *redis.Cache
 	AccessTokenCache *redis.Cache
+
+	PushedAuthorizationRequestCache *redis.Cache
+	DPoPProofCache                  *redis.Cache
+	ClientJWKSCache                 *redis.Cache
+	ClientAssertionCache            *redis.Cache
+	ClientMetadataDocumentCache     *redis.Cache
 )
 
 // InitCaches : Cache should only know about get/retrieve data
@@ -80,4 +86,39 @@ func InitCaches(disabled bool) {
 		"oct",
 		5*time.Minute,
 	)
+
+	PushedAuthorizationRequestCache = redis.NewCache(
+		bkauthredis.GetDefaultRedisClient(),
+		// opar = oauth pushed authorization request
+		"opar",
+		5*time.Minute,
+	)
+
+	DPoPProofCache = redis.NewCache(
+		bkauthredis.GetDefaultRedisClient(),
+		// odp = oauth dpop proof
+		"odp",
+		5*time.Minute,
+	)
+
+	ClientJWKSCache = redis.NewCache(
+		bkauthredis.GetDefaultRedisClient(),
+		// ojwks = oauth client jwks
+		"ojwks",
+		5*time.Minute,
+	)
+
+	ClientAssertionCache = redis.NewCache(
+		bkauthredis.GetDefaultRedisClient(),
+		// oca = oauth client assertion
+		"oca",
+		5*time.Minute,
+	)
+
+	ClientMetadataDocumentCache = redis.NewCache(
+		bkauthredis.GetDefaultRedisClient(),
+		// ocimd = oauth client id metadata document
+		"ocimd",
+		5*time.Minute,
+	)
 }
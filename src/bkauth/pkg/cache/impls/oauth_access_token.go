@@ -21,45 +21,60 @@ package impls
 import (
 	"context"
 
+	"go.uber.org/zap"
+
 	"bkauth/pkg/cache"
 	"bkauth/pkg/errorx"
+	"bkauth/pkg/oauth"
 	"bkauth/pkg/service"
 	"bkauth/pkg/service/types"
 )
 
+// AccessTokenHashKey is keyed by the first (current) of the hash candidates of a token,
+// the others are used to look up a token stored under a previous key.
 type AccessTokenHashKey struct {
-	TokenHash string
+	TokenHashes []oauth.TokenHash
 }
 
 func (k AccessTokenHashKey) Key() string {
-	return k.TokenHash
+	return k.TokenHashes[0].Hash
 }
 
-var retrieveAccessTokenByTokenHash = func(ctx context.Context, key cache.Key) (interface{}, error) {
+var retrieveAccessTokenByTokenHashes = func(ctx context.Context, key cache.Key) (interface{}, error) {
 	k := key.(AccessTokenHashKey)
 
 	svc := service.NewOAuthTokenService()
-	return svc.GetAccessTokenByTokenHash(ctx, k.TokenHash)
+	return svc.GetAccessTokenByTokenHashes(ctx, k.TokenHashes)
 }
 
-func GetAccessTokenByTokenHash(ctx context.Context, tokenHash string) (token types.ResolvedAccessToken, err error) {
+func GetAccessTokenByTokenHashes(
+	ctx context.Context, tokenHashes []oauth.TokenHash,
+) (token types.ResolvedAccessToken, err error) {
 	key := AccessTokenHashKey{
-		TokenHash: tokenHash,
+		TokenHashes: tokenHashes,
 	}
 
-	err = AccessTokenCache.GetInto(ctx, key, &token, retrieveAccessTokenByTokenHash)
+	err = AccessTokenCache.GetInto(ctx, key, &token, retrieveAccessTokenByTokenHashes)
 	if err != nil {
-		err = errorx.Wrapf(err, CacheLayer, "GetAccessTokenByTokenHash",
-			"AccessTokenCache.GetInto tokenHash=`%s` fail", tokenHash)
+		err = errorx.Wrapf(err, CacheLayer, "GetAccessTokenByTokenHashes",
+			"AccessTokenCache.GetInto tokenHash=`%s` fail", key.Key())
 		return token, err
 	}
 
 	return token, nil
 }
 
-func DeleteAccessTokenCache(ctx context.Context, tokenHash string) error {
-	key := AccessTokenHashKey{
-		TokenHash: tokenHash,
+// DeleteAccessTokenCaches evicts revoked access tokens by their hashes,
+// registered as the evictor of invalidation.KindAccessToken.
+func DeleteAccessTokenCaches(ctx context.Context, tokenHashes []string) {
+	keys := make([]cache.Key, 0, len(tokenHashes))
+	for _, tokenHash := range tokenHashes {
+		keys = append(keys, AccessTokenHashKey{
+			TokenHashes: []oauth.TokenHash{{Hash: tokenHash}},
+		})
+	}
+
+	if err := AccessTokenCache.BatchDelete(ctx, keys); err != nil {
+		zap.S().Errorf("delete access token cache fail, count=%d, err=%v", len(keys), err)
 	}
-	return AccessTokenCache.Delete(ctx, key)
 }
@@ -11,6 +11,7 @@ import (
 
 	"bkauth/pkg/cache"
 	"bkauth/pkg/cache/redis"
+	"bkauth/pkg/oauth"
 	"bkauth/pkg/service/mock"
 	"bkauth/pkg/service/types"
 )
@@ -22,12 +23,14 @@ var _ = Describe("AccessTokenCache", func() {
 	})
 
 	It("Key", func() {
-		key := AccessTokenHashKey{TokenHash: "abc123"}
+		key := AccessTokenHashKey{TokenHashes: []oauth.TokenHash{{KeyID: "k1", Hash: "abc123"}, {Hash: "legacy"}}}
 		assert.Equal(GinkgoT(), "abc123", key.Key())
 	})
 
-	Context("GetAccessTokenByTokenHash", func() {
+	Context("GetAccessTokenByTokenHashes", func() {
 		var ctl *gomock.Controller
+		hashes1 := []oauth.TokenHash{{KeyID: "k1", Hash: "hash1"}, {Hash: "legacy1"}}
+		hashesMissing := []oauth.TokenHash{{KeyID: "k1", Hash: "hash-missing"}, {Hash: "legacy-missing"}}
 		BeforeEach(func() {
 			ctl = gomock.NewController(GinkgoT())
 		})
@@ -38,7 +41,7 @@ var _ = Describe("AccessTokenCache", func() {
 		It("ok", func() {
 			mockSvc := mock.NewMockOAuthTokenService(ctl)
 			mockSvc.EXPECT().
-				GetAccessTokenByTokenHash(gomock.Any(), "hash1").
+				GetAccessTokenByTokenHashes(gomock.Any(), hashes1).
 				Return(types.ResolvedAccessToken{
 					ClientID:  "client-1",
 					RealmName: "blueking",
@@ -49,14 +52,14 @@ var _ = Describe("AccessTokenCache", func() {
 				}, nil).
 				AnyTimes()
 
-			origRetrieve := retrieveAccessTokenByTokenHash
-			retrieveAccessTokenByTokenHash = func(ctx context.Context, key cache.Key) (interface{}, error) {
+			origRetrieve := retrieveAccessTokenByTokenHashes
+			retrieveAccessTokenByTokenHashes = func(ctx context.Context, key cache.Key) (interface{}, error) {
 				k := key.(AccessTokenHashKey)
-				return mockSvc.GetAccessTokenByTokenHash(ctx, k.TokenHash)
+				return mockSvc.GetAccessTokenByTokenHashes(ctx, k.TokenHashes)
 			}
-			defer func() { retrieveAccessTokenByTokenHash = origRetrieve }()
+			defer func() { retrieveAccessTokenByTokenHashes = origRetrieve }()
 
-			token, err := GetAccessTokenByTokenHash(context.Background(), "hash1")
+			token, err := GetAccessTokenByTokenHashes(context.Background(), hashes1)
 			assert.NoError(GinkgoT(), err)
 			assert.Equal(GinkgoT(), "client-1", token.ClientID)
 			assert.Equal(GinkgoT(), "blueking", token.RealmName)
@@ -69,44 +72,51 @@ var _ = Describe("AccessTokenCache", func() {
 		It("error", func() {
 			mockSvc := mock.NewMockOAuthTokenService(ctl)
 			mockSvc.EXPECT().
-				GetAccessTokenByTokenHash(gomock.Any(), "hash1").
+				GetAccessTokenByTokenHashes(gomock.Any(), hashes1).
 				Return(types.ResolvedAccessToken{}, errors.New("db error")).
 				AnyTimes()
 
-			origRetrieve := retrieveAccessTokenByTokenHash
-			retrieveAccessTokenByTokenHash = func(ctx context.Context, key cache.Key) (interface{}, error) {
+			origRetrieve := retrieveAccessTokenByTokenHashes
+			retrieveAccessTokenByTokenHashes = func(ctx context.Context, key cache.Key) (interface{}, error) {
 				k := key.(AccessTokenHashKey)
-				return mockSvc.GetAccessTokenByTokenHash(ctx, k.TokenHash)
+				return mockSvc.GetAccessTokenByTokenHashes(ctx, k.TokenHashes)
 			}
-			defer func() { retrieveAccessTokenByTokenHash = origRetrieve }()
+			defer func() { retrieveAccessTokenByTokenHashes = origRetrieve }()
 
-			_, err := GetAccessTokenByTokenHash(context.Background(), "hash1")
+			_, err := GetAccessTokenByTokenHashes(context.Background(), hashes1)
 			assert.Error(GinkgoT(), err)
 		})
 
 		It("not found returns zero value", func() {
 			mockSvc := mock.NewMockOAuthTokenService(ctl)
 			mockSvc.EXPECT().
-				GetAccessTokenByTokenHash(gomock.Any(), "hash-missing").
+				GetAccessTokenByTokenHashes(gomock.Any(), hashesMissing).
 				Return(types.ResolvedAccessToken{}, nil).
 				AnyTimes()
 
-			origRetrieve := retrieveAccessTokenByTokenHash
-			retrieveAccessTokenByTokenHash = func(ctx context.Context, key cache.Key) (interface{}, error) {
+			origRetrieve := retrieveAccessTokenByTokenHashes
+			retrieveAccessTokenByTokenHashes = func(ctx context.Context, key cache.Key) (interface{}, error) {
 				k := key.(AccessTokenHashKey)
-				return mockSvc.GetAccessTokenByTokenHash(ctx, k.TokenHash)
+				return mockSvc.GetAccessTokenByTokenHashes(ctx, k.TokenHashes)
 			}
-			defer func() { retrieveAccessTokenByTokenHash = origRetrieve }()
+			defer func() { retrieveAccessTokenByTokenHashes = origRetrieve }()
 
-			token, err := GetAccessTokenByTokenHash(context.Background(), "hash-missing")
+			token, err := GetAccessTokenByTokenHashes(context.Background(), hashesMissing)
 			assert.NoError(GinkgoT(), err)
 			assert.Equal(GinkgoT(), "", token.ClientID)
 			assert.Equal(GinkgoT(), int64(0), token.ExpiresAt)
 		})
 	})
 
-	It("DeleteAccessTokenCache", func() {
-		err := DeleteAccessTokenCache(context.Background(), "hash1")
+	It("DeleteAccessTokenCaches", func() {
+		hashes := []oauth.TokenHash{{Hash: "hash1"}}
+		err := AccessTokenCache.Set(context.Background(), AccessTokenHashKey{TokenHashes: hashes},
+			types.ResolvedAccessToken{ClientID: "client-1"}, 0)
 		assert.NoError(GinkgoT(), err)
+		assert.True(GinkgoT(), AccessTokenCache.Exists(context.Background(), AccessTokenHashKey{TokenHashes: hashes}))
+
+		DeleteAccessTokenCaches(context.Background(), []string{"hash1", "hash2"})
+
+		assert.False(GinkgoT(), AccessTokenCache.Exists(context.Background(), AccessTokenHashKey{TokenHashes: hashes}))
 	})
 })
@@ -0,0 +1,82 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package impls
+
+import (
+	"context"
+	"time"
+
+	"bkauth/pkg/errorx"
+)
+
+// PushedAuthorizationRequest holds the authorization request parameters pushed to
+// the /par endpoint (RFC 9126), referenced by a one-time request_uri.
+type PushedAuthorizationRequest struct {
+	RealmName           string `msgpack:"realm_name"`
+	ClientID            string `msgpack:"client_id"`
+	RedirectURI         string `msgpack:"redirect_uri"`
+	ResponseType        string `msgpack:"response_type"`
+	State               string `msgpack:"state,omitempty"`
+	CodeChallenge       string `msgpack:"code_challenge"`
+	CodeChallengeMethod string `msgpack:"code_challenge_method,omitempty"`
+	Resource            string `msgpack:"resource"`
+	// AuthorizationDetails is the normalized RFC 9396 JSON, empty unless requested
+	AuthorizationDetails string `msgpack:"authorization_details,omitempty"`
+	Scope                string `msgpack:"scope,omitempty"`
+	Nonce                string `msgpack:"nonce,omitempty"`
+	Prompt               string `msgpack:"prompt,omitempty"`
+}
+
+type pushedAuthorizationRequestKey struct {
+	requestURI string
+}
+
+func (k pushedAuthorizationRequestKey) Key() string {
+	return k.requestURI
+}
+
+// CreatePushedAuthorizationRequest stores a pushed authorization request in Redis under the given request_uri.
+func CreatePushedAuthorizationRequest(
+	ctx context.Context, requestURI string, par PushedAuthorizationRequest, ttl int64,
+) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(CacheLayer, "CreatePushedAuthorizationRequest")
+
+	key := pushedAuthorizationRequestKey{requestURI: requestURI}
+	if err := PushedAuthorizationRequestCache.Set(ctx, key, par, time.Duration(ttl)*time.Second); err != nil {
+		return errorWrapf(err, "PushedAuthorizationRequestCache.Set fail")
+	}
+
+	return nil
+}
+
+// ConsumePushedAuthorizationRequest retrieves and deletes a pushed authorization request
+// atomically, so that a request_uri can be used only once (RFC 9126 §4).
+func ConsumePushedAuthorizationRequest(ctx context.Context, requestURI string) (PushedAuthorizationRequest, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(CacheLayer, "ConsumePushedAuthorizationRequest")
+
+	key := pushedAuthorizationRequestKey{requestURI: requestURI}
+	var par PushedAuthorizationRequest
+	if err := PushedAuthorizationRequestCache.GetAndDelete(ctx, key, &par); err != nil {
+		return PushedAuthorizationRequest{}, errorWrapf(
+			err, "PushedAuthorizationRequestCache.GetAndDelete request_uri=`%s` fail", requestURI,
+		)
+	}
+
+	return par, nil
+}
@@ -0,0 +1,72 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth服务(BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package impls
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+
+	"bkauth/pkg/cache/redis"
+)
+
+var _ = Describe("PushedAuthorizationRequest", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		PushedAuthorizationRequestCache = redis.NewMockCache(newTestRedisClient(), "oauth_par", 5*time.Minute)
+	})
+
+	requestURI := "urn:ietf:params:oauth:request_uri:abc"
+
+	It("should persist a request that can be consumed", func() {
+		par := PushedAuthorizationRequest{
+			RealmName:   "blueking",
+			ClientID:    "test-client",
+			RedirectURI: "https://example.com/callback",
+			Resource:    "bk_paas",
+		}
+
+		err := CreatePushedAuthorizationRequest(ctx, requestURI, par, 60)
+		assert.NoError(GinkgoT(), err)
+
+		got, err := ConsumePushedAuthorizationRequest(ctx, requestURI)
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), par, got)
+	})
+
+	It("should allow a request_uri to be consumed only once", func() {
+		err := CreatePushedAuthorizationRequest(ctx, requestURI, PushedAuthorizationRequest{ClientID: "c"}, 60)
+		assert.NoError(GinkgoT(), err)
+
+		_, err = ConsumePushedAuthorizationRequest(ctx, requestURI)
+		assert.NoError(GinkgoT(), err)
+
+		_, err = ConsumePushedAuthorizationRequest(ctx, requestURI)
+		assert.Error(GinkgoT(), err)
+	})
+
+	It("should return error for non-existent request_uri", func() {
+		_, err := ConsumePushedAuthorizationRequest(ctx, "urn:ietf:params:oauth:request_uri:none")
+		assert.Error(GinkgoT(), err)
+	})
+})
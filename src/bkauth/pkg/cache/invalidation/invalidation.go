@@ -0,0 +1,107 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package invalidation
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+
+	bkauthredis "bkauth/pkg/redis"
+)
+
+// KindAccessToken is the kind of the cached access tokens, keyed by token hash
+const KindAccessToken = "access_token"
+
+// Message is an invalidation broadcast over redis pub/sub
+type Message struct {
+	Kind string   `json:"kind"`
+	Keys []string `json:"keys"`
+}
+
+// Evictor evicts the cached objects of a kind by their keys
+type Evictor func(ctx context.Context, keys []string)
+
+// evictors holds the evictor of each kind, see RegisterEvictor.
+var evictors = make(map[string]Evictor)
+
+// RegisterEvictor registers the evictor of the shared (redis) cache of a kind.
+// The cache layer depends on the service layer, which invalidates the objects it changes,
+// so the evictors are registered at startup (initCaches) instead of being called directly,
+// before any request may invalidate; the map is not guarded against later writes.
+func RegisterEvictor(kind string, evictor Evictor) {
+	evictors[kind] = evictor
+}
+
+// Invalidate evicts the objects from the shared cache, then broadcasts the invalidation
+// so that every replica can flush its local caches too.
+// Failures are only logged: the cached objects expire on their own.
+func Invalidate(ctx context.Context, kind string, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	if evict, ok := evictors[kind]; ok {
+		evict(ctx, keys)
+	}
+
+	cli := bkauthredis.GetDefaultRedisClient()
+	if cli == nil {
+		return
+	}
+	payload, err := json.Marshal(Message{Kind: kind, Keys: keys})
+	if err != nil {
+		zap.S().Warnf("marshal cache invalidation fail, kind=%s, err=%v", kind, err)
+		return
+	}
+	if err := cli.Publish(ctx, bkauthredis.GetChannelKey(), payload).Err(); err != nil {
+		zap.S().Warnf("publish cache invalidation fail, kind=%s, err=%v", kind, err)
+	}
+}
+
+// Subscribe calls handle with every invalidation broadcast, until the context is done.
+// Local (in-process) caches subscribe to flush the objects invalidated by any replica.
+func Subscribe(ctx context.Context, handle func(Message)) {
+	cli := bkauthredis.GetDefaultRedisClient()
+	if cli == nil {
+		return
+	}
+
+	sub := cli.Subscribe(ctx, bkauthredis.GetChannelKey())
+	defer sub.Close()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var m Message
+			if err := json.Unmarshal([]byte(msg.Payload), &m); err != nil {
+				zap.S().Warnf("unmarshal cache invalidation fail, payload=%s, err=%v", msg.Payload, err)
+				continue
+			}
+			handle(m)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package invalidation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvalidate(t *testing.T) {
+	var evicted []string
+	RegisterEvictor(KindAccessToken, func(_ context.Context, keys []string) {
+		evicted = append(evicted, keys...)
+	})
+	defer delete(evictors, KindAccessToken)
+
+	Invalidate(context.Background(), KindAccessToken, nil)
+	assert.Empty(t, evicted)
+
+	Invalidate(context.Background(), KindAccessToken, []string{"hash-1", "hash-2"})
+	assert.Equal(t, []string{"hash-1", "hash-2"}, evicted)
+
+	// nothing registered for the kind
+	Invalidate(context.Background(), "unknown", []string{"hash-3"})
+	assert.Equal(t, []string{"hash-1", "hash-2"}, evicted)
+}
+
+func TestMessage(t *testing.T) {
+	payload, err := json.Marshal(Message{Kind: KindAccessToken, Keys: []string{"hash-1"}})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"kind":"access_token","keys":["hash-1"]}`, string(payload))
+}
@@ -125,6 +125,23 @@ func (c *Cache) Set(ctx context.Context, key bkauthCache.Key, value interface{},
 	})
 }
 
+// SetNX execute `setnx`, returns false if the key already exists
+func (c *Cache) SetNX(
+	ctx context.Context, key bkauthCache.Key, value interface{}, duration time.Duration,
+) (bool, error) {
+	if duration == time.Duration(0) {
+		duration = c.defaultExpiration
+	}
+
+	b, err := c.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	k := c.genKey(key.Key())
+	return c.cli.SetNX(ctx, k, b, duration).Result()
+}
+
 // Get execute `get`
 func (c *Cache) Get(ctx context.Context, key bkauthCache.Key, value interface{}) error {
 	k := c.genKey(key.Key())
@@ -189,6 +206,24 @@ func (c *Cache) Delete(ctx context.Context, key bkauthCache.Key) (err error) {
 	return err
 }
 
+// GetAndDelete execute `get` and `del` in a tx pipeline, so that the value can be read only once
+func (c *Cache) GetAndDelete(ctx context.Context, key bkauthCache.Key, value interface{}) error {
+	k := c.genKey(key.Key())
+
+	pipe := c.cli.TxPipeline()
+	getCmd := pipe.Get(ctx, k)
+	pipe.Del(ctx, k)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	b, err := getCmd.Bytes()
+	if err != nil {
+		return err
+	}
+	return c.Unmarshal(b, value)
+}
+
 // BatchDelete execute `del` with pipeline
 func (c *Cache) BatchDelete(ctx context.Context, keys []bkauthCache.Key) error {
 	newKeys := make([]string, 0, len(keys))
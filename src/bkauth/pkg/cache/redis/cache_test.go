@@ -69,6 +69,25 @@ var _ = Describe("RedisCache", func() {
 		assert.Equal(GinkgoT(), 1, a)
 	})
 
+	It("SetNX", func() {
+		key := cache.NewStringKey("nxkey")
+		ctx := context.Background()
+
+		ok, err := c.SetNX(ctx, key, 1, 0)
+		assert.NoError(GinkgoT(), err)
+		assert.True(GinkgoT(), ok)
+
+		// already exists
+		ok, err = c.SetNX(ctx, key, 2, 0)
+		assert.NoError(GinkgoT(), err)
+		assert.False(GinkgoT(), ok)
+
+		var a int
+		err = c.Get(ctx, key, &a)
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), 1, a)
+	})
+
 	It("GetInto", func() {
 		retrieveTest := func(ctx context.Context, key cache.Key) (interface{}, error) {
 			return "ok", nil
@@ -105,6 +124,30 @@ var _ = Describe("RedisCache", func() {
 		assert.NoError(GinkgoT(), err)
 	})
 
+	It("GetAndDelete", func() {
+		key := cache.NewStringKey("gdkey")
+		ctx := context.Background()
+
+		// not exists
+		var a int
+		err := c.GetAndDelete(ctx, key, &a)
+		assert.Error(GinkgoT(), err)
+
+		// set
+		err = c.Set(ctx, key, 1, 0)
+		assert.NoError(GinkgoT(), err)
+
+		// get and delete
+		err = c.GetAndDelete(ctx, key, &a)
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), 1, a)
+		assert.False(GinkgoT(), c.Exists(ctx, key))
+
+		// can only be read once
+		err = c.GetAndDelete(ctx, key, &a)
+		assert.Error(GinkgoT(), err)
+	})
+
 	It("BatchDelete", func() {
 		key1 := cache.NewStringKey("d1key")
 		key2 := cache.NewStringKey("d2key")
@@ -0,0 +1,72 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"bkauth/pkg/service"
+	"bkauth/pkg/service/types"
+)
+
+// CleanupExpiredTokens deletes the tokens and codes that expired or were revoked more than
+// retention seconds ago, or only counts them in a dry run.
+func CleanupExpiredTokens(retention, batchSize int64, dryRun bool) {
+	// 1. 参数校验
+	if retention <= 0 || batchSize <= 0 {
+		fmt.Println("retention and batch_size must positive integer")
+		return
+	}
+
+	// 2. 统计或删除
+	ctx := context.Background()
+	before := time.Now().Add(-time.Duration(retention) * time.Second)
+	svc := service.NewOAuthCleanupService()
+
+	var (
+		results []types.CleanupResult
+		err     error
+	)
+	if dryRun {
+		results, err = svc.CountExpired(ctx, before)
+	} else {
+		results, err = svc.DeleteExpired(ctx, before, batchSize)
+	}
+
+	// 3. 统一输出, 失败时也输出已删除的行数
+	action := "Deleted"
+	if dryRun {
+		action = "ToDelete"
+	}
+	fmt.Printf("Table\t%s\n", action)
+	for _, r := range results {
+		fmt.Printf("%s\t%d\n", r.Table, r.Count)
+	}
+
+	if err != nil {
+		zap.S().Error(err, fmt.Sprintf("cleanup expired tokens before=%s fail", before))
+		return
+	}
+
+	fmt.Println("cleanup success")
+}
@@ -27,6 +27,13 @@ import (
 const (
 	defaultAccessTokenTTL  int64 = 7200    // 2 hours
 	defaultRefreshTokenTTL int64 = 2592000 // 30 days
+
+	defaultPersonalAccessTokenMaxTTL int64 = 7776000 // 90 days
+	defaultConsentTTL                int64 = 2592000 // 30 days
+
+	defaultTokenCleanupInterval  int64 = 3600   // 1 hour
+	defaultTokenCleanupRetention int64 = 604800 // 7 days
+	defaultTokenCleanupBatchSize int64 = 1000
 )
 
 // Server ...
@@ -39,6 +46,10 @@ type Server struct {
 	ReadTimeout  int
 	WriteTimeout int
 	IdleTimeout  int
+
+	// TLS lets BKAuth terminate TLS itself. Client certificates are requested
+	// but not verified by the handshake, mutual-TLS client authentication does that.
+	TLS TLS
 }
 
 // LogConfig ...
@@ -171,13 +182,14 @@ type ProfilingConfig struct {
 }
 
 // TokenTTLOverride allows overriding the default AccessToken/RefreshToken TTL
-// for a specific (RealmName, ClientID) combination.
+// and RefreshToken idle timeout for a specific (RealmName, ClientID) combination.
 // ClientID can be "*" to match all clients within a realm.
 type TokenTTLOverride struct {
-	RealmName       string
-	ClientID        string
-	AccessTokenTTL  int64
-	RefreshTokenTTL int64
+	RealmName               string
+	ClientID                string
+	AccessTokenTTL          int64
+	RefreshTokenTTL         int64
+	RefreshTokenIdleTimeout int64
 }
 
 // tokenTTLKey is the lookup key for pre-computed TTL override map.
@@ -200,12 +212,93 @@ type IntrospectAllowedAppCode struct {
 	AppCode   string
 }
 
+// PushedAuthorizationRequestRequirement requires a client to push its authorization
+// requests via the PAR endpoint (RFC 9126) on the specified realm.
+// ClientID can be "*" to require PAR for all clients within a realm.
+type PushedAuthorizationRequestRequirement struct {
+	RealmName string
+	ClientID  string
+}
+
+// PersonalAccessTokenMaxTTL caps the lifetime a user may choose for a personal
+// access token on the specified realm (exact match only).
+type PersonalAccessTokenMaxTTL struct {
+	RealmName string
+	MaxTTL    int64
+}
+
+// PublicClientPolicy is the default policy on a realm of the public clients
+// (e.g. DCR / CIMD) that have no policy of their own.
+// AllowedResources are resource patterns in which "*" matches any sequence,
+// empty means any resource accepted by the realm.
+type PublicClientPolicy struct {
+	RealmName        string
+	AllowedResources []string
+}
+
+// TokenCleanup configures the janitor that deletes expired and revoked rows of the
+// access token, refresh token, authorization code and device code tables.
+type TokenCleanup struct {
+	// Enabled runs the janitor inside bkauth; only the replica holding the redis lock works.
+	Enabled bool
+	// Interval between two runs, in seconds (default: 3600)
+	Interval int64
+	// Retention is how long, in seconds, a row is kept after it expired or was revoked (default: 604800)
+	Retention int64
+	// BatchSize is the maximum number of rows deleted by one statement (default: 1000)
+	BatchSize int64
+}
+
+// MutualTLS configures mutual-TLS client authentication and certificate-bound
+// access tokens (RFC 8705).
+type MutualTLS struct {
+	// ClientCertificateHeader is the request header in which a TLS-terminating ingress
+	// forwards the verified client certificate, as URL-escaped PEM or base64 DER
+	// (e.g. X-Client-Cert). The ingress MUST strip this header from incoming requests.
+	// Empty means only certificates presented on a TLS connection to BKAuth itself are used.
+	ClientCertificateHeader string
+	// TrustedCAs is the PEM bundle of CAs issuing certificates of tls_client_auth clients.
+	// Empty disables tls_client_auth; self_signed_tls_client_auth needs no CA.
+	TrustedCAs string
+}
+
+// SigningKey is an asymmetric private key used to sign JWTs issued by BKAuth
+// (e.g. JWT access tokens, RFC 9068). Keys are published via the JWKS endpoint.
+type SigningKey struct {
+	// KID is the key identifier, carried in the JWT header and the JWKS.
+	KID string
+	// Algorithm is the JWS algorithm: RS256 or ES256.
+	// If empty, it is derived from the key type (RSA -> RS256, EC P-256 -> ES256).
+	Algorithm string
+	// PrivateKey is the PEM-encoded private key (PKCS#1, PKCS#8 or SEC1).
+	PrivateKey string
+	// RetiredAt (RFC 3339) is the time the key stops signing new tokens.
+	// A retired key remains published until every token it signed has expired.
+	// Empty means the key is active.
+	RetiredAt string
+}
+
+// TokenHashKey is an HMAC key ("pepper") of the stored access token, refresh token and
+// device code hashes.
+type TokenHashKey struct {
+	// ID is the key identifier, stored alongside every hash computed with the key.
+	ID string
+	// Secret is the HMAC secret, at least 32 bytes.
+	Secret string
+}
+
 // OAuth holds OAuth 2.0 protocol-specific configuration.
 type OAuth struct {
 	// AccessTokenTTL is the lifetime of access token in seconds (default: 7200)
 	AccessTokenTTL int64
 	// RefreshTokenTTL is the lifetime of refresh token in seconds (default: 2592000)
 	RefreshTokenTTL int64
+	// RefreshTokenIdleTimeout is how long, in seconds, a refresh token family may go without
+	// being rotated before it expires, regardless of RefreshTokenTTL (default: 0, disabled).
+	RefreshTokenIdleTimeout int64
+	// ConsentTTL is how long, in seconds, an approved consent is re-used by /authorize
+	// without showing the consent page again (default: 2592000). Negative disables re-use.
+	ConsentTTL int64
 	// DCREnabled indicates whether Dynamic Client Registration is enabled
 	DCREnabled bool
 	// DefaultRealmName is used for backward-compatible endpoints that don't specify a realm.
@@ -214,6 +307,10 @@ type OAuth struct {
 	// endpoint, on a per-realm basis (exact match only).
 	// If empty, all requests are denied.
 	IntrospectAllowedAppCodes []IntrospectAllowedAppCode
+	// RefreshTokenIntrospectAllowedAppCodes controls which AppCodes may introspect
+	// refresh tokens, separately from access tokens (exact match only).
+	// If empty, refresh tokens are introspected as inactive.
+	RefreshTokenIntrospectAllowedAppCodes []IntrospectAllowedAppCode
 	// ConfidentialClientSecretExemptions exempts specific confidential clients
 	// from client_secret verification on a per-(Realm, ClientID) basis (exact match only).
 	// Default: empty (all confidential clients must provide client_secret).
@@ -221,6 +318,35 @@ type OAuth struct {
 	// TokenTTLOverrides allows per-(realm, clientID) TTL configuration.
 	// Lookup priority: exact (realm, clientID) > realm wildcard (realm, "*") > global default.
 	TokenTTLOverrides []TokenTTLOverride
+	// JWTAccessTokenRealms lists the realms that issue JWT access tokens (RFC 9068)
+	// instead of opaque tokens. Requires at least one active SigningKeys entry.
+	JWTAccessTokenRealms []string
+	// SigningKeys holds the JWT signing keys, all published via the JWKS endpoint.
+	// The first active (not retired) key signs new tokens; any later active key is
+	// published only, which allows announcing a new key before switching to it.
+	// To rotate, move the new key first and set RetiredAt on the old one.
+	SigningKeys []SigningKey
+	// PushedAuthorizationRequestRequirements lists the (realm, clientID) pairs whose
+	// /authorize requests must reference a pushed authorization request (RFC 9126).
+	// Lookup priority: exact (realm, clientID) > realm wildcard (realm, "*").
+	// Default: empty (PAR is optional for all clients).
+	PushedAuthorizationRequestRequirements []PushedAuthorizationRequestRequirement
+	// MutualTLS configures tls_client_auth / self_signed_tls_client_auth (RFC 8705).
+	MutualTLS MutualTLS
+	// PersonalAccessTokenMaxTTLs caps the lifetime of personal access tokens per realm,
+	// in seconds. Realms without an entry use the default cap of 90 days.
+	PersonalAccessTokenMaxTTLs []PersonalAccessTokenMaxTTL
+	// PublicClientPolicies restricts the realms and resources of public clients without
+	// a policy of their own. Once configured, such clients may only use the listed realms.
+	// Default: empty (public clients are not restricted).
+	PublicClientPolicies []PublicClientPolicy
+	// TokenCleanup configures the deletion of expired and revoked tokens and codes.
+	TokenCleanup TokenCleanup
+	// TokenHashKeys holds the HMAC keys of the stored token hashes. The first key hashes
+	// new tokens; the others are only used to look up tokens stored under them.
+	// To rotate, first append the new key, then move it first once every replica has it.
+	// Default: empty (tokens are stored with plain SHA-256, which stays readable afterwards).
+	TokenHashKeys []TokenHashKey
 
 	// tokenTTLMap is pre-computed in Load() for O(1) lookups.
 	tokenTTLMap map[tokenTTLKey]*TokenTTLOverride
@@ -228,6 +354,14 @@ type OAuth struct {
 	secretExemptMap map[ConfidentialClientSecretExemption]struct{}
 	// introspectAllowedMap is pre-computed in Load() for O(1) lookups.
 	introspectAllowedMap map[IntrospectAllowedAppCode]struct{}
+	// refreshTokenIntrospectAllowedMap is pre-computed in Load() for O(1) lookups.
+	refreshTokenIntrospectAllowedMap map[IntrospectAllowedAppCode]struct{}
+	// jwtAccessTokenRealmSet is pre-computed in Load() for O(1) lookups.
+	jwtAccessTokenRealmSet map[string]struct{}
+	// parRequiredMap is pre-computed in Load() for O(1) lookups.
+	parRequiredMap map[PushedAuthorizationRequestRequirement]struct{}
+	// patMaxTTLMap is pre-computed in Load() for O(1) lookups.
+	patMaxTTLMap map[string]int64
 }
 
 // ResolveTokenTTL returns the effective (accessTokenTTL, refreshTokenTTL) for the
@@ -266,6 +400,49 @@ func (o *OAuth) ResolveTokenTTL(realmName, clientID string) (accessTTL, refreshT
 	return accessTTL, refreshTTL
 }
 
+// ResolveRefreshTokenIdleTimeout returns the effective refresh token idle timeout for the
+// given realm and clientID, with the same lookup priority as ResolveTokenTTL.
+// Zero means the refresh token family never expires for inactivity.
+func (o *OAuth) ResolveRefreshTokenIdleTimeout(realmName, clientID string) int64 {
+	idleTimeout := o.RefreshTokenIdleTimeout
+
+	if o.tokenTTLMap == nil {
+		return idleTimeout
+	}
+
+	if ov, ok := o.tokenTTLMap[tokenTTLKey{RealmName: realmName, ClientID: "*"}]; ok {
+		if ov.RefreshTokenIdleTimeout > 0 {
+			idleTimeout = ov.RefreshTokenIdleTimeout
+		}
+	}
+
+	if ov, ok := o.tokenTTLMap[tokenTTLKey{RealmName: realmName, ClientID: clientID}]; ok {
+		if ov.RefreshTokenIdleTimeout > 0 {
+			idleTimeout = ov.RefreshTokenIdleTimeout
+		}
+	}
+
+	return idleTimeout
+}
+
+// MaxAccessTokenTTL returns the longest access token TTL across the global default
+// and all overrides, i.e. the longest time any issued access token may stay valid.
+func (o *OAuth) MaxAccessTokenTTL() int64 {
+	maxTTL := o.AccessTokenTTL
+	for _, ov := range o.TokenTTLOverrides {
+		if ov.AccessTokenTTL > maxTTL {
+			maxTTL = ov.AccessTokenTTL
+		}
+	}
+	return maxTTL
+}
+
+// IsJWTAccessTokenEnabled reports whether the given realm issues JWT access tokens.
+func (o *OAuth) IsJWTAccessTokenEnabled(realmName string) bool {
+	_, ok := o.jwtAccessTokenRealmSet[realmName]
+	return ok
+}
+
 // IsIntrospectAllowed reports whether the given appCode is allowed to call
 // the introspect endpoint for the specified realm.
 // Returns false when no entries are configured (deny by default).
@@ -274,6 +451,14 @@ func (o *OAuth) IsIntrospectAllowed(realmName, appCode string) bool {
 	return ok
 }
 
+// IsRefreshTokenIntrospectAllowed reports whether the given appCode is allowed to
+// introspect refresh tokens for the specified realm.
+// Returns false when no entries are configured (deny by default).
+func (o *OAuth) IsRefreshTokenIntrospectAllowed(realmName, appCode string) bool {
+	_, ok := o.refreshTokenIntrospectAllowedMap[IntrospectAllowedAppCode{RealmName: realmName, AppCode: appCode}]
+	return ok
+}
+
 // IsClientSecretExempt reports whether the given (realmName, clientID) is exempt
 // from client_secret verification (exact match only).
 func (o *OAuth) IsClientSecretExempt(realmName, clientID string) bool {
@@ -281,6 +466,25 @@ func (o *OAuth) IsClientSecretExempt(realmName, clientID string) bool {
 	return ok
 }
 
+// IsPARRequired reports whether the given client must use pushed authorization
+// requests on the given realm, either by an exact entry or by the realm wildcard.
+func (o *OAuth) IsPARRequired(realmName, clientID string) bool {
+	if _, ok := o.parRequiredMap[PushedAuthorizationRequestRequirement{RealmName: realmName, ClientID: "*"}]; ok {
+		return true
+	}
+	_, ok := o.parRequiredMap[PushedAuthorizationRequestRequirement{RealmName: realmName, ClientID: clientID}]
+	return ok
+}
+
+// ResolvePersonalAccessTokenMaxTTL returns the longest lifetime, in seconds, a personal
+// access token may be created with on the given realm.
+func (o *OAuth) ResolvePersonalAccessTokenMaxTTL(realmName string) int64 {
+	if maxTTL, ok := o.patMaxTTLMap[realmName]; ok && maxTTL > 0 {
+		return maxTTL
+	}
+	return defaultPersonalAccessTokenMaxTTL
+}
+
 type Config struct {
 	Debug bool
 	// 是否开启多租户模式
@@ -356,6 +560,18 @@ func Load(v *viper.Viper) (*Config, error) {
 	if cfg.OAuth.RefreshTokenTTL == 0 {
 		cfg.OAuth.RefreshTokenTTL = defaultRefreshTokenTTL
 	}
+	if cfg.OAuth.ConsentTTL == 0 {
+		cfg.OAuth.ConsentTTL = defaultConsentTTL
+	}
+	if cfg.OAuth.TokenCleanup.Interval <= 0 {
+		cfg.OAuth.TokenCleanup.Interval = defaultTokenCleanupInterval
+	}
+	if cfg.OAuth.TokenCleanup.Retention <= 0 {
+		cfg.OAuth.TokenCleanup.Retention = defaultTokenCleanupRetention
+	}
+	if cfg.OAuth.TokenCleanup.BatchSize <= 0 {
+		cfg.OAuth.TokenCleanup.BatchSize = defaultTokenCleanupBatchSize
+	}
 	// 5. Build token TTL override map for O(1) lookups
 	cfg.OAuth.tokenTTLMap = make(map[tokenTTLKey]*TokenTTLOverride, len(cfg.OAuth.TokenTTLOverrides))
 	for i := range cfg.OAuth.TokenTTLOverrides {
@@ -377,6 +593,31 @@ func Load(v *viper.Viper) (*Config, error) {
 	for _, entry := range cfg.OAuth.IntrospectAllowedAppCodes {
 		cfg.OAuth.introspectAllowedMap[entry] = struct{}{}
 	}
+	cfg.OAuth.refreshTokenIntrospectAllowedMap = make(
+		map[IntrospectAllowedAppCode]struct{}, len(cfg.OAuth.RefreshTokenIntrospectAllowedAppCodes),
+	)
+	for _, entry := range cfg.OAuth.RefreshTokenIntrospectAllowedAppCodes {
+		cfg.OAuth.refreshTokenIntrospectAllowedMap[entry] = struct{}{}
+	}
+
+	// 8. Build JWT access token realm set for O(1) lookups
+	cfg.OAuth.jwtAccessTokenRealmSet = make(map[string]struct{}, len(cfg.OAuth.JWTAccessTokenRealms))
+	for _, realmName := range cfg.OAuth.JWTAccessTokenRealms {
+		cfg.OAuth.jwtAccessTokenRealmSet[realmName] = struct{}{}
+	}
+
+	// 9. Build PAR requirement map for O(1) lookups
+	parRequirements := cfg.OAuth.PushedAuthorizationRequestRequirements
+	cfg.OAuth.parRequiredMap = make(map[PushedAuthorizationRequestRequirement]struct{}, len(parRequirements))
+	for _, req := range parRequirements {
+		cfg.OAuth.parRequiredMap[req] = struct{}{}
+	}
+
+	// 10. Build personal access token max TTL map for O(1) lookups
+	cfg.OAuth.patMaxTTLMap = make(map[string]int64, len(cfg.OAuth.PersonalAccessTokenMaxTTLs))
+	for _, entry := range cfg.OAuth.PersonalAccessTokenMaxTTLs {
+		cfg.OAuth.patMaxTTLMap[entry.RealmName] = entry.MaxTTL
+	}
 
 	return &cfg, nil
 }
@@ -117,6 +117,32 @@ var _ = Describe("OAuth Config", func() {
 		})
 	})
 
+	Describe("ResolveRefreshTokenIdleTimeout", func() {
+		It("should return the global default when no overrides configured", func() {
+			o := buildOAuthWithOverrides(nil)
+			o.RefreshTokenIdleTimeout = 86400
+			assert.Equal(GinkgoT(), int64(86400), o.ResolveRefreshTokenIdleTimeout("blueking", "some_app"))
+		})
+
+		It("should let exact match override wildcard", func() {
+			o := buildOAuthWithOverrides([]TokenTTLOverride{
+				{RealmName: "blueking", ClientID: "*", RefreshTokenIdleTimeout: 604800},
+				{RealmName: "blueking", ClientID: "special_app", RefreshTokenIdleTimeout: 3600},
+				{RealmName: "blueking", ClientID: "ttl_only_app", AccessTokenTTL: 900},
+			})
+
+			assert.Equal(GinkgoT(), int64(3600), o.ResolveRefreshTokenIdleTimeout("blueking", "special_app"))
+			assert.Equal(GinkgoT(), int64(604800), o.ResolveRefreshTokenIdleTimeout("blueking", "ttl_only_app"))
+			assert.Equal(GinkgoT(), int64(604800), o.ResolveRefreshTokenIdleTimeout("blueking", "normal_app"))
+			assert.Equal(GinkgoT(), int64(0), o.ResolveRefreshTokenIdleTimeout("bk-devops", "normal_app"))
+		})
+
+		It("should return the global default when tokenTTLMap is nil", func() {
+			o := &OAuth{RefreshTokenIdleTimeout: 86400}
+			assert.Equal(GinkgoT(), int64(86400), o.ResolveRefreshTokenIdleTimeout("blueking", "any"))
+		})
+	})
+
 	Describe("IsIntrospectAllowed", func() {
 		buildOAuthWithIntrospectAllowed := func(entries []IntrospectAllowedAppCode) *OAuth {
 			o := &OAuth{
@@ -163,6 +189,28 @@ var _ = Describe("OAuth Config", func() {
 		})
 	})
 
+	Describe("IsRefreshTokenIntrospectAllowed", func() {
+		It("should deny all when refreshTokenIntrospectAllowedMap is nil", func() {
+			o := &OAuth{}
+			assert.False(GinkgoT(), o.IsRefreshTokenIntrospectAllowed("blueking", "any_app"))
+		})
+
+		It("should be configured separately from access token introspection", func() {
+			o := &OAuth{
+				introspectAllowedMap: map[IntrospectAllowedAppCode]struct{}{
+					{RealmName: "blueking", AppCode: "bk_apigateway"}: {},
+				},
+				refreshTokenIntrospectAllowedMap: map[IntrospectAllowedAppCode]struct{}{
+					{RealmName: "blueking", AppCode: "bk_support"}: {},
+				},
+			}
+			assert.False(GinkgoT(), o.IsRefreshTokenIntrospectAllowed("blueking", "bk_apigateway"))
+			assert.True(GinkgoT(), o.IsRefreshTokenIntrospectAllowed("blueking", "bk_support"))
+			assert.False(GinkgoT(), o.IsRefreshTokenIntrospectAllowed("bk-devops", "bk_support"))
+			assert.False(GinkgoT(), o.IsIntrospectAllowed("blueking", "bk_support"))
+		})
+	})
+
 	Describe("IsClientSecretExempt", func() {
 		buildOAuthWithExemptions := func(exemptions []ConfidentialClientSecretExemption) *OAuth {
 			o := &OAuth{
@@ -194,4 +242,72 @@ var _ = Describe("OAuth Config", func() {
 			assert.False(GinkgoT(), o.IsClientSecretExempt("blueking", "other_app"))
 		})
 	})
+
+	Describe("MaxAccessTokenTTL", func() {
+		It("should return the global default when no overrides configured", func() {
+			o := buildOAuthWithOverrides(nil)
+			assert.Equal(GinkgoT(), int64(7200), o.MaxAccessTokenTTL())
+		})
+
+		It("should return the longest override", func() {
+			o := buildOAuthWithOverrides([]TokenTTLOverride{
+				{RealmName: "blueking", ClientID: "*", AccessTokenTTL: 3600},
+				{RealmName: "blueking", ClientID: "long_app", AccessTokenTTL: 86400},
+				{RealmName: "bk-devops", ClientID: "*", RefreshTokenTTL: 604800},
+			})
+			assert.Equal(GinkgoT(), int64(86400), o.MaxAccessTokenTTL())
+		})
+	})
+
+	Describe("IsJWTAccessTokenEnabled", func() {
+		It("should return false when jwtAccessTokenRealmSet is nil", func() {
+			o := &OAuth{}
+			assert.False(GinkgoT(), o.IsJWTAccessTokenEnabled("blueking"))
+		})
+
+		It("should match configured realms only", func() {
+			o := &OAuth{jwtAccessTokenRealmSet: map[string]struct{}{"blueking": {}}}
+			assert.True(GinkgoT(), o.IsJWTAccessTokenEnabled("blueking"))
+			assert.False(GinkgoT(), o.IsJWTAccessTokenEnabled("bk-devops"))
+		})
+	})
+
+	Describe("IsPARRequired", func() {
+		It("should return false when parRequiredMap is nil", func() {
+			o := &OAuth{}
+			assert.False(GinkgoT(), o.IsPARRequired("blueking", "my_app"))
+		})
+
+		It("should match exact (realm, clientID)", func() {
+			o := &OAuth{parRequiredMap: map[PushedAuthorizationRequestRequirement]struct{}{
+				{RealmName: "blueking", ClientID: "my_app"}: {},
+			}}
+			assert.True(GinkgoT(), o.IsPARRequired("blueking", "my_app"))
+			assert.False(GinkgoT(), o.IsPARRequired("blueking", "other_app"))
+			assert.False(GinkgoT(), o.IsPARRequired("bk-devops", "my_app"))
+		})
+
+		It("should apply realm wildcard to all clients", func() {
+			o := &OAuth{parRequiredMap: map[PushedAuthorizationRequestRequirement]struct{}{
+				{RealmName: "bk-devops", ClientID: "*"}: {},
+			}}
+			assert.True(GinkgoT(), o.IsPARRequired("bk-devops", "my_app"))
+			assert.True(GinkgoT(), o.IsPARRequired("bk-devops", "other_app"))
+			assert.False(GinkgoT(), o.IsPARRequired("blueking", "my_app"))
+		})
+	})
+
+	Describe("ResolvePersonalAccessTokenMaxTTL", func() {
+		It("should return the default cap when patMaxTTLMap is nil", func() {
+			o := &OAuth{}
+			assert.Equal(GinkgoT(), int64(7776000), o.ResolvePersonalAccessTokenMaxTTL("blueking"))
+		})
+
+		It("should return the configured cap of the realm", func() {
+			o := &OAuth{patMaxTTLMap: map[string]int64{"blueking": 2592000, "bk-devops": 0}}
+			assert.Equal(GinkgoT(), int64(2592000), o.ResolvePersonalAccessTokenMaxTTL("blueking"))
+			assert.Equal(GinkgoT(), int64(7776000), o.ResolvePersonalAccessTokenMaxTTL("bk-devops"))
+			assert.Equal(GinkgoT(), int64(7776000), o.ResolvePersonalAccessTokenMaxTTL("other"))
+		})
+	})
 })
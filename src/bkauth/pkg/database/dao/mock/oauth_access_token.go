@@ -13,6 +13,7 @@ import (
 	dao "bkauth/pkg/database/dao"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	sqlx "github.com/jmoiron/sqlx"
 	gomock "go.uber.org/mock/gomock"
@@ -42,6 +43,21 @@ func (m *MockOAuthAccessTokenManager) EXPECT() *MockOAuthAccessTokenManagerMockR
 	return m.recorder
 }
 
+// CountExpired mocks base method.
+func (m *MockOAuthAccessTokenManager) CountExpired(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountExpired", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountExpired indicates an expected call of CountExpired.
+func (mr *MockOAuthAccessTokenManagerMockRecorder) CountExpired(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountExpired", reflect.TypeOf((*MockOAuthAccessTokenManager)(nil).CountExpired), ctx, before)
+}
+
 // CreateWithTx mocks base method.
 func (m *MockOAuthAccessTokenManager) CreateWithTx(ctx context.Context, tx *sqlx.Tx, token dao.OAuthAccessToken) (int64, error) {
 	m.ctrl.T.Helper()
@@ -57,19 +73,34 @@ func (mr *MockOAuthAccessTokenManagerMockRecorder) CreateWithTx(ctx, tx, token a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithTx", reflect.TypeOf((*MockOAuthAccessTokenManager)(nil).CreateWithTx), ctx, tx, token)
 }
 
-// GetByTokenHash mocks base method.
-func (m *MockOAuthAccessTokenManager) GetByTokenHash(ctx context.Context, tokenHash string) (dao.OAuthAccessToken, error) {
+// DeleteExpired mocks base method.
+func (m *MockOAuthAccessTokenManager) DeleteExpired(ctx context.Context, before time.Time, limit int64) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByTokenHash", ctx, tokenHash)
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx, before, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockOAuthAccessTokenManagerMockRecorder) DeleteExpired(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockOAuthAccessTokenManager)(nil).DeleteExpired), ctx, before, limit)
+}
+
+// GetByTokenHashes mocks base method.
+func (m *MockOAuthAccessTokenManager) GetByTokenHashes(ctx context.Context, tokenHashes []string) (dao.OAuthAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByTokenHashes", ctx, tokenHashes)
 	ret0, _ := ret[0].(dao.OAuthAccessToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetByTokenHash indicates an expected call of GetByTokenHash.
-func (mr *MockOAuthAccessTokenManagerMockRecorder) GetByTokenHash(ctx, tokenHash any) *gomock.Call {
+// GetByTokenHashes indicates an expected call of GetByTokenHashes.
+func (mr *MockOAuthAccessTokenManagerMockRecorder) GetByTokenHashes(ctx, tokenHashes any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTokenHash", reflect.TypeOf((*MockOAuthAccessTokenManager)(nil).GetByTokenHash), ctx, tokenHash)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTokenHashes", reflect.TypeOf((*MockOAuthAccessTokenManager)(nil).GetByTokenHashes), ctx, tokenHashes)
 }
 
 // Revoke mocks base method.
@@ -87,11 +118,26 @@ func (mr *MockOAuthAccessTokenManagerMockRecorder) Revoke(ctx, id any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockOAuthAccessTokenManager)(nil).Revoke), ctx, id)
 }
 
+// RevokeByClientIDWithTx mocks base method.
+func (m *MockOAuthAccessTokenManager) RevokeByClientIDWithTx(ctx context.Context, tx *sqlx.Tx, clientID string) ([]dao.RevokedToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeByClientIDWithTx", ctx, tx, clientID)
+	ret0, _ := ret[0].([]dao.RevokedToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeByClientIDWithTx indicates an expected call of RevokeByClientIDWithTx.
+func (mr *MockOAuthAccessTokenManagerMockRecorder) RevokeByClientIDWithTx(ctx, tx, clientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeByClientIDWithTx", reflect.TypeOf((*MockOAuthAccessTokenManager)(nil).RevokeByClientIDWithTx), ctx, tx, clientID)
+}
+
 // RevokeByGrantIDWithTx mocks base method.
-func (m *MockOAuthAccessTokenManager) RevokeByGrantIDWithTx(ctx context.Context, tx *sqlx.Tx, grantID string) (int64, error) {
+func (m *MockOAuthAccessTokenManager) RevokeByGrantIDWithTx(ctx context.Context, tx *sqlx.Tx, grantID string) ([]dao.RevokedToken, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "RevokeByGrantIDWithTx", ctx, tx, grantID)
-	ret0, _ := ret[0].(int64)
+	ret0, _ := ret[0].([]dao.RevokedToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -103,10 +149,10 @@ func (mr *MockOAuthAccessTokenManagerMockRecorder) RevokeByGrantIDWithTx(ctx, tx
 }
 
 // RevokeWithTx mocks base method.
-func (m *MockOAuthAccessTokenManager) RevokeWithTx(ctx context.Context, tx *sqlx.Tx, id int64) (int64, error) {
+func (m *MockOAuthAccessTokenManager) RevokeWithTx(ctx context.Context, tx *sqlx.Tx, id int64) ([]dao.RevokedToken, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "RevokeWithTx", ctx, tx, id)
-	ret0, _ := ret[0].(int64)
+	ret0, _ := ret[0].([]dao.RevokedToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -116,3 +162,18 @@ func (mr *MockOAuthAccessTokenManagerMockRecorder) RevokeWithTx(ctx, tx, id any)
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeWithTx", reflect.TypeOf((*MockOAuthAccessTokenManager)(nil).RevokeWithTx), ctx, tx, id)
 }
+
+// UpdateTokenHash mocks base method.
+func (m *MockOAuthAccessTokenManager) UpdateTokenHash(ctx context.Context, id int64, tokenHash, tokenHashKeyID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTokenHash", ctx, id, tokenHash, tokenHashKeyID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTokenHash indicates an expected call of UpdateTokenHash.
+func (mr *MockOAuthAccessTokenManagerMockRecorder) UpdateTokenHash(ctx, id, tokenHash, tokenHashKeyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTokenHash", reflect.TypeOf((*MockOAuthAccessTokenManager)(nil).UpdateTokenHash), ctx, id, tokenHash, tokenHashKeyID)
+}
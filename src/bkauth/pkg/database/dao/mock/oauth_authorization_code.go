@@ -13,6 +13,7 @@ import (
 	dao "bkauth/pkg/database/dao"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,6 +42,21 @@ func (m *MockOAuthAuthorizationCodeManager) EXPECT() *MockOAuthAuthorizationCode
 	return m.recorder
 }
 
+// CountExpired mocks base method.
+func (m *MockOAuthAuthorizationCodeManager) CountExpired(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountExpired", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountExpired indicates an expected call of CountExpired.
+func (mr *MockOAuthAuthorizationCodeManagerMockRecorder) CountExpired(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountExpired", reflect.TypeOf((*MockOAuthAuthorizationCodeManager)(nil).CountExpired), ctx, before)
+}
+
 // Create mocks base method.
 func (m *MockOAuthAuthorizationCodeManager) Create(ctx context.Context, code dao.OAuthAuthorizationCode) error {
 	m.ctrl.T.Helper()
@@ -55,6 +71,21 @@ func (mr *MockOAuthAuthorizationCodeManagerMockRecorder) Create(ctx, code any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOAuthAuthorizationCodeManager)(nil).Create), ctx, code)
 }
 
+// DeleteExpired mocks base method.
+func (m *MockOAuthAuthorizationCodeManager) DeleteExpired(ctx context.Context, before time.Time, limit int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx, before, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockOAuthAuthorizationCodeManagerMockRecorder) DeleteExpired(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockOAuthAuthorizationCodeManager)(nil).DeleteExpired), ctx, before, limit)
+}
+
 // Get mocks base method.
 func (m *MockOAuthAuthorizationCodeManager) Get(ctx context.Context, code string) (dao.OAuthAuthorizationCode, error) {
 	m.ctrl.T.Helper()
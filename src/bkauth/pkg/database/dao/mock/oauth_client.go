@@ -14,6 +14,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	sqlx "github.com/jmoiron/sqlx"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -55,6 +56,21 @@ func (mr *MockOAuthClientManagerMockRecorder) Create(ctx, client any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOAuthClientManager)(nil).Create), ctx, client)
 }
 
+// DeleteWithTx mocks base method.
+func (m *MockOAuthClientManager) DeleteWithTx(ctx context.Context, tx *sqlx.Tx, clientID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWithTx", ctx, tx, clientID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWithTx indicates an expected call of DeleteWithTx.
+func (mr *MockOAuthClientManagerMockRecorder) DeleteWithTx(ctx, tx, clientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWithTx", reflect.TypeOf((*MockOAuthClientManager)(nil).DeleteWithTx), ctx, tx, clientID)
+}
+
 // Exists mocks base method.
 func (m *MockOAuthClientManager) Exists(ctx context.Context, clientID string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -85,6 +101,21 @@ func (mr *MockOAuthClientManagerMockRecorder) Get(ctx, clientID any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOAuthClientManager)(nil).Get), ctx, clientID)
 }
 
+// GetAuthentication mocks base method.
+func (m *MockOAuthClientManager) GetAuthentication(ctx context.Context, clientID string) (dao.OAuthClientAuthentication, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthentication", ctx, clientID)
+	ret0, _ := ret[0].(dao.OAuthClientAuthentication)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthentication indicates an expected call of GetAuthentication.
+func (mr *MockOAuthClientManagerMockRecorder) GetAuthentication(ctx, clientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthentication", reflect.TypeOf((*MockOAuthClientManager)(nil).GetAuthentication), ctx, clientID)
+}
+
 // GetDisplay mocks base method.
 func (m *MockOAuthClientManager) GetDisplay(ctx context.Context, clientID string) (dao.OAuthClientDisplay, error) {
 	m.ctrl.T.Helper()
@@ -114,3 +145,33 @@ func (mr *MockOAuthClientManagerMockRecorder) GetGrants(ctx, clientID any) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGrants", reflect.TypeOf((*MockOAuthClientManager)(nil).GetGrants), ctx, clientID)
 }
+
+// Update mocks base method.
+func (m *MockOAuthClientManager) Update(ctx context.Context, client dao.OAuthClient) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, client)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockOAuthClientManagerMockRecorder) Update(ctx, client any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockOAuthClientManager)(nil).Update), ctx, client)
+}
+
+// UpdateMetadata mocks base method.
+func (m *MockOAuthClientManager) UpdateMetadata(ctx context.Context, client dao.OAuthClient) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMetadata", ctx, client)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMetadata indicates an expected call of UpdateMetadata.
+func (mr *MockOAuthClientManagerMockRecorder) UpdateMetadata(ctx, client any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMetadata", reflect.TypeOf((*MockOAuthClientManager)(nil).UpdateMetadata), ctx, client)
+}
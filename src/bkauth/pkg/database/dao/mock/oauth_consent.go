@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: oauth_consent.go
+//
+// Generated by this command:
+//
+//	mockgen -source=oauth_consent.go -destination=./mock/oauth_consent.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	dao "bkauth/pkg/database/dao"
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOAuthConsentManager is a mock of OAuthConsentManager interface.
+type MockOAuthConsentManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockOAuthConsentManagerMockRecorder
+	isgomock struct{}
+}
+
+// MockOAuthConsentManagerMockRecorder is the mock recorder for MockOAuthConsentManager.
+type MockOAuthConsentManagerMockRecorder struct {
+	mock *MockOAuthConsentManager
+}
+
+// NewMockOAuthConsentManager creates a new mock instance.
+func NewMockOAuthConsentManager(ctrl *gomock.Controller) *MockOAuthConsentManager {
+	mock := &MockOAuthConsentManager{ctrl: ctrl}
+	mock.recorder = &MockOAuthConsentManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOAuthConsentManager) EXPECT() *MockOAuthConsentManagerMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockOAuthConsentManager) Delete(ctx context.Context, tenantID, sub, realmName, clientID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, tenantID, sub, realmName, clientID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockOAuthConsentManagerMockRecorder) Delete(ctx, tenantID, sub, realmName, clientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOAuthConsentManager)(nil).Delete), ctx, tenantID, sub, realmName, clientID)
+}
+
+// Get mocks base method.
+func (m *MockOAuthConsentManager) Get(ctx context.Context, tenantID, sub, realmName, clientID string) (dao.OAuthConsent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, tenantID, sub, realmName, clientID)
+	ret0, _ := ret[0].(dao.OAuthConsent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockOAuthConsentManagerMockRecorder) Get(ctx, tenantID, sub, realmName, clientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOAuthConsentManager)(nil).Get), ctx, tenantID, sub, realmName, clientID)
+}
+
+// Upsert mocks base method.
+func (m *MockOAuthConsentManager) Upsert(ctx context.Context, consent dao.OAuthConsent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, consent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockOAuthConsentManagerMockRecorder) Upsert(ctx, consent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockOAuthConsentManager)(nil).Upsert), ctx, consent)
+}
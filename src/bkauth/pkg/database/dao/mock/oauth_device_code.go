@@ -13,6 +13,7 @@ import (
 	dao "bkauth/pkg/database/dao"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -57,18 +58,33 @@ func (mr *MockOAuthDeviceCodeManagerMockRecorder) Approve(ctx, id, tenantID, sub
 }
 
 // ConsumeApproved mocks base method.
-func (m *MockOAuthDeviceCodeManager) ConsumeApproved(ctx context.Context, deviceCode, clientID string) (int64, error) {
+func (m *MockOAuthDeviceCodeManager) ConsumeApproved(ctx context.Context, deviceCodeHash, clientID string) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ConsumeApproved", ctx, deviceCode, clientID)
+	ret := m.ctrl.Call(m, "ConsumeApproved", ctx, deviceCodeHash, clientID)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ConsumeApproved indicates an expected call of ConsumeApproved.
-func (mr *MockOAuthDeviceCodeManagerMockRecorder) ConsumeApproved(ctx, deviceCode, clientID any) *gomock.Call {
+func (mr *MockOAuthDeviceCodeManagerMockRecorder) ConsumeApproved(ctx, deviceCodeHash, clientID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeApproved", reflect.TypeOf((*MockOAuthDeviceCodeManager)(nil).ConsumeApproved), ctx, deviceCode, clientID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeApproved", reflect.TypeOf((*MockOAuthDeviceCodeManager)(nil).ConsumeApproved), ctx, deviceCodeHash, clientID)
+}
+
+// CountExpired mocks base method.
+func (m *MockOAuthDeviceCodeManager) CountExpired(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountExpired", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountExpired indicates an expected call of CountExpired.
+func (mr *MockOAuthDeviceCodeManagerMockRecorder) CountExpired(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountExpired", reflect.TypeOf((*MockOAuthDeviceCodeManager)(nil).CountExpired), ctx, before)
 }
 
 // Create mocks base method.
@@ -86,19 +102,34 @@ func (mr *MockOAuthDeviceCodeManagerMockRecorder) Create(ctx, dc any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOAuthDeviceCodeManager)(nil).Create), ctx, dc)
 }
 
-// GetByDeviceCode mocks base method.
-func (m *MockOAuthDeviceCodeManager) GetByDeviceCode(ctx context.Context, deviceCode string) (dao.OAuthDeviceCode, error) {
+// DeleteExpired mocks base method.
+func (m *MockOAuthDeviceCodeManager) DeleteExpired(ctx context.Context, before time.Time, limit int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx, before, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockOAuthDeviceCodeManagerMockRecorder) DeleteExpired(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockOAuthDeviceCodeManager)(nil).DeleteExpired), ctx, before, limit)
+}
+
+// GetByDeviceCodeHashes mocks base method.
+func (m *MockOAuthDeviceCodeManager) GetByDeviceCodeHashes(ctx context.Context, deviceCodeHashes []string) (dao.OAuthDeviceCode, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByDeviceCode", ctx, deviceCode)
+	ret := m.ctrl.Call(m, "GetByDeviceCodeHashes", ctx, deviceCodeHashes)
 	ret0, _ := ret[0].(dao.OAuthDeviceCode)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetByDeviceCode indicates an expected call of GetByDeviceCode.
-func (mr *MockOAuthDeviceCodeManagerMockRecorder) GetByDeviceCode(ctx, deviceCode any) *gomock.Call {
+// GetByDeviceCodeHashes indicates an expected call of GetByDeviceCodeHashes.
+func (mr *MockOAuthDeviceCodeManagerMockRecorder) GetByDeviceCodeHashes(ctx, deviceCodeHashes any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByDeviceCode", reflect.TypeOf((*MockOAuthDeviceCodeManager)(nil).GetByDeviceCode), ctx, deviceCode)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByDeviceCodeHashes", reflect.TypeOf((*MockOAuthDeviceCodeManager)(nil).GetByDeviceCodeHashes), ctx, deviceCodeHashes)
 }
 
 // GetByUserCode mocks base method.
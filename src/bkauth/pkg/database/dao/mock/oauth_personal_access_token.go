@@ -0,0 +1,103 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: oauth_personal_access_token.go
+//
+// Generated by this command:
+//
+//	mockgen -source=oauth_personal_access_token.go -destination=./mock/oauth_personal_access_token.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	dao "bkauth/pkg/database/dao"
+	context "context"
+	reflect "reflect"
+
+	sqlx "github.com/jmoiron/sqlx"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOAuthPersonalAccessTokenManager is a mock of OAuthPersonalAccessTokenManager interface.
+type MockOAuthPersonalAccessTokenManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockOAuthPersonalAccessTokenManagerMockRecorder
+	isgomock struct{}
+}
+
+// MockOAuthPersonalAccessTokenManagerMockRecorder is the mock recorder for MockOAuthPersonalAccessTokenManager.
+type MockOAuthPersonalAccessTokenManagerMockRecorder struct {
+	mock *MockOAuthPersonalAccessTokenManager
+}
+
+// NewMockOAuthPersonalAccessTokenManager creates a new mock instance.
+func NewMockOAuthPersonalAccessTokenManager(ctrl *gomock.Controller) *MockOAuthPersonalAccessTokenManager {
+	mock := &MockOAuthPersonalAccessTokenManager{ctrl: ctrl}
+	mock.recorder = &MockOAuthPersonalAccessTokenManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOAuthPersonalAccessTokenManager) EXPECT() *MockOAuthPersonalAccessTokenManagerMockRecorder {
+	return m.recorder
+}
+
+// CreateWithTx mocks base method.
+func (m *MockOAuthPersonalAccessTokenManager) CreateWithTx(ctx context.Context, tx *sqlx.Tx, token dao.OAuthPersonalAccessToken) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithTx", ctx, tx, token)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWithTx indicates an expected call of CreateWithTx.
+func (mr *MockOAuthPersonalAccessTokenManagerMockRecorder) CreateWithTx(ctx, tx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithTx", reflect.TypeOf((*MockOAuthPersonalAccessTokenManager)(nil).CreateWithTx), ctx, tx, token)
+}
+
+// DeleteWithTx mocks base method.
+func (m *MockOAuthPersonalAccessTokenManager) DeleteWithTx(ctx context.Context, tx *sqlx.Tx, id int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWithTx", ctx, tx, id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWithTx indicates an expected call of DeleteWithTx.
+func (mr *MockOAuthPersonalAccessTokenManagerMockRecorder) DeleteWithTx(ctx, tx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWithTx", reflect.TypeOf((*MockOAuthPersonalAccessTokenManager)(nil).DeleteWithTx), ctx, tx, id)
+}
+
+// Get mocks base method.
+func (m *MockOAuthPersonalAccessTokenManager) Get(ctx context.Context, id int64) (dao.OAuthPersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(dao.OAuthPersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockOAuthPersonalAccessTokenManagerMockRecorder) Get(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOAuthPersonalAccessTokenManager)(nil).Get), ctx, id)
+}
+
+// ListBySub mocks base method.
+func (m *MockOAuthPersonalAccessTokenManager) ListBySub(ctx context.Context, tenantID, sub string) ([]dao.OAuthPersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBySub", ctx, tenantID, sub)
+	ret0, _ := ret[0].([]dao.OAuthPersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBySub indicates an expected call of ListBySub.
+func (mr *MockOAuthPersonalAccessTokenManagerMockRecorder) ListBySub(ctx, tenantID, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBySub", reflect.TypeOf((*MockOAuthPersonalAccessTokenManager)(nil).ListBySub), ctx, tenantID, sub)
+}
@@ -13,6 +13,7 @@ import (
 	dao "bkauth/pkg/database/dao"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	sqlx "github.com/jmoiron/sqlx"
 	gomock "go.uber.org/mock/gomock"
@@ -42,6 +43,21 @@ func (m *MockOAuthRefreshTokenManager) EXPECT() *MockOAuthRefreshTokenManagerMoc
 	return m.recorder
 }
 
+// CountExpired mocks base method.
+func (m *MockOAuthRefreshTokenManager) CountExpired(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountExpired", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountExpired indicates an expected call of CountExpired.
+func (mr *MockOAuthRefreshTokenManagerMockRecorder) CountExpired(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountExpired", reflect.TypeOf((*MockOAuthRefreshTokenManager)(nil).CountExpired), ctx, before)
+}
+
 // CreateWithTx mocks base method.
 func (m *MockOAuthRefreshTokenManager) CreateWithTx(ctx context.Context, tx *sqlx.Tx, token dao.OAuthRefreshToken) (int64, error) {
 	m.ctrl.T.Helper()
@@ -57,26 +73,71 @@ func (mr *MockOAuthRefreshTokenManagerMockRecorder) CreateWithTx(ctx, tx, token
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithTx", reflect.TypeOf((*MockOAuthRefreshTokenManager)(nil).CreateWithTx), ctx, tx, token)
 }
 
-// GetByTokenHash mocks base method.
-func (m *MockOAuthRefreshTokenManager) GetByTokenHash(ctx context.Context, tokenHash string) (dao.OAuthRefreshToken, error) {
+// DeleteExpired mocks base method.
+func (m *MockOAuthRefreshTokenManager) DeleteExpired(ctx context.Context, before time.Time, limit int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx, before, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockOAuthRefreshTokenManagerMockRecorder) DeleteExpired(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockOAuthRefreshTokenManager)(nil).DeleteExpired), ctx, before, limit)
+}
+
+// GetByTokenHashes mocks base method.
+func (m *MockOAuthRefreshTokenManager) GetByTokenHashes(ctx context.Context, tokenHashes []string) (dao.OAuthRefreshToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByTokenHash", ctx, tokenHash)
+	ret := m.ctrl.Call(m, "GetByTokenHashes", ctx, tokenHashes)
 	ret0, _ := ret[0].(dao.OAuthRefreshToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetByTokenHash indicates an expected call of GetByTokenHash.
-func (mr *MockOAuthRefreshTokenManagerMockRecorder) GetByTokenHash(ctx, tokenHash any) *gomock.Call {
+// GetByTokenHashes indicates an expected call of GetByTokenHashes.
+func (mr *MockOAuthRefreshTokenManagerMockRecorder) GetByTokenHashes(ctx, tokenHashes any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTokenHash", reflect.TypeOf((*MockOAuthRefreshTokenManager)(nil).GetByTokenHash), ctx, tokenHash)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTokenHashes", reflect.TypeOf((*MockOAuthRefreshTokenManager)(nil).GetByTokenHashes), ctx, tokenHashes)
+}
+
+// ListActiveGrantsBySub mocks base method.
+func (m *MockOAuthRefreshTokenManager) ListActiveGrantsBySub(ctx context.Context, tenantID, sub string, now time.Time) ([]dao.OAuthRefreshTokenGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveGrantsBySub", ctx, tenantID, sub, now)
+	ret0, _ := ret[0].([]dao.OAuthRefreshTokenGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveGrantsBySub indicates an expected call of ListActiveGrantsBySub.
+func (mr *MockOAuthRefreshTokenManagerMockRecorder) ListActiveGrantsBySub(ctx, tenantID, sub, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveGrantsBySub", reflect.TypeOf((*MockOAuthRefreshTokenManager)(nil).ListActiveGrantsBySub), ctx, tenantID, sub, now)
+}
+
+// RevokeByClientIDWithTx mocks base method.
+func (m *MockOAuthRefreshTokenManager) RevokeByClientIDWithTx(ctx context.Context, tx *sqlx.Tx, clientID string) ([]dao.RevokedToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeByClientIDWithTx", ctx, tx, clientID)
+	ret0, _ := ret[0].([]dao.RevokedToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeByClientIDWithTx indicates an expected call of RevokeByClientIDWithTx.
+func (mr *MockOAuthRefreshTokenManagerMockRecorder) RevokeByClientIDWithTx(ctx, tx, clientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeByClientIDWithTx", reflect.TypeOf((*MockOAuthRefreshTokenManager)(nil).RevokeByClientIDWithTx), ctx, tx, clientID)
 }
 
 // RevokeByGrantIDWithTx mocks base method.
-func (m *MockOAuthRefreshTokenManager) RevokeByGrantIDWithTx(ctx context.Context, tx *sqlx.Tx, grantID string) (int64, error) {
+func (m *MockOAuthRefreshTokenManager) RevokeByGrantIDWithTx(ctx context.Context, tx *sqlx.Tx, grantID string) ([]dao.RevokedToken, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "RevokeByGrantIDWithTx", ctx, tx, grantID)
-	ret0, _ := ret[0].(int64)
+	ret0, _ := ret[0].([]dao.RevokedToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -33,31 +33,69 @@ import (
 
 // OAuthAccessToken represents an OAuth access token
 type OAuthAccessToken struct {
-	ID        int64     `db:"id"`
-	JTI       string    `db:"jti"`
-	TokenHash string    `db:"token_hash"`
-	TokenMask string    `db:"token_mask"`
-	GrantID   string    `db:"grant_id"`
-	ClientID  string    `db:"client_id"`
-	TenantID  string    `db:"tenant_id"`
-	RealmName string    `db:"realm_name"`
-	Sub       string    `db:"sub"`
-	Username  string    `db:"username"`
-	Audience  string    `db:"audience"` // JSON string
-	Scope     string    `db:"scope"`
-	ExpiresAt time.Time `db:"expires_at"`
-	Revoked   bool      `db:"revoked"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	ID        int64  `db:"id"`
+	JTI       string `db:"jti"`
+	TokenHash string `db:"token_hash"`
+	// TokenHashKeyID is the id of the HMAC key of TokenHash, empty for a legacy SHA-256 hash
+	TokenHashKeyID string `db:"token_hash_kid"`
+	TokenMask      string `db:"token_mask"`
+	GrantID        string `db:"grant_id"`
+	ClientID       string `db:"client_id"`
+	TenantID       string `db:"tenant_id"`
+	RealmName      string `db:"realm_name"`
+	Sub            string `db:"sub"`
+	Username       string `db:"username"`
+	Audience       string `db:"audience"` // JSON string
+	Scope          string `db:"scope"`
+	// JSON string, empty unless requested with authorization_details (RFC 9396)
+	AuthorizationDetails string    `db:"authorization_details"`
+	GrantType            string    `db:"grant_type"`
+	Act                  string    `db:"act"` // JSON string, empty unless issued via token exchange
+	CnfJKT               string    `db:"cnf_jkt"`
+	CnfX5T               string    `db:"cnf_x5t_s256"`
+	ExpiresAt            time.Time `db:"expires_at"`
+	Revoked              bool      `db:"revoked"`
+	CreatedAt            time.Time `db:"created_at"`
+	UpdatedAt            time.Time `db:"updated_at"`
+}
+
+// RevokedToken is a token revoked by a bulk revocation, read while locking it
+type RevokedToken struct {
+	TokenHash string `db:"token_hash"`
+	// JTI is empty for refresh tokens
+	JTI       string `db:"jti"`
+	GrantID   string `db:"grant_id"`
+	ClientID  string `db:"client_id"`
+	RealmName string `db:"realm_name"`
+	Sub       string `db:"sub"`
+}
+
+// RevokedTokenHashes returns the hashes of the revoked tokens
+func RevokedTokenHashes(tokens []RevokedToken) []string {
+	tokenHashes := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		tokenHashes = append(tokenHashes, t.TokenHash)
+	}
+	return tokenHashes
 }
 
 // OAuthAccessTokenManager defines the interface for access token operations
 type OAuthAccessTokenManager interface {
 	CreateWithTx(ctx context.Context, tx *sqlx.Tx, token OAuthAccessToken) (int64, error)
-	GetByTokenHash(ctx context.Context, tokenHash string) (OAuthAccessToken, error)
+	// GetByTokenHashes gets the token stored under any of the hashes, one per hash key
+	GetByTokenHashes(ctx context.Context, tokenHashes []string) (OAuthAccessToken, error)
+	// UpdateTokenHash re-hashes a token with another key, keeping updated_at unchanged
+	UpdateTokenHash(ctx context.Context, id int64, tokenHash, tokenHashKeyID string) (int64, error)
 	Revoke(ctx context.Context, id int64) (int64, error)
-	RevokeWithTx(ctx context.Context, tx *sqlx.Tx, id int64) (int64, error)
-	RevokeByGrantIDWithTx(ctx context.Context, tx *sqlx.Tx, grantID string) (int64, error)
+	// RevokeWithTx, RevokeByGrantIDWithTx and RevokeByClientIDWithTx return the tokens they revoke,
+	// which the caller evicts from the access token cache and publishes once committed
+	RevokeWithTx(ctx context.Context, tx *sqlx.Tx, id int64) ([]RevokedToken, error)
+	RevokeByGrantIDWithTx(ctx context.Context, tx *sqlx.Tx, grantID string) ([]RevokedToken, error)
+	RevokeByClientIDWithTx(ctx context.Context, tx *sqlx.Tx, clientID string) ([]RevokedToken, error)
+	// CountExpired counts the rows DeleteExpired would delete, for a dry run
+	CountExpired(ctx context.Context, before time.Time) (int64, error)
+	// DeleteExpired deletes at most limit rows that expired or were revoked before the given time
+	DeleteExpired(ctx context.Context, before time.Time, limit int64) (int64, error)
 }
 
 type oauthAccessTokenManager struct {
@@ -77,6 +115,7 @@ func (m *oauthAccessTokenManager) CreateWithTx(
 	query := `INSERT INTO oauth_access_token (
 		jti,
 		token_hash,
+		token_hash_kid,
 		token_mask,
 		grant_id,
 		client_id,
@@ -86,11 +125,17 @@ func (m *oauthAccessTokenManager) CreateWithTx(
 		username,
 		audience,
 		scope,
+		authorization_details,
+		grant_type,
+		act,
+		cnf_jkt,
+		cnf_x5t_s256,
 		expires_at,
 		revoked
 	) VALUES (
 		:jti,
 		:token_hash,
+		:token_hash_kid,
 		:token_mask,
 		:grant_id,
 		:client_id,
@@ -100,19 +145,25 @@ func (m *oauthAccessTokenManager) CreateWithTx(
 		:username,
 		:audience,
 		:scope,
+		:authorization_details,
+		:grant_type,
+		:act,
+		:cnf_jkt,
+		:cnf_x5t_s256,
 		:expires_at,
 		:revoked
 	)`
 	return database.SqlxInsertWithTx(ctx, tx, query, token)
 }
 
-func (m *oauthAccessTokenManager) GetByTokenHash(
-	ctx context.Context, tokenHash string,
+func (m *oauthAccessTokenManager) GetByTokenHashes(
+	ctx context.Context, tokenHashes []string,
 ) (token OAuthAccessToken, err error) {
 	query := `SELECT 
 		id,
 		jti,
 		token_hash,
+		token_hash_kid,
 		token_mask,
 		grant_id,
 		client_id,
@@ -122,46 +173,130 @@ func (m *oauthAccessTokenManager) GetByTokenHash(
 		username,
 		audience,
 		scope,
+		authorization_details,
+		grant_type,
+		act,
+		cnf_jkt,
+		cnf_x5t_s256,
 		expires_at,
 		revoked,
 		created_at,
 		updated_at
 	FROM oauth_access_token 
-	WHERE token_hash = ? 
+	WHERE token_hash IN (?) 
 	LIMIT 1`
 
-	err = database.SqlxGet(ctx, m.DB, &token, query, tokenHash)
+	err = database.SqlxGet(ctx, m.DB, &token, query, tokenHashes)
 	if errors.Is(err, sql.ErrNoRows) {
 		return token, nil
 	}
 	return token, err
 }
 
-func (m *oauthAccessTokenManager) Revoke(ctx context.Context, id int64) (int64, error) {
-	query := `UPDATE oauth_access_token SET revoked = 1 WHERE id = ?`
-	result, err := m.DB.ExecContext(ctx, query, id)
+// UpdateTokenHash keeps updated_at, which is the time of revocation of a revoked token.
+func (m *oauthAccessTokenManager) UpdateTokenHash(
+	ctx context.Context, id int64, tokenHash, tokenHashKeyID string,
+) (int64, error) {
+	query := `UPDATE oauth_access_token SET token_hash = ?, token_hash_kid = ?, updated_at = updated_at WHERE id = ?`
+	result, err := m.DB.ExecContext(ctx, query, tokenHash, tokenHashKeyID, id)
 	if err != nil {
 		return 0, err
 	}
 	return result.RowsAffected()
 }
 
-func (m *oauthAccessTokenManager) RevokeWithTx(ctx context.Context, tx *sqlx.Tx, id int64) (int64, error) {
+func (m *oauthAccessTokenManager) Revoke(ctx context.Context, id int64) (int64, error) {
 	query := `UPDATE oauth_access_token SET revoked = 1 WHERE id = ?`
-	result, err := tx.ExecContext(ctx, query, id)
+	result, err := m.DB.ExecContext(ctx, query, id)
 	if err != nil {
 		return 0, err
 	}
 	return result.RowsAffected()
 }
 
+// RevokeWithTx locks the token with SELECT ... FOR UPDATE to read it before revoking it,
+// the same for RevokeByGrantIDWithTx and RevokeByClientIDWithTx.
+func (m *oauthAccessTokenManager) RevokeWithTx(
+	ctx context.Context, tx *sqlx.Tx, id int64,
+) (tokens []RevokedToken, err error) {
+	query := `SELECT
+		token_hash,
+		jti,
+		grant_id,
+		client_id,
+		realm_name,
+		sub
+		FROM oauth_access_token
+		WHERE id = ? AND revoked = 0
+		FOR UPDATE`
+	if err = tx.SelectContext(ctx, &tokens, query, id); err != nil || len(tokens) == 0 {
+		return nil, err
+	}
+
+	query = `UPDATE oauth_access_token SET revoked = 1 WHERE id = ?`
+	if _, err = tx.ExecContext(ctx, query, id); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
 func (m *oauthAccessTokenManager) RevokeByGrantIDWithTx(
 	ctx context.Context, tx *sqlx.Tx, grantID string,
-) (int64, error) {
-	query := `UPDATE oauth_access_token SET revoked = 1 WHERE grant_id = ? AND revoked = 0`
-	result, err := tx.ExecContext(ctx, query, grantID)
-	if err != nil {
-		return 0, err
+) (tokens []RevokedToken, err error) {
+	query := `SELECT
+		token_hash,
+		jti,
+		grant_id,
+		client_id,
+		realm_name,
+		sub
+		FROM oauth_access_token
+		WHERE grant_id = ? AND revoked = 0
+		FOR UPDATE`
+	if err = tx.SelectContext(ctx, &tokens, query, grantID); err != nil || len(tokens) == 0 {
+		return nil, err
 	}
-	return result.RowsAffected()
+
+	query = `UPDATE oauth_access_token SET revoked = 1 WHERE grant_id = ? AND revoked = 0`
+	if _, err = tx.ExecContext(ctx, query, grantID); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (m *oauthAccessTokenManager) RevokeByClientIDWithTx(
+	ctx context.Context, tx *sqlx.Tx, clientID string,
+) (tokens []RevokedToken, err error) {
+	query := `SELECT
+		token_hash,
+		jti,
+		grant_id,
+		client_id,
+		realm_name,
+		sub
+		FROM oauth_access_token
+		WHERE client_id = ? AND revoked = 0
+		FOR UPDATE`
+	if err = tx.SelectContext(ctx, &tokens, query, clientID); err != nil || len(tokens) == 0 {
+		return nil, err
+	}
+
+	query = `UPDATE oauth_access_token SET revoked = 1 WHERE client_id = ? AND revoked = 0`
+	if _, err = tx.ExecContext(ctx, query, clientID); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (m *oauthAccessTokenManager) CountExpired(ctx context.Context, before time.Time) (count int64, err error) {
+	query := `SELECT COUNT(*) FROM oauth_access_token WHERE expires_at < ? OR (revoked = 1 AND updated_at < ?)`
+	err = database.SqlxGet(ctx, m.DB, &count, query, before, before)
+	return count, err
+}
+
+// DeleteExpired deletes a batch of access tokens that expired, or were revoked, before the given time.
+// Revocation sets updated_at, which is used as the time of revocation.
+func (m *oauthAccessTokenManager) DeleteExpired(ctx context.Context, before time.Time, limit int64) (int64, error) {
+	query := `DELETE FROM oauth_access_token WHERE expires_at < ? OR (revoked = 1 AND updated_at < ?) LIMIT ?`
+	return database.SqlxDelete(ctx, m.DB, query, before, before, limit)
 }
@@ -16,9 +16,10 @@ func Test_oauthAccessTokenManager_CreateWithTx(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mock.ExpectBegin()
 		mock.ExpectExec(`^INSERT INTO oauth_access_token`).WithArgs(
-			"jti-001", "hash123", "mask123", "grant-001",
+			"jti-001", "hash123", "kid-1", "mask123", "grant-001",
 			"client1", "", "devops", "user1", "admin",
-			`["aud1"]`, "openid profile",
+			`["aud1"]`, "openid profile", `[{"type":"bk_gateway_api","identifier":"gw","actions":["get_host"]}]`,
+			"authorization_code", "", "", "",
 			sqlmock.AnyArg(), // expires_at
 			false,            // revoked
 		).WillReturnResult(sqlmock.NewResult(1, 1))
@@ -28,18 +29,21 @@ func Test_oauthAccessTokenManager_CreateWithTx(t *testing.T) {
 		assert.NoError(t, err)
 
 		token := OAuthAccessToken{
-			JTI:       "jti-001",
-			TokenHash: "hash123",
-			TokenMask: "mask123",
-			GrantID:   "grant-001",
-			ClientID:  "client1",
-			RealmName: "devops",
-			Sub:       "user1",
-			Username:  "admin",
-			Audience:  `["aud1"]`,
-			Scope:     "openid profile",
-			ExpiresAt: time.Now().Add(time.Hour),
-			Revoked:   false,
+			JTI:                  "jti-001",
+			TokenHash:            "hash123",
+			TokenHashKeyID:       "kid-1",
+			TokenMask:            "mask123",
+			GrantID:              "grant-001",
+			ClientID:             "client1",
+			RealmName:            "devops",
+			Sub:                  "user1",
+			Username:             "admin",
+			Audience:             `["aud1"]`,
+			Scope:                "openid profile",
+			GrantType:            "authorization_code",
+			ExpiresAt:            time.Now().Add(time.Hour),
+			AuthorizationDetails: `[{"type":"bk_gateway_api","identifier":"gw","actions":["get_host"]}]`,
+			Revoked:              false,
 		}
 
 		manager := &oauthAccessTokenManager{DB: db}
@@ -52,29 +56,31 @@ func Test_oauthAccessTokenManager_CreateWithTx(t *testing.T) {
 	})
 }
 
-func Test_oauthAccessTokenManager_GetByTokenHash(t *testing.T) {
+func Test_oauthAccessTokenManager_GetByTokenHashes(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		now := time.Now()
 		mockRows := sqlmock.NewRows([]string{
-			"id", "jti", "token_hash", "token_mask", "grant_id",
+			"id", "jti", "token_hash", "token_hash_kid", "token_mask", "grant_id",
 			"client_id", "tenant_id", "realm_name", "sub", "username",
-			"audience", "scope", "expires_at", "revoked",
+			"audience", "scope", "grant_type", "act", "cnf_jkt", "cnf_x5t_s256", "expires_at", "revoked",
 			"created_at", "updated_at",
 		}).AddRow(
-			int64(1), "jti-001", "hash123", "mask123", "grant-001",
+			int64(1), "jti-001", "hash123", "kid-1", "mask123", "grant-001",
 			"client1", "", "devops", "user1", "admin",
-			`["aud1"]`, "openid profile", now.Add(time.Hour), false,
+			`["aud1"]`, "openid profile", "client_credentials", `{"client_id":"mcp"}`, "jkt-1", "x5t-1",
+			now.Add(time.Hour), false,
 			now, now,
 		)
-		mock.ExpectQuery(`^SELECT`).WithArgs("hash123").WillReturnRows(mockRows)
+		mock.ExpectQuery(`WHERE token_hash IN \(\?, \?\)`).WithArgs("hash123", "legacy123").WillReturnRows(mockRows)
 
 		manager := &oauthAccessTokenManager{DB: db}
-		token, err := manager.GetByTokenHash(context.Background(), "hash123")
+		token, err := manager.GetByTokenHashes(context.Background(), []string{"hash123", "legacy123"})
 
 		assert.NoError(t, err)
 		assert.Equal(t, int64(1), token.ID)
 		assert.Equal(t, "jti-001", token.JTI)
 		assert.Equal(t, "hash123", token.TokenHash)
+		assert.Equal(t, "kid-1", token.TokenHashKeyID)
 		assert.Equal(t, "mask123", token.TokenMask)
 		assert.Equal(t, "grant-001", token.GrantID)
 		assert.Equal(t, "client1", token.ClientID)
@@ -83,28 +89,58 @@ func Test_oauthAccessTokenManager_GetByTokenHash(t *testing.T) {
 		assert.Equal(t, "admin", token.Username)
 		assert.Equal(t, `["aud1"]`, token.Audience)
 		assert.Equal(t, "openid profile", token.Scope)
+		assert.Equal(t, "client_credentials", token.GrantType)
+		assert.Equal(t, `{"client_id":"mcp"}`, token.Act)
+		assert.Equal(t, "jkt-1", token.CnfJKT)
+		assert.Equal(t, "x5t-1", token.CnfX5T)
 		assert.False(t, token.Revoked)
 	})
 }
 
-func Test_oauthAccessTokenManager_GetByTokenHash_NotFound(t *testing.T) {
+func Test_oauthAccessTokenManager_GetByTokenHashes_NotFound(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mockRows := sqlmock.NewRows([]string{
-			"id", "jti", "token_hash", "token_mask", "grant_id",
+			"id", "jti", "token_hash", "token_hash_kid", "token_mask", "grant_id",
 			"client_id", "tenant_id", "realm_name", "sub", "username",
-			"audience", "scope", "expires_at", "revoked",
+			"audience", "scope", "grant_type", "act", "cnf_jkt", "cnf_x5t_s256", "expires_at", "revoked",
 			"created_at", "updated_at",
 		})
 		mock.ExpectQuery(`^SELECT`).WithArgs("nonexistent").WillReturnRows(mockRows)
 
 		manager := &oauthAccessTokenManager{DB: db}
-		token, err := manager.GetByTokenHash(context.Background(), "nonexistent")
+		token, err := manager.GetByTokenHashes(context.Background(), []string{"nonexistent"})
 
 		assert.NoError(t, err)
 		assert.Empty(t, token.TokenHash)
 	})
 }
 
+func Test_oauthAccessTokenManager_UpdateTokenHash(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectExec(
+			`^UPDATE oauth_access_token SET token_hash = \?, token_hash_kid = \?, updated_at = updated_at WHERE id = \?$`,
+		).
+			WithArgs("hash456", "kid-2", int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		manager := &oauthAccessTokenManager{DB: db}
+		affected, err := manager.UpdateTokenHash(context.Background(), 1, "hash456", "kid-2")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+	})
+}
+
+const (
+	revokedTokenSelect       = `^SELECT token_hash, grant_id, client_id, realm_name, sub FROM `
+	revokedAccessTokenSelect = `^SELECT token_hash, jti, grant_id, client_id, realm_name, sub FROM `
+)
+
+var (
+	revokedTokenColumns       = []string{"token_hash", "grant_id", "client_id", "realm_name", "sub"}
+	revokedAccessTokenColumns = []string{"token_hash", "jti", "grant_id", "client_id", "realm_name", "sub"}
+)
+
 func Test_oauthAccessTokenManager_Revoke(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mock.ExpectExec(`^UPDATE oauth_access_token SET revoked = 1 WHERE id = \?$`).
@@ -122,6 +158,11 @@ func Test_oauthAccessTokenManager_Revoke(t *testing.T) {
 func Test_oauthAccessTokenManager_RevokeWithTx(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mock.ExpectBegin()
+		mock.ExpectQuery(
+			revokedAccessTokenSelect + `oauth_access_token WHERE id = \? AND revoked = 0 FOR UPDATE$`,
+		).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(revokedAccessTokenColumns).AddRow("hash1", "jti-1", "grant-001", "client1", "realm1", "user1"))
 		mock.ExpectExec(`^UPDATE oauth_access_token SET revoked = 1 WHERE id = \?$`).
 			WithArgs(int64(1)).
 			WillReturnResult(sqlmock.NewResult(0, 1))
@@ -131,18 +172,50 @@ func Test_oauthAccessTokenManager_RevokeWithTx(t *testing.T) {
 		assert.NoError(t, err)
 
 		manager := &oauthAccessTokenManager{DB: db}
-		affected, err := manager.RevokeWithTx(context.Background(), tx, 1)
+		tokens, err := manager.RevokeWithTx(context.Background(), tx, 1)
 
 		tx.Commit()
 
 		assert.NoError(t, err)
-		assert.Equal(t, int64(1), affected)
+		assert.Equal(t, []RevokedToken{
+			{TokenHash: "hash1", JTI: "jti-1", GrantID: "grant-001", ClientID: "client1", RealmName: "realm1", Sub: "user1"},
+		}, tokens)
+	})
+}
+
+func Test_oauthAccessTokenManager_RevokeWithTx_AlreadyRevoked(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(
+			revokedAccessTokenSelect + `oauth_access_token WHERE id = \? AND revoked = 0 FOR UPDATE$`,
+		).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(revokedAccessTokenColumns))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &oauthAccessTokenManager{DB: db}
+		tokens, err := manager.RevokeWithTx(context.Background(), tx, 1)
+
+		tx.Commit()
+
+		assert.NoError(t, err)
+		assert.Empty(t, tokens)
 	})
 }
 
 func Test_oauthAccessTokenManager_RevokeByGrantIDWithTx(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mock.ExpectBegin()
+		mock.ExpectQuery(
+			revokedAccessTokenSelect + `oauth_access_token WHERE grant_id = \? AND revoked = 0 FOR UPDATE$`,
+		).
+			WithArgs("grant-001").
+			WillReturnRows(sqlmock.NewRows(revokedAccessTokenColumns).
+				AddRow("hash1", "jti-1", "grant-001", "client1", "realm1", "user1").
+				AddRow("hash2", "jti-2", "grant-001", "client1", "realm1", "user1"))
 		mock.ExpectExec(`^UPDATE oauth_access_token SET revoked = 1 WHERE grant_id = \? AND revoked = 0$`).
 			WithArgs("grant-001").
 			WillReturnResult(sqlmock.NewResult(0, 2))
@@ -152,11 +225,73 @@ func Test_oauthAccessTokenManager_RevokeByGrantIDWithTx(t *testing.T) {
 		assert.NoError(t, err)
 
 		manager := &oauthAccessTokenManager{DB: db}
-		affected, err := manager.RevokeByGrantIDWithTx(context.Background(), tx, "grant-001")
+		tokens, err := manager.RevokeByGrantIDWithTx(context.Background(), tx, "grant-001")
 
 		tx.Commit()
 
 		assert.NoError(t, err)
-		assert.Equal(t, int64(2), affected)
+		assert.Equal(t, []string{"hash1", "hash2"}, RevokedTokenHashes(tokens))
+	})
+}
+
+func Test_oauthAccessTokenManager_RevokeByClientIDWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(
+			revokedAccessTokenSelect + `oauth_access_token WHERE client_id = \? AND revoked = 0 FOR UPDATE$`,
+		).
+			WithArgs("client1").
+			WillReturnRows(sqlmock.NewRows(revokedAccessTokenColumns).
+				AddRow("hash1", "jti-1", "grant-001", "client1", "realm1", "user1").
+				AddRow("hash2", "jti-2", "grant-002", "client1", "realm2", "user2"))
+		mock.ExpectExec(`^UPDATE oauth_access_token SET revoked = 1 WHERE client_id = \? AND revoked = 0$`).
+			WithArgs("client1").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &oauthAccessTokenManager{DB: db}
+		tokens, err := manager.RevokeByClientIDWithTx(context.Background(), tx, "client1")
+
+		tx.Commit()
+
+		assert.NoError(t, err)
+		assert.Len(t, tokens, 2)
+		assert.Equal(t, "realm2", tokens[1].RealmName)
+		assert.Equal(t, "user2", tokens[1].Sub)
+	})
+}
+
+func Test_oauthAccessTokenManager_CountExpired(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		before := time.Now()
+		mock.ExpectQuery(
+			`^SELECT COUNT\(\*\) FROM oauth_access_token WHERE expires_at < \? OR \(revoked = 1 AND updated_at < \?\)$`,
+		).
+			WithArgs(before, before).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+		manager := &oauthAccessTokenManager{DB: db}
+		count, err := manager.CountExpired(context.Background(), before)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+}
+
+func Test_oauthAccessTokenManager_DeleteExpired(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		before := time.Now()
+		mock.ExpectExec(`^DELETE FROM oauth_access_token WHERE expires_at < \? OR \(revoked = 1 AND updated_at < \?\) LIMIT \?$`).
+			WithArgs(before, before, int64(100)).
+			WillReturnResult(sqlmock.NewResult(0, 100))
+
+		manager := &oauthAccessTokenManager{DB: db}
+		rows, err := manager.DeleteExpired(context.Background(), before, 100)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(100), rows)
 	})
 }
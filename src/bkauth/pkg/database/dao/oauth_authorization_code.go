@@ -42,12 +42,16 @@ type OAuthAuthorizationCode struct {
 	RedirectURI string `db:"redirect_uri"`
 	Scope       string `db:"scope"`
 	// JSON string
-	Audience            string    `db:"audience"`
-	CodeChallenge       string    `db:"code_challenge"`
-	CodeChallengeMethod string    `db:"code_challenge_method"`
-	ExpiresAt           time.Time `db:"expires_at"`
-	Used                bool      `db:"used"`
-	CreatedAt           time.Time `db:"created_at"`
+	Audience string `db:"audience"`
+	Resource string `db:"resource"`
+	// JSON string, empty unless requested with authorization_details (RFC 9396)
+	AuthorizationDetails string    `db:"authorization_details"`
+	CodeChallenge        string    `db:"code_challenge"`
+	CodeChallengeMethod  string    `db:"code_challenge_method"`
+	Nonce                string    `db:"nonce"`
+	ExpiresAt            time.Time `db:"expires_at"`
+	Used                 bool      `db:"used"`
+	CreatedAt            time.Time `db:"created_at"`
 }
 
 // OAuthAuthorizationCodeManager defines the interface for authorization code operations
@@ -55,6 +59,10 @@ type OAuthAuthorizationCodeManager interface {
 	Create(ctx context.Context, code OAuthAuthorizationCode) error
 	Get(ctx context.Context, code string) (OAuthAuthorizationCode, error)
 	MarkAsUsed(ctx context.Context, code string) (int64, error)
+	// CountExpired counts the rows DeleteExpired would delete, for a dry run
+	CountExpired(ctx context.Context, before time.Time) (int64, error)
+	// DeleteExpired deletes at most limit rows that expired before the given time
+	DeleteExpired(ctx context.Context, before time.Time, limit int64) (int64, error)
 }
 
 type oauthAuthorizationCodeManager struct {
@@ -79,8 +87,11 @@ func (m *oauthAuthorizationCodeManager) Create(ctx context.Context, code OAuthAu
 		redirect_uri,
 		scope,
 		audience,
+		resource,
+		authorization_details,
 		code_challenge,
 		code_challenge_method,
+		nonce,
 		expires_at,
 		used
 	) VALUES (
@@ -93,8 +104,11 @@ func (m *oauthAuthorizationCodeManager) Create(ctx context.Context, code OAuthAu
 		:redirect_uri,
 		:scope,
 		:audience,
+		:resource,
+		:authorization_details,
 		:code_challenge,
 		:code_challenge_method,
+		:nonce,
 		:expires_at,
 		:used
 	)`
@@ -115,8 +129,11 @@ func (m *oauthAuthorizationCodeManager) Get(
 		redirect_uri,
 		scope,
 		audience,
+		resource,
+		authorization_details,
 		code_challenge,
 		code_challenge_method,
+		nonce,
 		expires_at,
 		used,
 		created_at
@@ -140,3 +157,18 @@ func (m *oauthAuthorizationCodeManager) MarkAsUsed(ctx context.Context, code str
 	}
 	return result.RowsAffected()
 }
+
+func (m *oauthAuthorizationCodeManager) CountExpired(ctx context.Context, before time.Time) (count int64, err error) {
+	query := `SELECT COUNT(*) FROM oauth_authorization_code WHERE expires_at < ?`
+	err = database.SqlxGet(ctx, m.DB, &count, query, before)
+	return count, err
+}
+
+// DeleteExpired deletes a batch of authorization codes that expired before the given time,
+// used or not: a code is only valid for minutes, so it has expired long before it could be deleted.
+func (m *oauthAuthorizationCodeManager) DeleteExpired(
+	ctx context.Context, before time.Time, limit int64,
+) (int64, error) {
+	query := `DELETE FROM oauth_authorization_code WHERE expires_at < ? LIMIT ?`
+	return database.SqlxDelete(ctx, m.DB, query, before, limit)
+}
@@ -16,25 +16,29 @@ func Test_oauthAuthorizationCodeManager_Create(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mock.ExpectExec(`^INSERT INTO oauth_authorization_code`).WithArgs(
 			"authcode123", "client1", "", "devops", "user1", "admin",
-			"https://example.com/cb", "openid profile", `["aud1"]`,
-			"challenge_value", "S256",
+			"https://example.com/cb", "openid profile", `["aud1"]`, "https://example.com/api",
+			`[{"type":"bk_gateway_api","identifier":"gw","actions":["get_host"]}]`,
+			"challenge_value", "S256", "n-0S6_WzA2Mj",
 			sqlmock.AnyArg(), // expires_at
 			false,
 		).WillReturnResult(sqlmock.NewResult(1, 1))
 
 		code := OAuthAuthorizationCode{
-			Code:                "authcode123",
-			ClientID:            "client1",
-			RealmName:           "devops",
-			Sub:                 "user1",
-			Username:            "admin",
-			RedirectURI:         "https://example.com/cb",
-			Scope:               "openid profile",
-			Audience:            `["aud1"]`,
-			CodeChallenge:       "challenge_value",
-			CodeChallengeMethod: "S256",
-			ExpiresAt:           time.Now().Add(10 * time.Minute),
-			Used:                false,
+			Code:                 "authcode123",
+			ClientID:             "client1",
+			RealmName:            "devops",
+			Sub:                  "user1",
+			Username:             "admin",
+			RedirectURI:          "https://example.com/cb",
+			Scope:                "openid profile",
+			Audience:             `["aud1"]`,
+			Resource:             "https://example.com/api",
+			AuthorizationDetails: `[{"type":"bk_gateway_api","identifier":"gw","actions":["get_host"]}]`,
+			CodeChallenge:        "challenge_value",
+			CodeChallengeMethod:  "S256",
+			Nonce:                "n-0S6_WzA2Mj",
+			ExpiresAt:            time.Now().Add(10 * time.Minute),
+			Used:                 false,
 		}
 
 		manager := &oauthAuthorizationCodeManager{DB: db}
@@ -50,13 +54,13 @@ func Test_oauthAuthorizationCodeManager_Get(t *testing.T) {
 		expiresAt := now.Add(10 * time.Minute)
 		mockRows := sqlmock.NewRows([]string{
 			"code", "client_id", "tenant_id", "realm_name", "sub", "username",
-			"redirect_uri", "scope", "audience",
-			"code_challenge", "code_challenge_method",
+			"redirect_uri", "scope", "audience", "resource",
+			"code_challenge", "code_challenge_method", "nonce",
 			"expires_at", "used", "created_at",
 		}).AddRow(
 			"authcode123", "client1", "", "devops", "user1", "admin",
-			"https://example.com/cb", "openid profile", `["aud1"]`,
-			"challenge_value", "S256",
+			"https://example.com/cb", "openid profile", `["aud1"]`, "https://example.com/api",
+			"challenge_value", "S256", "n-0S6_WzA2Mj",
 			expiresAt, false, now,
 		)
 		mock.ExpectQuery(`^SELECT`).WithArgs("authcode123").WillReturnRows(mockRows)
@@ -73,8 +77,10 @@ func Test_oauthAuthorizationCodeManager_Get(t *testing.T) {
 		assert.Equal(t, "https://example.com/cb", authCode.RedirectURI)
 		assert.Equal(t, "openid profile", authCode.Scope)
 		assert.Equal(t, `["aud1"]`, authCode.Audience)
+		assert.Equal(t, "https://example.com/api", authCode.Resource)
 		assert.Equal(t, "challenge_value", authCode.CodeChallenge)
 		assert.Equal(t, "S256", authCode.CodeChallengeMethod)
+		assert.Equal(t, "n-0S6_WzA2Mj", authCode.Nonce)
 		assert.False(t, authCode.Used)
 	})
 }
@@ -83,8 +89,8 @@ func Test_oauthAuthorizationCodeManager_Get_NotFound(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mockRows := sqlmock.NewRows([]string{
 			"code", "client_id", "tenant_id", "realm_name", "sub", "username",
-			"redirect_uri", "scope", "audience",
-			"code_challenge", "code_challenge_method",
+			"redirect_uri", "scope", "audience", "resource",
+			"code_challenge", "code_challenge_method", "nonce",
 			"expires_at", "used", "created_at",
 		})
 		mock.ExpectQuery(`^SELECT`).WithArgs("nonexistent").WillReturnRows(mockRows)
@@ -124,3 +130,18 @@ func Test_oauthAuthorizationCodeManager_MarkAsUsed_AlreadyUsed(t *testing.T) {
 		assert.Equal(t, int64(0), affected)
 	})
 }
+
+func Test_oauthAuthorizationCodeManager_DeleteExpired(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		before := time.Now()
+		mock.ExpectExec(`^DELETE FROM oauth_authorization_code WHERE expires_at < \? LIMIT \?$`).
+			WithArgs(before, int64(100)).
+			WillReturnResult(sqlmock.NewResult(0, 100))
+
+		manager := &oauthAuthorizationCodeManager{DB: db}
+		rows, err := manager.DeleteExpired(context.Background(), before, 100)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(100), rows)
+	})
+}
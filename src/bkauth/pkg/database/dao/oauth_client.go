@@ -44,13 +44,31 @@ type OAuthClient struct {
 	LogoURI      string    `db:"logo_uri"`
 	CreatedAt    time.Time `db:"created_at"`
 	UpdatedAt    time.Time `db:"updated_at"`
+
+	// empty means derived from type
+	TokenEndpointAuthMethod string  `db:"token_endpoint_auth_method"`
+	TLSClientAuthSubjectDN  string  `db:"tls_client_auth_subject_dn"`
+	TLSClientAuthSAN        string  `db:"tls_client_auth_san"`
+	JWKS                    *string `db:"jwks"` // JSON string, NULL unless registered
+	JWKSURI                 string  `db:"jwks_uri"`
+
+	// RFC 7592: empty for clients not managed through the client configuration endpoint
+	RegistrationAccessTokenHash string `db:"registration_access_token_hash"`
+
+	// comma-separated, empty means not restricted
+	AllowedRealms    string `db:"allowed_realms"`
+	AllowedResources string `db:"allowed_resources"`
 }
 
 // OAuthClientGrants holds the authorization capability configuration of the client.
 type OAuthClientGrants struct {
 	ID           string `db:"id"`
+	Type         string `db:"type"`
 	RedirectURIs string `db:"redirect_uris"` // JSON array
 	GrantTypes   string `db:"grant_types"`   // comma-separated
+
+	AllowedRealms    string `db:"allowed_realms"`    // comma-separated
+	AllowedResources string `db:"allowed_resources"` // comma-separated
 }
 
 // OAuthClientDisplay holds the presentable identity of the client.
@@ -61,6 +79,18 @@ type OAuthClientDisplay struct {
 	LogoURI string `db:"logo_uri"`
 }
 
+// OAuthClientAuthentication holds the registered token endpoint authentication of the client.
+type OAuthClientAuthentication struct {
+	ID   string `db:"id"`
+	Type string `db:"type"`
+	// empty means derived from type
+	TokenEndpointAuthMethod string  `db:"token_endpoint_auth_method"`
+	TLSClientAuthSubjectDN  string  `db:"tls_client_auth_subject_dn"`
+	TLSClientAuthSAN        string  `db:"tls_client_auth_san"`
+	JWKS                    *string `db:"jwks"` // JSON string, NULL unless registered
+	JWKSURI                 string  `db:"jwks_uri"`
+}
+
 // OAuthClientManager defines the interface for OAuth client operations
 type OAuthClientManager interface {
 	Create(ctx context.Context, client OAuthClient) error
@@ -68,6 +98,10 @@ type OAuthClientManager interface {
 	Exists(ctx context.Context, clientID string) (bool, error)
 	GetGrants(ctx context.Context, clientID string) (OAuthClientGrants, error)
 	GetDisplay(ctx context.Context, clientID string) (OAuthClientDisplay, error)
+	GetAuthentication(ctx context.Context, clientID string) (OAuthClientAuthentication, error)
+	Update(ctx context.Context, client OAuthClient) (int64, error)
+	UpdateMetadata(ctx context.Context, client OAuthClient) (int64, error)
+	DeleteWithTx(ctx context.Context, tx *sqlx.Tx, clientID string) (int64, error)
 }
 
 type oauthClientManager struct {
@@ -88,14 +122,30 @@ func (m *oauthClientManager) Create(ctx context.Context, client OAuthClient) err
 		type,
 		redirect_uris,
 		grant_types,
-		logo_uri
+		logo_uri,
+		token_endpoint_auth_method,
+		tls_client_auth_subject_dn,
+		tls_client_auth_san,
+		jwks,
+		jwks_uri,
+		registration_access_token_hash,
+		allowed_realms,
+		allowed_resources
 	) VALUES (
 		:id,
 		:name,
 		:type,
 		:redirect_uris,
 		:grant_types,
-		:logo_uri
+		:logo_uri,
+		:token_endpoint_auth_method,
+		:tls_client_auth_subject_dn,
+		:tls_client_auth_san,
+		:jwks,
+		:jwks_uri,
+		:registration_access_token_hash,
+		:allowed_realms,
+		:allowed_resources
 	)`
 	_, err := database.SqlxInsert(ctx, m.DB, query, client)
 	return err
@@ -109,6 +159,14 @@ func (m *oauthClientManager) Get(ctx context.Context, clientID string) (client O
 		redirect_uris,
 		grant_types,
 		logo_uri,
+		token_endpoint_auth_method,
+		tls_client_auth_subject_dn,
+		tls_client_auth_san,
+		jwks,
+		jwks_uri,
+		registration_access_token_hash,
+		allowed_realms,
+		allowed_resources,
 		created_at,
 		updated_at
 	FROM oauth_client 
@@ -136,7 +194,16 @@ func (m *oauthClientManager) Exists(ctx context.Context, clientID string) (bool,
 }
 
 func (m *oauthClientManager) GetGrants(ctx context.Context, clientID string) (grants OAuthClientGrants, err error) {
-	query := `SELECT id, redirect_uris, grant_types FROM oauth_client WHERE id = ? LIMIT 1`
+	query := `SELECT 
+		id,
+		type,
+		redirect_uris,
+		grant_types,
+		allowed_realms,
+		allowed_resources
+	FROM oauth_client 
+	WHERE id = ? 
+	LIMIT 1`
 	err = database.SqlxGet(ctx, m.DB, &grants, query, clientID)
 	if errors.Is(err, sql.ErrNoRows) {
 		return grants, nil
@@ -152,3 +219,64 @@ func (m *oauthClientManager) GetDisplay(ctx context.Context, clientID string) (d
 	}
 	return display, err
 }
+
+func (m *oauthClientManager) GetAuthentication(
+	ctx context.Context, clientID string,
+) (authentication OAuthClientAuthentication, err error) {
+	query := `SELECT 
+		id,
+		type,
+		token_endpoint_auth_method,
+		tls_client_auth_subject_dn,
+		tls_client_auth_san,
+		jwks,
+		jwks_uri
+	FROM oauth_client 
+	WHERE id = ? 
+	LIMIT 1`
+
+	err = database.SqlxGet(ctx, m.DB, &authentication, query, clientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return authentication, nil
+	}
+	return authentication, err
+}
+
+// Update overwrites the client metadata, the client type, the registration access token
+// and the client policy are kept.
+func (m *oauthClientManager) Update(ctx context.Context, client OAuthClient) (int64, error) {
+	query := `UPDATE oauth_client SET
+		name = :name,
+		redirect_uris = :redirect_uris,
+		grant_types = :grant_types,
+		logo_uri = :logo_uri,
+		token_endpoint_auth_method = :token_endpoint_auth_method,
+		jwks = :jwks,
+		jwks_uri = :jwks_uri
+	WHERE id = :id`
+	return database.SqlxUpdate(ctx, m.DB, query, client)
+}
+
+// UpdateMetadata overwrites the display name, redirect URIs, grant types, logo, token endpoint
+// authentication and policy of the client, its type and registration access token are kept.
+func (m *oauthClientManager) UpdateMetadata(ctx context.Context, client OAuthClient) (int64, error) {
+	query := `UPDATE oauth_client SET
+		name = :name,
+		redirect_uris = :redirect_uris,
+		grant_types = :grant_types,
+		logo_uri = :logo_uri,
+		token_endpoint_auth_method = :token_endpoint_auth_method,
+		tls_client_auth_subject_dn = :tls_client_auth_subject_dn,
+		tls_client_auth_san = :tls_client_auth_san,
+		jwks = :jwks,
+		jwks_uri = :jwks_uri,
+		allowed_realms = :allowed_realms,
+		allowed_resources = :allowed_resources
+	WHERE id = :id`
+	return database.SqlxUpdate(ctx, m.DB, query, client)
+}
+
+func (m *oauthClientManager) DeleteWithTx(ctx context.Context, tx *sqlx.Tx, clientID string) (int64, error) {
+	query := `DELETE FROM oauth_client WHERE id = ?`
+	return database.SqlxDeleteWithTx(ctx, tx, query, clientID)
+}
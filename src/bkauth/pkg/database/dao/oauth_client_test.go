@@ -16,6 +16,7 @@ func Test_oauthClientManager_Create(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mock.ExpectExec(`^INSERT INTO oauth_client`).WithArgs(
 			"client1", "Test Client", "public", `["https://example.com/cb"]`, "authorization_code", "https://example.com/logo.png",
+			"", "", "", nil, "", "", "", "",
 		).WillReturnResult(sqlmock.NewResult(1, 1))
 
 		client := OAuthClient{
@@ -38,8 +39,11 @@ func Test_oauthClientManager_Get(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		now := time.Now()
 		mockRows := sqlmock.NewRows([]string{
-			"id", "name", "type", "redirect_uris", "grant_types", "logo_uri", "created_at", "updated_at",
-		}).AddRow("client1", "Test Client", "public", `["https://example.com/cb"]`, "authorization_code", "https://example.com/logo.png", now, now)
+			"id", "name", "type", "redirect_uris", "grant_types", "logo_uri",
+			"token_endpoint_auth_method", "tls_client_auth_subject_dn", "tls_client_auth_san", "jwks", "jwks_uri",
+			"registration_access_token_hash", "allowed_realms", "allowed_resources", "created_at", "updated_at",
+		}).AddRow("client1", "Test Client", "public", `["https://example.com/cb"]`, "authorization_code", "https://example.com/logo.png",
+			"private_key_jwt", "", "", nil, "https://example.com/jwks.json", "rat-hash", "blueking", "mcp:*", now, now)
 		mock.ExpectQuery(`^SELECT`).WithArgs("client1").WillReturnRows(mockRows)
 
 		manager := &oauthClientManager{DB: db}
@@ -52,13 +56,21 @@ func Test_oauthClientManager_Get(t *testing.T) {
 		assert.Equal(t, `["https://example.com/cb"]`, client.RedirectURIs)
 		assert.Equal(t, "authorization_code", client.GrantTypes)
 		assert.Equal(t, "https://example.com/logo.png", client.LogoURI)
+		assert.Equal(t, "private_key_jwt", client.TokenEndpointAuthMethod)
+		assert.Nil(t, client.JWKS)
+		assert.Equal(t, "https://example.com/jwks.json", client.JWKSURI)
+		assert.Equal(t, "rat-hash", client.RegistrationAccessTokenHash)
+		assert.Equal(t, "blueking", client.AllowedRealms)
+		assert.Equal(t, "mcp:*", client.AllowedResources)
 	})
 }
 
 func Test_oauthClientManager_Get_NotFound(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mockRows := sqlmock.NewRows([]string{
-			"id", "name", "type", "redirect_uris", "grant_types", "logo_uri", "created_at", "updated_at",
+			"id", "name", "type", "redirect_uris", "grant_types", "logo_uri",
+			"token_endpoint_auth_method", "jwks", "jwks_uri", "registration_access_token_hash",
+			"allowed_realms", "allowed_resources", "created_at", "updated_at",
 		})
 		mock.ExpectQuery(`^SELECT`).WithArgs("nonexistent").WillReturnRows(mockRows)
 
@@ -100,9 +112,11 @@ func Test_oauthClientManager_Exists_NotFound(t *testing.T) {
 
 func Test_oauthClientManager_GetGrants(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
-		mockRows := sqlmock.NewRows([]string{"id", "redirect_uris", "grant_types"}).
-			AddRow("client1", `["https://example.com/cb"]`, "authorization_code")
-		mock.ExpectQuery(`^SELECT id, redirect_uris, grant_types FROM oauth_client WHERE id = \? LIMIT 1$`).
+		mockRows := sqlmock.NewRows([]string{
+			"id", "type", "redirect_uris", "grant_types", "allowed_realms", "allowed_resources",
+		}).AddRow("client1", "confidential", `["https://example.com/cb"]`, "authorization_code", "blueking", "mcp:foo")
+		mock.ExpectQuery(`^SELECT id, type, redirect_uris, grant_types, allowed_realms, allowed_resources ` +
+			`FROM oauth_client WHERE id = \? LIMIT 1$`).
 			WithArgs("client1").WillReturnRows(mockRows)
 
 		manager := &oauthClientManager{DB: db}
@@ -110,15 +124,21 @@ func Test_oauthClientManager_GetGrants(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.Equal(t, "client1", grants.ID)
+		assert.Equal(t, "confidential", grants.Type)
 		assert.Equal(t, `["https://example.com/cb"]`, grants.RedirectURIs)
 		assert.Equal(t, "authorization_code", grants.GrantTypes)
+		assert.Equal(t, "blueking", grants.AllowedRealms)
+		assert.Equal(t, "mcp:foo", grants.AllowedResources)
 	})
 }
 
 func Test_oauthClientManager_GetGrants_NotFound(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
-		mockRows := sqlmock.NewRows([]string{"id", "redirect_uris", "grant_types"})
-		mock.ExpectQuery(`^SELECT id, redirect_uris, grant_types FROM oauth_client WHERE id = \? LIMIT 1$`).
+		mockRows := sqlmock.NewRows([]string{
+			"id", "type", "redirect_uris", "grant_types", "allowed_realms", "allowed_resources",
+		})
+		mock.ExpectQuery(`^SELECT id, type, redirect_uris, grant_types, allowed_realms, allowed_resources ` +
+			`FROM oauth_client WHERE id = \? LIMIT 1$`).
 			WithArgs("nonexistent").WillReturnRows(mockRows)
 
 		manager := &oauthClientManager{DB: db}
@@ -160,3 +180,113 @@ func Test_oauthClientManager_GetDisplay_NotFound(t *testing.T) {
 		assert.Empty(t, display.ID)
 	})
 }
+
+func Test_oauthClientManager_GetAuthentication(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockRows := sqlmock.NewRows([]string{
+			"id", "type", "token_endpoint_auth_method", "tls_client_auth_subject_dn", "tls_client_auth_san", "jwks",
+			"jwks_uri",
+		}).AddRow("client1", "confidential", "tls_client_auth", "CN=client1,O=BlueKing", "", nil, "")
+		mock.ExpectQuery(`^SELECT`).WithArgs("client1").WillReturnRows(mockRows)
+
+		manager := &oauthClientManager{DB: db}
+		authentication, err := manager.GetAuthentication(context.Background(), "client1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "client1", authentication.ID)
+		assert.Equal(t, "confidential", authentication.Type)
+		assert.Equal(t, "tls_client_auth", authentication.TokenEndpointAuthMethod)
+		assert.Equal(t, "CN=client1,O=BlueKing", authentication.TLSClientAuthSubjectDN)
+		assert.Empty(t, authentication.TLSClientAuthSAN)
+		assert.Nil(t, authentication.JWKS)
+	})
+}
+
+func Test_oauthClientManager_GetAuthentication_NotFound(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockRows := sqlmock.NewRows([]string{
+			"id", "type", "token_endpoint_auth_method", "tls_client_auth_subject_dn", "tls_client_auth_san", "jwks",
+			"jwks_uri",
+		})
+		mock.ExpectQuery(`^SELECT`).WithArgs("nonexistent").WillReturnRows(mockRows)
+
+		manager := &oauthClientManager{DB: db}
+		authentication, err := manager.GetAuthentication(context.Background(), "nonexistent")
+
+		assert.NoError(t, err)
+		assert.Empty(t, authentication.ID)
+	})
+}
+
+func Test_oauthClientManager_Update(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectExec(`^UPDATE oauth_client SET`).WithArgs(
+			"Renamed Client", `["https://example.com/cb2"]`, "authorization_code", "",
+			"", nil, "", "client1",
+		).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		client := OAuthClient{
+			ID:           "client1",
+			Name:         "Renamed Client",
+			RedirectURIs: `["https://example.com/cb2"]`,
+			GrantTypes:   "authorization_code",
+		}
+
+		manager := &oauthClientManager{DB: db}
+		affected, err := manager.Update(context.Background(), client)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+	})
+}
+
+func Test_oauthClientManager_UpdateMetadata(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectExec(`^UPDATE oauth_client SET name = \?, redirect_uris = \?, grant_types = \?, logo_uri = \?, `+
+			`token_endpoint_auth_method = \?, tls_client_auth_subject_dn = \?, tls_client_auth_san = \?, `+
+			`jwks = \?, jwks_uri = \?, allowed_realms = \?, allowed_resources = \? WHERE`).
+			WithArgs(
+				"BK PaaS", `["https://example.com/cb"]`, "authorization_code,client_credentials", "",
+				"tls_client_auth", "CN=bk_paas,O=BlueKing", "", nil, "",
+				"blueking", "gateway:bk_paas:api:*", "bk_paas",
+			).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		client := OAuthClient{
+			ID:                      "bk_paas",
+			Name:                    "BK PaaS",
+			RedirectURIs:            `["https://example.com/cb"]`,
+			GrantTypes:              "authorization_code,client_credentials",
+			TokenEndpointAuthMethod: "tls_client_auth",
+			TLSClientAuthSubjectDN:  "CN=bk_paas,O=BlueKing",
+			AllowedRealms:           "blueking",
+			AllowedResources:        "gateway:bk_paas:api:*",
+		}
+
+		manager := &oauthClientManager{DB: db}
+		affected, err := manager.UpdateMetadata(context.Background(), client)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+	})
+}
+
+func Test_oauthClientManager_DeleteWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`^DELETE FROM oauth_client WHERE id = \?$`).
+			WithArgs("client1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &oauthClientManager{DB: db}
+		affected, err := manager.DeleteWithTx(context.Background(), tx, "client1")
+
+		tx.Commit()
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+	})
+}
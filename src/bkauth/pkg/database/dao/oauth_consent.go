@@ -0,0 +1,121 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth服务(BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package dao
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"bkauth/pkg/database"
+)
+
+// OAuthConsent represents the consent a user approved for a client on a realm
+type OAuthConsent struct {
+	ID        int64     `db:"id"`
+	TenantID  string    `db:"tenant_id"`
+	Sub       string    `db:"sub"`
+	RealmName string    `db:"realm_name"`
+	ClientID  string    `db:"client_id"`
+	Audience  string    `db:"audience"` // JSON string
+	Scope     string    `db:"scope"`
+	GrantedAt time.Time `db:"granted_at"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+// OAuthConsentManager defines the interface for consent operations
+type OAuthConsentManager interface {
+	Get(ctx context.Context, tenantID, sub, realmName, clientID string) (OAuthConsent, error)
+	// Upsert creates the consent of (tenant_id, sub, realm_name, client_id) or replaces it
+	Upsert(ctx context.Context, consent OAuthConsent) error
+	Delete(ctx context.Context, tenantID, sub, realmName, clientID string) (int64, error)
+}
+
+type oauthConsentManager struct {
+	DB *sqlx.DB
+}
+
+// NewOAuthConsentManager creates a new OAuthConsentManager
+func NewOAuthConsentManager() OAuthConsentManager {
+	return &oauthConsentManager{
+		DB: database.GetDefaultDBClient().DB,
+	}
+}
+
+func (m *oauthConsentManager) Get(
+	ctx context.Context, tenantID, sub, realmName, clientID string,
+) (consent OAuthConsent, err error) {
+	query := `SELECT
+		id,
+		tenant_id,
+		sub,
+		realm_name,
+		client_id,
+		audience,
+		scope,
+		granted_at,
+		expires_at
+	FROM oauth_consent
+	WHERE tenant_id = ? AND sub = ? AND realm_name = ? AND client_id = ?
+	LIMIT 1`
+
+	err = database.SqlxGet(ctx, m.DB, &consent, query, tenantID, sub, realmName, clientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return consent, nil
+	}
+	return consent, err
+}
+
+func (m *oauthConsentManager) Upsert(ctx context.Context, consent OAuthConsent) error {
+	query := `INSERT INTO oauth_consent (
+		tenant_id,
+		sub,
+		realm_name,
+		client_id,
+		audience,
+		scope,
+		granted_at,
+		expires_at
+	) VALUES (
+		:tenant_id,
+		:sub,
+		:realm_name,
+		:client_id,
+		:audience,
+		:scope,
+		:granted_at,
+		:expires_at
+	) ON DUPLICATE KEY UPDATE
+		audience = VALUES(audience),
+		scope = VALUES(scope),
+		granted_at = VALUES(granted_at),
+		expires_at = VALUES(expires_at)`
+	_, err := database.SqlxInsert(ctx, m.DB, query, consent)
+	return err
+}
+
+func (m *oauthConsentManager) Delete(ctx context.Context, tenantID, sub, realmName, clientID string) (int64, error) {
+	query := `DELETE FROM oauth_consent WHERE tenant_id = ? AND sub = ? AND realm_name = ? AND client_id = ?`
+	return database.SqlxDelete(ctx, m.DB, query, tenantID, sub, realmName, clientID)
+}
@@ -0,0 +1,86 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"bkauth/pkg/database"
+)
+
+func Test_oauthConsentManager_Get(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		now := time.Now()
+		mockRows := sqlmock.NewRows([]string{
+			"id", "tenant_id", "sub", "realm_name", "client_id", "audience", "scope", "granted_at", "expires_at",
+		}).AddRow(int64(1), "default", "user1", "blueking", "dcr_abc", `["mcp:foo"]`, "openid", now, now.Add(time.Hour))
+		mock.ExpectQuery(`^SELECT`).WithArgs("default", "user1", "blueking", "dcr_abc").WillReturnRows(mockRows)
+
+		manager := &oauthConsentManager{DB: db}
+		consent, err := manager.Get(context.Background(), "default", "user1", "blueking", "dcr_abc")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), consent.ID)
+		assert.Equal(t, `["mcp:foo"]`, consent.Audience)
+		assert.Equal(t, "openid", consent.Scope)
+	})
+}
+
+func Test_oauthConsentManager_Get_NotFound(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockRows := sqlmock.NewRows([]string{
+			"id", "tenant_id", "sub", "realm_name", "client_id", "audience", "scope", "granted_at", "expires_at",
+		})
+		mock.ExpectQuery(`^SELECT`).WithArgs("default", "user1", "blueking", "dcr_abc").WillReturnRows(mockRows)
+
+		manager := &oauthConsentManager{DB: db}
+		consent, err := manager.Get(context.Background(), "default", "user1", "blueking", "dcr_abc")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), consent.ID)
+	})
+}
+
+func Test_oauthConsentManager_Upsert(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectExec(`^INSERT INTO oauth_consent .* ON DUPLICATE KEY UPDATE`).WithArgs(
+			"default", "user1", "blueking", "dcr_abc", `["mcp:foo"]`, "openid",
+			sqlmock.AnyArg(), // granted_at
+			sqlmock.AnyArg(), // expires_at
+		).WillReturnResult(sqlmock.NewResult(1, 1))
+
+		consent := OAuthConsent{
+			TenantID:  "default",
+			Sub:       "user1",
+			RealmName: "blueking",
+			ClientID:  "dcr_abc",
+			Audience:  `["mcp:foo"]`,
+			Scope:     "openid",
+			GrantedAt: time.Now(),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+
+		manager := &oauthConsentManager{DB: db}
+		err := manager.Upsert(context.Background(), consent)
+
+		assert.NoError(t, err)
+	})
+}
+
+func Test_oauthConsentManager_Delete(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectExec(`^DELETE FROM oauth_consent WHERE tenant_id = \? AND sub = \?`).
+			WithArgs("default", "user1", "blueking", "dcr_abc").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		manager := &oauthConsentManager{DB: db}
+		rows, err := manager.Delete(context.Background(), "default", "user1", "blueking", "dcr_abc")
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), rows)
+	})
+}
@@ -33,14 +33,19 @@ import (
 
 // OAuthDeviceCode represents an OAuth device authorization code (RFC 8628)
 type OAuthDeviceCode struct {
-	ID         int64  `db:"id"`
-	DeviceCode string `db:"device_code"`
-	UserCode   string `db:"user_code"`
-	ClientID   string `db:"client_id"`
-	TenantID   string `db:"tenant_id"`
-	Scope      string `db:"scope"`
-	Resource   string `db:"resource"`
-	RealmName  string `db:"realm_name"`
+	ID int64 `db:"id"`
+	// DeviceCodeHash is the hash of the device code; rows created before hashing hold the code itself
+	DeviceCodeHash string `db:"device_code"`
+	// DeviceCodeHashKeyID is the id of the HMAC key of DeviceCodeHash, empty for a legacy SHA-256 hash
+	DeviceCodeHashKeyID string `db:"device_code_hash_kid"`
+	UserCode            string `db:"user_code"`
+	ClientID            string `db:"client_id"`
+	TenantID            string `db:"tenant_id"`
+	Scope               string `db:"scope"`
+	Resource            string `db:"resource"`
+	// JSON string, empty unless requested with authorization_details (RFC 9396)
+	AuthorizationDetails string `db:"authorization_details"`
+	RealmName            string `db:"realm_name"`
 	// JSON string
 	Audience *string `db:"audience"`
 	// pending, approved, denied, consumed
@@ -57,14 +62,19 @@ type OAuthDeviceCode struct {
 // OAuthDeviceCodeManager defines the interface for device code operations
 type OAuthDeviceCodeManager interface {
 	Create(ctx context.Context, dc OAuthDeviceCode) (int64, error)
-	GetByDeviceCode(ctx context.Context, deviceCode string) (OAuthDeviceCode, error)
+	// GetByDeviceCodeHashes gets the device code stored under any of the hashes, one per hash key
+	GetByDeviceCodeHashes(ctx context.Context, deviceCodeHashes []string) (OAuthDeviceCode, error)
 	GetByUserCode(ctx context.Context, userCode string) (OAuthDeviceCode, error)
 	UpdateStatus(ctx context.Context, id int64, status string) (int64, error)
 	Approve(ctx context.Context, id int64, tenantID, sub, username, audience string) (int64, error)
-	ConsumeApproved(ctx context.Context, deviceCode, clientID string) (int64, error)
+	ConsumeApproved(ctx context.Context, deviceCodeHash, clientID string) (int64, error)
 	UpdateLastPolledAt(ctx context.Context, id int64) (int64, error)
 	// SlowDown atomically increases poll_interval and refreshes last_polled_at (RFC 8628 §3.5).
 	SlowDown(ctx context.Context, id int64, increment int64) (int64, error)
+	// CountExpired counts the rows DeleteExpired would delete, for a dry run
+	CountExpired(ctx context.Context, before time.Time) (int64, error)
+	// DeleteExpired deletes at most limit rows that expired before the given time
+	DeleteExpired(ctx context.Context, before time.Time, limit int64) (int64, error)
 }
 
 type oauthDeviceCodeManager struct {
@@ -81,11 +91,13 @@ func NewOAuthDeviceCodeManager() OAuthDeviceCodeManager {
 func (m *oauthDeviceCodeManager) Create(ctx context.Context, dc OAuthDeviceCode) (int64, error) {
 	query := `INSERT INTO oauth_device_code (
 		device_code,
+		device_code_hash_kid,
 		user_code,
 		client_id,
 		tenant_id,
 		scope,
 		resource,
+		authorization_details,
 		realm_name,
 		audience,
 		status,
@@ -96,11 +108,13 @@ func (m *oauthDeviceCodeManager) Create(ctx context.Context, dc OAuthDeviceCode)
 		expires_at
 	) VALUES (
 		:device_code,
+		:device_code_hash_kid,
 		:user_code,
 		:client_id,
 		:tenant_id,
 		:scope,
 		:resource,
+		:authorization_details,
 		:realm_name,
 		:audience,
 		:status,
@@ -113,17 +127,19 @@ func (m *oauthDeviceCodeManager) Create(ctx context.Context, dc OAuthDeviceCode)
 	return database.SqlxInsert(ctx, m.DB, query, dc)
 }
 
-func (m *oauthDeviceCodeManager) GetByDeviceCode(
-	ctx context.Context, deviceCode string,
+func (m *oauthDeviceCodeManager) GetByDeviceCodeHashes(
+	ctx context.Context, deviceCodeHashes []string,
 ) (dc OAuthDeviceCode, err error) {
 	query := `SELECT
 		id,
 		device_code,
+		device_code_hash_kid,
 		user_code,
 		client_id,
 		tenant_id,
 		scope,
 		resource,
+		authorization_details,
 		realm_name,
 		audience,
 		status,
@@ -135,10 +151,10 @@ func (m *oauthDeviceCodeManager) GetByDeviceCode(
 		created_at,
 		updated_at
 	FROM oauth_device_code
-	WHERE device_code = ?
+	WHERE device_code IN (?)
 	LIMIT 1`
 
-	err = database.SqlxGet(ctx, m.DB, &dc, query, deviceCode)
+	err = database.SqlxGet(ctx, m.DB, &dc, query, deviceCodeHashes)
 	if errors.Is(err, sql.ErrNoRows) {
 		return dc, nil
 	}
@@ -149,11 +165,13 @@ func (m *oauthDeviceCodeManager) GetByUserCode(ctx context.Context, userCode str
 	query := `SELECT
 		id,
 		device_code,
+		device_code_hash_kid,
 		user_code,
 		client_id,
 		tenant_id,
 		scope,
 		resource,
+		authorization_details,
 		realm_name,
 		audience,
 		status,
@@ -196,11 +214,13 @@ func (m *oauthDeviceCodeManager) Approve(
 	return result.RowsAffected()
 }
 
-func (m *oauthDeviceCodeManager) ConsumeApproved(ctx context.Context, deviceCode, clientID string) (int64, error) {
+func (m *oauthDeviceCodeManager) ConsumeApproved(
+	ctx context.Context, deviceCodeHash, clientID string,
+) (int64, error) {
 	query := `UPDATE oauth_device_code
 	SET status = 'consumed'
 	WHERE device_code = ? AND client_id = ? AND status = 'approved' AND expires_at > NOW()`
-	result, err := m.DB.ExecContext(ctx, query, deviceCode, clientID)
+	result, err := m.DB.ExecContext(ctx, query, deviceCodeHash, clientID)
 	if err != nil {
 		return 0, err
 	}
@@ -224,3 +244,15 @@ func (m *oauthDeviceCodeManager) SlowDown(ctx context.Context, id int64, increme
 	}
 	return result.RowsAffected()
 }
+
+func (m *oauthDeviceCodeManager) CountExpired(ctx context.Context, before time.Time) (count int64, err error) {
+	query := `SELECT COUNT(*) FROM oauth_device_code WHERE expires_at < ?`
+	err = database.SqlxGet(ctx, m.DB, &count, query, before)
+	return count, err
+}
+
+// DeleteExpired deletes a batch of device codes that expired before the given time, whatever their status.
+func (m *oauthDeviceCodeManager) DeleteExpired(ctx context.Context, before time.Time, limit int64) (int64, error) {
+	query := `DELETE FROM oauth_device_code WHERE expires_at < ? LIMIT ?`
+	return database.SqlxDelete(ctx, m.DB, query, before, limit)
+}
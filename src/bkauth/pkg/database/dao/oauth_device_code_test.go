@@ -15,8 +15,8 @@ import (
 func Test_oauthDeviceCodeManager_Create(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mock.ExpectExec(`^INSERT INTO oauth_device_code`).WithArgs(
-			"device123", "ABCD-EFGH", "client1", "",
-			"openid", "bk_paas", "devops",
+			"device_hash123", "kid-1", "ABCD-EFGH", "client1", "",
+			"openid", "bk_paas", "", "devops",
 			nil,              // audience (*string, nil)
 			"pending",        // status
 			"",               // sub
@@ -27,19 +27,20 @@ func Test_oauthDeviceCodeManager_Create(t *testing.T) {
 		).WillReturnResult(sqlmock.NewResult(1, 1))
 
 		dc := OAuthDeviceCode{
-			DeviceCode:   "device123",
-			UserCode:     "ABCD-EFGH",
-			ClientID:     "client1",
-			Scope:        "openid",
-			Resource:     "bk_paas",
-			RealmName:    "devops",
-			Audience:     nil,
-			Status:       "pending",
-			Sub:          "",
-			Username:     "",
-			PollInterval: 5,
-			LastPolledAt: nil,
-			ExpiresAt:    time.Now().Add(10 * time.Minute),
+			DeviceCodeHash:      "device_hash123",
+			DeviceCodeHashKeyID: "kid-1",
+			UserCode:            "ABCD-EFGH",
+			ClientID:            "client1",
+			Scope:               "openid",
+			Resource:            "bk_paas",
+			RealmName:           "devops",
+			Audience:            nil,
+			Status:              "pending",
+			Sub:                 "",
+			Username:            "",
+			PollInterval:        5,
+			LastPolledAt:        nil,
+			ExpiresAt:           time.Now().Add(10 * time.Minute),
 		}
 
 		manager := &oauthDeviceCodeManager{DB: db}
@@ -50,27 +51,28 @@ func Test_oauthDeviceCodeManager_Create(t *testing.T) {
 	})
 }
 
-func Test_oauthDeviceCodeManager_GetByDeviceCode(t *testing.T) {
+func Test_oauthDeviceCodeManager_GetByDeviceCodeHashes(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		now := time.Now()
 		audience := `["aud1"]`
 		mockRows := sqlmock.NewRows([]string{
-			"id", "device_code", "user_code", "client_id", "tenant_id", "scope", "resource", "realm_name",
-			"audience", "status", "sub", "username", "poll_interval",
+			"id", "device_code", "device_code_hash_kid", "user_code", "client_id", "tenant_id", "scope", "resource",
+			"realm_name", "audience", "status", "sub", "username", "poll_interval",
 			"last_polled_at", "expires_at", "created_at", "updated_at",
 		}).AddRow(
-			int64(1), "device123", "ABCD-EFGH", "client1", "", "openid", "bk_paas", "devops",
-			audience, "pending", "", "", int64(5),
+			int64(1), "device_hash123", "kid-1", "ABCD-EFGH", "client1", "", "openid", "bk_paas",
+			"devops", audience, "pending", "", "", int64(5),
 			nil, now.Add(10*time.Minute), now, now,
 		)
-		mock.ExpectQuery(`^SELECT`).WithArgs("device123").WillReturnRows(mockRows)
+		mock.ExpectQuery(`WHERE device_code IN \(\?, \?\)`).WithArgs("device_hash123", "device123").WillReturnRows(mockRows)
 
 		manager := &oauthDeviceCodeManager{DB: db}
-		dc, err := manager.GetByDeviceCode(context.Background(), "device123")
+		dc, err := manager.GetByDeviceCodeHashes(context.Background(), []string{"device_hash123", "device123"})
 
 		assert.NoError(t, err)
 		assert.Equal(t, int64(1), dc.ID)
-		assert.Equal(t, "device123", dc.DeviceCode)
+		assert.Equal(t, "device_hash123", dc.DeviceCodeHash)
+		assert.Equal(t, "kid-1", dc.DeviceCodeHashKeyID)
 		assert.Equal(t, "ABCD-EFGH", dc.UserCode)
 		assert.Equal(t, "client1", dc.ClientID)
 		assert.Equal(t, "pending", dc.Status)
@@ -80,7 +82,7 @@ func Test_oauthDeviceCodeManager_GetByDeviceCode(t *testing.T) {
 	})
 }
 
-func Test_oauthDeviceCodeManager_GetByDeviceCode_NotFound(t *testing.T) {
+func Test_oauthDeviceCodeManager_GetByDeviceCodeHashes_NotFound(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mockRows := sqlmock.NewRows([]string{
 			"id", "device_code", "user_code", "client_id", "tenant_id", "scope", "resource", "realm_name",
@@ -90,10 +92,10 @@ func Test_oauthDeviceCodeManager_GetByDeviceCode_NotFound(t *testing.T) {
 		mock.ExpectQuery(`^SELECT`).WithArgs("nonexistent").WillReturnRows(mockRows)
 
 		manager := &oauthDeviceCodeManager{DB: db}
-		dc, err := manager.GetByDeviceCode(context.Background(), "nonexistent")
+		dc, err := manager.GetByDeviceCodeHashes(context.Background(), []string{"nonexistent"})
 
 		assert.NoError(t, err)
-		assert.Empty(t, dc.DeviceCode)
+		assert.Empty(t, dc.DeviceCodeHash)
 	})
 }
 
@@ -170,11 +172,11 @@ func Test_oauthDeviceCodeManager_Approve(t *testing.T) {
 func Test_oauthDeviceCodeManager_ConsumeApproved(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mock.ExpectExec(`^UPDATE oauth_device_code`).
-			WithArgs("device123", "client1").
+			WithArgs("device_hash123", "client1").
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
 		manager := &oauthDeviceCodeManager{DB: db}
-		affected, err := manager.ConsumeApproved(context.Background(), "device123", "client1")
+		affected, err := manager.ConsumeApproved(context.Background(), "device_hash123", "client1")
 
 		assert.NoError(t, err)
 		assert.Equal(t, int64(1), affected)
@@ -208,3 +210,18 @@ func Test_oauthDeviceCodeManager_SlowDown(t *testing.T) {
 		assert.Equal(t, int64(1), affected)
 	})
 }
+
+func Test_oauthDeviceCodeManager_DeleteExpired(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		before := time.Now()
+		mock.ExpectExec(`^DELETE FROM oauth_device_code WHERE expires_at < \? LIMIT \?$`).
+			WithArgs(before, int64(100)).
+			WillReturnResult(sqlmock.NewResult(0, 100))
+
+		manager := &oauthDeviceCodeManager{DB: db}
+		rows, err := manager.DeleteExpired(context.Background(), before, 100)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(100), rows)
+	})
+}
@@ -0,0 +1,153 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth服务(BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package dao
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"bkauth/pkg/database"
+)
+
+// OAuthPersonalAccessToken represents a personal access token created by a user.
+// The token itself is stored as an OAuthAccessToken of the same GrantID.
+type OAuthPersonalAccessToken struct {
+	ID        int64     `db:"id"`
+	GrantID   string    `db:"grant_id"`
+	Name      string    `db:"name"`
+	TenantID  string    `db:"tenant_id"`
+	RealmName string    `db:"realm_name"`
+	Sub       string    `db:"sub"`
+	Username  string    `db:"username"`
+	Resource  string    `db:"resource"`
+	TokenMask string    `db:"token_mask"`
+	ExpiresAt time.Time `db:"expires_at"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// OAuthPersonalAccessTokenManager defines the interface for personal access token operations
+type OAuthPersonalAccessTokenManager interface {
+	CreateWithTx(ctx context.Context, tx *sqlx.Tx, token OAuthPersonalAccessToken) (int64, error)
+	Get(ctx context.Context, id int64) (OAuthPersonalAccessToken, error)
+	ListBySub(ctx context.Context, tenantID, sub string) ([]OAuthPersonalAccessToken, error)
+	DeleteWithTx(ctx context.Context, tx *sqlx.Tx, id int64) (int64, error)
+}
+
+type oauthPersonalAccessTokenManager struct {
+	DB *sqlx.DB
+}
+
+// NewOAuthPersonalAccessTokenManager creates a new OAuthPersonalAccessTokenManager
+func NewOAuthPersonalAccessTokenManager() OAuthPersonalAccessTokenManager {
+	return &oauthPersonalAccessTokenManager{
+		DB: database.GetDefaultDBClient().DB,
+	}
+}
+
+func (m *oauthPersonalAccessTokenManager) CreateWithTx(
+	ctx context.Context, tx *sqlx.Tx, token OAuthPersonalAccessToken,
+) (int64, error) {
+	query := `INSERT INTO oauth_personal_access_token (
+		grant_id,
+		name,
+		tenant_id,
+		realm_name,
+		sub,
+		username,
+		resource,
+		token_mask,
+		expires_at
+	) VALUES (
+		:grant_id,
+		:name,
+		:tenant_id,
+		:realm_name,
+		:sub,
+		:username,
+		:resource,
+		:token_mask,
+		:expires_at
+	)`
+	return database.SqlxInsertWithTx(ctx, tx, query, token)
+}
+
+func (m *oauthPersonalAccessTokenManager) Get(
+	ctx context.Context, id int64,
+) (token OAuthPersonalAccessToken, err error) {
+	query := `SELECT
+		id,
+		grant_id,
+		name,
+		tenant_id,
+		realm_name,
+		sub,
+		username,
+		resource,
+		token_mask,
+		expires_at,
+		created_at
+	FROM oauth_personal_access_token
+	WHERE id = ?
+	LIMIT 1`
+
+	err = database.SqlxGet(ctx, m.DB, &token, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return token, nil
+	}
+	return token, err
+}
+
+// ListBySub lists only the tokens whose access token is still valid, the access token
+// may have been revoked on its own or deleted by the janitor.
+func (m *oauthPersonalAccessTokenManager) ListBySub(
+	ctx context.Context, tenantID, sub string,
+) (tokens []OAuthPersonalAccessToken, err error) {
+	query := `SELECT
+		id,
+		grant_id,
+		name,
+		tenant_id,
+		realm_name,
+		sub,
+		username,
+		resource,
+		token_mask,
+		expires_at,
+		created_at
+	FROM oauth_personal_access_token AS pat
+	WHERE tenant_id = ? AND sub = ? AND EXISTS (
+		SELECT 1 FROM oauth_access_token AS t
+		WHERE t.grant_id = pat.grant_id AND t.revoked = 0 AND t.expires_at > NOW()
+	)
+	ORDER BY id DESC`
+
+	err = database.SqlxSelect(ctx, m.DB, &tokens, query, tenantID, sub)
+	return tokens, err
+}
+
+func (m *oauthPersonalAccessTokenManager) DeleteWithTx(ctx context.Context, tx *sqlx.Tx, id int64) (int64, error) {
+	query := `DELETE FROM oauth_personal_access_token WHERE id = ?`
+	return database.SqlxDeleteWithTx(ctx, tx, query, id)
+}
@@ -0,0 +1,134 @@
+package dao
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"bkauth/pkg/database"
+)
+
+var personalAccessTokenColumns = []string{
+	"id", "grant_id", "name", "tenant_id", "realm_name", "sub", "username",
+	"resource", "token_mask", "expires_at", "created_at",
+}
+
+func Test_oauthPersonalAccessTokenManager_CreateWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`^INSERT INTO oauth_personal_access_token`).WithArgs(
+			"grant-001", "ci-script", "default", "blueking", "user1", "admin",
+			"mcp:foo", "mask123",
+			sqlmock.AnyArg(), // expires_at
+		).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		token := OAuthPersonalAccessToken{
+			GrantID:   "grant-001",
+			Name:      "ci-script",
+			TenantID:  "default",
+			RealmName: "blueking",
+			Sub:       "user1",
+			Username:  "admin",
+			Resource:  "mcp:foo",
+			TokenMask: "mask123",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+
+		manager := &oauthPersonalAccessTokenManager{DB: db}
+		id, err := manager.CreateWithTx(context.Background(), tx, token)
+
+		tx.Commit()
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), id)
+	})
+}
+
+func Test_oauthPersonalAccessTokenManager_Get(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		now := time.Now()
+		mockRows := sqlmock.NewRows(personalAccessTokenColumns).AddRow(
+			int64(1), "grant-001", "ci-script", "default", "blueking", "user1", "admin",
+			"mcp:foo", "mask123", now.Add(time.Hour), now,
+		)
+		mock.ExpectQuery(`^SELECT`).WithArgs(int64(1)).WillReturnRows(mockRows)
+
+		manager := &oauthPersonalAccessTokenManager{DB: db}
+		token, err := manager.Get(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), token.ID)
+		assert.Equal(t, "grant-001", token.GrantID)
+		assert.Equal(t, "ci-script", token.Name)
+		assert.Equal(t, "mcp:foo", token.Resource)
+		assert.Equal(t, "mask123", token.TokenMask)
+	})
+}
+
+func Test_oauthPersonalAccessTokenManager_Get_NotFound(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mockRows := sqlmock.NewRows(personalAccessTokenColumns)
+		mock.ExpectQuery(`^SELECT`).WithArgs(int64(404)).WillReturnRows(mockRows)
+
+		manager := &oauthPersonalAccessTokenManager{DB: db}
+		token, err := manager.Get(context.Background(), 404)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), token.ID)
+	})
+}
+
+func Test_oauthPersonalAccessTokenManager_ListBySub(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		now := time.Now()
+		mockRows := sqlmock.NewRows(personalAccessTokenColumns).
+			AddRow(
+				int64(2), "grant-002", "laptop", "default", "blueking", "user1", "admin",
+				"mcp:bar", "mask456", now.Add(time.Hour), now,
+			).
+			AddRow(
+				int64(1), "grant-001", "ci-script", "default", "blueking", "user1", "admin",
+				"mcp:foo", "mask123", now.Add(time.Hour), now,
+			)
+		mock.ExpectQuery(`^SELECT .* FROM oauth_personal_access_token AS pat WHERE tenant_id = \? AND sub = \? `+
+			`AND EXISTS \( SELECT 1 FROM oauth_access_token AS t WHERE t.grant_id = pat.grant_id `+
+			`AND t.revoked = 0 AND t.expires_at > NOW\(\) \)`).
+			WithArgs("default", "user1").WillReturnRows(mockRows)
+
+		manager := &oauthPersonalAccessTokenManager{DB: db}
+		tokens, err := manager.ListBySub(context.Background(), "default", "user1")
+
+		assert.NoError(t, err)
+		assert.Len(t, tokens, 2)
+		assert.Equal(t, "laptop", tokens[0].Name)
+		assert.Equal(t, "ci-script", tokens[1].Name)
+	})
+}
+
+func Test_oauthPersonalAccessTokenManager_DeleteWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`^DELETE FROM oauth_personal_access_token WHERE id = \?$`).
+			WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &oauthPersonalAccessTokenManager{DB: db}
+		rows, err := manager.DeleteWithTx(context.Background(), tx, 1)
+
+		tx.Commit()
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), rows)
+	})
+}
@@ -33,38 +33,69 @@ import (
 
 // OAuthRefreshToken represents an OAuth refresh token
 type OAuthRefreshToken struct {
-	ID            int64     `db:"id"`
-	TokenHash     string    `db:"token_hash"`
-	TokenMask     string    `db:"token_mask"`
-	GrantID       string    `db:"grant_id"`
-	AccessTokenID int64     `db:"access_token_id"`
-	ClientID      string    `db:"client_id"`
-	TenantID      string    `db:"tenant_id"`
-	RealmName     string    `db:"realm_name"`
-	Sub           string    `db:"sub"`
-	Username      string    `db:"username"`
-	Audience      string    `db:"audience"` // JSON string
-	Scope         string    `db:"scope"`
-	ExpiresAt     time.Time `db:"expires_at"`
-	Revoked       bool      `db:"revoked"`
+	ID        int64  `db:"id"`
+	TokenHash string `db:"token_hash"`
+	// TokenHashKeyID is the id of the HMAC key of TokenHash, empty for a legacy SHA-256 hash
+	TokenHashKeyID string `db:"token_hash_kid"`
+	TokenMask      string `db:"token_mask"`
+	GrantID        string `db:"grant_id"`
+	AccessTokenID  int64  `db:"access_token_id"`
+	ClientID       string `db:"client_id"`
+	TenantID       string `db:"tenant_id"`
+	RealmName      string `db:"realm_name"`
+	Sub            string `db:"sub"`
+	Username       string `db:"username"`
+	Audience       string `db:"audience"` // JSON string
+	Resource       string `db:"resource"`
+	// JSON string, empty unless requested with authorization_details (RFC 9396)
+	AuthorizationDetails string    `db:"authorization_details"`
+	Scope                string    `db:"scope"`
+	CnfJKT               string    `db:"cnf_jkt"`
+	CnfX5T               string    `db:"cnf_x5t_s256"`
+	ExpiresAt            time.Time `db:"expires_at"`
+	Revoked              bool      `db:"revoked"`
 	// RotationCount tracks how many times the grant family (identified by
 	// GrantID) has been rotated via refresh token rotation (RFC 6749 §6).
 	// Each successful rotation creates a new refresh token row with
 	// count = previous_token.RotationCount + 1; initial issuance starts at 0.
 	// Retained for auditing and observability; session lifetime is bounded
-	// by the absolute ExpiresAt inherited from initial issuance.
+	// by the absolute ExpiresAt inherited from initial issuance, and by the
+	// idle timeout measured from the CreatedAt of the current token.
 	RotationCount int64     `db:"rotation_count"`
 	CreatedAt     time.Time `db:"created_at"`
 	UpdatedAt     time.Time `db:"updated_at"`
 }
 
+// OAuthRefreshTokenGrant is an active grant family, represented by its current refresh token
+type OAuthRefreshTokenGrant struct {
+	GrantID   string `db:"grant_id"`
+	ClientID  string `db:"client_id"`
+	RealmName string `db:"realm_name"`
+	Resource  string `db:"resource"`
+	Scope     string `db:"scope"`
+	// FirstIssuedAt is the creation time of the first refresh token of the family,
+	// LastRefreshedAt that of the current one.
+	FirstIssuedAt   time.Time `db:"first_issued_at"`
+	LastRefreshedAt time.Time `db:"last_refreshed_at"`
+	ExpiresAt       time.Time `db:"expires_at"`
+}
+
 // OAuthRefreshTokenManager defines the interface for refresh token operations
 type OAuthRefreshTokenManager interface {
 	CreateWithTx(ctx context.Context, tx *sqlx.Tx, token OAuthRefreshToken) (int64, error)
-	GetByTokenHash(ctx context.Context, tokenHash string) (OAuthRefreshToken, error)
+	// GetByTokenHashes gets the token stored under any of the hashes, one per hash key
+	GetByTokenHashes(ctx context.Context, tokenHashes []string) (OAuthRefreshToken, error)
 	RevokeWithTx(ctx context.Context, tx *sqlx.Tx, id int64) (int64, error)
 	RevokeIfNotRevokedWithTx(ctx context.Context, tx *sqlx.Tx, id int64) (int64, error)
-	RevokeByGrantIDWithTx(ctx context.Context, tx *sqlx.Tx, grantID string) (int64, error)
+	// RevokeByGrantIDWithTx and RevokeByClientIDWithTx return the tokens they revoke,
+	// which the caller publishes once committed
+	RevokeByGrantIDWithTx(ctx context.Context, tx *sqlx.Tx, grantID string) ([]RevokedToken, error)
+	RevokeByClientIDWithTx(ctx context.Context, tx *sqlx.Tx, clientID string) ([]RevokedToken, error)
+	ListActiveGrantsBySub(ctx context.Context, tenantID, sub string, now time.Time) ([]OAuthRefreshTokenGrant, error)
+	// CountExpired counts the rows DeleteExpired would delete, for a dry run
+	CountExpired(ctx context.Context, before time.Time) (int64, error)
+	// DeleteExpired deletes at most limit rows that expired before the given time
+	DeleteExpired(ctx context.Context, before time.Time, limit int64) (int64, error)
 }
 
 type oauthRefreshTokenManager struct {
@@ -83,6 +114,7 @@ func (m *oauthRefreshTokenManager) CreateWithTx(
 ) (int64, error) {
 	query := `INSERT INTO oauth_refresh_token (
 		token_hash,
+		token_hash_kid,
 		token_mask,
 		grant_id,
 		access_token_id,
@@ -92,12 +124,17 @@ func (m *oauthRefreshTokenManager) CreateWithTx(
 		sub,
 		username,
 		audience,
+		resource,
+		authorization_details,
 		scope,
+		cnf_jkt,
+		cnf_x5t_s256,
 		expires_at,
 		revoked,
 		rotation_count
 	) VALUES (
 		:token_hash,
+		:token_hash_kid,
 		:token_mask,
 		:grant_id,
 		:access_token_id,
@@ -107,7 +144,11 @@ func (m *oauthRefreshTokenManager) CreateWithTx(
 		:sub,
 		:username,
 		:audience,
+		:resource,
+		:authorization_details,
 		:scope,
+		:cnf_jkt,
+		:cnf_x5t_s256,
 		:expires_at,
 		:revoked,
 		:rotation_count
@@ -115,12 +156,13 @@ func (m *oauthRefreshTokenManager) CreateWithTx(
 	return database.SqlxInsertWithTx(ctx, tx, query, token)
 }
 
-func (m *oauthRefreshTokenManager) GetByTokenHash(
-	ctx context.Context, tokenHash string,
+func (m *oauthRefreshTokenManager) GetByTokenHashes(
+	ctx context.Context, tokenHashes []string,
 ) (token OAuthRefreshToken, err error) {
 	query := `SELECT 
 		id,
 		token_hash,
+		token_hash_kid,
 		token_mask,
 		grant_id,
 		access_token_id,
@@ -130,17 +172,21 @@ func (m *oauthRefreshTokenManager) GetByTokenHash(
 		sub,
 		username,
 		audience,
+		resource,
+		authorization_details,
 		scope,
+		cnf_jkt,
+		cnf_x5t_s256,
 		expires_at,
 		revoked,
 		rotation_count,
 		created_at,
 		updated_at
 	FROM oauth_refresh_token 
-	WHERE token_hash = ? 
+	WHERE token_hash IN (?) 
 	LIMIT 1`
 
-	err = database.SqlxGet(ctx, m.DB, &token, query, tokenHash)
+	err = database.SqlxGet(ctx, m.DB, &token, query, tokenHashes)
 	if errors.Is(err, sql.ErrNoRows) {
 		return token, nil
 	}
@@ -170,13 +216,88 @@ func (m *oauthRefreshTokenManager) RevokeIfNotRevokedWithTx(
 	return result.RowsAffected()
 }
 
+// RevokeByGrantIDWithTx locks the tokens with SELECT ... FOR UPDATE to read them before revoking them,
+// the same for RevokeByClientIDWithTx.
 func (m *oauthRefreshTokenManager) RevokeByGrantIDWithTx(
 	ctx context.Context, tx *sqlx.Tx, grantID string,
-) (int64, error) {
-	query := `UPDATE oauth_refresh_token SET revoked = 1 WHERE grant_id = ? AND revoked = 0`
-	result, err := tx.ExecContext(ctx, query, grantID)
-	if err != nil {
-		return 0, err
+) (tokens []RevokedToken, err error) {
+	query := `SELECT
+		token_hash,
+		grant_id,
+		client_id,
+		realm_name,
+		sub
+		FROM oauth_refresh_token
+		WHERE grant_id = ? AND revoked = 0
+		FOR UPDATE`
+	if err = tx.SelectContext(ctx, &tokens, query, grantID); err != nil || len(tokens) == 0 {
+		return nil, err
 	}
-	return result.RowsAffected()
+
+	query = `UPDATE oauth_refresh_token SET revoked = 1 WHERE grant_id = ? AND revoked = 0`
+	if _, err = tx.ExecContext(ctx, query, grantID); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (m *oauthRefreshTokenManager) RevokeByClientIDWithTx(
+	ctx context.Context, tx *sqlx.Tx, clientID string,
+) (tokens []RevokedToken, err error) {
+	query := `SELECT
+		token_hash,
+		grant_id,
+		client_id,
+		realm_name,
+		sub
+		FROM oauth_refresh_token
+		WHERE client_id = ? AND revoked = 0
+		FOR UPDATE`
+	if err = tx.SelectContext(ctx, &tokens, query, clientID); err != nil || len(tokens) == 0 {
+		return nil, err
+	}
+
+	query = `UPDATE oauth_refresh_token SET revoked = 1 WHERE client_id = ? AND revoked = 0`
+	if _, err = tx.ExecContext(ctx, query, clientID); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// ListActiveGrantsBySub lists the grant families of a user that still hold an unrevoked,
+// unexpired refresh token, most recently refreshed first.
+func (m *oauthRefreshTokenManager) ListActiveGrantsBySub(
+	ctx context.Context, tenantID, sub string, now time.Time,
+) (grants []OAuthRefreshTokenGrant, err error) {
+	query := `SELECT
+		rt.grant_id,
+		rt.client_id,
+		rt.realm_name,
+		rt.resource,
+		rt.scope,
+		(SELECT MIN(f.created_at) FROM oauth_refresh_token f WHERE f.grant_id = rt.grant_id) AS first_issued_at,
+		rt.created_at AS last_refreshed_at,
+		rt.expires_at
+	FROM oauth_refresh_token rt
+	WHERE rt.tenant_id = ? AND rt.sub = ? AND rt.revoked = 0 AND rt.expires_at > ?
+	ORDER BY rt.created_at DESC`
+
+	err = database.SqlxSelect(ctx, m.DB, &grants, query, tenantID, sub, now)
+	return grants, err
+}
+
+func (m *oauthRefreshTokenManager) CountExpired(ctx context.Context, before time.Time) (count int64, err error) {
+	query := `SELECT COUNT(*) FROM oauth_refresh_token WHERE expires_at < ?`
+	err = database.SqlxGet(ctx, m.DB, &count, query, before)
+	return count, err
+}
+
+// DeleteExpired deletes a batch of refresh tokens that expired before the given time.
+//
+// Revoked refresh tokens are kept until the family expires: every token of a family shares the
+// absolute expires_at, and the rotated ones are still needed for replay detection and for the
+// first_issued_at of the grant.
+func (m *oauthRefreshTokenManager) DeleteExpired(ctx context.Context, before time.Time, limit int64) (int64, error) {
+	query := `DELETE FROM oauth_refresh_token WHERE expires_at < ? LIMIT ?`
+	return database.SqlxDelete(ctx, m.DB, query, before, limit)
 }
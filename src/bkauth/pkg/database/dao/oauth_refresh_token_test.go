@@ -14,11 +14,11 @@ import (
 
 func Test_oauthRefreshTokenManager_CreateWithTx(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
-		// VALUES clause has 14 named params
+		// VALUES clause has 17 named params
 		mock.ExpectBegin()
 		mock.ExpectExec(`^INSERT INTO oauth_refresh_token`).WithArgs(
-			"rt_hash123", "rt_mask123", "grant-001", int64(10), "client1", "", "",
-			"user1", "admin", `["aud1"]`, "openid profile",
+			"rt_hash123", "kid-1", "rt_mask123", "grant-001", int64(10), "client1", "", "",
+			"user1", "admin", `["aud1"]`, "https://example.com/api", "", "openid profile", "", "",
 			sqlmock.AnyArg(), // expires_at
 			false,            // revoked
 			int64(0),         // rotation_count
@@ -29,18 +29,20 @@ func Test_oauthRefreshTokenManager_CreateWithTx(t *testing.T) {
 		assert.NoError(t, err)
 
 		token := OAuthRefreshToken{
-			TokenHash:     "rt_hash123",
-			TokenMask:     "rt_mask123",
-			GrantID:       "grant-001",
-			AccessTokenID: 10,
-			ClientID:      "client1",
-			Sub:           "user1",
-			Username:      "admin",
-			Audience:      `["aud1"]`,
-			Scope:         "openid profile",
-			ExpiresAt:     time.Now().Add(24 * time.Hour),
-			Revoked:       false,
-			RotationCount: 0,
+			TokenHash:      "rt_hash123",
+			TokenHashKeyID: "kid-1",
+			TokenMask:      "rt_mask123",
+			GrantID:        "grant-001",
+			AccessTokenID:  10,
+			ClientID:       "client1",
+			Sub:            "user1",
+			Username:       "admin",
+			Audience:       `["aud1"]`,
+			Resource:       "https://example.com/api",
+			Scope:          "openid profile",
+			ExpiresAt:      time.Now().Add(24 * time.Hour),
+			Revoked:        false,
+			RotationCount:  0,
 		}
 
 		manager := &oauthRefreshTokenManager{DB: db}
@@ -53,28 +55,29 @@ func Test_oauthRefreshTokenManager_CreateWithTx(t *testing.T) {
 	})
 }
 
-func Test_oauthRefreshTokenManager_GetByTokenHash(t *testing.T) {
+func Test_oauthRefreshTokenManager_GetByTokenHashes(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		now := time.Now()
 		mockRows := sqlmock.NewRows([]string{
-			"id", "token_hash", "token_mask", "grant_id", "access_token_id",
+			"id", "token_hash", "token_hash_kid", "token_mask", "grant_id", "access_token_id",
 			"client_id", "tenant_id", "realm_name", "sub", "username",
-			"audience", "scope", "expires_at", "revoked", "rotation_count",
+			"audience", "resource", "scope", "cnf_jkt", "cnf_x5t_s256", "expires_at", "revoked", "rotation_count",
 			"created_at", "updated_at",
 		}).AddRow(
-			int64(1), "rt_hash123", "rt_mask123", "grant-001", int64(10),
+			int64(1), "rt_hash123", "kid-1", "rt_mask123", "grant-001", int64(10),
 			"client1", "", "devops", "user1", "admin",
-			`["aud1"]`, "openid profile", now.Add(24*time.Hour), false, int64(0),
+			`["aud1"]`, "https://example.com/api", "openid profile", "jkt-1", "x5t-1", now.Add(24*time.Hour), false, int64(0),
 			now, now,
 		)
-		mock.ExpectQuery(`^SELECT`).WithArgs("rt_hash123").WillReturnRows(mockRows)
+		mock.ExpectQuery(`WHERE token_hash IN \(\?, \?\)`).WithArgs("rt_hash123", "rt_legacy123").WillReturnRows(mockRows)
 
 		manager := &oauthRefreshTokenManager{DB: db}
-		token, err := manager.GetByTokenHash(context.Background(), "rt_hash123")
+		token, err := manager.GetByTokenHashes(context.Background(), []string{"rt_hash123", "rt_legacy123"})
 
 		assert.NoError(t, err)
 		assert.Equal(t, int64(1), token.ID)
 		assert.Equal(t, "rt_hash123", token.TokenHash)
+		assert.Equal(t, "kid-1", token.TokenHashKeyID)
 		assert.Equal(t, "rt_mask123", token.TokenMask)
 		assert.Equal(t, "grant-001", token.GrantID)
 		assert.Equal(t, int64(10), token.AccessTokenID)
@@ -83,24 +86,27 @@ func Test_oauthRefreshTokenManager_GetByTokenHash(t *testing.T) {
 		assert.Equal(t, "user1", token.Sub)
 		assert.Equal(t, "admin", token.Username)
 		assert.Equal(t, `["aud1"]`, token.Audience)
+		assert.Equal(t, "https://example.com/api", token.Resource)
 		assert.Equal(t, "openid profile", token.Scope)
+		assert.Equal(t, "jkt-1", token.CnfJKT)
+		assert.Equal(t, "x5t-1", token.CnfX5T)
 		assert.False(t, token.Revoked)
 		assert.Equal(t, int64(0), token.RotationCount)
 	})
 }
 
-func Test_oauthRefreshTokenManager_GetByTokenHash_NotFound(t *testing.T) {
+func Test_oauthRefreshTokenManager_GetByTokenHashes_NotFound(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mockRows := sqlmock.NewRows([]string{
-			"id", "token_hash", "token_mask", "grant_id", "access_token_id",
+			"id", "token_hash", "token_hash_kid", "token_mask", "grant_id", "access_token_id",
 			"client_id", "tenant_id", "realm_name", "sub", "username",
-			"audience", "scope", "expires_at", "revoked", "rotation_count",
+			"audience", "resource", "scope", "cnf_jkt", "cnf_x5t_s256", "expires_at", "revoked", "rotation_count",
 			"created_at", "updated_at",
 		})
 		mock.ExpectQuery(`^SELECT`).WithArgs("nonexistent").WillReturnRows(mockRows)
 
 		manager := &oauthRefreshTokenManager{DB: db}
-		token, err := manager.GetByTokenHash(context.Background(), "nonexistent")
+		token, err := manager.GetByTokenHashes(context.Background(), []string{"nonexistent"})
 
 		assert.NoError(t, err)
 		assert.Empty(t, token.TokenHash)
@@ -173,20 +179,97 @@ func Test_oauthRefreshTokenManager_RevokeIfNotRevokedWithTx_AlreadyRevoked(t *te
 func Test_oauthRefreshTokenManager_RevokeByGrantIDWithTx(t *testing.T) {
 	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
 		mock.ExpectBegin()
+		mock.ExpectQuery(
+			revokedTokenSelect + `oauth_refresh_token WHERE grant_id = \? AND revoked = 0 FOR UPDATE$`,
+		).
+			WithArgs("grant-001").
+			WillReturnRows(sqlmock.NewRows(revokedTokenColumns).
+				AddRow("rt_hash1", "grant-001", "client1", "realm1", "user1"))
 		mock.ExpectExec(`^UPDATE oauth_refresh_token SET revoked = 1 WHERE grant_id = \? AND revoked = 0$`).
 			WithArgs("grant-001").
-			WillReturnResult(sqlmock.NewResult(0, 3))
+			WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectCommit()
 
 		tx, err := db.Beginx()
 		assert.NoError(t, err)
 
 		manager := &oauthRefreshTokenManager{DB: db}
-		affected, err := manager.RevokeByGrantIDWithTx(context.Background(), tx, "grant-001")
+		tokens, err := manager.RevokeByGrantIDWithTx(context.Background(), tx, "grant-001")
 
 		tx.Commit()
 
 		assert.NoError(t, err)
-		assert.Equal(t, int64(3), affected)
+		assert.Equal(t, []RevokedToken{
+			{TokenHash: "rt_hash1", GrantID: "grant-001", ClientID: "client1", RealmName: "realm1", Sub: "user1"},
+		}, tokens)
+	})
+}
+
+func Test_oauthRefreshTokenManager_RevokeByClientIDWithTx(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(
+			revokedTokenSelect + `oauth_refresh_token WHERE client_id = \? AND revoked = 0 FOR UPDATE$`,
+		).
+			WithArgs("client1").
+			WillReturnRows(sqlmock.NewRows(revokedTokenColumns).
+				AddRow("rt_hash1", "grant-001", "client1", "realm1", "user1").
+				AddRow("rt_hash2", "grant-002", "client1", "realm1", "user2"))
+		mock.ExpectExec(`^UPDATE oauth_refresh_token SET revoked = 1 WHERE client_id = \? AND revoked = 0$`).
+			WithArgs("client1").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		tx, err := db.Beginx()
+		assert.NoError(t, err)
+
+		manager := &oauthRefreshTokenManager{DB: db}
+		tokens, err := manager.RevokeByClientIDWithTx(context.Background(), tx, "client1")
+
+		tx.Commit()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"rt_hash1", "rt_hash2"}, RevokedTokenHashes(tokens))
+	})
+}
+
+func Test_oauthRefreshTokenManager_ListActiveGrantsBySub(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		now := time.Now()
+		mockRows := sqlmock.NewRows([]string{
+			"grant_id", "client_id", "realm_name", "resource", "scope",
+			"first_issued_at", "last_refreshed_at", "expires_at",
+		}).AddRow(
+			"grant-001", "client1", "blueking", "https://example.com/api", "openid",
+			now.Add(-48*time.Hour), now.Add(-time.Hour), now.Add(24*time.Hour),
+		)
+		mock.ExpectQuery(`^SELECT .* FROM oauth_refresh_token rt WHERE rt.tenant_id = \? AND rt.sub = \?`).
+			WithArgs("default", "user1", now).
+			WillReturnRows(mockRows)
+
+		manager := &oauthRefreshTokenManager{DB: db}
+		grants, err := manager.ListActiveGrantsBySub(context.Background(), "default", "user1", now)
+
+		assert.NoError(t, err)
+		assert.Len(t, grants, 1)
+		assert.Equal(t, "grant-001", grants[0].GrantID)
+		assert.Equal(t, "https://example.com/api", grants[0].Resource)
+		assert.Equal(t, now.Add(-48*time.Hour), grants[0].FirstIssuedAt)
+		assert.Equal(t, now.Add(-time.Hour), grants[0].LastRefreshedAt)
+	})
+}
+
+func Test_oauthRefreshTokenManager_DeleteExpired(t *testing.T) {
+	database.RunWithMock(t, func(db *sqlx.DB, mock sqlmock.Sqlmock, t *testing.T) {
+		before := time.Now()
+		mock.ExpectExec(`^DELETE FROM oauth_refresh_token WHERE expires_at < \? LIMIT \?$`).
+			WithArgs(before, int64(100)).
+			WillReturnResult(sqlmock.NewResult(0, 100))
+
+		manager := &oauthRefreshTokenManager{DB: db}
+		rows, err := manager.DeleteExpired(context.Background(), before, 100)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(100), rows)
 	})
 }
@@ -0,0 +1,132 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package clientdoc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("clientdoc", func() {
+	var (
+		server     *httptest.Server
+		httpClient *http.Client
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/jwks.json":
+				_, _ = w.Write([]byte(`{"keys":[]}`))
+			case "/large.json":
+				_, _ = w.Write([]byte(strings.Repeat(" ", maxJWKSSize+1)))
+			case "/client.json":
+				_, _ = w.Write([]byte(`{"client_id":"https://client.example.com/client.json"}`))
+			case "/large-client.json":
+				_, _ = w.Write([]byte(strings.Repeat(" ", maxClientMetadataSize+1)))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		// the test server listens on loopback, which the default client refuses to dial
+		httpClient = defaultHTTPClient
+		defaultHTTPClient = server.Client()
+	})
+
+	AfterEach(func() {
+		defaultHTTPClient = httpClient
+		server.Close()
+	})
+
+	It("should return the published document", func() {
+		doc, err := FetchJWKS(context.Background(), server.URL+"/jwks.json")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc).To(Equal(`{"keys":[]}`))
+	})
+
+	It("should fail on a non-200 response", func() {
+		_, err := FetchJWKS(context.Background(), server.URL+"/missing.json")
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail when the document exceeds the size limit", func() {
+		_, err := FetchJWKS(context.Background(), server.URL+"/large.json")
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should refuse to dial internal addresses", func() {
+		defaultHTTPClient = httpClient
+
+		_, err := FetchJWKS(context.Background(), server.URL+"/jwks.json")
+
+		Expect(err).To(MatchError(ContainSubstring("non-public address")))
+	})
+
+	It("should refuse a loopback url", func() {
+		defaultHTTPClient = httpClient
+
+		_, err := FetchClientMetadata(context.Background(), "http://127.0.0.1:1/client.json")
+
+		Expect(err).To(MatchError(ContainSubstring("non-public address")))
+	})
+
+	DescribeTable("rejectNonPublicAddress",
+		func(address string, wantErr bool) {
+			err := rejectNonPublicAddress("tcp", address, nil)
+			if wantErr {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+		},
+		Entry("public", "203.0.113.10:443", false),
+		Entry("public ipv6", "[2001:db8::1]:443", false),
+		Entry("loopback", "127.0.0.1:443", true),
+		Entry("loopback ipv6", "[::1]:443", true),
+		Entry("private", "10.0.0.1:443", true),
+		Entry("unspecified", "0.0.0.0:443", true),
+		Entry("link local", "169.254.169.254:80", true),
+		Entry("multicast", "224.0.0.251:443", true),
+		Entry("multicast ipv6", "[ff02::1]:443", true),
+		Entry("shared address space", "100.64.0.1:443", true),
+		Entry("shared address space upper bound", "100.127.255.255:443", true),
+		Entry("above the shared address space", "100.128.0.1:443", false),
+	)
+
+	It("should return the client metadata document", func() {
+		doc, err := FetchClientMetadata(context.Background(), server.URL+"/client.json")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(doc)).To(Equal(`{"client_id":"https://client.example.com/client.json"}`))
+	})
+
+	It("should bound the client metadata document to a smaller size", func() {
+		_, err := FetchClientMetadata(context.Background(), server.URL+"/large-client.json")
+
+		Expect(err).To(MatchError(ContainSubstring("size limit")))
+	})
+})
@@ -0,0 +1,108 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package janitor
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"bkauth/pkg/config"
+	"bkauth/pkg/metric"
+	"bkauth/pkg/redis"
+	"bkauth/pkg/service"
+)
+
+const (
+	lockKey = "bkauth:lock:token_cleanup"
+
+	releaseTimeout = 5 * time.Second
+)
+
+// Janitor periodically deletes the expired and revoked tokens and codes.
+// Every replica runs it, but only the one holding the redis lock does the work.
+type Janitor struct {
+	interval  time.Duration
+	retention time.Duration
+	batchSize int64
+
+	lock *redis.Lock
+	svc  service.OAuthCleanupService
+}
+
+// New creates a Janitor from the token cleanup config.
+func New(cfg config.TokenCleanup) *Janitor {
+	interval := time.Duration(cfg.Interval) * time.Second
+	return &Janitor{
+		interval:  interval,
+		retention: time.Duration(cfg.Retention) * time.Second,
+		batchSize: cfg.BatchSize,
+		// the lease outlives the interval so that the leader keeps it from one run to the next,
+		// if the leader is gone another replica takes over within two intervals
+		lock: redis.NewLock(redis.GetDefaultRedisClient(), lockKey, 2*interval),
+		svc:  service.NewOAuthCleanupService(),
+	}
+}
+
+// Run cleans up once at start and then on every interval, until ctx is canceled.
+func (j *Janitor) Run(ctx context.Context) {
+	zap.S().Infof("token cleanup started, interval=%s, retention=%s, batchSize=%d",
+		j.interval, j.retention, j.batchSize)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			// give the lease up so that another replica takes over at once
+			releaseCtx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+			if err := j.lock.Release(releaseCtx); err != nil {
+				zap.S().Warnf("release token cleanup lock fail: %v", err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *Janitor) runOnce(ctx context.Context) {
+	ok, err := j.lock.Acquire(ctx)
+	if err != nil {
+		zap.S().Errorf("acquire token cleanup lock fail: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	results, err := j.svc.DeleteExpired(ctx, time.Now().Add(-j.retention), j.batchSize)
+	for _, r := range results {
+		metric.OAuthCleanupDeletedCount.WithLabelValues(r.Table).Add(float64(r.Count))
+		zap.S().Infof("token cleanup deleted %d rows from %s", r.Count, r.Table)
+	}
+	if err != nil {
+		metric.OAuthCleanupFailureCount.Inc()
+		zap.S().Errorf("token cleanup fail: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package janitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"bkauth/pkg/metric"
+	"bkauth/pkg/redis"
+	"bkauth/pkg/service/mock"
+	"bkauth/pkg/service/types"
+)
+
+func counterValue(c prometheus.Counter) float64 {
+	var m dto.Metric
+	_ = c.Write(&m)
+	return m.GetCounter().GetValue()
+}
+
+func newTestJanitor(t *testing.T, cli *goredis.Client) (*Janitor, *mock.MockOAuthCleanupService) {
+	svc := mock.NewMockOAuthCleanupService(gomock.NewController(t))
+	return &Janitor{
+		interval:  time.Hour,
+		retention: 24 * time.Hour,
+		batchSize: 100,
+		lock:      redis.NewLock(cli, lockKey, 2*time.Hour),
+		svc:       svc,
+	}, svc
+}
+
+func TestJanitor_runOnce(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer mr.Close()
+	cli := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	leader, leaderSvc := newTestJanitor(t, cli)
+	follower, followerSvc := newTestJanitor(t, cli)
+
+	deleted := counterValue(metric.OAuthCleanupDeletedCount.WithLabelValues("oauth_access_token"))
+	failures := counterValue(metric.OAuthCleanupFailureCount)
+
+	leaderSvc.EXPECT().DeleteExpired(ctx, gomock.Any(), int64(100)).
+		Return([]types.CleanupResult{{Table: "oauth_access_token", Count: 5}}, nil)
+	leader.runOnce(ctx)
+
+	// the lock is held by the leader, the follower does nothing
+	followerSvc.EXPECT().DeleteExpired(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	follower.runOnce(ctx)
+
+	leaderSvc.EXPECT().DeleteExpired(ctx, gomock.Any(), int64(100)).
+		Return([]types.CleanupResult{{Table: "oauth_access_token", Count: 2}}, errors.New("db error"))
+	leader.runOnce(ctx)
+
+	assert.Equal(t, deleted+7,
+		counterValue(metric.OAuthCleanupDeletedCount.WithLabelValues("oauth_access_token")))
+	assert.Equal(t, failures+1, counterValue(metric.OAuthCleanupFailureCount))
+}
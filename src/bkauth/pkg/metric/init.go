@@ -57,6 +57,25 @@ var (
 	},
 		[]string{"method", "path", "status", "component"},
 	)
+
+	// OAuthCleanupDeletedCount 过期 token / code 清理的删除行数
+	OAuthCleanupDeletedCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        serviceName + "_oauth_cleanup_deleted_rows_total",
+			Help:        "How many expired or revoked rows deleted by the token cleanup, partitioned by table.",
+			ConstLabels: prometheus.Labels{"service": serviceName},
+		},
+		[]string{"table"},
+	)
+
+	// OAuthCleanupFailureCount 过期 token / code 清理的失败次数
+	OAuthCleanupFailureCount = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name:        serviceName + "_oauth_cleanup_failures_total",
+			Help:        "How many token cleanup runs failed.",
+			ConstLabels: prometheus.Labels{"service": serviceName},
+		},
+	)
 )
 
 // InitMetrics ...
@@ -65,4 +84,6 @@ func InitMetrics() {
 	prometheus.MustRegister(RequestCount)
 	prometheus.MustRegister(RequestDuration)
 	prometheus.MustRegister(ComponentRequestDuration)
+	prometheus.MustRegister(OAuthCleanupDeletedCount)
+	prometheus.MustRegister(OAuthCleanupFailureCount)
 }
@@ -0,0 +1,127 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"bkauth/pkg/util"
+)
+
+// MaxAuthorizationDetailsLength bounds the authorization_details parameter, which is stored
+// with the authorization code and the tokens issued for it.
+const MaxAuthorizationDetailsLength = 2048
+
+// MaxResourceLength bounds the resource parameter, merged with the resources named by the
+// authorization_details, which is stored with the grant and the tokens issued for it.
+const MaxResourceLength = 2048
+
+// AuthorizationDetail is an entry of the authorization_details parameter (RFC 9396 §2).
+// Only the common fields used by the realms are accepted; what Identifier and Actions
+// name depends on the type, which is defined by the realm.
+type AuthorizationDetail struct {
+	Type       string   `json:"type"`
+	Identifier string   `json:"identifier,omitempty"`
+	Actions    []string `json:"actions,omitempty"`
+}
+
+// ParseAuthorizationDetails parses the authorization_details parameter, a JSON array of
+// authorization details objects (RFC 9396 §2). An empty parameter yields no details.
+func ParseAuthorizationDetails(raw string) ([]AuthorizationDetail, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if len(raw) > MaxAuthorizationDetailsLength {
+		return nil, fmt.Errorf("authorization_details must not exceed %d bytes", MaxAuthorizationDetailsLength)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	var details []AuthorizationDetail
+	if err := decoder.Decode(&details); err != nil {
+		return nil, fmt.Errorf("authorization_details is not a valid JSON array of objects: %s", err.Error())
+	}
+	if len(details) == 0 {
+		return nil, errors.New("authorization_details must not be empty")
+	}
+	for _, detail := range details {
+		if detail.Type == "" {
+			return nil, errors.New("authorization_details: type is required")
+		}
+	}
+	return details, nil
+}
+
+// FormatAuthorizationDetails returns the JSON form of the authorization details, "" for none.
+func FormatAuthorizationDetails(details []AuthorizationDetail) string {
+	if len(details) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	// identifiers are kept as is, e.g. "&" in an API name
+	encoder.SetEscapeHTML(false)
+	// the details only hold strings, encoding them cannot fail
+	_ = encoder.Encode(details)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// ValidateAuthorizationDetails parses the authorization_details parameter and checks it against
+// the realm, returning the details (none when absent) and the resource items they grant access to,
+// from which the audiences are extracted like from the resource parameter.
+func ValidateAuthorizationDetails(
+	ctx context.Context, realm Realm, raw string,
+) ([]AuthorizationDetail, string, error) {
+	details, err := ParseAuthorizationDetails(raw)
+	if err != nil || len(details) == 0 {
+		return nil, "", err
+	}
+
+	for _, detail := range details {
+		if !slices.Contains(realm.AuthorizationDetailsTypes(), detail.Type) {
+			return nil, "", fmt.Errorf("unsupported authorization details type: %s", detail.Type)
+		}
+	}
+	resource, err := realm.ResolveAuthorizationDetails(ctx, details)
+	if err != nil {
+		return nil, "", err
+	}
+	return details, resource, nil
+}
+
+// MergeResource joins the comma-separated resource items of both, de-duplicated in first-seen order.
+func MergeResource(resource, other string) string {
+	items := append(util.SplitCommaList(resource), util.SplitCommaList(other)...)
+	merged := make([]string, 0, len(items))
+	seen := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		if _, ok := seen[item]; ok {
+			continue
+		}
+		seen[item] = struct{}{}
+		merged = append(merged, item)
+	}
+	return strings.Join(merged, ",")
+}
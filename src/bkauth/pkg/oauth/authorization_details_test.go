@@ -0,0 +1,142 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bkauth/pkg/oauth"
+)
+
+// stubDetailsRealm accepts the "api" type, rendered as api:<identifier> items.
+type stubDetailsRealm struct {
+	stubScopeRealm
+}
+
+func (stubDetailsRealm) AuthorizationDetailsTypes() []string { return []string{"api"} }
+func (stubDetailsRealm) ResolveAuthorizationDetails(
+	_ context.Context, details []oauth.AuthorizationDetail,
+) (string, error) {
+	items := make([]string, 0, len(details))
+	for _, detail := range details {
+		if detail.Identifier == "" {
+			return "", errors.New("identifier is required")
+		}
+		items = append(items, "api:"+detail.Identifier)
+	}
+	return strings.Join(items, ","), nil
+}
+
+var _ = Describe("AuthorizationDetails", func() {
+	ctx := context.Background()
+
+	Describe("ParseAuthorizationDetails", func() {
+		It("should parse a JSON array of details", func() {
+			details, err := oauth.ParseAuthorizationDetails(
+				`[{"type":"api","identifier":"gw","actions":["get_host"]}]`,
+			)
+			require.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []oauth.AuthorizationDetail{
+				{Type: "api", Identifier: "gw", Actions: []string{"get_host"}},
+			}, details)
+		})
+
+		It("should yield no details for an empty parameter", func() {
+			details, err := oauth.ParseAuthorizationDetails("")
+			assert.NoError(GinkgoT(), err)
+			assert.Nil(GinkgoT(), details)
+		})
+
+		DescribeTable("invalid",
+			func(raw string) {
+				_, err := oauth.ParseAuthorizationDetails(raw)
+				assert.Error(GinkgoT(), err)
+			},
+			Entry("not JSON", "api"),
+			Entry("an object", `{"type":"api"}`),
+			Entry("an empty array", `[]`),
+			Entry("a missing type", `[{"identifier":"gw"}]`),
+			Entry("an unknown field", `[{"type":"api","locations":["https://gw.example.com"]}]`),
+			Entry("too long", `[{"type":"api","identifier":"`+strings.Repeat("a", 2048)+`"}]`),
+		)
+	})
+
+	Describe("FormatAuthorizationDetails", func() {
+		It("should return the JSON form", func() {
+			raw := oauth.FormatAuthorizationDetails([]oauth.AuthorizationDetail{
+				{Type: "api", Identifier: "a&b"},
+			})
+			assert.Equal(GinkgoT(), `[{"type":"api","identifier":"a&b"}]`, raw)
+		})
+
+		It("should return empty for no details", func() {
+			assert.Empty(GinkgoT(), oauth.FormatAuthorizationDetails(nil))
+		})
+	})
+
+	Describe("ValidateAuthorizationDetails", func() {
+		realm := stubDetailsRealm{}
+
+		It("should return the details and render their resource", func() {
+			details, resource, err := oauth.ValidateAuthorizationDetails(ctx, realm,
+				`[ {"type": "api", "identifier": "a"}, {"type": "api", "identifier": "b"} ]`,
+			)
+			require.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []oauth.AuthorizationDetail{
+				{Type: "api", Identifier: "a"}, {Type: "api", Identifier: "b"},
+			}, details)
+			assert.Equal(GinkgoT(), "api:a,api:b", resource)
+		})
+
+		It("should accept an empty parameter", func() {
+			details, resource, err := oauth.ValidateAuthorizationDetails(ctx, realm, "")
+			assert.NoError(GinkgoT(), err)
+			assert.Empty(GinkgoT(), details)
+			assert.Empty(GinkgoT(), resource)
+		})
+
+		It("should reject a type the realm does not accept", func() {
+			_, _, err := oauth.ValidateAuthorizationDetails(ctx, realm, `[{"type":"mcp","identifier":"a"}]`)
+			assert.ErrorContains(GinkgoT(), err, "unsupported authorization details type")
+		})
+
+		It("should reject details the realm cannot render", func() {
+			_, _, err := oauth.ValidateAuthorizationDetails(ctx, realm, `[{"type":"api"}]`)
+			assert.Error(GinkgoT(), err)
+		})
+	})
+
+	Describe("MergeResource", func() {
+		DescribeTable("cases",
+			func(resource, other, want string) {
+				assert.Equal(GinkgoT(), want, oauth.MergeResource(resource, other))
+			},
+			Entry("both", "mcp:a", "gateway:gw:api:x", "mcp:a,gateway:gw:api:x"),
+			Entry("duplicates", "mcp:a, mcp:b", "mcp:b,mcp:c", "mcp:a,mcp:b,mcp:c"),
+			Entry("only other", "", "mcp:a", "mcp:a"),
+			Entry("none", "", "", ""),
+		)
+	})
+})
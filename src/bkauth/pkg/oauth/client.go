@@ -30,30 +30,44 @@ const (
 
 	dynamicClientIDPrefix = "dcr_"
 
+	// RegistrationAccessTokenPrefix prefixes the registration_access_token of a
+	// dynamically registered client (RFC 7592 §3), never accepted as an access token.
+	RegistrationAccessTokenPrefix = "bkreg_"
+
 	AuthMethodNone              = "none"
 	AuthMethodClientSecretBasic = "client_secret_basic"
 	AuthMethodClientSecretPost  = "client_secret_post"
+	// RFC 8705 §2: mutual-TLS client authentication
+	AuthMethodTLSClientAuth           = "tls_client_auth"
+	AuthMethodSelfSignedTLSClientAuth = "self_signed_tls_client_auth"
+	// RFC 7523 §2.2 / OIDC Core §9: JWT client assertion signed with a registered key
+	AuthMethodPrivateKeyJWT = "private_key_jwt"
 
 	// PublicAppCode is returned in introspection response for all DCR registered (public) clients.
 	PublicAppCode = "public"
+	// PrivateAppCode is the client_id of personal access tokens, so it is also returned
+	// as bk_app_code in their introspection response. No app can register it.
+	PrivateAppCode = "private"
 )
 
 // IsPublicClient reports whether the client_id belongs to a public client.
 //
 // Client type can be determined purely from the client_id prefix because:
 //   - Public clients are created via dynamic registration (e.g. DCR / RFC 7591),
-//     which generates a prefixed ID (see GenerateDynamicClientID).
-//   - Confidential clients reuse the app_code as client_id, which never carries this prefix.
+//     which generates a prefixed ID (see GenerateDynamicClientID), or are described
+//     by a Client ID Metadata Document, whose client_id is an https URL.
+//   - Confidential clients reuse the app_code as client_id, which never carries these prefixes.
 //
-// This convention is enforced at registration time, so callers can skip
-// an oauth_client DB lookup when they only need the client type.
+// This convention is enforced at registration time, but it is only used where no
+// client lookup is at hand (e.g. the bk_app_code of introspection). Security decisions
+// must rely on the persisted client type instead (see OAuthClientAuthSpec / OAuthClientFlowSpec).
 func IsPublicClient(clientID string) bool {
-	return strings.HasPrefix(clientID, dynamicClientIDPrefix)
+	return strings.HasPrefix(clientID, dynamicClientIDPrefix) || IsClientIDMetadataDocumentURL(clientID)
 }
 
 // ResolveAppCode derives the platform app_code from a client_id.
-// For confidential clients the client_id *is* the app_code;
-// for public (DCR) clients a fixed sentinel is returned.
+// For confidential clients the client_id *is* the app_code, and so is PrivateAppCode
+// for personal access tokens; for public (DCR) clients a fixed sentinel is returned.
 func ResolveAppCode(clientID string) string {
 	if IsPublicClient(clientID) {
 		return PublicAppCode
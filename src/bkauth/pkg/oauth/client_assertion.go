@@ -0,0 +1,143 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const (
+	// ClientAssertionTypeJWTBearer is the client_assertion_type of private_key_jwt (RFC 7523 §2.2).
+	ClientAssertionTypeJWTBearer = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+	// ClientAssertionMaxLifetime bounds how far in the future the "exp" of an assertion
+	// may be. A jti is remembered until the assertion expires, so this also bounds
+	// the replay cache entries.
+	ClientAssertionMaxLifetime = 5 * time.Minute
+
+	// clientAssertionLeeway tolerates clock skew between the client and BKAuth.
+	clientAssertionLeeway = 30 * time.Second
+)
+
+// ClientAssertionSigningAlgorithms is the set of JWS algorithms accepted for client
+// assertions, advertised as token_endpoint_auth_signing_alg_values_supported.
+var ClientAssertionSigningAlgorithms = []string{SigningAlgorithmRS256, SigningAlgorithmES256}
+
+// ClientAssertion is a verified private_key_jwt client assertion.
+type ClientAssertion struct {
+	JTI       string
+	ExpiresAt time.Time
+}
+
+// ReplayWindow is how long the jti must be remembered: until the assertion
+// can no longer be accepted, clock skew included.
+func (a ClientAssertion) ReplayWindow() time.Duration {
+	return time.Until(a.ExpiresAt) + clientAssertionLeeway
+}
+
+func parseClientAssertion(assertion string) (*jwt.JSONWebToken, error) {
+	algs := make([]jose.SignatureAlgorithm, 0, len(ClientAssertionSigningAlgorithms))
+	for _, alg := range ClientAssertionSigningAlgorithms {
+		algs = append(algs, jose.SignatureAlgorithm(alg))
+	}
+	token, err := jwt.ParseSigned(assertion, algs)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidClientAssertion, err.Error())
+	}
+	return token, nil
+}
+
+// ClientAssertionSubject returns the "sub" of a client assertion WITHOUT verifying it.
+// It only identifies the client when the request carries no client_id (RFC 7523 §3);
+// the assertion must still be verified against that client's keys.
+func ClientAssertionSubject(assertion string) (string, error) {
+	token, err := parseClientAssertion(assertion)
+	if err != nil {
+		return "", err
+	}
+
+	var claims jwt.Claims
+	if err := token.UnsafeClaimsWithoutVerification(&claims); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidClientAssertion, err.Error())
+	}
+	if claims.Subject == "" {
+		return "", fmt.Errorf("%w: sub is required", ErrInvalidClientAssertion)
+	}
+	return claims.Subject, nil
+}
+
+// VerifyClientAssertion verifies a private_key_jwt client assertion (RFC 7523 §3) against
+// the client's JWK Set: it must be signed by one of the keys, issued by and for clientID,
+// and addressed to one of the given audiences (the token endpoint or the issuer).
+//
+// Replay of the returned jti is NOT checked here; callers must remember it.
+func VerifyClientAssertion(assertion, jwks, clientID string, audiences []string) (ClientAssertion, error) {
+	token, err := parseClientAssertion(assertion)
+	if err != nil {
+		return ClientAssertion{}, err
+	}
+
+	var keySet jose.JSONWebKeySet
+	if err := json.Unmarshal([]byte(jwks), &keySet); err != nil {
+		return ClientAssertion{}, fmt.Errorf("%w: invalid registered jwks: %s", ErrInvalidClientAssertion, err.Error())
+	}
+	keys := keySet.Keys
+	if kid := token.Headers[0].KeyID; kid != "" {
+		keys = keySet.Key(kid)
+	}
+
+	var claims jwt.Claims
+	verified := false
+	for _, key := range keys {
+		if err := token.Claims(key.Public().Key, &claims); err == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return ClientAssertion{}, fmt.Errorf("%w: signature does not match any registered key", ErrInvalidClientAssertion)
+	}
+
+	now := time.Now()
+	if err := claims.ValidateWithLeeway(jwt.Expected{
+		Issuer:      clientID,
+		Subject:     clientID,
+		AnyAudience: audiences,
+		Time:        now,
+	}, clientAssertionLeeway); err != nil {
+		return ClientAssertion{}, fmt.Errorf("%w: %s", ErrInvalidClientAssertion, err.Error())
+	}
+	if claims.ID == "" {
+		return ClientAssertion{}, fmt.Errorf("%w: jti is required", ErrInvalidClientAssertion)
+	}
+	if claims.Expiry == nil {
+		return ClientAssertion{}, fmt.Errorf("%w: exp is required", ErrInvalidClientAssertion)
+	}
+	expiresAt := claims.Expiry.Time()
+	if expiresAt.After(now.Add(ClientAssertionMaxLifetime)) {
+		return ClientAssertion{}, fmt.Errorf("%w: exp is too far in the future", ErrInvalidClientAssertion)
+	}
+
+	return ClientAssertion{JTI: claims.ID, ExpiresAt: expiresAt}, nil
+}
@@ -0,0 +1,137 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+
+	"bkauth/pkg/oauth"
+)
+
+var _ = Describe("ClientAssertion", func() {
+	const (
+		clientID = "dcr_abc"
+		tokenURL = "https://bkauth.example.com/realms/blueking/oauth2/token"
+	)
+
+	var (
+		key    *ecdsa.PrivateKey
+		jwks   string
+		claims map[string]any
+	)
+
+	signAssertion := func(signingKey *ecdsa.PrivateKey, claims map[string]any) string {
+		signer, err := jose.NewSigner(
+			jose.SigningKey{Algorithm: jose.ES256, Key: jose.JSONWebKey{Key: signingKey, KeyID: "k1"}}, nil,
+		)
+		assert.NoError(GinkgoT(), err)
+		assertion, err := jwt.Signed(signer).Claims(claims).Serialize()
+		assert.NoError(GinkgoT(), err)
+		return assertion
+	}
+
+	BeforeEach(func() {
+		var err error
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.NoError(GinkgoT(), err)
+
+		keySet, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: key.Public(), KeyID: "k1", Algorithm: string(jose.ES256), Use: "sig"},
+		}})
+		assert.NoError(GinkgoT(), err)
+		jwks = string(keySet)
+
+		claims = map[string]any{
+			"iss": clientID,
+			"sub": clientID,
+			"aud": tokenURL,
+			"jti": "assertion-1",
+			"exp": time.Now().Add(time.Minute).Unix(),
+		}
+	})
+
+	Describe("VerifyClientAssertion", func() {
+		It("should return the jti and expiry of a valid assertion", func() {
+			verified, err := oauth.VerifyClientAssertion(signAssertion(key, claims), jwks, clientID, []string{tokenURL})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "assertion-1", verified.JTI)
+			assert.True(GinkgoT(), verified.ReplayWindow() > time.Minute)
+		})
+
+		It("should reject an assertion signed by an unregistered key", func() {
+			other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			assert.NoError(GinkgoT(), err)
+
+			_, err = oauth.VerifyClientAssertion(signAssertion(other, claims), jwks, clientID, []string{tokenURL})
+			assert.ErrorIs(GinkgoT(), err, oauth.ErrInvalidClientAssertion)
+		})
+
+		It("should reject an assertion issued by another client", func() {
+			claims["iss"] = "dcr_other"
+			_, err := oauth.VerifyClientAssertion(signAssertion(key, claims), jwks, clientID, []string{tokenURL})
+			assert.ErrorIs(GinkgoT(), err, oauth.ErrInvalidClientAssertion)
+		})
+
+		It("should reject an assertion for another audience", func() {
+			claims["aud"] = "https://other.example.com/token"
+			_, err := oauth.VerifyClientAssertion(signAssertion(key, claims), jwks, clientID, []string{tokenURL})
+			assert.ErrorIs(GinkgoT(), err, oauth.ErrInvalidClientAssertion)
+		})
+
+		It("should reject an expired assertion", func() {
+			claims["exp"] = time.Now().Add(-time.Hour).Unix()
+			_, err := oauth.VerifyClientAssertion(signAssertion(key, claims), jwks, clientID, []string{tokenURL})
+			assert.ErrorIs(GinkgoT(), err, oauth.ErrInvalidClientAssertion)
+		})
+
+		It("should reject an assertion that lives too long", func() {
+			claims["exp"] = time.Now().Add(time.Hour).Unix()
+			_, err := oauth.VerifyClientAssertion(signAssertion(key, claims), jwks, clientID, []string{tokenURL})
+			assert.ErrorIs(GinkgoT(), err, oauth.ErrInvalidClientAssertion)
+		})
+
+		It("should reject an assertion without jti", func() {
+			delete(claims, "jti")
+			_, err := oauth.VerifyClientAssertion(signAssertion(key, claims), jwks, clientID, []string{tokenURL})
+			assert.ErrorIs(GinkgoT(), err, oauth.ErrInvalidClientAssertion)
+		})
+	})
+
+	Describe("ClientAssertionSubject", func() {
+		It("should return the sub without verifying the signature", func() {
+			sub, err := oauth.ClientAssertionSubject(signAssertion(key, claims))
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), clientID, sub)
+		})
+
+		It("should reject a malformed assertion", func() {
+			_, err := oauth.ClientAssertionSubject("not-a-jwt")
+			assert.ErrorIs(GinkgoT(), err, oauth.ErrInvalidClientAssertion)
+		})
+	})
+})
@@ -0,0 +1,153 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"bkauth/pkg/util"
+)
+
+// MaxClientIDLength bounds a URL-formatted client_id, which is stored in the client_id columns.
+const MaxClientIDLength = 255
+
+// ClientMetadataDocument is the client metadata a client publishes at its URL-formatted
+// client_id (draft-ietf-oauth-client-id-metadata-document), instead of registering it.
+// Such clients are public: they cannot authenticate and are never persisted in oauth_client.
+type ClientMetadataDocument struct {
+	ClientID     string   `json:"client_id"`
+	ClientName   string   `json:"client_name"`
+	RedirectURIs []string `json:"redirect_uris"`
+	GrantTypes   []string `json:"grant_types"`
+	LogoURI      string   `json:"logo_uri"`
+}
+
+// clientMetadataDocumentFields are the document fields that matter to bkauth;
+// the other metadata (client_uri, scope, ...) is ignored.
+type clientMetadataDocumentFields struct {
+	ClientMetadataDocument
+	TokenEndpointAuthMethod string `json:"token_endpoint_auth_method"`
+	ClientSecret            string `json:"client_secret"`
+}
+
+// IsClientIDMetadataDocumentURL reports whether the client_id is a URL pointing at
+// a Client ID Metadata Document rather than a registered client.
+func IsClientIDMetadataDocumentURL(clientID string) bool {
+	return strings.HasPrefix(clientID, "https://")
+}
+
+// ValidateClientIDMetadataDocumentURL checks the client_id URL (draft-ietf-oauth-client-id-metadata-document §3):
+// https with a host and a path, without dot segments, fragment or userinfo.
+func ValidateClientIDMetadataDocumentURL(clientID string) error {
+	if len(clientID) > MaxClientIDLength {
+		return fmt.Errorf("%w: client_id exceeds %d characters", ErrInvalidClientMetadataDocument, MaxClientIDLength)
+	}
+
+	parsed, err := url.Parse(clientID)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("%w: client_id is not an https URL", ErrInvalidClientMetadataDocument)
+	}
+	if parsed.Path == "" || parsed.Path == "/" {
+		return fmt.Errorf("%w: client_id must contain a path", ErrInvalidClientMetadataDocument)
+	}
+	for _, segment := range strings.Split(parsed.Path, "/") {
+		if segment == "." || segment == ".." {
+			return fmt.Errorf("%w: client_id must not contain dot segments", ErrInvalidClientMetadataDocument)
+		}
+	}
+	if parsed.Fragment != "" || strings.Contains(clientID, "#") {
+		return fmt.Errorf("%w: client_id must not contain a fragment", ErrInvalidClientMetadataDocument)
+	}
+	if parsed.User != nil {
+		return fmt.Errorf("%w: client_id must not contain userinfo", ErrInvalidClientMetadataDocument)
+	}
+
+	return nil
+}
+
+// ParseClientMetadataDocument parses and validates the document fetched from the client_id URL.
+// The document must name the same client_id and describe a public client; grant_types defaults
+// to authorization_code + refresh_token like Dynamic Client Registration.
+func ParseClientMetadataDocument(clientID string, doc []byte) (ClientMetadataDocument, error) {
+	var fields clientMetadataDocumentFields
+	if err := json.Unmarshal(doc, &fields); err != nil {
+		return ClientMetadataDocument{}, fmt.Errorf("%w: %s", ErrInvalidClientMetadataDocument, err.Error())
+	}
+	metadata := fields.ClientMetadataDocument
+
+	if metadata.ClientID != clientID {
+		return ClientMetadataDocument{}, fmt.Errorf(
+			"%w: client_id `%s` does not match the document URL", ErrInvalidClientMetadataDocument, metadata.ClientID,
+		)
+	}
+	if fields.ClientSecret != "" ||
+		(fields.TokenEndpointAuthMethod != "" && fields.TokenEndpointAuthMethod != AuthMethodNone) {
+		return ClientMetadataDocument{}, fmt.Errorf(
+			"%w: only public clients (token_endpoint_auth_method=none) are supported", ErrInvalidClientMetadataDocument,
+		)
+	}
+
+	if len(metadata.RedirectURIs) == 0 {
+		return ClientMetadataDocument{}, fmt.Errorf("%w: redirect_uris is required", ErrInvalidClientMetadataDocument)
+	}
+	for _, uri := range metadata.RedirectURIs {
+		if err := ValidateRedirectURI(uri); err != nil {
+			return ClientMetadataDocument{}, fmt.Errorf("%w: %s", ErrInvalidClientMetadataDocument, err.Error())
+		}
+	}
+	metadata.RedirectURIs = util.Deduplicate(metadata.RedirectURIs)
+
+	if len(metadata.GrantTypes) == 0 {
+		metadata.GrantTypes = []string{GrantTypeAuthorizationCode, GrantTypeRefreshToken}
+	}
+	if err := ValidateGrantTypes(metadata.GrantTypes); err != nil {
+		return ClientMetadataDocument{}, fmt.Errorf("%w: %s", ErrInvalidClientMetadataDocument, err.Error())
+	}
+	for _, gt := range metadata.GrantTypes {
+		if _, ok := ConfidentialGrantTypes[gt]; ok {
+			return ClientMetadataDocument{}, fmt.Errorf(
+				"%w: %s is not allowed for public clients", ErrInvalidClientMetadataDocument, gt,
+			)
+		}
+	}
+	metadata.GrantTypes = util.Deduplicate(metadata.GrantTypes)
+
+	if metadata.LogoURI != "" {
+		if err := ValidateLogoURI(metadata.LogoURI); err != nil {
+			return ClientMetadataDocument{}, fmt.Errorf("%w: %s", ErrInvalidClientMetadataDocument, err.Error())
+		}
+	}
+
+	// client_name is OPTIONAL, fall back to the host so that the consent page names the client
+	metadata.ClientName = strings.TrimSpace(metadata.ClientName)
+	if metadata.ClientName == "" {
+		parsed, _ := url.Parse(clientID)
+		metadata.ClientName = parsed.Host
+	}
+	if len([]rune(metadata.ClientName)) > 128 {
+		return ClientMetadataDocument{}, fmt.Errorf(
+			"%w: client_name exceeds 128 characters", ErrInvalidClientMetadataDocument,
+		)
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,146 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"bkauth/pkg/util"
+)
+
+// ClientPolicy restricts the realms a client may use and the resources it may request.
+//
+// AllowedResources are patterns matched against every item of the comma-separated
+// resource parameter, in which "*" matches any sequence of characters,
+// e.g. "mcp:foo" or "gateway:bar:api:*".
+type ClientPolicy struct {
+	// AllowedRealms is empty when the client may use any realm.
+	AllowedRealms []string
+	// AllowedResources is empty when the client may request any resource accepted by the realm.
+	AllowedResources []string
+}
+
+// IsEmpty reports whether the policy restricts nothing.
+func (p ClientPolicy) IsEmpty() bool {
+	return len(p.AllowedRealms) == 0 && len(p.AllowedResources) == 0
+}
+
+// Check reports whether the client may request the resource on the realm.
+// An empty resource, e.g. of a grant issued before resources were recorded, is only checked by realm.
+func (p ClientPolicy) Check(realmName, resource string) error {
+	if len(p.AllowedRealms) > 0 && !slices.Contains(p.AllowedRealms, realmName) {
+		return fmt.Errorf("%w: %s", ErrRealmNotAllowed, realmName)
+	}
+
+	if len(p.AllowedResources) == 0 {
+		return nil
+	}
+	for _, item := range util.SplitCommaList(resource) {
+		if !p.allowsResourceItem(item) {
+			return fmt.Errorf("%w: %s", ErrResourceNotAllowed, item)
+		}
+	}
+	return nil
+}
+
+func (p ClientPolicy) allowsResourceItem(item string) bool {
+	for _, pattern := range p.AllowedResources {
+		if MatchResourcePattern(pattern, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchResourcePattern reports whether the resource item matches the pattern,
+// in which "*" matches any sequence of characters, including none.
+func MatchResourcePattern(pattern, item string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == item
+	}
+
+	if !strings.HasPrefix(item, parts[0]) {
+		return false
+	}
+	item = item[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(item, part)
+		if idx < 0 {
+			return false
+		}
+		item = item[idx+len(part):]
+	}
+	return strings.HasSuffix(item, last)
+}
+
+// ValidateResourcePattern checks that the pattern can match a single resource item.
+func ValidateResourcePattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return errors.New("resource pattern cannot be empty")
+	}
+	if strings.Contains(pattern, ",") {
+		return fmt.Errorf("resource pattern must not contain a comma: %s", pattern)
+	}
+	return nil
+}
+
+// publicClientPolicies holds the allowed resource patterns of public clients per realm.
+var publicClientPolicies = make(map[string][]string)
+
+// RegisterPublicClientPolicy registers the default policy of public clients on a realm,
+// applied to the public clients that have no policy of their own. Must be called during
+// startup (initClientPolicies) before the HTTP server begins accepting requests: the map
+// is read concurrently afterwards and must no longer change.
+func RegisterPublicClientPolicy(realmName string, allowedResources []string) error {
+	for _, pattern := range allowedResources {
+		if err := ValidateResourcePattern(pattern); err != nil {
+			return err
+		}
+	}
+	publicClientPolicies[realmName] = allowedResources
+	return nil
+}
+
+// DefaultPublicClientPolicy returns the policy applied on the realm to a public client
+// that has no policy of its own.
+//
+// Without any registered default, public clients are not restricted. Once a default is
+// registered, public clients may only use the realms with a default and request the
+// resources they allow.
+func DefaultPublicClientPolicy(realmName string) ClientPolicy {
+	if len(publicClientPolicies) == 0 {
+		return ClientPolicy{}
+	}
+
+	allowedResources, ok := publicClientPolicies[realmName]
+	if !ok {
+		allowedRealms := make([]string, 0, len(publicClientPolicies))
+		for name := range publicClientPolicies {
+			allowedRealms = append(allowedRealms, name)
+		}
+		return ClientPolicy{AllowedRealms: allowedRealms}
+	}
+	return ClientPolicy{AllowedRealms: []string{realmName}, AllowedResources: allowedResources}
+}
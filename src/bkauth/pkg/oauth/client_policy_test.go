@@ -0,0 +1,108 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ = Describe("ClientPolicy", func() {
+	Describe("MatchResourcePattern", func() {
+		It("should match exactly without wildcard", func() {
+			assert.True(GinkgoT(), MatchResourcePattern("mcp:foo", "mcp:foo"))
+			assert.False(GinkgoT(), MatchResourcePattern("mcp:foo", "mcp:foobar"))
+		})
+
+		It("should match any sequence with wildcard", func() {
+			assert.True(GinkgoT(), MatchResourcePattern("gateway:bar:api:*", "gateway:bar:api:get_user"))
+			assert.True(GinkgoT(), MatchResourcePattern("gateway:bar:api:*", "gateway:bar:api:*"))
+			assert.True(GinkgoT(), MatchResourcePattern("mcp:*", "mcp:foo"))
+			assert.True(GinkgoT(), MatchResourcePattern(
+				"https://*/mcp-servers/foo/*", "https://bkapi.example.com/prod/mcp-servers/foo/mcp",
+			))
+			assert.False(GinkgoT(), MatchResourcePattern("gateway:bar:api:*", "gateway:baz:api:get_user"))
+			assert.False(GinkgoT(), MatchResourcePattern("a*a", "a"))
+		})
+	})
+
+	Describe("Check", func() {
+		policy := ClientPolicy{
+			AllowedRealms:    []string{"blueking"},
+			AllowedResources: []string{"mcp:foo", "gateway:bar:api:*"},
+		}
+
+		It("should allow everything with an empty policy", func() {
+			assert.NoError(GinkgoT(), ClientPolicy{}.Check("bk-devops", "gateway:*:api:*"))
+		})
+
+		It("should reject other realms", func() {
+			err := policy.Check("bk-devops", "mcp:foo")
+			assert.True(GinkgoT(), errors.Is(err, ErrRealmNotAllowed))
+		})
+
+		It("should require every resource item to be allowed", func() {
+			assert.NoError(GinkgoT(), policy.Check("blueking", "mcp:foo,gateway:bar:api:get_user"))
+
+			err := policy.Check("blueking", "mcp:foo,gateway:*:api:*")
+			assert.True(GinkgoT(), errors.Is(err, ErrResourceNotAllowed))
+			assert.Contains(GinkgoT(), err.Error(), "gateway:*:api:*")
+		})
+
+		It("should only check the realm of an empty resource", func() {
+			assert.NoError(GinkgoT(), policy.Check("blueking", ""))
+		})
+	})
+
+	Describe("DefaultPublicClientPolicy", func() {
+		BeforeEach(func() {
+			publicClientPolicies = make(map[string][]string)
+		})
+
+		AfterEach(func() {
+			publicClientPolicies = make(map[string][]string)
+		})
+
+		It("should not restrict without registered defaults", func() {
+			assert.True(GinkgoT(), DefaultPublicClientPolicy("blueking").IsEmpty())
+		})
+
+		It("should apply the default of the realm", func() {
+			assert.NoError(GinkgoT(), RegisterPublicClientPolicy("blueking", []string{"mcp:*"}))
+
+			policy := DefaultPublicClientPolicy("blueking")
+			assert.NoError(GinkgoT(), policy.Check("blueking", "mcp:foo"))
+			assert.True(GinkgoT(), errors.Is(policy.Check("blueking", "gateway:bar:api:*"), ErrResourceNotAllowed))
+		})
+
+		It("should reject the realms without a default once any is registered", func() {
+			assert.NoError(GinkgoT(), RegisterPublicClientPolicy("blueking", []string{"mcp:*"}))
+
+			policy := DefaultPublicClientPolicy("bk-devops")
+			assert.True(GinkgoT(), errors.Is(policy.Check("bk-devops", "project:foo"), ErrRealmNotAllowed))
+		})
+
+		It("should reject invalid patterns", func() {
+			assert.Error(GinkgoT(), RegisterPublicClientPolicy("blueking", []string{"mcp:foo,mcp:bar"}))
+			assert.Error(GinkgoT(), RegisterPublicClientPolicy("blueking", []string{" "}))
+		})
+	})
+})
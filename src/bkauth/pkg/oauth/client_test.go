@@ -39,6 +39,8 @@ var _ = Describe("Client", func() {
 			Entry("empty", "", false),
 			Entry("partial prefix", "dcr", false),
 			Entry("uppercase prefix", "DCR_abc123", false),
+			Entry("client id metadata document", "https://client.example.com/oauth/client.json", true),
+			Entry("http url", "http://client.example.com/oauth/client.json", false),
 		)
 	})
 
@@ -0,0 +1,66 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth
+
+import (
+	"errors"
+	"strings"
+
+	"bkauth/pkg/util"
+)
+
+// HasPrompt reports whether the space-delimited prompt parameter contains value.
+func HasPrompt(prompt, value string) bool {
+	for _, p := range strings.Fields(prompt) {
+		if p == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePrompt checks the prompt parameter (OIDC Core §3.1.2.1): none must not be
+// combined with any other value. Values other than none and consent (e.g. login) are
+// accepted and ignored, since user login is handled by the platform login service.
+func ValidatePrompt(prompt string) error {
+	if HasPrompt(prompt, PromptNone) && len(strings.Fields(prompt)) > 1 {
+		return errors.New("prompt=none must not be combined with other values")
+	}
+	return nil
+}
+
+// ConsentCovers reports whether a consent the user approved for grantedAudience and
+// grantedScope covers the requested audience and scope, i.e. the user has already
+// approved every requested audience and scope.
+func ConsentCovers(grantedAudience []string, grantedScope string, audience []string, scope string) bool {
+	audienceSet := util.NewStringSetWithValues(grantedAudience)
+	for _, aud := range audience {
+		if !audienceSet.Has(aud) {
+			return false
+		}
+	}
+
+	scopeSet := util.NewStringSetWithValues(ParseScope(grantedScope))
+	for _, s := range ParseScope(scope) {
+		if !scopeSet.Has(s) {
+			return false
+		}
+	}
+	return true
+}
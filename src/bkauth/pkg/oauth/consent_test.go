@@ -0,0 +1,65 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+
+	"bkauth/pkg/oauth"
+)
+
+var _ = Describe("Consent", func() {
+	Describe("HasPrompt", func() {
+		It("should match space-delimited values", func() {
+			assert.True(GinkgoT(), oauth.HasPrompt("login consent", oauth.PromptConsent))
+			assert.False(GinkgoT(), oauth.HasPrompt("login", oauth.PromptConsent))
+			assert.False(GinkgoT(), oauth.HasPrompt("", oauth.PromptNone))
+		})
+	})
+
+	Describe("ValidatePrompt", func() {
+		It("should accept empty, none, consent and unknown values", func() {
+			assert.NoError(GinkgoT(), oauth.ValidatePrompt(""))
+			assert.NoError(GinkgoT(), oauth.ValidatePrompt("none"))
+			assert.NoError(GinkgoT(), oauth.ValidatePrompt("login consent"))
+		})
+
+		It("should reject none combined with other values", func() {
+			assert.Error(GinkgoT(), oauth.ValidatePrompt("none consent"))
+		})
+	})
+
+	Describe("ConsentCovers", func() {
+		granted := []string{"mcp:foo", "mcp:bar"}
+
+		It("should cover a subset of the approved audiences and scopes", func() {
+			assert.True(GinkgoT(), oauth.ConsentCovers(granted, "openid profile", []string{"mcp:foo"}, "openid"))
+			assert.True(GinkgoT(), oauth.ConsentCovers(granted, "", granted, ""))
+		})
+
+		It("should not cover a new audience", func() {
+			assert.False(GinkgoT(), oauth.ConsentCovers(granted, "", []string{"mcp:foo", "mcp:baz"}, ""))
+		})
+
+		It("should not cover a new scope", func() {
+			assert.False(GinkgoT(), oauth.ConsentCovers(granted, "profile", []string{"mcp:foo"}, "openid"))
+		})
+	})
+})
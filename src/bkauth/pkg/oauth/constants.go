@@ -19,10 +19,15 @@
 package oauth
 
 const (
-	// Grant types (RFC 6749, RFC 8628)
+	// Grant types (RFC 6749, RFC 8628, RFC 8693)
 	GrantTypeAuthorizationCode = "authorization_code"
 	GrantTypeRefreshToken      = "refresh_token"
 	GrantTypeDeviceCode        = "urn:ietf:params:oauth:grant-type:device_code"
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeTokenExchange     = "urn:ietf:params:oauth:grant-type:token-exchange"
+	// GrantTypePersonalAccessToken marks access tokens a user creates through the web API,
+	// it is not accepted by the token endpoint.
+	GrantTypePersonalAccessToken = "personal_access_token"
 
 	// Response types (RFC 6749 §3.1.1)
 	ResponseTypeCode = "code"
@@ -32,10 +37,41 @@ const (
 
 	// Token type (RFC 6749 §5.1)
 	TokenTypeBearer = "Bearer"
+	// Token type of DPoP-bound access tokens (RFC 9449 §5)
+	TokenTypeDPoP = "DPoP"
 
 	// Token type hints (RFC 7009)
 	TokenTypeAccessToken  = "access_token"
 	TokenTypeRefreshToken = "refresh_token"
+
+	// Token type identifiers (RFC 8693 §3)
+	TokenTypeURIAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+
+	// Access token formats
+	AccessTokenFormatOpaque = "opaque"
+	AccessTokenFormatJWT    = "jwt"
+
+	// JWS algorithms supported for signing keys (RFC 7518 §3.1)
+	SigningAlgorithmRS256 = "RS256"
+	SigningAlgorithmES256 = "ES256"
+
+	// JWT "typ" header of JWT access tokens (RFC 9068 §2.1)
+	JWTTypeAccessToken = "at+jwt"
+	// JWT "typ" header of OpenID Connect id_tokens
+	JWTTypeIDToken = "JWT"
+	// JWT "typ" header of DPoP proofs (RFC 9449 §4.2)
+	JWTTypeDPoPProof = "dpop+jwt"
+	// JWT "typ" header of signed introspection responses (RFC 9701 §5)
+	JWTTypeTokenIntrospection = "token-introspection+jwt"
+	// Media type of signed introspection responses, requested via Accept (RFC 9701 §4)
+	ContentTypeTokenIntrospectionJWT = "application/token-introspection+jwt"
+
+	// ScopeOpenID marks an OpenID Connect request (OIDC Core §3.1.2.1)
+	ScopeOpenID = "openid"
+
+	// Prompt values (OIDC Core §3.1.2.1)
+	PromptNone    = "none"
+	PromptConsent = "consent"
 )
 
 // SupportedGrantTypes is the set of grant types this server supports.
@@ -43,4 +79,13 @@ var SupportedGrantTypes = map[string]struct{}{
 	GrantTypeAuthorizationCode: {},
 	GrantTypeRefreshToken:      {},
 	GrantTypeDeviceCode:        {},
+	GrantTypeClientCredentials: {},
+	GrantTypeTokenExchange:     {},
+}
+
+// ConfidentialGrantTypes are the grant types in which the client acts on its
+// own credentials, so only confidential clients may use them.
+var ConfidentialGrantTypes = map[string]struct{}{
+	GrantTypeClientCredentials: {},
+	GrantTypeTokenExchange:     {},
 }
@@ -0,0 +1,154 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const (
+	// DPoPHeader is the HTTP header carrying a DPoP proof (RFC 9449 §4.1).
+	DPoPHeader = "DPoP"
+
+	// DPoPProofMaxAge bounds how far the "iat" of a proof may be from the server time,
+	// in either direction to tolerate clock skew. A jti is remembered for twice this
+	// window, which covers every proof that can still pass the iat check.
+	DPoPProofMaxAge = 60 * time.Second
+)
+
+// DPoPSigningAlgorithms is the set of JWS algorithms accepted for DPoP proofs,
+// advertised as dpop_signing_alg_values_supported (RFC 9449 §5.1).
+var DPoPSigningAlgorithms = []string{SigningAlgorithmRS256, SigningAlgorithmES256}
+
+// Confirmation is the "cnf" claim binding a token to a proof-of-possession key (RFC 7800).
+// The zero value means the token is a plain bearer token.
+type Confirmation struct {
+	// JKT is the base64url JWK SHA-256 thumbprint of the DPoP key (RFC 9449 §6).
+	JKT string `json:"jkt,omitempty"`
+	// X5TS256 is the base64url SHA-256 thumbprint of the client certificate (RFC 8705 §3.1).
+	X5TS256 string `json:"x5t#S256,omitempty"`
+}
+
+// IsZero reports whether the token is not bound to any key.
+func (c Confirmation) IsZero() bool {
+	return c.JKT == "" && c.X5TS256 == ""
+}
+
+// DPoPProof is a verified DPoP proof.
+type DPoPProof struct {
+	// JKT is the thumbprint of the public key the proof was signed with.
+	JKT string
+	JTI string
+}
+
+type dpopProofClaims struct {
+	JTI      string `json:"jti"`
+	HTM      string `json:"htm"`
+	HTU      string `json:"htu"`
+	IssuedAt int64  `json:"iat"`
+	ATH      string `json:"ath,omitempty"`
+}
+
+// VerifyDPoPProof verifies a DPoP proof JWT (RFC 9449 §4.3) for a request with
+// the given method and URL. accessToken is the token presented along with the
+// proof to a protected resource and must be empty at the token endpoint, where
+// the proof carries no "ath" claim.
+//
+// Replay of the returned jti is NOT checked here; callers must remember it.
+func VerifyDPoPProof(proof, method, uri, accessToken string) (DPoPProof, error) {
+	algs := make([]jose.SignatureAlgorithm, 0, len(DPoPSigningAlgorithms))
+	for _, alg := range DPoPSigningAlgorithms {
+		algs = append(algs, jose.SignatureAlgorithm(alg))
+	}
+	token, err := jwt.ParseSigned(proof, algs)
+	if err != nil {
+		return DPoPProof{}, fmt.Errorf("%w: %s", ErrInvalidDPoPProof, err.Error())
+	}
+
+	header := token.Headers[0]
+	if typ, _ := header.ExtraHeaders[jose.HeaderType].(string); typ != JWTTypeDPoPProof {
+		return DPoPProof{}, fmt.Errorf("%w: typ must be %s", ErrInvalidDPoPProof, JWTTypeDPoPProof)
+	}
+	// the proof is signed by the key it carries, which must be a public asymmetric key
+	jwk := header.JSONWebKey
+	if jwk == nil || !jwk.Valid() || !jwk.IsPublic() {
+		return DPoPProof{}, fmt.Errorf("%w: jwk must be a public key", ErrInvalidDPoPProof)
+	}
+
+	var claims dpopProofClaims
+	if err := token.Claims(jwk.Key, &claims); err != nil {
+		return DPoPProof{}, fmt.Errorf("%w: %s", ErrInvalidDPoPProof, err.Error())
+	}
+
+	if claims.JTI == "" {
+		return DPoPProof{}, fmt.Errorf("%w: jti is required", ErrInvalidDPoPProof)
+	}
+	if claims.HTM != method {
+		return DPoPProof{}, fmt.Errorf("%w: htm does not match the request method", ErrInvalidDPoPProof)
+	}
+	if normalizeHTU(claims.HTU) != normalizeHTU(uri) {
+		return DPoPProof{}, fmt.Errorf("%w: htu does not match the request URL", ErrInvalidDPoPProof)
+	}
+	age := time.Since(time.Unix(claims.IssuedAt, 0))
+	if age > DPoPProofMaxAge || age < -DPoPProofMaxAge {
+		return DPoPProof{}, fmt.Errorf("%w: iat is out of the acceptable window", ErrInvalidDPoPProof)
+	}
+	if accessToken != "" && claims.ATH != hashAccessTokenForDPoP(accessToken) {
+		return DPoPProof{}, fmt.Errorf("%w: ath does not match the access token", ErrInvalidDPoPProof)
+	}
+
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return DPoPProof{}, fmt.Errorf("%w: %s", ErrInvalidDPoPProof, err.Error())
+	}
+
+	return DPoPProof{
+		JKT: base64.RawURLEncoding.EncodeToString(thumbprint),
+		JTI: claims.JTI,
+	}, nil
+}
+
+// normalizeHTU drops the query and fragment and lower-cases the scheme and host,
+// the URL parts that RFC 9449 §4.3 excludes from the htu comparison.
+func normalizeHTU(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// hashAccessTokenForDPoP returns the "ath" claim value of an access token (RFC 9449 §4.2).
+func hashAccessTokenForDPoP(accessToken string) string {
+	hash := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
@@ -0,0 +1,121 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+
+	"bkauth/pkg/oauth"
+)
+
+var _ = Describe("VerifyDPoPProof", func() {
+	const tokenURL = "https://bkauth.example.com/realms/blueking/oauth2/token"
+
+	var (
+		key    *ecdsa.PrivateKey
+		claims map[string]any
+	)
+
+	signProof := func(typ string, claims map[string]any) string {
+		signer, err := jose.NewSigner(
+			jose.SigningKey{Algorithm: jose.ES256, Key: key},
+			(&jose.SignerOptions{EmbedJWK: true}).WithType(jose.ContentType(typ)),
+		)
+		assert.NoError(GinkgoT(), err)
+		proof, err := jwt.Signed(signer).Claims(claims).Serialize()
+		assert.NoError(GinkgoT(), err)
+		return proof
+	}
+
+	BeforeEach(func() {
+		var err error
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.NoError(GinkgoT(), err)
+
+		claims = map[string]any{
+			"jti": "proof-1",
+			"htm": "POST",
+			"htu": tokenURL,
+			"iat": time.Now().Unix(),
+		}
+	})
+
+	It("should return the key thumbprint and jti of a valid proof", func() {
+		proof, err := oauth.VerifyDPoPProof(signProof(oauth.JWTTypeDPoPProof, claims), "POST", tokenURL, "")
+		assert.NoError(GinkgoT(), err)
+
+		thumbprint, err := (&jose.JSONWebKey{Key: key.Public()}).Thumbprint(crypto.SHA256)
+		assert.NoError(GinkgoT(), err)
+		assert.Equal(GinkgoT(), base64.RawURLEncoding.EncodeToString(thumbprint), proof.JKT)
+		assert.Equal(GinkgoT(), "proof-1", proof.JTI)
+	})
+
+	It("should ignore the query of htu", func() {
+		claims["htu"] = tokenURL + "?foo=bar"
+		_, err := oauth.VerifyDPoPProof(signProof(oauth.JWTTypeDPoPProof, claims), "POST", tokenURL, "")
+		assert.NoError(GinkgoT(), err)
+	})
+
+	It("should verify ath against the presented access token", func() {
+		hash := sha256.Sum256([]byte("bk_token"))
+		claims["ath"] = base64.RawURLEncoding.EncodeToString(hash[:])
+
+		_, err := oauth.VerifyDPoPProof(signProof(oauth.JWTTypeDPoPProof, claims), "POST", tokenURL, "bk_token")
+		assert.NoError(GinkgoT(), err)
+
+		_, err = oauth.VerifyDPoPProof(signProof(oauth.JWTTypeDPoPProof, claims), "POST", tokenURL, "bk_other")
+		assert.ErrorIs(GinkgoT(), err, oauth.ErrInvalidDPoPProof)
+	})
+
+	DescribeTable("invalid proofs",
+		func(typ string, mutate func(map[string]any)) {
+			mutate(claims)
+			_, err := oauth.VerifyDPoPProof(signProof(typ, claims), "POST", tokenURL, "")
+			assert.ErrorIs(GinkgoT(), err, oauth.ErrInvalidDPoPProof)
+		},
+		Entry("wrong typ", "JWT", func(map[string]any) {}),
+		Entry("missing jti", oauth.JWTTypeDPoPProof, func(c map[string]any) { delete(c, "jti") }),
+		Entry("wrong htm", oauth.JWTTypeDPoPProof, func(c map[string]any) { c["htm"] = "GET" }),
+		Entry("wrong htu", oauth.JWTTypeDPoPProof, func(c map[string]any) {
+			c["htu"] = "https://bkauth.example.com/realms/devops/oauth2/token"
+		}),
+		Entry("stale iat", oauth.JWTTypeDPoPProof, func(c map[string]any) {
+			c["iat"] = time.Now().Add(-5 * time.Minute).Unix()
+		}),
+		Entry("future iat", oauth.JWTTypeDPoPProof, func(c map[string]any) {
+			c["iat"] = time.Now().Add(5 * time.Minute).Unix()
+		}),
+	)
+
+	It("should reject a malformed proof", func() {
+		_, err := oauth.VerifyDPoPProof("not-a-jwt", "POST", tokenURL, "")
+		assert.ErrorIs(GinkgoT(), err, oauth.ErrInvalidDPoPProof)
+	})
+})
@@ -52,6 +52,8 @@ const (
 	ErrorCodeUnauthorizedClient = "unauthorized_client"
 	// RFC 6749 §4.1.2.1 — Authorization Endpoint
 	ErrorCodeAccessDenied = "access_denied"
+	// RFC 6749 §4.1.2.1, §5.2 — Authorization & Token Endpoint
+	ErrorCodeInvalidScope = "invalid_scope"
 	// RFC 6749 §4.1.2.1 — Authorization Endpoint
 	ErrorCodeUnsupportedResponseType = "unsupported_response_type"
 	// RFC 6749 §5.2 — Token Endpoint
@@ -70,6 +72,22 @@ const (
 	ErrorCodeSlowDown = "slow_down"
 	// RFC 8628 §3.5 — Device Authorization Grant
 	ErrorCodeExpiredToken = "expired_token"
+	// RFC 8707 §2 — Resource Indicators
+	ErrorCodeInvalidTarget = "invalid_target"
+	// RFC 6750 §3.1 — Bearer Token Usage
+	ErrorCodeInvalidToken = "invalid_token"
+	// RFC 6750 §3.1 — Bearer Token Usage
+	ErrorCodeInsufficientScope = "insufficient_scope"
+	// RFC 9101 §6.2 — JWT-Secured Authorization Request, used by RFC 9126 for request_uri
+	ErrorCodeInvalidRequestURI = "invalid_request_uri"
+	// RFC 9449 §5 — OAuth 2.0 Demonstrating Proof of Possession
+	ErrorCodeInvalidDPoPProof = "invalid_dpop_proof"
+	// OIDC Core §3.1.2.6 — Authentication Error Response, for prompt=none
+	ErrorCodeLoginRequired = "login_required"
+	// OIDC Core §3.1.2.6 — Authentication Error Response, for prompt=none
+	ErrorCodeConsentRequired = "consent_required"
+	// RFC 9396 §5 — Rich Authorization Requests
+	ErrorCodeInvalidAuthorizationDetails = "invalid_authorization_details"
 )
 
 func NewInvalidRequestError(description string) *OAuthError {
@@ -92,6 +110,10 @@ func NewAccessDeniedError(description string) *OAuthError {
 	return &OAuthError{Code: ErrorCodeAccessDenied, Description: description}
 }
 
+func NewInvalidScopeError(description string) *OAuthError {
+	return &OAuthError{Code: ErrorCodeInvalidScope, Description: description}
+}
+
 func NewUnsupportedResponseTypeError(description string) *OAuthError {
 	return &OAuthError{Code: ErrorCodeUnsupportedResponseType, Description: description}
 }
@@ -128,6 +150,38 @@ func NewExpiredTokenError(description string) *OAuthError {
 	return &OAuthError{Code: ErrorCodeExpiredToken, Description: description}
 }
 
+func NewInvalidTargetError(description string) *OAuthError {
+	return &OAuthError{Code: ErrorCodeInvalidTarget, Description: description}
+}
+
+func NewInvalidTokenError(description string) *OAuthError {
+	return &OAuthError{Code: ErrorCodeInvalidToken, Description: description}
+}
+
+func NewInsufficientScopeError(description string) *OAuthError {
+	return &OAuthError{Code: ErrorCodeInsufficientScope, Description: description}
+}
+
+func NewInvalidRequestURIError(description string) *OAuthError {
+	return &OAuthError{Code: ErrorCodeInvalidRequestURI, Description: description}
+}
+
+func NewInvalidDPoPProofError(description string) *OAuthError {
+	return &OAuthError{Code: ErrorCodeInvalidDPoPProof, Description: description}
+}
+
+func NewLoginRequiredError(description string) *OAuthError {
+	return &OAuthError{Code: ErrorCodeLoginRequired, Description: description}
+}
+
+func NewConsentRequiredError(description string) *OAuthError {
+	return &OAuthError{Code: ErrorCodeConsentRequired, Description: description}
+}
+
+func NewInvalidAuthorizationDetailsError(description string) *OAuthError {
+	return &OAuthError{Code: ErrorCodeInvalidAuthorizationDetails, Description: description}
+}
+
 // AsOAuthError extracts an *OAuthError from err using errors.As.
 func AsOAuthError(err error) (*OAuthError, bool) {
 	var oauthErr *OAuthError
@@ -159,9 +213,10 @@ var (
 
 // Refresh token errors
 var (
-	ErrInvalidRefreshToken = errors.New("invalid refresh token")
-	ErrRefreshTokenExpired = errors.New("refresh token expired")
-	ErrRefreshTokenRevoked = errors.New("refresh token revoked")
+	ErrInvalidRefreshToken     = errors.New("invalid refresh token")
+	ErrRefreshTokenExpired     = errors.New("refresh token expired")
+	ErrRefreshTokenRevoked     = errors.New("refresh token revoked")
+	ErrRefreshTokenIdleExpired = errors.New("refresh token expired for inactivity")
 )
 
 // Device code errors (RFC 8628)
@@ -180,6 +235,40 @@ var (
 
 // Client authentication errors
 var (
-	ErrMissingClientSecret = errors.New("client_secret is required for confidential clients")
-	ErrInvalidClientSecret = errors.New("invalid client_secret")
+	ErrMissingClientSecret    = errors.New("client_secret is required for confidential clients")
+	ErrInvalidClientSecret    = errors.New("invalid client_secret")
+	ErrMissingClientAssertion = errors.New("client_assertion is required for private_key_jwt clients")
+	ErrInvalidClientAssertion = errors.New("invalid client_assertion")
+	ErrClientAssertionReplay  = errors.New("client_assertion has already been used")
+)
+
+// Signing key errors
+var ErrNoActiveSigningKey = errors.New("no active signing key")
+
+// DPoP errors (RFC 9449)
+var (
+	ErrInvalidDPoPProof = errors.New("invalid dpop proof")
+	ErrDPoPKeyMismatch  = errors.New("dpop key mismatch")
+)
+
+// Mutual-TLS errors (RFC 8705)
+var (
+	ErrMissingClientCertificate  = errors.New("client certificate is required")
+	ErrInvalidClientCertificate  = errors.New("invalid client certificate")
+	ErrClientCertificateMismatch = errors.New("client certificate mismatch")
+)
+
+// Client ID Metadata Document errors
+var ErrInvalidClientMetadataDocument = errors.New("invalid client metadata document")
+
+// Personal access token errors
+var ErrPersonalAccessTokenNotFound = errors.New("personal access token not found")
+
+// Grant errors
+var ErrGrantNotFound = errors.New("grant not found")
+
+// Client policy errors
+var (
+	ErrRealmNotAllowed    = errors.New("realm is not allowed for the client")
+	ErrResourceNotAllowed = errors.New("resource is not allowed for the client")
 )
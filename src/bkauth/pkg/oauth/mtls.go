@@ -0,0 +1,189 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// trustedClientCAs verifies the certificates of tls_client_auth clients,
+// nil means tls_client_auth is unavailable.
+var trustedClientCAs *x509.CertPool
+
+// RegisterTrustedClientCAs registers the PEM-encoded CA certificates that issue
+// certificates of tls_client_auth clients. Must be called during startup (initMutualTLS)
+// before the HTTP server begins accepting requests, which then share the pool read-only.
+func RegisterTrustedClientCAs(pemData string) error {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(pemData)) {
+		return errors.New("no valid PEM certificate found")
+	}
+	trustedClientCAs = pool
+	return nil
+}
+
+// ClientCertificateFromRequest returns the client certificate of the request and the
+// intermediates sent along with it: the certificate presented on the TLS connection
+// when BKAuth terminates TLS itself, otherwise the one forwarded by the ingress in the
+// given header. A nil certificate with a nil error means none was presented.
+func ClientCertificateFromRequest(
+	r *http.Request, header string,
+) (cert *x509.Certificate, intermediates []*x509.Certificate, err error) {
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		return r.TLS.PeerCertificates[0], r.TLS.PeerCertificates[1:], nil
+	}
+
+	if header == "" {
+		return nil, nil, nil
+	}
+	value := r.Header.Get(header)
+	if value == "" {
+		return nil, nil, nil
+	}
+
+	cert, err = ParseClientCertificate(value)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, nil, nil
+}
+
+// ParseClientCertificate parses a client certificate forwarded by a TLS-terminating proxy.
+// The value is either a (URL-escaped) PEM block, e.g. nginx $ssl_client_escaped_cert,
+// or the base64-encoded DER certificate.
+func ParseClientCertificate(value string) (*x509.Certificate, error) {
+	unescaped, err := url.PathUnescape(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidClientCertificate, err.Error())
+	}
+
+	var der []byte
+	if block, _ := pem.Decode([]byte(unescaped)); block != nil {
+		der = block.Bytes
+	} else if der, err = base64.StdEncoding.DecodeString(strings.TrimSpace(value)); err != nil {
+		return nil, fmt.Errorf("%w: neither PEM nor base64 DER", ErrInvalidClientCertificate)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidClientCertificate, err.Error())
+	}
+	return cert, nil
+}
+
+// CertificateThumbprint returns the base64url SHA-256 thumbprint of the DER-encoded
+// certificate, the "x5t#S256" confirmation method of RFC 8705 §3.1.
+func CertificateThumbprint(cert *x509.Certificate) string {
+	hash := sha256.Sum256(cert.Raw)
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+// VerifyPKIClientCertificate authenticates a tls_client_auth client (RFC 8705 §2.1):
+// the certificate must chain to one of the trusted CAs and carry the registered
+// subject DN or subject alternative name.
+func VerifyPKIClientCertificate(
+	cert *x509.Certificate, intermediates []*x509.Certificate, subjectDN, san string,
+) error {
+	if trustedClientCAs == nil {
+		return fmt.Errorf("%w: no trusted CA is configured", ErrClientCertificateMismatch)
+	}
+
+	pool := x509.NewCertPool()
+	for _, c := range intermediates {
+		pool.AddCert(c)
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         trustedClientCAs,
+		Intermediates: pool,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		return fmt.Errorf("%w: %s", ErrClientCertificateMismatch, err.Error())
+	}
+
+	if !matchCertificateSubject(cert, subjectDN, san) {
+		return fmt.Errorf("%w: subject does not match the registered one", ErrClientCertificateMismatch)
+	}
+	return nil
+}
+
+// VerifySelfSignedClientCertificate authenticates a self_signed_tls_client_auth client
+// (RFC 8705 §2.2): the certificate must be one of the "x5c" certificates of the
+// client's registered JWK Set. No chain is built, the registration is the trust anchor.
+func VerifySelfSignedClientCertificate(cert *x509.Certificate, jwks string) error {
+	var keySet jose.JSONWebKeySet
+	if err := json.Unmarshal([]byte(jwks), &keySet); err != nil {
+		return fmt.Errorf("%w: invalid registered jwks: %s", ErrClientCertificateMismatch, err.Error())
+	}
+
+	for _, key := range keySet.Keys {
+		if len(key.Certificates) > 0 && key.Certificates[0].Equal(cert) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: certificate is not registered", ErrClientCertificateMismatch)
+}
+
+// matchCertificateSubject reports whether the certificate carries the registered
+// subject DN (RFC 4514 string) or subject alternative name (dNSName, URI, IP or email).
+// Exactly one of subjectDN and san is expected to be set; neither set never matches.
+func matchCertificateSubject(cert *x509.Certificate, subjectDN, san string) bool {
+	if subjectDN != "" {
+		return cert.Subject.String() == subjectDN
+	}
+	if san == "" {
+		return false
+	}
+
+	for _, name := range cert.DNSNames {
+		if strings.EqualFold(name, san) {
+			return true
+		}
+	}
+	for _, uri := range cert.URIs {
+		if uri.String() == san {
+			return true
+		}
+	}
+	// an IP address may be registered in any of its textual forms
+	if sanIP := net.ParseIP(san); sanIP != nil {
+		for _, ip := range cert.IPAddresses {
+			if ip.Equal(sanIP) {
+				return true
+			}
+		}
+	}
+	for _, email := range cert.EmailAddresses {
+		if email == san {
+			return true
+		}
+	}
+	return false
+}
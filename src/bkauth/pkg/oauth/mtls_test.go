@@ -0,0 +1,198 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+
+	"bkauth/pkg/oauth"
+)
+
+// newTestCertificate issues a client certificate for CN=cn, self-signed when parent is nil.
+func newTestCertificate(
+	cn string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey,
+) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(GinkgoT(), err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn, Organization: []string{"BlueKing"}},
+		DNSNames:              []string{cn + ".example.com"},
+		IPAddresses:           []net.IP{net.ParseIP("2001:db8::1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.NoError(GinkgoT(), err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(GinkgoT(), err)
+	return cert, key
+}
+
+func encodeCertificatePEM(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
+
+var _ = Describe("ClientCertificateFromRequest", func() {
+	It("should parse the URL-escaped PEM forwarded by the ingress", func() {
+		cert, _ := newTestCertificate("client1", false, nil, nil)
+		r := httptest.NewRequest("POST", "/token", nil)
+		r.Header.Set("X-Client-Cert", url.PathEscape(encodeCertificatePEM(cert)))
+
+		got, intermediates, err := oauth.ClientCertificateFromRequest(r, "X-Client-Cert")
+
+		assert.NoError(GinkgoT(), err)
+		assert.True(GinkgoT(), got.Equal(cert))
+		assert.Empty(GinkgoT(), intermediates)
+	})
+
+	It("should parse the base64 DER forwarded by the ingress", func() {
+		cert, _ := newTestCertificate("client1", false, nil, nil)
+		r := httptest.NewRequest("POST", "/token", nil)
+		r.Header.Set("X-Client-Cert", base64.StdEncoding.EncodeToString(cert.Raw))
+
+		got, _, err := oauth.ClientCertificateFromRequest(r, "X-Client-Cert")
+
+		assert.NoError(GinkgoT(), err)
+		assert.True(GinkgoT(), got.Equal(cert))
+	})
+
+	It("should ignore the header when it is not configured", func() {
+		cert, _ := newTestCertificate("client1", false, nil, nil)
+		r := httptest.NewRequest("POST", "/token", nil)
+		r.Header.Set("X-Client-Cert", url.PathEscape(encodeCertificatePEM(cert)))
+
+		got, _, err := oauth.ClientCertificateFromRequest(r, "")
+
+		assert.NoError(GinkgoT(), err)
+		assert.Nil(GinkgoT(), got)
+	})
+
+	It("should reject a malformed certificate", func() {
+		r := httptest.NewRequest("POST", "/token", nil)
+		r.Header.Set("X-Client-Cert", "not-a-certificate")
+
+		_, _, err := oauth.ClientCertificateFromRequest(r, "X-Client-Cert")
+
+		assert.ErrorIs(GinkgoT(), err, oauth.ErrInvalidClientCertificate)
+	})
+})
+
+var _ = Describe("CertificateThumbprint", func() {
+	It("should return the base64url SHA-256 of the DER certificate", func() {
+		cert, _ := newTestCertificate("client1", false, nil, nil)
+		hash := sha256.Sum256(cert.Raw)
+
+		assert.Equal(GinkgoT(), base64.RawURLEncoding.EncodeToString(hash[:]), oauth.CertificateThumbprint(cert))
+	})
+})
+
+var _ = Describe("VerifyPKIClientCertificate", func() {
+	var (
+		ca   *x509.Certificate
+		leaf *x509.Certificate
+	)
+
+	BeforeEach(func() {
+		var caKey *ecdsa.PrivateKey
+		ca, caKey = newTestCertificate("ca", true, nil, nil)
+		leaf, _ = newTestCertificate("client1", false, ca, caKey)
+		assert.NoError(GinkgoT(), oauth.RegisterTrustedClientCAs(encodeCertificatePEM(ca)))
+	})
+
+	It("should accept the registered subject DN", func() {
+		err := oauth.VerifyPKIClientCertificate(leaf, nil, "CN=client1,O=BlueKing", "")
+		assert.NoError(GinkgoT(), err)
+	})
+
+	It("should accept the registered SAN", func() {
+		err := oauth.VerifyPKIClientCertificate(leaf, nil, "", "client1.example.com")
+		assert.NoError(GinkgoT(), err)
+	})
+
+	It("should accept the registered IP SAN in another textual form", func() {
+		err := oauth.VerifyPKIClientCertificate(leaf, nil, "", "2001:0db8:0:0::1")
+		assert.NoError(GinkgoT(), err)
+	})
+
+	It("should reject another subject", func() {
+		err := oauth.VerifyPKIClientCertificate(leaf, nil, "CN=client2,O=BlueKing", "")
+		assert.ErrorIs(GinkgoT(), err, oauth.ErrClientCertificateMismatch)
+	})
+
+	It("should reject a certificate not issued by a trusted CA", func() {
+		selfSigned, _ := newTestCertificate("client1", false, nil, nil)
+		err := oauth.VerifyPKIClientCertificate(selfSigned, nil, "CN=client1,O=BlueKing", "")
+		assert.ErrorIs(GinkgoT(), err, oauth.ErrClientCertificateMismatch)
+	})
+})
+
+var _ = Describe("VerifySelfSignedClientCertificate", func() {
+	var (
+		cert *x509.Certificate
+		jwks string
+	)
+
+	BeforeEach(func() {
+		var key *ecdsa.PrivateKey
+		cert, key = newTestCertificate("client1", false, nil, nil)
+		data, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+			Key:          &key.PublicKey,
+			Certificates: []*x509.Certificate{cert},
+		}}})
+		assert.NoError(GinkgoT(), err)
+		jwks = string(data)
+	})
+
+	It("should accept a registered certificate", func() {
+		assert.NoError(GinkgoT(), oauth.VerifySelfSignedClientCertificate(cert, jwks))
+	})
+
+	It("should reject an unregistered certificate", func() {
+		other, _ := newTestCertificate("client1", false, nil, nil)
+		err := oauth.VerifySelfSignedClientCertificate(other, jwks)
+		assert.ErrorIs(GinkgoT(), err, oauth.ErrClientCertificateMismatch)
+	})
+})
@@ -0,0 +1,41 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth
+
+import "bkauth/pkg/util"
+
+const (
+	// RequestURIPrefix is the URN prefix of request_uri values issued by the
+	// pushed authorization request endpoint (RFC 9126 §2.2).
+	RequestURIPrefix = "urn:ietf:params:oauth:request_uri:"
+
+	// PushedAuthorizationRequestTTL is the lifetime of a request_uri in seconds.
+	// RFC 9126 §2.2 recommends a short lifetime, the request_uri is consumed by /authorize immediately.
+	PushedAuthorizationRequestTTL = 60
+)
+
+// GenerateRequestURI returns a one-time request_uri referencing a pushed authorization request.
+// RFC 9126 §2.2: the reference MUST be hard to guess, so it carries 128 bits of entropy.
+func GenerateRequestURI() (string, error) {
+	ref, err := util.RandHex(16)
+	if err != nil {
+		return "", err
+	}
+	return RequestURIPrefix + ref, nil
+}
@@ -0,0 +1,43 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth_test
+
+import (
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+
+	"bkauth/pkg/oauth"
+)
+
+var _ = Describe("GenerateRequestURI", func() {
+	It("returns a request_uri URN with a 32-char hex reference", func() {
+		requestURI, err := oauth.GenerateRequestURI()
+		assert.NoError(GinkgoT(), err)
+		assert.Regexp(GinkgoT(),
+			regexp.MustCompile(`^urn:ietf:params:oauth:request_uri:[0-9a-f]{32}$`), requestURI)
+	})
+
+	It("generates unique values", func() {
+		uri1, _ := oauth.GenerateRequestURI()
+		uri2, _ := oauth.GenerateRequestURI()
+		assert.NotEqual(GinkgoT(), uri1, uri2)
+	})
+})
@@ -20,15 +20,30 @@ package oauth
 
 import "context"
 
+// Scope is an entry of a realm's scope catalog (RFC 6749 §3.3).
+type Scope struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
 // Realm defines per-realm resource handling strategy.
 // Implementations live in pkg/realm/{name}/ and are registered at startup.
 type Realm interface {
 	Name() string
 	TokenPrefix() string
+	// Scopes returns the scope catalog of the realm; requested scopes outside it are rejected.
+	Scopes() []Scope
 
 	ValidateResource(ctx context.Context, resource string) error
 	ExtractAudiences(ctx context.Context, resource string) ([]string, error)
 	ResolveResourceDisplay(ctx context.Context, resource string) (any, error)
+
+	// AuthorizationDetailsTypes returns the authorization details types the realm accepts
+	// (RFC 9396 §2); none when it does not support authorization details.
+	AuthorizationDetailsTypes() []string
+	// ResolveAuthorizationDetails validates the authorization details and renders them as the
+	// resource items they grant access to.
+	ResolveAuthorizationDetails(ctx context.Context, details []AuthorizationDetail) (string, error)
 }
 
 // realms is written during single-threaded startup (initRealms) and read-only
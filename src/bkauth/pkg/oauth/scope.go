@@ -0,0 +1,119 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultScopes lets resource servers tell read-only access from write access;
+// realms return it from Realm.Scopes, with their own entries appended if any.
+var DefaultScopes = []Scope{
+	{Name: "read", DisplayName: "读取数据"},
+	{Name: "write", DisplayName: "读写数据"},
+}
+
+// openIDScope is offered by every realm once an id_token signing key is configured.
+var openIDScope = Scope{Name: ScopeOpenID, DisplayName: "获取你的身份信息"}
+
+// scopeCatalog returns the realm's scope catalog, plus openid when an active
+// signing key is available to sign id_tokens.
+func scopeCatalog(realm Realm) []Scope {
+	scopes := realm.Scopes()
+	if _, ok := ActiveSigningKey(); ok {
+		scopes = append(scopes[:len(scopes):len(scopes)], openIDScope)
+	}
+	return scopes
+}
+
+// HasScope reports whether the space-delimited scope string contains name.
+func HasScope(scope, name string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseScope splits a space-delimited scope parameter (RFC 6749 §3.3) into
+// de-duplicated scope tokens, keeping the first-seen order.
+func ParseScope(scope string) []string {
+	fields := strings.Fields(scope)
+	scopes := make([]string, 0, len(fields))
+	seen := make(map[string]struct{}, len(fields))
+	for _, s := range fields {
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		scopes = append(scopes, s)
+	}
+	return scopes
+}
+
+// ValidateScope checks every requested scope against the realm's scope catalog
+// and returns the normalized scope string (single-space separated, de-duplicated).
+// An empty scope is valid and yields "".
+func ValidateScope(realm Realm, scope string) (string, error) {
+	catalog := make(map[string]struct{})
+	for _, s := range scopeCatalog(realm) {
+		catalog[s.Name] = struct{}{}
+	}
+
+	scopes := ParseScope(scope)
+	for _, s := range scopes {
+		if _, ok := catalog[s]; !ok {
+			return "", fmt.Errorf("unsupported scope: %s", s)
+		}
+	}
+	return strings.Join(scopes, " "), nil
+}
+
+// ResolveScopeDisplay returns the catalog entries for the given scope string,
+// in request order, for rendering on the consent page.
+// Scopes missing from the catalog are shown by name.
+func ResolveScopeDisplay(realm Realm, scope string) []Scope {
+	catalog := make(map[string]Scope)
+	for _, s := range scopeCatalog(realm) {
+		catalog[s.Name] = s
+	}
+
+	scopes := ParseScope(scope)
+	display := make([]Scope, 0, len(scopes))
+	for _, name := range scopes {
+		if s, ok := catalog[name]; ok {
+			display = append(display, s)
+		} else {
+			display = append(display, Scope{Name: name, DisplayName: name})
+		}
+	}
+	return display
+}
+
+// ScopeNames returns the names in the realm's scope catalog.
+func ScopeNames(realm Realm) []string {
+	scopes := scopeCatalog(realm)
+	names := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		names = append(names, s.Name)
+	}
+	return names
+}
@@ -0,0 +1,108 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+
+	"bkauth/pkg/oauth"
+)
+
+type stubScopeRealm struct{}
+
+func (stubScopeRealm) Name() string        { return "stub" }
+func (stubScopeRealm) TokenPrefix() string { return "stub_" }
+func (stubScopeRealm) Scopes() []oauth.Scope {
+	return []oauth.Scope{{Name: "read", DisplayName: "Read"}, {Name: "write", DisplayName: "Write"}}
+}
+func (stubScopeRealm) ValidateResource(context.Context, string) error { return nil }
+func (stubScopeRealm) ExtractAudiences(context.Context, string) ([]string, error) {
+	return nil, nil
+}
+func (stubScopeRealm) ResolveResourceDisplay(context.Context, string) (any, error) { return nil, nil }
+func (stubScopeRealm) AuthorizationDetailsTypes() []string                         { return nil }
+func (stubScopeRealm) ResolveAuthorizationDetails(context.Context, []oauth.AuthorizationDetail) (string, error) {
+	return "", nil
+}
+
+var _ = Describe("Scope", func() {
+	realm := stubScopeRealm{}
+
+	Describe("ParseScope", func() {
+		DescribeTable("cases",
+			func(scope string, want []string) {
+				assert.Equal(GinkgoT(), want, oauth.ParseScope(scope))
+			},
+			Entry("empty", "", []string{}),
+			Entry("single", "read", []string{"read"}),
+			Entry("extra whitespace", "  read \t write ", []string{"read", "write"}),
+			Entry("duplicates", "write read write", []string{"write", "read"}),
+		)
+	})
+
+	Describe("ValidateScope", func() {
+		DescribeTable("cases",
+			func(scope string, want string, wantOK bool) {
+				got, err := oauth.ValidateScope(realm, scope)
+				if wantOK {
+					assert.NoError(GinkgoT(), err)
+					assert.Equal(GinkgoT(), want, got)
+				} else {
+					assert.Error(GinkgoT(), err)
+				}
+			},
+			Entry("empty scope", "", "", true),
+			Entry("single scope", "read", "read", true),
+			Entry("normalized", " write  read write", "write read", true),
+			Entry("unknown scope", "read admin", "", false),
+		)
+	})
+
+	Describe("ResolveScopeDisplay", func() {
+		It("should resolve display names in request order", func() {
+			display := oauth.ResolveScopeDisplay(realm, "write read")
+			assert.Equal(GinkgoT(), []oauth.Scope{
+				{Name: "write", DisplayName: "Write"},
+				{Name: "read", DisplayName: "Read"},
+			}, display)
+		})
+
+		It("should fall back to the scope name when missing from the catalog", func() {
+			display := oauth.ResolveScopeDisplay(realm, "admin")
+			assert.Equal(GinkgoT(), []oauth.Scope{{Name: "admin", DisplayName: "admin"}}, display)
+		})
+	})
+
+	Describe("HasScope", func() {
+		It("should match whole scope tokens only", func() {
+			assert.True(GinkgoT(), oauth.HasScope("openid read", "openid"))
+			assert.False(GinkgoT(), oauth.HasScope("openidx read", "openid"))
+			assert.False(GinkgoT(), oauth.HasScope("", "openid"))
+		})
+	})
+
+	Describe("ScopeNames", func() {
+		It("should list the catalog names", func() {
+			assert.Equal(GinkgoT(), []string{"read", "write"}, oauth.ScopeNames(realm))
+		})
+	})
+})
@@ -0,0 +1,200 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// SigningKey is a private key used to sign JWTs issued by this server.
+//
+// Rotation: a new key is registered ahead of the old one, and the old key gets a
+// RetiredAt. From RetiredAt on the old key no longer signs, but it stays in the
+// JWKS until PublishUntil, so resource servers can still verify every token it
+// signed. PublishUntil should be RetiredAt + the longest access token TTL.
+type SigningKey struct {
+	KID        string
+	Algorithm  string
+	PrivateKey crypto.Signer
+
+	// RetiredAt is zero for an active key.
+	RetiredAt time.Time
+	// PublishUntil is zero for an active key.
+	PublishUntil time.Time
+}
+
+// isActive reports whether the key may sign new tokens at the given time.
+func (k SigningKey) isActive(now time.Time) bool {
+	return k.RetiredAt.IsZero() || now.Before(k.RetiredAt)
+}
+
+// isPublished reports whether the key must be listed in the JWKS at the given time.
+func (k SigningKey) isPublished(now time.Time) bool {
+	return k.isActive(now) || now.Before(k.PublishUntil)
+}
+
+// signingKeys are the registered signing keys, see RegisterSigningKey.
+// Order matters: the first active key signs new tokens.
+var signingKeys []SigningKey
+
+// ParsePrivateKeyPEM parses a PEM-encoded RSA or EC private key
+// in PKCS#1, PKCS#8 or SEC1 form.
+func ParsePrivateKeyPEM(data string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key does not support signing")
+	}
+	return signer, nil
+}
+
+// resolveSigningAlgorithm checks that alg matches the key type; an empty alg
+// is derived from the key type.
+func resolveSigningAlgorithm(alg string, key crypto.Signer) (string, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if alg == "" {
+			alg = SigningAlgorithmRS256
+		}
+		if alg != SigningAlgorithmRS256 {
+			return "", fmt.Errorf("algorithm %s does not match RSA key", alg)
+		}
+		if k.N.BitLen() < 2048 {
+			return "", errors.New("RSA key must be at least 2048 bits")
+		}
+	case *ecdsa.PrivateKey:
+		if alg == "" {
+			alg = SigningAlgorithmES256
+		}
+		if alg != SigningAlgorithmES256 || k.Curve != elliptic.P256() {
+			return "", fmt.Errorf("algorithm %s does not match EC key, only ES256 with P-256 is supported", alg)
+		}
+	default:
+		return "", errors.New("unsupported key type, only RSA and EC keys are supported")
+	}
+	return alg, nil
+}
+
+// RegisterSigningKey registers a signing key. Must be called during startup
+// (initSigningKeys) before the HTTP server begins accepting requests, as the
+// requests sign and publish with the keys without locking.
+func RegisterSigningKey(key SigningKey) error {
+	if key.KID == "" {
+		return errors.New("kid is required")
+	}
+	if key.PrivateKey == nil {
+		return errors.New("private key is required")
+	}
+	for _, k := range signingKeys {
+		if k.KID == key.KID {
+			return fmt.Errorf("duplicate kid %s", key.KID)
+		}
+	}
+
+	alg, err := resolveSigningAlgorithm(key.Algorithm, key.PrivateKey)
+	if err != nil {
+		return err
+	}
+	key.Algorithm = alg
+
+	signingKeys = append(signingKeys, key)
+	return nil
+}
+
+// ResetSigningKeys unregisters every signing key, for tests outside this package
+// that register their own.
+func ResetSigningKeys() {
+	signingKeys = nil
+}
+
+// ActiveSigningKey returns the key that signs new tokens, i.e. the first registered key not yet retired.
+func ActiveSigningKey() (SigningKey, bool) {
+	now := time.Now()
+	for _, k := range signingKeys {
+		if k.isActive(now) {
+			return k, true
+		}
+	}
+	return SigningKey{}, false
+}
+
+// PublishedJWKS returns the public keys that resource servers need to verify
+// tokens: every active key plus every retired key still within its publish window.
+func PublishedJWKS() jose.JSONWebKeySet {
+	now := time.Now()
+	jwks := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{}}
+	for _, k := range signingKeys {
+		if !k.isPublished(now) {
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jose.JSONWebKey{
+			Key:       k.PrivateKey.Public(),
+			KeyID:     k.KID,
+			Algorithm: k.Algorithm,
+			Use:       "sig",
+		})
+	}
+	return jwks
+}
+
+// SignJWT signs the claims with the active signing key, setting the given "typ" header.
+func SignJWT(typ string, claims any) (string, error) {
+	key, ok := ActiveSigningKey()
+	if !ok {
+		return "", ErrNoActiveSigningKey
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{
+			Algorithm: jose.SignatureAlgorithm(key.Algorithm),
+			Key:       jose.JSONWebKey{Key: key.PrivateKey, KeyID: key.KID},
+		},
+		(&jose.SignerOptions{}).WithType(jose.ContentType(typ)),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return jwt.Signed(signer).Claims(claims).Serialize()
+}
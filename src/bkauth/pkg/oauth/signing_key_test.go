@@ -0,0 +1,241 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type noScopeRealm struct{}
+
+func (noScopeRealm) Name() string                                               { return "no-scope" }
+func (noScopeRealm) TokenPrefix() string                                        { return "ns_" }
+func (noScopeRealm) Scopes() []Scope                                            { return nil }
+func (noScopeRealm) ValidateResource(context.Context, string) error             { return nil }
+func (noScopeRealm) ExtractAudiences(context.Context, string) ([]string, error) { return nil, nil }
+func (noScopeRealm) ResolveResourceDisplay(context.Context, string) (any, error) {
+	return nil, nil
+}
+func (noScopeRealm) AuthorizationDetailsTypes() []string { return nil }
+func (noScopeRealm) ResolveAuthorizationDetails(context.Context, []AuthorizationDetail) (string, error) {
+	return "", nil
+}
+
+var _ = Describe("SigningKey", func() {
+	var rsaKey *rsa.PrivateKey
+	var ecKey *ecdsa.PrivateKey
+
+	BeforeEach(func() {
+		signingKeys = nil
+
+		var err error
+		rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		assert.NoError(GinkgoT(), err)
+		ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.NoError(GinkgoT(), err)
+	})
+
+	AfterEach(func() {
+		signingKeys = nil
+	})
+
+	Describe("ParsePrivateKeyPEM", func() {
+		It("should parse PKCS#1 RSA key", func() {
+			data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+			key, err := ParsePrivateKeyPEM(string(data))
+			assert.NoError(GinkgoT(), err)
+			assert.IsType(GinkgoT(), &rsa.PrivateKey{}, key)
+		})
+
+		It("should parse SEC1 EC key", func() {
+			der, err := x509.MarshalECPrivateKey(ecKey)
+			assert.NoError(GinkgoT(), err)
+			data := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+			key, err := ParsePrivateKeyPEM(string(data))
+			assert.NoError(GinkgoT(), err)
+			assert.IsType(GinkgoT(), &ecdsa.PrivateKey{}, key)
+		})
+
+		It("should parse PKCS#8 key", func() {
+			der, err := x509.MarshalPKCS8PrivateKey(ecKey)
+			assert.NoError(GinkgoT(), err)
+			data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+			_, err = ParsePrivateKeyPEM(string(data))
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("should fail on non-PEM input", func() {
+			_, err := ParsePrivateKeyPEM("not a pem")
+			assert.Error(GinkgoT(), err)
+		})
+	})
+
+	Describe("RegisterSigningKey", func() {
+		It("should derive the algorithm from the key type", func() {
+			assert.NoError(GinkgoT(), RegisterSigningKey(SigningKey{KID: "rsa", PrivateKey: rsaKey}))
+			assert.NoError(GinkgoT(), RegisterSigningKey(SigningKey{KID: "ec", PrivateKey: ecKey}))
+			assert.Equal(GinkgoT(), SigningAlgorithmRS256, signingKeys[0].Algorithm)
+			assert.Equal(GinkgoT(), SigningAlgorithmES256, signingKeys[1].Algorithm)
+		})
+
+		It("should reject mismatched algorithm", func() {
+			err := RegisterSigningKey(SigningKey{KID: "k1", Algorithm: SigningAlgorithmES256, PrivateKey: rsaKey})
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("should reject duplicate kid", func() {
+			assert.NoError(GinkgoT(), RegisterSigningKey(SigningKey{KID: "k1", PrivateKey: rsaKey}))
+			assert.Error(GinkgoT(), RegisterSigningKey(SigningKey{KID: "k1", PrivateKey: ecKey}))
+		})
+
+		It("should reject empty kid", func() {
+			assert.Error(GinkgoT(), RegisterSigningKey(SigningKey{PrivateKey: rsaKey}))
+		})
+	})
+
+	Describe("rotation", func() {
+		It("should sign with the first active key and keep retired keys published", func() {
+			now := time.Now()
+			assert.NoError(GinkgoT(), RegisterSigningKey(SigningKey{KID: "new", PrivateKey: ecKey}))
+			assert.NoError(GinkgoT(), RegisterSigningKey(SigningKey{
+				KID:          "old",
+				PrivateKey:   rsaKey,
+				RetiredAt:    now.Add(-time.Hour),
+				PublishUntil: now.Add(time.Hour),
+			}))
+			assert.NoError(GinkgoT(), RegisterSigningKey(SigningKey{
+				KID:          "expired",
+				PrivateKey:   rsaKey,
+				RetiredAt:    now.Add(-2 * time.Hour),
+				PublishUntil: now.Add(-time.Hour),
+			}))
+
+			key, ok := ActiveSigningKey()
+			assert.True(GinkgoT(), ok)
+			assert.Equal(GinkgoT(), "new", key.KID)
+
+			jwks := PublishedJWKS()
+			assert.Len(GinkgoT(), jwks.Keys, 2)
+			assert.Len(GinkgoT(), jwks.Key("new"), 1)
+			assert.Len(GinkgoT(), jwks.Key("old"), 1)
+			assert.Empty(GinkgoT(), jwks.Key("expired"))
+			assert.True(GinkgoT(), jwks.Keys[0].IsPublic())
+		})
+
+		It("should report no active key when all keys are retired", func() {
+			assert.NoError(GinkgoT(), RegisterSigningKey(SigningKey{
+				KID:          "old",
+				PrivateKey:   rsaKey,
+				RetiredAt:    time.Now().Add(-time.Minute),
+				PublishUntil: time.Now().Add(time.Hour),
+			}))
+
+			_, ok := ActiveSigningKey()
+			assert.False(GinkgoT(), ok)
+
+			_, err := SignJWT(JWTTypeAccessToken, AccessTokenClaims{})
+			assert.ErrorIs(GinkgoT(), err, ErrNoActiveSigningKey)
+		})
+	})
+
+	Describe("SignJWT", func() {
+		It("should produce a JWT verifiable with the published JWKS", func() {
+			assert.NoError(GinkgoT(), RegisterSigningKey(SigningKey{KID: "k1", PrivateKey: rsaKey}))
+
+			token, err := SignJWT(JWTTypeAccessToken, AccessTokenClaims{
+				Issuer:    "https://bkauth.example.com/realms/blueking/oauth2",
+				Subject:   "admin",
+				Audience:  []string{"mcp:foo"},
+				ClientID:  "dcr_abc",
+				JTI:       "jti-001",
+				ExpiresAt: time.Now().Add(time.Hour).Unix(),
+				IssuedAt:  time.Now().Unix(),
+			})
+			assert.NoError(GinkgoT(), err)
+
+			parsed, err := jwt.ParseSigned(token, []jose.SignatureAlgorithm{jose.RS256})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "k1", parsed.Headers[0].KeyID)
+			assert.Equal(GinkgoT(), JWTTypeAccessToken, parsed.Headers[0].ExtraHeaders[jose.HeaderType])
+
+			jwks := PublishedJWKS()
+			var claims AccessTokenClaims
+			assert.NoError(GinkgoT(), parsed.Claims(jwks.Key("k1")[0].Key, &claims))
+			assert.Equal(GinkgoT(), "admin", claims.Subject)
+			assert.Equal(GinkgoT(), []string{"mcp:foo"}, claims.Audience)
+			assert.Equal(GinkgoT(), "dcr_abc", claims.ClientID)
+			assert.Equal(GinkgoT(), "jti-001", claims.JTI)
+		})
+
+		It("should wrap an introspection response verifiable with the published JWKS", func() {
+			assert.NoError(GinkgoT(), RegisterSigningKey(SigningKey{KID: "k1", PrivateKey: ecKey}))
+
+			token, err := SignJWT(JWTTypeTokenIntrospection, TokenIntrospectionClaims{
+				Issuer:             "https://bkauth.example.com/realms/blueking/oauth2",
+				Audience:           "bk_apigateway",
+				IssuedAt:           time.Now().Unix(),
+				TokenIntrospection: map[string]any{"active": true, "client_id": "dcr_abc"},
+			})
+			assert.NoError(GinkgoT(), err)
+
+			parsed, err := jwt.ParseSigned(token, []jose.SignatureAlgorithm{jose.ES256})
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), JWTTypeTokenIntrospection, parsed.Headers[0].ExtraHeaders[jose.HeaderType])
+
+			jwks := PublishedJWKS()
+			var claims struct {
+				Audience           string         `json:"aud"`
+				TokenIntrospection map[string]any `json:"token_introspection"`
+			}
+			assert.NoError(GinkgoT(), parsed.Claims(jwks.Key("k1")[0].Key, &claims))
+			assert.Equal(GinkgoT(), "bk_apigateway", claims.Audience)
+			assert.Equal(GinkgoT(), true, claims.TokenIntrospection["active"])
+			assert.Equal(GinkgoT(), "dcr_abc", claims.TokenIntrospection["client_id"])
+		})
+	})
+
+	Describe("openid scope", func() {
+		It("should be rejected without an active signing key", func() {
+			_, err := ValidateScope(noScopeRealm{}, ScopeOpenID)
+			assert.Error(GinkgoT(), err)
+			assert.Empty(GinkgoT(), ScopeNames(noScopeRealm{}))
+		})
+
+		It("should be offered by every realm once a signing key is active", func() {
+			assert.NoError(GinkgoT(), RegisterSigningKey(SigningKey{KID: "k1", PrivateKey: ecKey}))
+
+			scope, err := ValidateScope(noScopeRealm{}, ScopeOpenID)
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), ScopeOpenID, scope)
+			assert.Equal(GinkgoT(), []string{ScopeOpenID}, ScopeNames(noScopeRealm{}))
+		})
+	})
+})
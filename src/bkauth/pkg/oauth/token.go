@@ -76,6 +76,8 @@ func MaskToken(token string) string {
 }
 
 // HashToken returns a truncated SHA-256 hex digest for storage and lookup.
+// Access tokens, refresh tokens and device codes are stored with HashTokenForStorage instead;
+// this plain digest is their legacy hash.
 // Only the first 16 bytes (128-bit) are kept, yielding a 32-char hex string
 // instead of the full 64 chars — shorter keys improve DB index fan-out and
 // reduce Redis cache key overhead, while 128-bit preimage resistance remains
@@ -105,3 +107,48 @@ func GenerateJTI() string {
 func GenerateGrantID() string {
 	return util.NewUUID()
 }
+
+// AccessTokenClaims is the payload of a JWT access token (RFC 9068 §2.2).
+// The same jti is stored on the access token row, so a JWT access token can still
+// be introspected and revoked like an opaque one.
+type AccessTokenClaims struct {
+	Issuer   string        `json:"iss"`
+	Subject  string        `json:"sub"`
+	Audience []string      `json:"aud"`
+	ClientID string        `json:"client_id"`
+	TenantID string        `json:"tenant_id"`
+	Scope    string        `json:"scope,omitempty"`
+	Act      *Actor        `json:"act,omitempty"`
+	Cnf      *Confirmation `json:"cnf,omitempty"`
+	// AuthorizationDetails is the authorization_details claim (RFC 9396 §9.1)
+	AuthorizationDetails []AuthorizationDetail `json:"authorization_details,omitempty"`
+	JTI                  string                `json:"jti"`
+	ExpiresAt            int64                 `json:"exp"`
+	IssuedAt             int64                 `json:"iat"`
+}
+
+// MaxNonceLength bounds the nonce parameter, which is stored with the authorization code
+// until it is echoed in the id_token.
+const MaxNonceLength = 256
+
+// IDTokenClaims is the payload of an OpenID Connect id_token (OIDC Core §2).
+// The audience is the client the user authenticated to.
+type IDTokenClaims struct {
+	Issuer            string `json:"iss"`
+	Subject           string `json:"sub"`
+	Audience          string `json:"aud"`
+	ExpiresAt         int64  `json:"exp"`
+	IssuedAt          int64  `json:"iat"`
+	Nonce             string `json:"nonce,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	TenantID          string `json:"tenant_id"`
+}
+
+// TokenIntrospectionClaims is the payload of a signed introspection response (RFC 9701 §5).
+// The audience is the app that called the introspection endpoint.
+type TokenIntrospectionClaims struct {
+	Issuer             string `json:"iss"`
+	Audience           string `json:"aud"`
+	IssuedAt           int64  `json:"iat"`
+	TokenIntrospection any    `json:"token_introspection"`
+}
@@ -0,0 +1,94 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MaxActorChainDepth bounds the delegation chain of an exchanged token,
+// which grows by one actor on every exchange.
+const MaxActorChainDepth = 5
+
+// MaxActorLength bounds the JSON of the actor chain, which is stored with the access token.
+const MaxActorLength = 1024
+
+// Actor is the "act" claim of an exchanged token (RFC 8693 §4.1): the client
+// that is acting on behalf of the subject. Prior actors of a delegation chain
+// nest inside Act, the outermost one being the current actor.
+type Actor struct {
+	ClientID string `json:"client_id"`
+	Act      *Actor `json:"act,omitempty"`
+}
+
+// NewActor returns the actor claim for clientID acting on a token that was
+// itself acting through prior (nil when the subject token was not exchanged).
+func NewActor(clientID string, prior *Actor) *Actor {
+	return &Actor{ClientID: clientID, Act: prior}
+}
+
+// Depth returns the number of actors in the chain, 0 for nil.
+func (a *Actor) Depth() int {
+	depth := 0
+	for ; a != nil; a = a.Act {
+		depth++
+	}
+	return depth
+}
+
+// ValidateActor checks the actor chain of a token to issue against MaxActorChainDepth
+// and MaxActorLength.
+func ValidateActor(act *Actor) error {
+	if act.Depth() > MaxActorChainDepth {
+		return fmt.Errorf("delegation chain must not exceed %d actors", MaxActorChainDepth)
+	}
+	data, err := json.Marshal(act)
+	if err != nil {
+		return err
+	}
+	if len(data) > MaxActorLength {
+		return fmt.Errorf("delegation chain must not exceed %d bytes", MaxActorLength)
+	}
+	return nil
+}
+
+// IsAudienceSubset reports whether every requested audience is present in allowed.
+// An exchanged token may narrow the subject token's audience but never widen it.
+func IsAudienceSubset(requested, allowed []string) bool {
+	return isSubset(requested, allowed)
+}
+
+// IsScopeSubset reports whether every scope in requested is also in granted.
+func IsScopeSubset(requested, granted string) bool {
+	return isSubset(ParseScope(requested), ParseScope(granted))
+}
+
+func isSubset(sub, super []string) bool {
+	set := make(map[string]struct{}, len(super))
+	for _, s := range super {
+		set[s] = struct{}{}
+	}
+	for _, s := range sub {
+		if _, ok := set[s]; !ok {
+			return false
+		}
+	}
+	return true
+}
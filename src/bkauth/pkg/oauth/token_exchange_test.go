@@ -0,0 +1,89 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+
+	"bkauth/pkg/oauth"
+)
+
+var _ = Describe("TokenExchange", func() {
+	Describe("NewActor", func() {
+		It("should nest the prior actor", func() {
+			act := oauth.NewActor("mcp-2", oauth.NewActor("mcp-1", nil))
+
+			b, err := json.Marshal(act)
+			assert.NoError(GinkgoT(), err)
+			assert.JSONEq(GinkgoT(), `{"client_id":"mcp-2","act":{"client_id":"mcp-1"}}`, string(b))
+		})
+	})
+
+	Describe("ValidateActor", func() {
+		chain := func(depth int, clientID string) *oauth.Actor {
+			var act *oauth.Actor
+			for i := 0; i < depth; i++ {
+				act = oauth.NewActor(clientID, act)
+			}
+			return act
+		}
+
+		It("should accept a chain at the maximum depth", func() {
+			act := chain(oauth.MaxActorChainDepth, "mcp-1")
+			assert.Equal(GinkgoT(), oauth.MaxActorChainDepth, act.Depth())
+			assert.NoError(GinkgoT(), oauth.ValidateActor(act))
+		})
+
+		It("should reject a deeper chain", func() {
+			assert.Error(GinkgoT(), oauth.ValidateActor(chain(oauth.MaxActorChainDepth+1, "mcp-1")))
+		})
+
+		It("should reject a chain longer than the stored column", func() {
+			act := chain(oauth.MaxActorChainDepth, "https://mcp.example.com/"+strings.Repeat("c", 200))
+			assert.Error(GinkgoT(), oauth.ValidateActor(act))
+		})
+	})
+
+	Describe("IsAudienceSubset", func() {
+		DescribeTable("cases",
+			func(requested, allowed []string, want bool) {
+				assert.Equal(GinkgoT(), want, oauth.IsAudienceSubset(requested, allowed))
+			},
+			Entry("equal", []string{"a", "b"}, []string{"b", "a"}, true),
+			Entry("narrower", []string{"a"}, []string{"a", "b"}, true),
+			Entry("empty requested", []string{}, []string{"a"}, true),
+			Entry("wider", []string{"a", "c"}, []string{"a", "b"}, false),
+		)
+	})
+
+	Describe("IsScopeSubset", func() {
+		DescribeTable("cases",
+			func(requested, granted string, want bool) {
+				assert.Equal(GinkgoT(), want, oauth.IsScopeSubset(requested, granted))
+			},
+			Entry("narrower", "read", "read write", true),
+			Entry("empty requested", "", "read", true),
+			Entry("wider", "read write", "read", false),
+		)
+	})
+})
@@ -0,0 +1,109 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+const (
+	// LegacyTokenHashKeyID is the key id of the hashes stored before HMAC keys were configured,
+	// which are the plain SHA-256 digests of HashToken.
+	LegacyTokenHashKeyID = ""
+
+	minTokenHashSecretLength = 32
+)
+
+// TokenHashKey is a server-side secret ("pepper") keying the HMAC of the stored token hashes,
+// so that a leaked token table cannot be used to confirm a token offline.
+type TokenHashKey struct {
+	ID     string
+	Secret []byte
+}
+
+// TokenHash is the hash of a token together with the id of the key it was computed with.
+type TokenHash struct {
+	KeyID string
+	Hash  string
+}
+
+// tokenHashKeys are the registered HMAC keys, see RegisterTokenHashKey.
+// Order matters: the first key hashes new tokens, the others are only used for lookups.
+var tokenHashKeys []TokenHashKey
+
+// RegisterTokenHashKey registers an HMAC key of the token hashes. Must be called during startup
+// (initTokenHashKeys) before the HTTP server begins accepting requests: the keys are registered
+// from a single goroutine and only read afterwards, which is why they are not guarded by a mutex.
+func RegisterTokenHashKey(key TokenHashKey) error {
+	if key.ID == "" {
+		return errors.New("id is required")
+	}
+	if len(key.Secret) < minTokenHashSecretLength {
+		return fmt.Errorf("secret must be at least %d bytes", minTokenHashSecretLength)
+	}
+	for _, k := range tokenHashKeys {
+		if k.ID == key.ID {
+			return fmt.Errorf("duplicate id %s", key.ID)
+		}
+	}
+
+	tokenHashKeys = append(tokenHashKeys, key)
+	return nil
+}
+
+// hmacToken returns the HMAC-SHA256 of the token, truncated like HashToken.
+func hmacToken(key TokenHashKey, token string) string {
+	mac := hmac.New(sha256.New, key.Secret)
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// HashTokenForStorage returns the hash under which a new token is stored: the HMAC with the
+// current key, or the legacy SHA-256 digest while no key is configured.
+func HashTokenForStorage(token string) TokenHash {
+	if len(tokenHashKeys) == 0 {
+		return TokenHash{KeyID: LegacyTokenHashKeyID, Hash: HashToken(token)}
+	}
+	key := tokenHashKeys[0]
+	return TokenHash{KeyID: key.ID, Hash: hmacToken(key, token)}
+}
+
+// TokenHashCandidates returns every hash a stored token may have been saved under, the current
+// one first, then those of the previous keys, then the legacy SHA-256 digest, which stays
+// accepted until the tokens stored before the HMAC keys have expired.
+func TokenHashCandidates(token string) []TokenHash {
+	candidates := make([]TokenHash, 0, len(tokenHashKeys)+1)
+	for _, key := range tokenHashKeys {
+		candidates = append(candidates, TokenHash{KeyID: key.ID, Hash: hmacToken(key, token)})
+	}
+	return append(candidates, TokenHash{KeyID: LegacyTokenHashKeyID, Hash: HashToken(token)})
+}
+
+// TokenHashValues returns the hash values of the candidates, for a lookup by any of them.
+func TokenHashValues(candidates []TokenHash) []string {
+	values := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		values = append(values, c.Hash)
+	}
+	return values
+}
@@ -0,0 +1,109 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package oauth
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ = Describe("TokenHash", func() {
+	key1 := TokenHashKey{ID: "k1", Secret: []byte(strings.Repeat("1", 32))}
+	key2 := TokenHashKey{ID: "k2", Secret: []byte(strings.Repeat("2", 32))}
+
+	BeforeEach(func() {
+		tokenHashKeys = nil
+	})
+
+	AfterEach(func() {
+		tokenHashKeys = nil
+	})
+
+	Describe("RegisterTokenHashKey", func() {
+		It("should reject a key without id", func() {
+			err := RegisterTokenHashKey(TokenHashKey{Secret: key1.Secret})
+			assert.ErrorContains(GinkgoT(), err, "id is required")
+		})
+
+		It("should reject a short secret", func() {
+			err := RegisterTokenHashKey(TokenHashKey{ID: "k1", Secret: []byte("short")})
+			assert.ErrorContains(GinkgoT(), err, "at least 32 bytes")
+		})
+
+		It("should reject a duplicate id", func() {
+			assert.NoError(GinkgoT(), RegisterTokenHashKey(key1))
+			err := RegisterTokenHashKey(TokenHashKey{ID: "k1", Secret: key2.Secret})
+			assert.ErrorContains(GinkgoT(), err, "duplicate id k1")
+		})
+	})
+
+	Describe("HashTokenForStorage", func() {
+		It("should use the legacy SHA-256 hash without keys", func() {
+			hash := HashTokenForStorage("bk_token")
+			assert.Equal(GinkgoT(), TokenHash{KeyID: LegacyTokenHashKeyID, Hash: HashToken("bk_token")}, hash)
+		})
+
+		It("should use the HMAC of the first key", func() {
+			assert.NoError(GinkgoT(), RegisterTokenHashKey(key1))
+			assert.NoError(GinkgoT(), RegisterTokenHashKey(key2))
+
+			hash := HashTokenForStorage("bk_token")
+
+			assert.Equal(GinkgoT(), "k1", hash.KeyID)
+			assert.Len(GinkgoT(), hash.Hash, 32)
+			assert.NotEqual(GinkgoT(), HashToken("bk_token"), hash.Hash)
+			assert.NotEqual(GinkgoT(), HashTokenForStorage("bk_other"), hash)
+		})
+
+		It("should depend on the secret", func() {
+			assert.NoError(GinkgoT(), RegisterTokenHashKey(key1))
+			hash1 := HashTokenForStorage("bk_token")
+
+			tokenHashKeys = nil
+			assert.NoError(GinkgoT(), RegisterTokenHashKey(TokenHashKey{ID: "k1", Secret: key2.Secret}))
+			hash2 := HashTokenForStorage("bk_token")
+
+			assert.NotEqual(GinkgoT(), hash1.Hash, hash2.Hash)
+		})
+	})
+
+	Describe("TokenHashCandidates", func() {
+		It("should only contain the legacy hash without keys", func() {
+			candidates := TokenHashCandidates("bk_token")
+			assert.Equal(GinkgoT(), []TokenHash{{Hash: HashToken("bk_token")}}, candidates)
+		})
+
+		It("should list the current key, the previous keys and the legacy hash in order", func() {
+			assert.NoError(GinkgoT(), RegisterTokenHashKey(key2))
+			previous := HashTokenForStorage("bk_token")
+			tokenHashKeys = nil
+			assert.NoError(GinkgoT(), RegisterTokenHashKey(key1))
+			current := HashTokenForStorage("bk_token")
+			assert.NoError(GinkgoT(), RegisterTokenHashKey(key2))
+
+			candidates := TokenHashCandidates("bk_token")
+
+			assert.Equal(GinkgoT(), []TokenHash{current, previous, {Hash: HashToken("bk_token")}}, candidates)
+			assert.Equal(GinkgoT(),
+				[]string{current.Hash, previous.Hash, HashToken("bk_token")}, TokenHashValues(candidates))
+		})
+	})
+})
@@ -36,6 +36,10 @@ func TokenEndpointURL(baseURL, realmName string) string {
 	return util.URLJoin(baseURL, realmBasePath(realmName), "token")
 }
 
+func PushedAuthorizationRequestEndpointURL(baseURL, realmName string) string {
+	return util.URLJoin(baseURL, realmBasePath(realmName), "par")
+}
+
 func DeviceAuthorizationEndpointURL(baseURL, realmName string) string {
 	return util.URLJoin(baseURL, realmBasePath(realmName), "device", "authorize")
 }
@@ -55,3 +59,15 @@ func RevocationEndpointURL(baseURL, realmName string) string {
 func RegistrationEndpointURL(baseURL, realmName string) string {
 	return util.URLJoin(baseURL, realmBasePath(realmName), "register")
 }
+
+func RegistrationClientURL(baseURL, realmName, clientID string) string {
+	return util.URLJoin(baseURL, realmBasePath(realmName), "register", clientID)
+}
+
+func UserInfoEndpointURL(baseURL, realmName string) string {
+	return util.URLJoin(baseURL, realmBasePath(realmName), "userinfo")
+}
+
+func JWKSURL(baseURL, realmName string) string {
+	return util.URLJoin(baseURL, realmBasePath(realmName), "jwks.json")
+}
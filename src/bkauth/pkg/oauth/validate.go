@@ -19,9 +19,14 @@
 package oauth
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"strings"
+
+	"github.com/go-jose/go-jose/v4"
 )
 
 // ValidateGrantTypes checks that every element is a server-supported grant type.
@@ -52,3 +57,83 @@ func ValidateLogoURI(raw string) error {
 
 	return nil
 }
+
+// ValidateJWKSURI checks that the URI is a valid https URL (RFC 7591 §2).
+func ValidateJWKSURI(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("jwks_uri is not a valid URL: %s", raw)
+	}
+
+	if strings.ToLower(parsed.Scheme) != "https" {
+		return fmt.Errorf("jwks_uri must use https scheme: %s", raw)
+	}
+
+	if parsed.Host == "" {
+		return fmt.Errorf("jwks_uri must have a host: %s", raw)
+	}
+
+	return nil
+}
+
+// ValidateJWKS checks that the document is a JWK Set of public keys only.
+func ValidateJWKS(raw string) error {
+	var keySet jose.JSONWebKeySet
+	if err := json.Unmarshal([]byte(raw), &keySet); err != nil {
+		return fmt.Errorf("jwks is not a valid JWK Set: %s", err.Error())
+	}
+
+	if len(keySet.Keys) == 0 {
+		return errors.New("jwks must contain at least one key")
+	}
+
+	for _, key := range keySet.Keys {
+		if !key.Valid() || !key.IsPublic() {
+			return fmt.Errorf("jwks must only contain public keys: kid=%s", key.KeyID)
+		}
+	}
+
+	return nil
+}
+
+// ValidateSelfSignedJWKS checks that the document is a JWK Set of public keys, at least one of which
+// carries the "x5c" certificate a self_signed_tls_client_auth client presents (RFC 8705 §2.2).
+func ValidateSelfSignedJWKS(raw string) error {
+	if err := ValidateJWKS(raw); err != nil {
+		return err
+	}
+
+	var keySet jose.JSONWebKeySet
+	_ = json.Unmarshal([]byte(raw), &keySet)
+	for _, key := range keySet.Keys {
+		if len(key.Certificates) > 0 {
+			return nil
+		}
+	}
+	return errors.New("jwks must contain a key with an x5c certificate")
+}
+
+// ValidateTLSClientAuthSubject checks the registered subject of a tls_client_auth client
+// (RFC 8705 §2.1.2): exactly one of the subject DN (RFC 4514 string) and a subject alternative
+// name, i.e. a dNSName, URI, IP address or email.
+func ValidateTLSClientAuthSubject(subjectDN, san string) error {
+	if (subjectDN == "") == (san == "") {
+		return errors.New("exactly one of tls_client_auth_subject_dn and tls_client_auth_san is required")
+	}
+	if subjectDN != "" {
+		if strings.TrimSpace(subjectDN) != subjectDN {
+			return fmt.Errorf("tls_client_auth_subject_dn must not have surrounding spaces: %s", subjectDN)
+		}
+		return nil
+	}
+
+	if strings.ContainsAny(san, " \t\r\n") {
+		return fmt.Errorf("tls_client_auth_san must not contain spaces: %s", san)
+	}
+	if net.ParseIP(san) == nil && strings.Contains(san, "://") {
+		if parsed, err := url.Parse(san); err != nil || parsed.Scheme == "" {
+			return fmt.Errorf("tls_client_auth_san is not a valid URI: %s", san)
+		}
+	}
+	return nil
+}
@@ -19,6 +19,10 @@
 package oauth_test
 
 import (
+	"crypto/x509"
+	"encoding/json"
+
+	"github.com/go-jose/go-jose/v4"
 	. "github.com/onsi/ginkgo/v2"
 	"github.com/stretchr/testify/assert"
 
@@ -44,8 +48,10 @@ var _ = Describe("Validate", func() {
 				[]string{"urn:ietf:params:oauth:grant-type:device_code"}, true),
 			Entry("all supported",
 				[]string{"authorization_code", "refresh_token", "urn:ietf:params:oauth:grant-type:device_code"}, true),
+			Entry("single client_credentials",
+				[]string{"client_credentials"}, true),
 			Entry("unsupported grant type",
-				[]string{"client_credentials"}, false),
+				[]string{"password"}, false),
 			Entry("mix valid and invalid",
 				[]string{"authorization_code", "implicit"}, false),
 			Entry("empty string element",
@@ -83,4 +89,95 @@ var _ = Describe("Validate", func() {
 				"myapp://logo", false),
 		)
 	})
+
+	Describe("ValidateJWKSURI", func() {
+		DescribeTable("cases",
+			func(uri string, wantOK bool) {
+				err := oauth.ValidateJWKSURI(uri)
+				if wantOK {
+					assert.NoError(GinkgoT(), err)
+				} else {
+					assert.Error(GinkgoT(), err)
+				}
+			},
+			Entry("https",
+				"https://client.example.com/jwks.json", true),
+			Entry("http",
+				"http://client.example.com/jwks.json", false),
+			Entry("no host",
+				"https:///jwks.json", false),
+		)
+	})
+
+	Describe("ValidateJWKS", func() {
+		DescribeTable("cases",
+			func(jwks string, wantOK bool) {
+				err := oauth.ValidateJWKS(jwks)
+				if wantOK {
+					assert.NoError(GinkgoT(), err)
+				} else {
+					assert.Error(GinkgoT(), err)
+				}
+			},
+			Entry("public EC key",
+				`{"keys":[{"kty":"EC","crv":"P-256",`+
+					`"x":"f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU",`+
+					`"y":"x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0"}]}`, true),
+			Entry("private EC key",
+				`{"keys":[{"kty":"EC","crv":"P-256",`+
+					`"x":"f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU",`+
+					`"y":"x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0",`+
+					`"d":"jpsQnnGQmL-YBIffH1136cspYG6-0iY7X1fCE9-E9LI"}]}`, false),
+			Entry("empty key set",
+				`{"keys":[]}`, false),
+			Entry("not JSON",
+				`not-json`, false),
+		)
+	})
+
+	Describe("ValidateSelfSignedJWKS", func() {
+		It("should accept a key with an x5c certificate", func() {
+			cert, key := newTestCertificate("client1", false, nil, nil)
+			data, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+				Key:          &key.PublicKey,
+				Certificates: []*x509.Certificate{cert},
+			}}})
+			assert.NoError(GinkgoT(), err)
+			assert.NoError(GinkgoT(), oauth.ValidateSelfSignedJWKS(string(data)))
+		})
+
+		It("should reject keys without a certificate", func() {
+			_, key := newTestCertificate("client1", false, nil, nil)
+			data, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &key.PublicKey}}})
+			assert.NoError(GinkgoT(), err)
+			assert.Error(GinkgoT(), oauth.ValidateSelfSignedJWKS(string(data)))
+		})
+
+		It("should reject an invalid key set", func() {
+			assert.Error(GinkgoT(), oauth.ValidateSelfSignedJWKS(`{"keys":[]}`))
+		})
+	})
+
+	Describe("ValidateTLSClientAuthSubject", func() {
+		DescribeTable("cases",
+			func(subjectDN, san string, wantOK bool) {
+				err := oauth.ValidateTLSClientAuthSubject(subjectDN, san)
+				if wantOK {
+					assert.NoError(GinkgoT(), err)
+				} else {
+					assert.Error(GinkgoT(), err)
+				}
+			},
+			Entry("subject DN", "CN=client1,O=BlueKing", "", true),
+			Entry("DNS SAN", "", "client1.example.com", true),
+			Entry("IP SAN", "", "10.0.0.1", true),
+			Entry("URI SAN", "", "spiffe://example.com/client1", true),
+			Entry("email SAN", "", "client1@example.com", true),
+			Entry("neither", "", "", false),
+			Entry("both", "CN=client1", "client1.example.com", false),
+			Entry("subject DN with surrounding spaces", " CN=client1", "", false),
+			Entry("SAN with spaces", "", "client1 .example.com", false),
+			Entry("URI SAN without scheme", "", "://example.com/client1", false),
+		)
+	})
 })
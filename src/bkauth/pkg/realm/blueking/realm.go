@@ -48,6 +48,14 @@ type ResourceGroup struct {
 
 const Name = "blueking"
 
+// Authorization details types of the blueking realm (RFC 9396 §2):
+//   - bk_gateway_api: identifier is the gateway name, actions are the names of its APIs, "*" for all.
+//   - bk_mcp_server: identifier is the MCP server name, it takes no actions.
+const (
+	AuthorizationDetailsTypeGatewayAPI = "bk_gateway_api"
+	AuthorizationDetailsTypeMCPServer  = "bk_mcp_server"
+)
+
 type bluekingRealm struct {
 	mcpServerClient bkapigateway.MCPServerClient
 }
@@ -59,8 +67,9 @@ func New() oauth.Realm {
 	}
 }
 
-func (r *bluekingRealm) Name() string        { return Name }
-func (r *bluekingRealm) TokenPrefix() string { return "bk_" }
+func (r *bluekingRealm) Name() string          { return Name }
+func (r *bluekingRealm) TokenPrefix() string   { return "bk_" }
+func (r *bluekingRealm) Scopes() []oauth.Scope { return oauth.DefaultScopes }
 
 var mcpServerNameRegex = regexp.MustCompile(`/mcp-servers/([^/]+)`)
 
@@ -157,6 +166,61 @@ func (r *bluekingRealm) ExtractAudiences(_ context.Context, resource string) ([]
 	return audiences, nil
 }
 
+// isSingleResourceItem reports whether item comes out of the comma-separated resource list unchanged.
+func isSingleResourceItem(item string) bool {
+	items := util.SplitCommaList(item)
+	return len(items) == 1 && items[0] == item
+}
+
+func (r *bluekingRealm) AuthorizationDetailsTypes() []string {
+	return []string{AuthorizationDetailsTypeGatewayAPI, AuthorizationDetailsTypeMCPServer}
+}
+
+// ResolveAuthorizationDetails renders the details as gateway:<name>:api:<api> and mcp:<name> items,
+// so that the consent page lists the approved APIs and the audiences are the gateways and MCP servers.
+func (r *bluekingRealm) ResolveAuthorizationDetails(
+	_ context.Context, details []oauth.AuthorizationDetail,
+) (string, error) {
+	var items []string
+	for _, detail := range details {
+		if detail.Identifier == "" {
+			return "", fmt.Errorf("invalid authorization details: identifier is required for %s", detail.Type)
+		}
+
+		switch detail.Type {
+		case AuthorizationDetailsTypeGatewayAPI:
+			if len(detail.Actions) == 0 {
+				return "", fmt.Errorf(
+					"invalid authorization details: actions must list the APIs of gateway %q", detail.Identifier,
+				)
+			}
+			for _, api := range detail.Actions {
+				item := "gateway:" + detail.Identifier + ":api:" + api
+				// the identifier and API names must come back unchanged, e.g. no "," or ":api:" in them
+				resType, name, apiName, err := parseBluekingResource(item)
+				if err != nil || resType != "gateway" || name != detail.Identifier || apiName != api ||
+					!isSingleResourceItem(item) {
+					return "", fmt.Errorf("invalid authorization details: invalid API %q of gateway %q",
+						api, detail.Identifier)
+				}
+				items = append(items, item)
+			}
+		case AuthorizationDetailsTypeMCPServer:
+			if len(detail.Actions) != 0 {
+				return "", fmt.Errorf("invalid authorization details: %s takes no actions", detail.Type)
+			}
+			item := "mcp:" + detail.Identifier
+			if !isSingleResourceItem(item) {
+				return "", fmt.Errorf("invalid authorization details: invalid MCP server %q", detail.Identifier)
+			}
+			items = append(items, item)
+		default:
+			return "", fmt.Errorf("invalid authorization details: unsupported type %q", detail.Type)
+		}
+	}
+	return strings.Join(items, ","), nil
+}
+
 func (r *bluekingRealm) ResolveResourceDisplay(ctx context.Context, resource string) (any, error) {
 	items := util.SplitCommaList(resource)
 	if len(items) == 0 {
@@ -27,6 +27,12 @@ var _ = Describe("bluekingRealm", func() {
 		})
 	})
 
+	Describe("Scopes", func() {
+		It("should expose read and write", func() {
+			assert.Equal(GinkgoT(), []string{"read", "write"}, oauth.ScopeNames(r))
+		})
+	})
+
 	Describe("ValidateResource", func() {
 		It("should accept valid MCP resource", func() {
 			assert.NoError(GinkgoT(), r.ValidateResource(ctx, "mcp:s1"))
@@ -89,6 +95,48 @@ var _ = Describe("bluekingRealm", func() {
 		})
 	})
 
+	Describe("ResolveAuthorizationDetails", func() {
+		It("should render gateway APIs and MCP servers as resource items", func() {
+			resource, err := r.ResolveAuthorizationDetails(ctx, []oauth.AuthorizationDetail{
+				{Type: "bk_gateway_api", Identifier: "gw", Actions: []string{"get_host", "list_hosts"}},
+				{Type: "bk_mcp_server", Identifier: "s1"},
+			})
+			require.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), "gateway:gw:api:get_host,gateway:gw:api:list_hosts,mcp:s1", resource)
+		})
+
+		It("should error on a gateway without APIs", func() {
+			_, err := r.ResolveAuthorizationDetails(ctx, []oauth.AuthorizationDetail{
+				{Type: "bk_gateway_api", Identifier: "gw"},
+			})
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("should error on a missing identifier", func() {
+			_, err := r.ResolveAuthorizationDetails(ctx, []oauth.AuthorizationDetail{{Type: "bk_mcp_server"}})
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("should error on names that change the resource items", func() {
+			_, err := r.ResolveAuthorizationDetails(ctx, []oauth.AuthorizationDetail{
+				{Type: "bk_gateway_api", Identifier: "gw", Actions: []string{"a,gateway:other:api:*"}},
+			})
+			assert.Error(GinkgoT(), err)
+
+			_, err = r.ResolveAuthorizationDetails(ctx, []oauth.AuthorizationDetail{
+				{Type: "bk_gateway_api", Identifier: "gw:api:x", Actions: []string{"y"}},
+			})
+			assert.Error(GinkgoT(), err)
+		})
+
+		It("should error on actions of an MCP server", func() {
+			_, err := r.ResolveAuthorizationDetails(ctx, []oauth.AuthorizationDetail{
+				{Type: "bk_mcp_server", Identifier: "s1", Actions: []string{"call"}},
+			})
+			assert.Error(GinkgoT(), err)
+		})
+	})
+
 	Describe("ResolveResourceDisplay", func() {
 		It("should parse a single MCP resource", func() {
 			display, err := r.ResolveResourceDisplay(ctx, "mcp:bk-cmdb-mcp-server")
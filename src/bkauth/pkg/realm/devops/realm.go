@@ -61,8 +61,9 @@ func New() oauth.Realm {
 	return &devopsRealm{}
 }
 
-func (r *devopsRealm) Name() string        { return Name }
-func (r *devopsRealm) TokenPrefix() string { return "bkci_" }
+func (r *devopsRealm) Name() string          { return Name }
+func (r *devopsRealm) TokenPrefix() string   { return "bkci_" }
+func (r *devopsRealm) Scopes() []oauth.Scope { return oauth.DefaultScopes }
 
 func parseServiceItem(item string) (string, error) {
 	if !strings.HasPrefix(item, "service:") {
@@ -133,3 +134,10 @@ func (r *devopsRealm) ResolveResourceDisplay(_ context.Context, resource string)
 		Items:       serviceItems,
 	}}, nil
 }
+
+// AuthorizationDetailsTypes returns none: the devops realm is only requested with the resource parameter.
+func (r *devopsRealm) AuthorizationDetailsTypes() []string { return nil }
+
+func (r *devopsRealm) ResolveAuthorizationDetails(_ context.Context, _ []oauth.AuthorizationDetail) (string, error) {
+	return "", fmt.Errorf("authorization_details is not supported by realm %q", Name)
+}
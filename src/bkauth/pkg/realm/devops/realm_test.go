@@ -25,6 +25,12 @@ var _ = Describe("devopsRealm", func() {
 		})
 	})
 
+	Describe("Scopes", func() {
+		It("should expose read and write", func() {
+			assert.Equal(GinkgoT(), []string{"read", "write"}, oauth.ScopeNames(r))
+		})
+	})
+
 	Describe("ValidateResource", func() {
 		It("should accept valid service resource", func() {
 			assert.NoError(GinkgoT(), r.ValidateResource(ctx, "service:codecc"))
@@ -87,4 +93,12 @@ var _ = Describe("devopsRealm", func() {
 			assert.Error(GinkgoT(), err)
 		})
 	})
+
+	Describe("ResolveAuthorizationDetails", func() {
+		It("should not support authorization details", func() {
+			assert.Empty(GinkgoT(), r.AuthorizationDetailsTypes())
+			_, err := r.ResolveAuthorizationDetails(ctx, []oauth.AuthorizationDetail{{Type: "bk_mcp_server"}})
+			assert.Error(GinkgoT(), err)
+		})
+	})
 })
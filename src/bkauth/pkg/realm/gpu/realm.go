@@ -54,6 +54,9 @@ func New() oauth.Realm {
 func (r *gpuRealm) Name() string        { return Name }
 func (r *gpuRealm) TokenPrefix() string { return "bkgpu_" }
 
+// Scopes returns no catalog: the gpu realm only grants the all-or-nothing resource:all.
+func (r *gpuRealm) Scopes() []oauth.Scope { return nil }
+
 func (r *gpuRealm) ValidateResource(_ context.Context, resource string) error {
 	if resource != validResource {
 		return fmt.Errorf("invalid resource: must be %q, got %q", validResource, resource)
@@ -81,3 +84,10 @@ func (r *gpuRealm) ResolveResourceDisplay(_ context.Context, resource string) (a
 		}},
 	}}, nil
 }
+
+// AuthorizationDetailsTypes returns none: the gpu realm is only requested with the resource parameter.
+func (r *gpuRealm) AuthorizationDetailsTypes() []string { return nil }
+
+func (r *gpuRealm) ResolveAuthorizationDetails(_ context.Context, _ []oauth.AuthorizationDetail) (string, error) {
+	return "", fmt.Errorf("authorization_details is not supported by realm %q", Name)
+}
@@ -25,6 +25,12 @@ var _ = Describe("gpuRealm", func() {
 		})
 	})
 
+	Describe("Scopes", func() {
+		It("should have an empty catalog", func() {
+			assert.Empty(GinkgoT(), r.Scopes())
+		})
+	})
+
 	Describe("ValidateResource", func() {
 		It("should accept resource:all", func() {
 			assert.NoError(GinkgoT(), r.ValidateResource(ctx, "resource:all"))
@@ -70,4 +76,12 @@ var _ = Describe("gpuRealm", func() {
 			assert.Error(GinkgoT(), err)
 		})
 	})
+
+	Describe("ResolveAuthorizationDetails", func() {
+		It("should not support authorization details", func() {
+			assert.Empty(GinkgoT(), r.AuthorizationDetailsTypes())
+			_, err := r.ResolveAuthorizationDetails(ctx, []oauth.AuthorizationDetail{{Type: "bk_mcp_server"}})
+			assert.Error(GinkgoT(), err)
+		})
+	})
 })
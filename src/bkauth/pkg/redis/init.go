@@ -35,8 +35,13 @@ const (
 	ModeSentinel   = "sentinel"
 )
 
+// DefaultChannelKey is the pub/sub channel of the broadcasts when redis.channelKey is not configured
+const DefaultChannelKey = "bkauth:channel"
+
 var rds *redis.Client
 
+var channelKey string
+
 var redisClientInitOnce sync.Once
 
 // InitRedisClient ...
@@ -53,6 +58,7 @@ func InitRedisClient(debugMode bool, redisConfig *config.Redis) {
 			}
 
 			rds.AddHook(redisotel.NewTracingHook())
+			channelKey = redisConfig.ChannelKey
 
 			_, err := rds.Ping(context.TODO()).Result()
 			if err != nil {
@@ -70,3 +76,11 @@ func InitRedisClient(debugMode bool, redisConfig *config.Redis) {
 func GetDefaultRedisClient() *redis.Client {
 	return rds
 }
+
+// GetChannelKey returns the pub/sub channel on which the replicas broadcast to each other
+func GetChannelKey() string {
+	if channelKey == "" {
+		return DefaultChannelKey
+	}
+	return channelKey
+}
@@ -32,6 +32,14 @@ func TestGetDefaultRedisClient(t *testing.T) {
 	assert.Nil(t, rds)
 }
 
+func TestGetChannelKey(t *testing.T) {
+	assert.Equal(t, DefaultChannelKey, GetChannelKey())
+
+	channelKey = "bkauth:test:channel"
+	defer func() { channelKey = "" }()
+	assert.Equal(t, "bkauth:test:channel", GetChannelKey())
+}
+
 func TestInitRedisClient(t *testing.T) {
 	// wrong config
 	redisConfig := &config.Redis{
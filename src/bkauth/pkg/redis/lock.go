@@ -0,0 +1,80 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"bkauth/pkg/util"
+)
+
+// acquireLockScript extends the lease if it is held by the owner, or takes it if nobody holds it
+var acquireLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 1
+end
+return 0`)
+
+// releaseLockScript deletes the lease only if it is still held by the owner
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Lock is a lease on a redis key, held by at most one owner at a time.
+// It is used for leader election between the replicas: the one holding the lease
+// does the work and keeps extending it; when it stops, the lease expires and
+// another replica takes over.
+type Lock struct {
+	cli   *redis.Client
+	key   string
+	owner string
+	ttl   time.Duration
+}
+
+// NewLock creates a lock on key with a random owner, the lease expires after ttl unless extended.
+func NewLock(cli *redis.Client, key string, ttl time.Duration) *Lock {
+	return &Lock{
+		cli:   cli,
+		key:   key,
+		owner: util.NewUUIDHex(),
+		ttl:   ttl,
+	}
+}
+
+// Acquire takes the lease, or extends it if already held, and reports whether it is held.
+func (l *Lock) Acquire(ctx context.Context) (bool, error) {
+	n, err := acquireLockScript.Run(ctx, l.cli, []string{l.key}, l.owner, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// Release gives the lease up if it is still held, so that another owner may take it at once.
+func (l *Lock) Release(ctx context.Context) error {
+	return releaseLockScript.Run(ctx, l.cli, []string{l.key}, l.owner).Err()
+}
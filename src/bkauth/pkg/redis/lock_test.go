@@ -0,0 +1,74 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLock(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	defer mr.Close()
+
+	cli := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	leader := NewLock(cli, "bkauth:lock:test", time.Minute)
+	follower := NewLock(cli, "bkauth:lock:test", time.Minute)
+
+	ok, err := leader.Acquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// only one owner holds the lease
+	ok, err = follower.Acquire(ctx)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// the leader extends its own lease
+	ok, err = leader.Acquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// releasing by a non owner does nothing
+	assert.NoError(t, follower.Release(ctx))
+	ok, err = follower.Acquire(ctx)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// the lease is taken over once expired
+	mr.FastForward(2 * time.Minute)
+	ok, err = follower.Acquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.NoError(t, follower.Release(ctx))
+	ok, err = leader.Acquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
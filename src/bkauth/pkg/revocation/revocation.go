@@ -0,0 +1,200 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+// Package revocation publishes the revocation of tokens to a redis stream per realm, so that
+// resource servers caching introspection results can drop revoked tokens without polling.
+package revocation
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+
+	redis "github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+
+	bkauthredis "bkauth/pkg/redis"
+)
+
+// StreamKeyPrefix is the prefix of the redis stream of the revocation events of a realm
+const StreamKeyPrefix = "bkauth:revocation:"
+
+// StreamMaxLen is the approximate number of events retained in the stream of a realm,
+// the older ones are trimmed and can no longer be resumed from
+const StreamMaxLen = 100000
+
+// reasons of the revocations
+const (
+	// ReasonRevoked is a revocation request of the client (RFC 7009)
+	ReasonRevoked = "revoked"
+	// ReasonRotated is the revocation of the previous tokens of a refresh token rotation
+	ReasonRotated = "rotated"
+	// ReasonReplayDetected is the revocation of a grant family whose revoked refresh token was replayed
+	ReasonReplayDetected = "replay_detected"
+	// ReasonGrantRevoked is the revocation of a grant family by the user it was issued to
+	ReasonGrantRevoked = "grant_revoked"
+	// ReasonClientDeleted is the revocation of the tokens of a deleted client
+	ReasonClientDeleted = "client_deleted"
+	// ReasonPersonalAccessTokenRevoked is the revocation of a personal access token by its owner
+	ReasonPersonalAccessTokenRevoked = "personal_access_token_revoked"
+)
+
+// ErrRedisUnavailable is returned when reading the events without a redis client
+var ErrRedisUnavailable = errors.New("redis client is not initialized")
+
+var eventIDRegexp = regexp.MustCompile(`^\d+(-\d+)?$`)
+
+// Event is the revocation of a token
+type Event struct {
+	// ID is the id of the stream entry, from which the consumers resume
+	ID        string `json:"id"`
+	TokenType string `json:"token_type"`
+	// JTI is the jti of an access token, as in the claims of a JWT access token and in the
+	// introspection response of an opaque one. Refresh tokens have none: resource servers
+	// never receive them, and the access tokens of the grant are revoked with them.
+	JTI       string `json:"jti"`
+	GrantID   string `json:"grant_id"`
+	ClientID  string `json:"client_id"`
+	Sub       string `json:"sub"`
+	RealmName string `json:"realm"`
+	Reason    string `json:"reason"`
+	RevokedAt int64  `json:"revoked_at"`
+}
+
+// StreamKey returns the key of the redis stream of the revocation events of a realm
+func StreamKey(realmName string) string {
+	return StreamKeyPrefix + realmName
+}
+
+// IsValidEventID reports whether id is a stream entry id, e.g. 1700000000000-0
+func IsValidEventID(id string) bool {
+	return eventIDRegexp.MatchString(id)
+}
+
+// values returns the fields of the stream entry of the event
+func (e Event) values() map[string]interface{} {
+	return map[string]interface{}{
+		"token_type": e.TokenType,
+		"jti":        e.JTI,
+		"grant_id":   e.GrantID,
+		"client_id":  e.ClientID,
+		"sub":        e.Sub,
+		"realm":      e.RealmName,
+		"reason":     e.Reason,
+		"revoked_at": e.RevokedAt,
+	}
+}
+
+// parseEvent converts a stream entry back to the event
+func parseEvent(msg redis.XMessage) Event {
+	field := func(name string) string {
+		value, _ := msg.Values[name].(string)
+		return value
+	}
+	revokedAt, _ := strconv.ParseInt(field("revoked_at"), 10, 64)
+	return Event{
+		ID:        msg.ID,
+		TokenType: field("token_type"),
+		JTI:       field("jti"),
+		GrantID:   field("grant_id"),
+		ClientID:  field("client_id"),
+		Sub:       field("sub"),
+		RealmName: field("realm"),
+		Reason:    field("reason"),
+		RevokedAt: revokedAt,
+	}
+}
+
+// Publish appends the events to the streams of their realms.
+// Failures are only logged: the revocations are committed already, and the consumers
+// still see them on introspection.
+func Publish(ctx context.Context, events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	cli := bkauthredis.GetDefaultRedisClient()
+	if cli == nil {
+		return
+	}
+
+	pipe := cli.Pipeline()
+	for _, e := range events {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: StreamKey(e.RealmName),
+			MaxLen: StreamMaxLen,
+			Approx: true,
+			Values: e.values(),
+		})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		zap.S().Warnf("publish revocation events fail, count=%d, err=%v", len(events), err)
+	}
+}
+
+// LastEventID returns the id of the latest event of a realm, "0-0" when there is none yet,
+// from which a consumer starts to only receive the upcoming events
+func LastEventID(ctx context.Context, realmName string) (string, error) {
+	cli := bkauthredis.GetDefaultRedisClient()
+	if cli == nil {
+		return "", ErrRedisUnavailable
+	}
+
+	msgs, err := cli.XRevRangeN(ctx, StreamKey(realmName), "+", "-", 1).Result()
+	if err != nil {
+		return "", err
+	}
+	if len(msgs) == 0 {
+		return "0-0", nil
+	}
+	return msgs[0].ID, nil
+}
+
+// Read returns at most count events of a realm after the given event id, waiting up to block
+// for the first one; no events are returned when none arrived in time
+func Read(ctx context.Context, realmName, afterID string, count int64, block time.Duration) ([]Event, error) {
+	cli := bkauthredis.GetDefaultRedisClient()
+	if cli == nil {
+		return nil, ErrRedisUnavailable
+	}
+
+	// BLOCK 0 waits forever, a negative duration omits BLOCK
+	if block <= 0 {
+		block = -1
+	}
+	streams, err := cli.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{StreamKey(realmName), afterID},
+		Count:   count,
+		Block:   block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			events = append(events, parseEvent(msg))
+		}
+	}
+	return events, nil
+}
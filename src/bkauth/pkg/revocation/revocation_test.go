@@ -0,0 +1,81 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	redis "github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamKey(t *testing.T) {
+	assert.Equal(t, "bkauth:revocation:blueking", StreamKey("blueking"))
+}
+
+func TestIsValidEventID(t *testing.T) {
+	assert.True(t, IsValidEventID("0"))
+	assert.True(t, IsValidEventID("0-0"))
+	assert.True(t, IsValidEventID("1700000000000-12"))
+
+	assert.False(t, IsValidEventID(""))
+	assert.False(t, IsValidEventID("$"))
+	assert.False(t, IsValidEventID("+"))
+	assert.False(t, IsValidEventID("1700000000000-"))
+	assert.False(t, IsValidEventID("abc-1"))
+}
+
+func TestParseEvent(t *testing.T) {
+	event := Event{
+		TokenType: "access_token",
+		JTI:       "jti-1",
+		GrantID:   "grant-001",
+		ClientID:  "client1",
+		Sub:       "user1",
+		RealmName: "blueking",
+		Reason:    ReasonRevoked,
+		RevokedAt: 1700000000,
+	}
+
+	// redis returns every field of a stream entry as a string
+	values := make(map[string]interface{})
+	for k, v := range event.values() {
+		values[k] = fmt.Sprint(v)
+	}
+	parsed := parseEvent(redis.XMessage{ID: "1700000000000-0", Values: values})
+
+	event.ID = "1700000000000-0"
+	assert.Equal(t, event, parsed)
+}
+
+func TestWithoutRedis(t *testing.T) {
+	ctx := context.Background()
+
+	// publishing is best-effort
+	Publish(ctx, []Event{{RealmName: "blueking", JTI: "jti-1"}})
+
+	_, err := LastEventID(ctx, "blueking")
+	assert.ErrorIs(t, err, ErrRedisUnavailable)
+
+	_, err = Read(ctx, "blueking", "0-0", 10, time.Second)
+	assert.ErrorIs(t, err, ErrRedisUnavailable)
+}
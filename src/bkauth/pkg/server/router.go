@@ -93,6 +93,29 @@ func NewRouter(cfg *config.Config) *gin.Engine {
 		middleware.APILogger(),
 		handler.NewMetadataHandler(cfg),
 	)
+	// [OIDC Client] OpenID Provider Metadata Discovery, same layout as above
+	router.GET(
+		"/.well-known/openid-configuration/realms/:realm_name/oauth2",
+		oauth.RealmMiddleware(),
+		middleware.Metrics(),
+		middleware.APILogger(),
+		handler.NewOpenIDConfigurationHandler(cfg),
+	)
+	router.GET(
+		"/.well-known/openid-configuration",
+		middleware.Metrics(),
+		middleware.APILogger(),
+		handler.NewDefaultRealmOpenIDConfigurationHandler(cfg),
+	)
+	// [MCP Server / Gateway] Protected Resource Metadata (RFC 9728) of a resource of the realm,
+	// to be proxied or redirected to from the resource's /.well-known/oauth-protected-resource
+	router.GET(
+		"/realms/:realm_name/resource-metadata",
+		oauth.RealmMiddleware(),
+		middleware.Metrics(),
+		middleware.APILogger(),
+		handler.NewResourceMetadataHandler(cfg),
+	)
 	// 临时兼容 CodeBuddy IDE MCP CLIENT BUG (defaults to blueking realm)
 	router.GET(
 		"/.well-known/oauth-authorization-server",
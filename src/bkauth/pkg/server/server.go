@@ -20,6 +20,7 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"time"
@@ -76,6 +77,11 @@ func NewServer(cfg *config.Config) *Server {
 		WriteTimeout: writeTimeout,
 		IdleTimeout:  idleTimeout,
 	}
+	if cfg.Server.TLS.Enabled {
+		// RFC 8705: request the client certificate for mutual-TLS client authentication,
+		// the certificate is verified per client by the OAuth endpoints, not by the handshake
+		server.TLSConfig = &tls.Config{ClientAuth: tls.RequestClientCert}
+	}
 
 	return &Server{
 		addr:     addr,
@@ -95,7 +101,12 @@ func (s *Server) Run(ctx context.Context) {
 	}()
 
 	go func() {
-		err := s.server.ListenAndServe()
+		var err error
+		if tlsCfg := s.config.Server.TLS; tlsCfg.Enabled {
+			err = s.server.ListenAndServeTLS(tlsCfg.CertFile, tlsCfg.CertKeyFile)
+		} else {
+			err = s.server.ListenAndServe()
+		}
 		if err != nil {
 			panic(err)
 		}
@@ -0,0 +1,127 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: oauth_cleanup.go
+//
+// Generated by this command:
+//
+//	mockgen -source=oauth_cleanup.go -destination=./mock/oauth_cleanup.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	types "bkauth/pkg/service/types"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOAuthCleanupService is a mock of OAuthCleanupService interface.
+type MockOAuthCleanupService struct {
+	ctrl     *gomock.Controller
+	recorder *MockOAuthCleanupServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockOAuthCleanupServiceMockRecorder is the mock recorder for MockOAuthCleanupService.
+type MockOAuthCleanupServiceMockRecorder struct {
+	mock *MockOAuthCleanupService
+}
+
+// NewMockOAuthCleanupService creates a new mock instance.
+func NewMockOAuthCleanupService(ctrl *gomock.Controller) *MockOAuthCleanupService {
+	mock := &MockOAuthCleanupService{ctrl: ctrl}
+	mock.recorder = &MockOAuthCleanupServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOAuthCleanupService) EXPECT() *MockOAuthCleanupServiceMockRecorder {
+	return m.recorder
+}
+
+// CountExpired mocks base method.
+func (m *MockOAuthCleanupService) CountExpired(ctx context.Context, before time.Time) ([]types.CleanupResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountExpired", ctx, before)
+	ret0, _ := ret[0].([]types.CleanupResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountExpired indicates an expected call of CountExpired.
+func (mr *MockOAuthCleanupServiceMockRecorder) CountExpired(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountExpired", reflect.TypeOf((*MockOAuthCleanupService)(nil).CountExpired), ctx, before)
+}
+
+// DeleteExpired mocks base method.
+func (m *MockOAuthCleanupService) DeleteExpired(ctx context.Context, before time.Time, batchSize int64) ([]types.CleanupResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx, before, batchSize)
+	ret0, _ := ret[0].([]types.CleanupResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockOAuthCleanupServiceMockRecorder) DeleteExpired(ctx, before, batchSize any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockOAuthCleanupService)(nil).DeleteExpired), ctx, before, batchSize)
+}
+
+// MockexpiredRowsManager is a mock of expiredRowsManager interface.
+type MockexpiredRowsManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockexpiredRowsManagerMockRecorder
+	isgomock struct{}
+}
+
+// MockexpiredRowsManagerMockRecorder is the mock recorder for MockexpiredRowsManager.
+type MockexpiredRowsManagerMockRecorder struct {
+	mock *MockexpiredRowsManager
+}
+
+// NewMockexpiredRowsManager creates a new mock instance.
+func NewMockexpiredRowsManager(ctrl *gomock.Controller) *MockexpiredRowsManager {
+	mock := &MockexpiredRowsManager{ctrl: ctrl}
+	mock.recorder = &MockexpiredRowsManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockexpiredRowsManager) EXPECT() *MockexpiredRowsManagerMockRecorder {
+	return m.recorder
+}
+
+// CountExpired mocks base method.
+func (m *MockexpiredRowsManager) CountExpired(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountExpired", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountExpired indicates an expected call of CountExpired.
+func (mr *MockexpiredRowsManagerMockRecorder) CountExpired(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountExpired", reflect.TypeOf((*MockexpiredRowsManager)(nil).CountExpired), ctx, before)
+}
+
+// DeleteExpired mocks base method.
+func (m *MockexpiredRowsManager) DeleteExpired(ctx context.Context, before time.Time, limit int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx, before, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockexpiredRowsManagerMockRecorder) DeleteExpired(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockexpiredRowsManager)(nil).DeleteExpired), ctx, before, limit)
+}
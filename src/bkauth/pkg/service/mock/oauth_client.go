@@ -41,6 +41,20 @@ func (m *MockOAuthClientService) EXPECT() *MockOAuthClientServiceMockRecorder {
 	return m.recorder
 }
 
+// Delete mocks base method.
+func (m *MockOAuthClientService) Delete(ctx context.Context, clientID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, clientID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockOAuthClientServiceMockRecorder) Delete(ctx, clientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOAuthClientService)(nil).Delete), ctx, clientID)
+}
+
 // DynamicRegister mocks base method.
 func (m *MockOAuthClientService) DynamicRegister(ctx context.Context, input types.OAuthClientDynamicRegistrationInput) (types.OAuthClient, error) {
 	m.ctrl.T.Helper()
@@ -56,6 +70,21 @@ func (mr *MockOAuthClientServiceMockRecorder) DynamicRegister(ctx, input any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DynamicRegister", reflect.TypeOf((*MockOAuthClientService)(nil).DynamicRegister), ctx, input)
 }
 
+// DynamicUpdate mocks base method.
+func (m *MockOAuthClientService) DynamicUpdate(ctx context.Context, clientID string, input types.OAuthClientDynamicRegistrationInput) (types.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DynamicUpdate", ctx, clientID, input)
+	ret0, _ := ret[0].(types.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DynamicUpdate indicates an expected call of DynamicUpdate.
+func (mr *MockOAuthClientServiceMockRecorder) DynamicUpdate(ctx, clientID, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DynamicUpdate", reflect.TypeOf((*MockOAuthClientService)(nil).DynamicUpdate), ctx, clientID, input)
+}
+
 // Exists mocks base method.
 func (m *MockOAuthClientService) Exists(ctx context.Context, clientID string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -86,6 +115,21 @@ func (mr *MockOAuthClientServiceMockRecorder) Get(ctx, clientID any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOAuthClientService)(nil).Get), ctx, clientID)
 }
 
+// GetAuthSpec mocks base method.
+func (m *MockOAuthClientService) GetAuthSpec(ctx context.Context, clientID string) (types.OAuthClientAuthSpec, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthSpec", ctx, clientID)
+	ret0, _ := ret[0].(types.OAuthClientAuthSpec)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthSpec indicates an expected call of GetAuthSpec.
+func (mr *MockOAuthClientServiceMockRecorder) GetAuthSpec(ctx, clientID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthSpec", reflect.TypeOf((*MockOAuthClientService)(nil).GetAuthSpec), ctx, clientID)
+}
+
 // GetFlowSpec mocks base method.
 func (m *MockOAuthClientService) GetFlowSpec(ctx context.Context, clientID string) (types.OAuthClientFlowSpec, error) {
 	m.ctrl.T.Helper()
@@ -115,3 +159,48 @@ func (mr *MockOAuthClientServiceMockRecorder) GetProfile(ctx, clientID any) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockOAuthClientService)(nil).GetProfile), ctx, clientID)
 }
+
+// RegisterConfidential mocks base method.
+func (m *MockOAuthClientService) RegisterConfidential(ctx context.Context, clientID string, input types.OAuthConfidentialClientInput) (types.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterConfidential", ctx, clientID, input)
+	ret0, _ := ret[0].(types.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterConfidential indicates an expected call of RegisterConfidential.
+func (mr *MockOAuthClientServiceMockRecorder) RegisterConfidential(ctx, clientID, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterConfidential", reflect.TypeOf((*MockOAuthClientService)(nil).RegisterConfidential), ctx, clientID, input)
+}
+
+// UpdateConfidential mocks base method.
+func (m *MockOAuthClientService) UpdateConfidential(ctx context.Context, clientID string, input types.OAuthConfidentialClientInput) (types.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateConfidential", ctx, clientID, input)
+	ret0, _ := ret[0].(types.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateConfidential indicates an expected call of UpdateConfidential.
+func (mr *MockOAuthClientServiceMockRecorder) UpdateConfidential(ctx, clientID, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConfidential", reflect.TypeOf((*MockOAuthClientService)(nil).UpdateConfidential), ctx, clientID, input)
+}
+
+// VerifyRegistrationAccessToken mocks base method.
+func (m *MockOAuthClientService) VerifyRegistrationAccessToken(ctx context.Context, clientID, registrationAccessToken string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyRegistrationAccessToken", ctx, clientID, registrationAccessToken)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyRegistrationAccessToken indicates an expected call of VerifyRegistrationAccessToken.
+func (mr *MockOAuthClientServiceMockRecorder) VerifyRegistrationAccessToken(ctx, clientID, registrationAccessToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyRegistrationAccessToken", reflect.TypeOf((*MockOAuthClientService)(nil).VerifyRegistrationAccessToken), ctx, clientID, registrationAccessToken)
+}
@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: oauth_consent.go
+//
+// Generated by this command:
+//
+//	mockgen -source=oauth_consent.go -destination=./mock/oauth_consent.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	types "bkauth/pkg/service/types"
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOAuthConsentService is a mock of OAuthConsentService interface.
+type MockOAuthConsentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockOAuthConsentServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockOAuthConsentServiceMockRecorder is the mock recorder for MockOAuthConsentService.
+type MockOAuthConsentServiceMockRecorder struct {
+	mock *MockOAuthConsentService
+}
+
+// NewMockOAuthConsentService creates a new mock instance.
+func NewMockOAuthConsentService(ctrl *gomock.Controller) *MockOAuthConsentService {
+	mock := &MockOAuthConsentService{ctrl: ctrl}
+	mock.recorder = &MockOAuthConsentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOAuthConsentService) EXPECT() *MockOAuthConsentServiceMockRecorder {
+	return m.recorder
+}
+
+// Grant mocks base method.
+func (m *MockOAuthConsentService) Grant(ctx context.Context, grant types.ConsentGrant, ttl int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Grant", ctx, grant, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Grant indicates an expected call of Grant.
+func (mr *MockOAuthConsentServiceMockRecorder) Grant(ctx, grant, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Grant", reflect.TypeOf((*MockOAuthConsentService)(nil).Grant), ctx, grant, ttl)
+}
+
+// IsGranted mocks base method.
+func (m *MockOAuthConsentService) IsGranted(ctx context.Context, grant types.ConsentGrant) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsGranted", ctx, grant)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsGranted indicates an expected call of IsGranted.
+func (mr *MockOAuthConsentServiceMockRecorder) IsGranted(ctx, grant any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsGranted", reflect.TypeOf((*MockOAuthConsentService)(nil).IsGranted), ctx, grant)
+}
+
+// Revoke mocks base method.
+func (m *MockOAuthConsentService) Revoke(ctx context.Context, grant types.ConsentGrant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, grant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockOAuthConsentServiceMockRecorder) Revoke(ctx, grant any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockOAuthConsentService)(nil).Revoke), ctx, grant)
+}
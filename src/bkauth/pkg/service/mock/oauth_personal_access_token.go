@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: oauth_personal_access_token.go
+//
+// Generated by this command:
+//
+//	mockgen -source=oauth_personal_access_token.go -destination=./mock/oauth_personal_access_token.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	types "bkauth/pkg/service/types"
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockOAuthPersonalAccessTokenService is a mock of OAuthPersonalAccessTokenService interface.
+type MockOAuthPersonalAccessTokenService struct {
+	ctrl     *gomock.Controller
+	recorder *MockOAuthPersonalAccessTokenServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockOAuthPersonalAccessTokenServiceMockRecorder is the mock recorder for MockOAuthPersonalAccessTokenService.
+type MockOAuthPersonalAccessTokenServiceMockRecorder struct {
+	mock *MockOAuthPersonalAccessTokenService
+}
+
+// NewMockOAuthPersonalAccessTokenService creates a new mock instance.
+func NewMockOAuthPersonalAccessTokenService(ctrl *gomock.Controller) *MockOAuthPersonalAccessTokenService {
+	mock := &MockOAuthPersonalAccessTokenService{ctrl: ctrl}
+	mock.recorder = &MockOAuthPersonalAccessTokenServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOAuthPersonalAccessTokenService) EXPECT() *MockOAuthPersonalAccessTokenServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockOAuthPersonalAccessTokenService) Create(ctx context.Context, input types.PersonalAccessTokenInput) (types.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, input)
+	ret0, _ := ret[0].(types.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockOAuthPersonalAccessTokenServiceMockRecorder) Create(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOAuthPersonalAccessTokenService)(nil).Create), ctx, input)
+}
+
+// ListBySub mocks base method.
+func (m *MockOAuthPersonalAccessTokenService) ListBySub(ctx context.Context, tenantID, sub string) ([]types.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBySub", ctx, tenantID, sub)
+	ret0, _ := ret[0].([]types.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBySub indicates an expected call of ListBySub.
+func (mr *MockOAuthPersonalAccessTokenServiceMockRecorder) ListBySub(ctx, tenantID, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBySub", reflect.TypeOf((*MockOAuthPersonalAccessTokenService)(nil).ListBySub), ctx, tenantID, sub)
+}
+
+// Revoke mocks base method.
+func (m *MockOAuthPersonalAccessTokenService) Revoke(ctx context.Context, tenantID, sub string, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, tenantID, sub, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockOAuthPersonalAccessTokenServiceMockRecorder) Revoke(ctx, tenantID, sub, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockOAuthPersonalAccessTokenService)(nil).Revoke), ctx, tenantID, sub, id)
+}
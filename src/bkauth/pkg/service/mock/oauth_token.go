@@ -10,6 +10,7 @@
 package mock
 
 import (
+	oauth "bkauth/pkg/oauth"
 	types "bkauth/pkg/service/types"
 	context "context"
 	reflect "reflect"
@@ -41,90 +42,165 @@ func (m *MockOAuthTokenService) EXPECT() *MockOAuthTokenServiceMockRecorder {
 	return m.recorder
 }
 
-// GetAccessTokenByTokenHash mocks base method.
-func (m *MockOAuthTokenService) GetAccessTokenByTokenHash(ctx context.Context, tokenHash string) (types.ResolvedAccessToken, error) {
+// GetAccessTokenByTokenHashes mocks base method.
+func (m *MockOAuthTokenService) GetAccessTokenByTokenHashes(ctx context.Context, tokenHashes []oauth.TokenHash) (types.ResolvedAccessToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccessTokenByTokenHash", ctx, tokenHash)
+	ret := m.ctrl.Call(m, "GetAccessTokenByTokenHashes", ctx, tokenHashes)
 	ret0, _ := ret[0].(types.ResolvedAccessToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccessTokenByTokenHash indicates an expected call of GetAccessTokenByTokenHash.
-func (mr *MockOAuthTokenServiceMockRecorder) GetAccessTokenByTokenHash(ctx, tokenHash any) *gomock.Call {
+// GetAccessTokenByTokenHashes indicates an expected call of GetAccessTokenByTokenHashes.
+func (mr *MockOAuthTokenServiceMockRecorder) GetAccessTokenByTokenHashes(ctx, tokenHashes any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessTokenByTokenHash", reflect.TypeOf((*MockOAuthTokenService)(nil).GetAccessTokenByTokenHash), ctx, tokenHash)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessTokenByTokenHashes", reflect.TypeOf((*MockOAuthTokenService)(nil).GetAccessTokenByTokenHashes), ctx, tokenHashes)
+}
+
+// GetRefreshTokenByTokenHashes mocks base method.
+func (m *MockOAuthTokenService) GetRefreshTokenByTokenHashes(ctx context.Context, tokenHashes []oauth.TokenHash) (types.ResolvedRefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshTokenByTokenHashes", ctx, tokenHashes)
+	ret0, _ := ret[0].(types.ResolvedRefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshTokenByTokenHashes indicates an expected call of GetRefreshTokenByTokenHashes.
+func (mr *MockOAuthTokenServiceMockRecorder) GetRefreshTokenByTokenHashes(ctx, tokenHashes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenByTokenHashes", reflect.TypeOf((*MockOAuthTokenService)(nil).GetRefreshTokenByTokenHashes), ctx, tokenHashes)
+}
+
+// IssueAccessTokenForClientCredentials mocks base method.
+func (m *MockOAuthTokenService) IssueAccessTokenForClientCredentials(ctx context.Context, realmName, clientID string, grant types.TokenGrant, policy types.TokenIssuancePolicy) (types.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueAccessTokenForClientCredentials", ctx, realmName, clientID, grant, policy)
+	ret0, _ := ret[0].(types.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueAccessTokenForClientCredentials indicates an expected call of IssueAccessTokenForClientCredentials.
+func (mr *MockOAuthTokenServiceMockRecorder) IssueAccessTokenForClientCredentials(ctx, realmName, clientID, grant, policy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueAccessTokenForClientCredentials", reflect.TypeOf((*MockOAuthTokenService)(nil).IssueAccessTokenForClientCredentials), ctx, realmName, clientID, grant, policy)
+}
+
+// IssueAccessTokenForTokenExchange mocks base method.
+func (m *MockOAuthTokenService) IssueAccessTokenForTokenExchange(ctx context.Context, realmName, grantID, clientID string, grant types.TokenGrant, policy types.TokenIssuancePolicy) (types.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueAccessTokenForTokenExchange", ctx, realmName, grantID, clientID, grant, policy)
+	ret0, _ := ret[0].(types.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueAccessTokenForTokenExchange indicates an expected call of IssueAccessTokenForTokenExchange.
+func (mr *MockOAuthTokenServiceMockRecorder) IssueAccessTokenForTokenExchange(ctx, realmName, grantID, clientID, grant, policy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueAccessTokenForTokenExchange", reflect.TypeOf((*MockOAuthTokenService)(nil).IssueAccessTokenForTokenExchange), ctx, realmName, grantID, clientID, grant, policy)
 }
 
 // IssueTokensForAuthorizationCode mocks base method.
-func (m *MockOAuthTokenService) IssueTokensForAuthorizationCode(ctx context.Context, realmName, clientID, tenantID, sub, username string, audience []string, policy types.TokenIssuancePolicy) (types.TokenPair, error) {
+func (m *MockOAuthTokenService) IssueTokensForAuthorizationCode(ctx context.Context, realmName, clientID string, grant types.TokenGrant, policy types.TokenIssuancePolicy) (types.TokenPair, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IssueTokensForAuthorizationCode", ctx, realmName, clientID, tenantID, sub, username, audience, policy)
+	ret := m.ctrl.Call(m, "IssueTokensForAuthorizationCode", ctx, realmName, clientID, grant, policy)
 	ret0, _ := ret[0].(types.TokenPair)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // IssueTokensForAuthorizationCode indicates an expected call of IssueTokensForAuthorizationCode.
-func (mr *MockOAuthTokenServiceMockRecorder) IssueTokensForAuthorizationCode(ctx, realmName, clientID, tenantID, sub, username, audience, policy any) *gomock.Call {
+func (mr *MockOAuthTokenServiceMockRecorder) IssueTokensForAuthorizationCode(ctx, realmName, clientID, grant, policy any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueTokensForAuthorizationCode", reflect.TypeOf((*MockOAuthTokenService)(nil).IssueTokensForAuthorizationCode), ctx, realmName, clientID, tenantID, sub, username, audience, policy)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueTokensForAuthorizationCode", reflect.TypeOf((*MockOAuthTokenService)(nil).IssueTokensForAuthorizationCode), ctx, realmName, clientID, grant, policy)
 }
 
 // IssueTokensForDeviceCode mocks base method.
-func (m *MockOAuthTokenService) IssueTokensForDeviceCode(ctx context.Context, realmName, clientID, tenantID, sub, username string, audience []string, policy types.TokenIssuancePolicy) (types.TokenPair, error) {
+func (m *MockOAuthTokenService) IssueTokensForDeviceCode(ctx context.Context, realmName, clientID string, grant types.TokenGrant, policy types.TokenIssuancePolicy) (types.TokenPair, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IssueTokensForDeviceCode", ctx, realmName, clientID, tenantID, sub, username, audience, policy)
+	ret := m.ctrl.Call(m, "IssueTokensForDeviceCode", ctx, realmName, clientID, grant, policy)
 	ret0, _ := ret[0].(types.TokenPair)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // IssueTokensForDeviceCode indicates an expected call of IssueTokensForDeviceCode.
-func (mr *MockOAuthTokenServiceMockRecorder) IssueTokensForDeviceCode(ctx, realmName, clientID, tenantID, sub, username, audience, policy any) *gomock.Call {
+func (mr *MockOAuthTokenServiceMockRecorder) IssueTokensForDeviceCode(ctx, realmName, clientID, grant, policy any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueTokensForDeviceCode", reflect.TypeOf((*MockOAuthTokenService)(nil).IssueTokensForDeviceCode), ctx, realmName, clientID, tenantID, sub, username, audience, policy)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueTokensForDeviceCode", reflect.TypeOf((*MockOAuthTokenService)(nil).IssueTokensForDeviceCode), ctx, realmName, clientID, grant, policy)
+}
+
+// ListActiveGrants mocks base method.
+func (m *MockOAuthTokenService) ListActiveGrants(ctx context.Context, tenantID, sub string) ([]types.UserGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveGrants", ctx, tenantID, sub)
+	ret0, _ := ret[0].([]types.UserGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveGrants indicates an expected call of ListActiveGrants.
+func (mr *MockOAuthTokenServiceMockRecorder) ListActiveGrants(ctx, tenantID, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveGrants", reflect.TypeOf((*MockOAuthTokenService)(nil).ListActiveGrants), ctx, tenantID, sub)
 }
 
 // RefreshAccessToken mocks base method.
-func (m *MockOAuthTokenService) RefreshAccessToken(ctx context.Context, realmName, refreshToken, clientID string, policy types.TokenIssuancePolicy) (types.TokenPair, error) {
+func (m *MockOAuthTokenService) RefreshAccessToken(ctx context.Context, realmName, refreshToken, clientID string, cnf oauth.Confirmation, policy types.TokenIssuancePolicy) (types.TokenPair, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RefreshAccessToken", ctx, realmName, refreshToken, clientID, policy)
+	ret := m.ctrl.Call(m, "RefreshAccessToken", ctx, realmName, refreshToken, clientID, cnf, policy)
 	ret0, _ := ret[0].(types.TokenPair)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // RefreshAccessToken indicates an expected call of RefreshAccessToken.
-func (mr *MockOAuthTokenServiceMockRecorder) RefreshAccessToken(ctx, realmName, refreshToken, clientID, policy any) *gomock.Call {
+func (mr *MockOAuthTokenServiceMockRecorder) RefreshAccessToken(ctx, realmName, refreshToken, clientID, cnf, policy any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshAccessToken", reflect.TypeOf((*MockOAuthTokenService)(nil).RefreshAccessToken), ctx, realmName, refreshToken, clientID, policy)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshAccessToken", reflect.TypeOf((*MockOAuthTokenService)(nil).RefreshAccessToken), ctx, realmName, refreshToken, clientID, cnf, policy)
 }
 
 // RevokeByGrantID mocks base method.
-func (m *MockOAuthTokenService) RevokeByGrantID(ctx context.Context, grantID string) error {
+func (m *MockOAuthTokenService) RevokeByGrantID(ctx context.Context, grantID, reason string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RevokeByGrantID", ctx, grantID)
+	ret := m.ctrl.Call(m, "RevokeByGrantID", ctx, grantID, reason)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // RevokeByGrantID indicates an expected call of RevokeByGrantID.
-func (mr *MockOAuthTokenServiceMockRecorder) RevokeByGrantID(ctx, grantID any) *gomock.Call {
+func (mr *MockOAuthTokenServiceMockRecorder) RevokeByGrantID(ctx, grantID, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeByGrantID", reflect.TypeOf((*MockOAuthTokenService)(nil).RevokeByGrantID), ctx, grantID, reason)
+}
+
+// RevokeGrantOfUser mocks base method.
+func (m *MockOAuthTokenService) RevokeGrantOfUser(ctx context.Context, tenantID, sub, grantID string) (types.UserGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeGrantOfUser", ctx, tenantID, sub, grantID)
+	ret0, _ := ret[0].(types.UserGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeGrantOfUser indicates an expected call of RevokeGrantOfUser.
+func (mr *MockOAuthTokenServiceMockRecorder) RevokeGrantOfUser(ctx, tenantID, sub, grantID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeByGrantID", reflect.TypeOf((*MockOAuthTokenService)(nil).RevokeByGrantID), ctx, grantID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeGrantOfUser", reflect.TypeOf((*MockOAuthTokenService)(nil).RevokeGrantOfUser), ctx, tenantID, sub, grantID)
 }
 
 // RevokeToken mocks base method.
-func (m *MockOAuthTokenService) RevokeToken(ctx context.Context, tokenHash, clientID string) error {
+func (m *MockOAuthTokenService) RevokeToken(ctx context.Context, tokenHashes []oauth.TokenHash, clientID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RevokeToken", ctx, tokenHash, clientID)
+	ret := m.ctrl.Call(m, "RevokeToken", ctx, tokenHashes, clientID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // RevokeToken indicates an expected call of RevokeToken.
-func (mr *MockOAuthTokenServiceMockRecorder) RevokeToken(ctx, tokenHash, clientID any) *gomock.Call {
+func (mr *MockOAuthTokenServiceMockRecorder) RevokeToken(ctx, tokenHashes, clientID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockOAuthTokenService)(nil).RevokeToken), ctx, tokenHash, clientID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockOAuthTokenService)(nil).RevokeToken), ctx, tokenHashes, clientID)
 }
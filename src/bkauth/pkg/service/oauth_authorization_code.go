@@ -63,18 +63,22 @@ func (s *oauthAuthorizationCodeService) CreateAuthorizationCode(
 	}
 
 	daoCode := dao.OAuthAuthorizationCode{
-		Code:                input.Code,
-		ClientID:            input.ClientID,
-		RealmName:           input.RealmName,
-		TenantID:            input.TenantID,
-		Sub:                 input.Sub,
-		Username:            input.Username,
-		RedirectURI:         input.RedirectURI,
-		Audience:            string(audienceJSON),
-		CodeChallenge:       input.CodeChallenge,
-		CodeChallengeMethod: input.CodeChallengeMethod,
-		ExpiresAt:           time.Now().Add(time.Duration(oauth.AuthorizationCodeTTL) * time.Second),
-		Used:                false,
+		Code:                 input.Code,
+		ClientID:             input.ClientID,
+		RealmName:            input.RealmName,
+		TenantID:             input.TenantID,
+		Sub:                  input.Sub,
+		Username:             input.Username,
+		RedirectURI:          input.RedirectURI,
+		Audience:             string(audienceJSON),
+		Resource:             input.Resource,
+		AuthorizationDetails: oauth.FormatAuthorizationDetails(input.AuthorizationDetails),
+		Scope:                input.Scope,
+		CodeChallenge:        input.CodeChallenge,
+		CodeChallengeMethod:  input.CodeChallengeMethod,
+		Nonce:                input.Nonce,
+		ExpiresAt:            time.Now().Add(time.Duration(oauth.AuthorizationCodeTTL) * time.Second),
+		Used:                 false,
 	}
 
 	if err := s.authCodeManager.Create(ctx, daoCode); err != nil {
@@ -136,12 +140,17 @@ func (s *oauthAuthorizationCodeService) ValidateAndConsume(
 		return types.ConsumedAuthorizationCode{}, errorWrapf(err, "json.Unmarshal audience fail")
 	}
 
+	authorizationDetails, err := oauth.ParseAuthorizationDetails(authCode.AuthorizationDetails)
+	if err != nil {
+		return types.ConsumedAuthorizationCode{}, errorWrapf(err, "oauth.ParseAuthorizationDetails fail")
+	}
+
 	// Optimistic lock: only the first request to CAS (used=0 -> used=1) succeeds.
 	// rowsAffected==0 means another concurrent request already consumed this code.
 	//
 	// Other theoretically possible causes (code deleted by TTL cleanup, or code
 	// expired between Get and UPDATE) are not handled here because:
-	//   - TTL cleanup runs at coarse intervals (minutes/hours), never races with in-flight requests.
+	//   - TTL cleanup (pkg/janitor) only deletes codes expired for longer than the retention.
 	//   - Expiry was already validated above; the sub-millisecond window is negligible.
 	rowsAffected, err := s.authCodeManager.MarkAsUsed(ctx, code)
 	if err != nil {
@@ -152,9 +161,13 @@ func (s *oauthAuthorizationCodeService) ValidateAndConsume(
 	}
 
 	return types.ConsumedAuthorizationCode{
-		TenantID: authCode.TenantID,
-		Sub:      authCode.Sub,
-		Username: authCode.Username,
-		Audience: audience,
+		TenantID:             authCode.TenantID,
+		Sub:                  authCode.Sub,
+		Username:             authCode.Username,
+		Audience:             audience,
+		Resource:             authCode.Resource,
+		AuthorizationDetails: authorizationDetails,
+		Scope:                authCode.Scope,
+		Nonce:                authCode.Nonce,
 	}, nil
 }
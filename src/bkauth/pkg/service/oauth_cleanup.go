@@ -0,0 +1,116 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"context"
+	"time"
+
+	"bkauth/pkg/database/dao"
+	"bkauth/pkg/errorx"
+	"bkauth/pkg/service/types"
+)
+
+const OAuthCleanupSVC = "OAuthCleanupSVC"
+
+// cleanupBatchPause is the pause between two full batches, so that the cleanup
+// neither holds InnoDB locks for long nor floods the replication
+const cleanupBatchPause = 100 * time.Millisecond
+
+// OAuthCleanupService defines the interface for deleting expired and revoked tokens and codes.
+type OAuthCleanupService interface {
+	// CountExpired counts, per table, the rows DeleteExpired would delete
+	CountExpired(ctx context.Context, before time.Time) ([]types.CleanupResult, error)
+	// DeleteExpired deletes, per table, the rows that expired or were revoked before the given time,
+	// batchSize rows per statement
+	DeleteExpired(ctx context.Context, before time.Time, batchSize int64) ([]types.CleanupResult, error)
+}
+
+// expiredRowsManager is implemented by the managers of the tables to clean up
+type expiredRowsManager interface {
+	CountExpired(ctx context.Context, before time.Time) (int64, error)
+	DeleteExpired(ctx context.Context, before time.Time, limit int64) (int64, error)
+}
+
+type cleanupTable struct {
+	name    string
+	manager expiredRowsManager
+}
+
+type oauthCleanupService struct {
+	tables []cleanupTable
+}
+
+// NewOAuthCleanupService creates a new OAuthCleanupService.
+func NewOAuthCleanupService() OAuthCleanupService {
+	return &oauthCleanupService{
+		tables: []cleanupTable{
+			{name: "oauth_authorization_code", manager: dao.NewOAuthAuthorizationCodeManager()},
+			{name: "oauth_device_code", manager: dao.NewOAuthDeviceCodeManager()},
+			{name: "oauth_access_token", manager: dao.NewOAuthAccessTokenManager()},
+			{name: "oauth_refresh_token", manager: dao.NewOAuthRefreshTokenManager()},
+		},
+	}
+}
+
+func (s *oauthCleanupService) CountExpired(ctx context.Context, before time.Time) ([]types.CleanupResult, error) {
+	results := make([]types.CleanupResult, 0, len(s.tables))
+	for _, t := range s.tables {
+		count, err := t.manager.CountExpired(ctx, before)
+		if err != nil {
+			return results, errorx.Wrapf(err, OAuthCleanupSVC, "CountExpired",
+				"manager.CountExpired table=`%s` fail", t.name)
+		}
+		results = append(results, types.CleanupResult{Table: t.name, Count: count})
+	}
+	return results, nil
+}
+
+// DeleteExpired deletes the expired rows table by table, in batches until a batch is not full.
+// On error, the rows deleted so far are still returned.
+func (s *oauthCleanupService) DeleteExpired(
+	ctx context.Context, before time.Time, batchSize int64,
+) ([]types.CleanupResult, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthCleanupSVC, "DeleteExpired")
+
+	results := make([]types.CleanupResult, 0, len(s.tables))
+	for _, t := range s.tables {
+		result := types.CleanupResult{Table: t.name}
+		for {
+			deleted, err := t.manager.DeleteExpired(ctx, before, batchSize)
+			result.Count += deleted
+			if err != nil {
+				return append(results, result), errorWrapf(err, "manager.DeleteExpired table=`%s` fail", t.name)
+			}
+			if deleted < batchSize {
+				break
+			}
+
+			select {
+			case <-ctx.Done():
+				return append(results, result), errorWrapf(ctx.Err(), "cleanup of table=`%s` canceled", t.name)
+			case <-time.After(cleanupBatchPause):
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
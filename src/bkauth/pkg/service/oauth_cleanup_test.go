@@ -0,0 +1,115 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+
+	"bkauth/pkg/database/dao/mock"
+	"bkauth/pkg/service/types"
+)
+
+var _ = Describe("oauthCleanupService", func() {
+	var (
+		ctx              context.Context
+		ctl              *gomock.Controller
+		mockAccessToken  *mock.MockOAuthAccessTokenManager
+		mockRefreshToken *mock.MockOAuthRefreshTokenManager
+		svc              *oauthCleanupService
+		before           time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctl = gomock.NewController(GinkgoT())
+		mockAccessToken = mock.NewMockOAuthAccessTokenManager(ctl)
+		mockRefreshToken = mock.NewMockOAuthRefreshTokenManager(ctl)
+		svc = &oauthCleanupService{
+			tables: []cleanupTable{
+				{name: "oauth_access_token", manager: mockAccessToken},
+				{name: "oauth_refresh_token", manager: mockRefreshToken},
+			},
+		}
+		before = time.Now().Add(-time.Hour)
+	})
+
+	AfterEach(func() {
+		ctl.Finish()
+	})
+
+	Describe("CountExpired", func() {
+		It("should count the expired rows of each table", func() {
+			mockAccessToken.EXPECT().CountExpired(ctx, before).Return(int64(3), nil)
+			mockRefreshToken.EXPECT().CountExpired(ctx, before).Return(int64(1), nil)
+
+			results, err := svc.CountExpired(ctx, before)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]types.CleanupResult{
+				{Table: "oauth_access_token", Count: 3},
+				{Table: "oauth_refresh_token", Count: 1},
+			}))
+		})
+	})
+
+	Describe("DeleteExpired", func() {
+		It("should delete in batches until a batch is not full", func() {
+			gomock.InOrder(
+				mockAccessToken.EXPECT().DeleteExpired(ctx, before, int64(2)).Return(int64(2), nil),
+				mockAccessToken.EXPECT().DeleteExpired(ctx, before, int64(2)).Return(int64(1), nil),
+			)
+			mockRefreshToken.EXPECT().DeleteExpired(ctx, before, int64(2)).Return(int64(0), nil)
+
+			results, err := svc.DeleteExpired(ctx, before, 2)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]types.CleanupResult{
+				{Table: "oauth_access_token", Count: 3},
+				{Table: "oauth_refresh_token", Count: 0},
+			}))
+		})
+
+		It("should return the rows deleted so far on error", func() {
+			mockAccessToken.EXPECT().DeleteExpired(ctx, before, int64(2)).Return(int64(2), nil)
+			mockAccessToken.EXPECT().DeleteExpired(ctx, before, int64(2)).Return(int64(0), errors.New("db error"))
+
+			results, err := svc.DeleteExpired(ctx, before, 2)
+
+			Expect(err).To(HaveOccurred())
+			Expect(results).To(Equal([]types.CleanupResult{{Table: "oauth_access_token", Count: 2}}))
+		})
+
+		It("should stop between batches once canceled", func() {
+			canceledCtx, cancel := context.WithCancel(ctx)
+			cancel()
+			mockAccessToken.EXPECT().DeleteExpired(canceledCtx, before, int64(2)).Return(int64(2), nil)
+
+			results, err := svc.DeleteExpired(canceledCtx, before, 2)
+
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+			Expect(results).To(Equal([]types.CleanupResult{{Table: "oauth_access_token", Count: 2}}))
+		})
+	})
+})
@@ -22,13 +22,18 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"strings"
 
+	"bkauth/pkg/cache/invalidation"
+	"bkauth/pkg/database"
 	"bkauth/pkg/database/dao"
 	"bkauth/pkg/errorx"
 	"bkauth/pkg/oauth"
+	"bkauth/pkg/revocation"
 	"bkauth/pkg/service/types"
+	"bkauth/pkg/util"
 )
 
 const OAuthClientSVC = "OAuthClientSVC"
@@ -40,20 +45,39 @@ type OAuthClientService interface {
 	Exists(ctx context.Context, clientID string) (bool, error)
 	GetFlowSpec(ctx context.Context, clientID string) (types.OAuthClientFlowSpec, error)
 	GetProfile(ctx context.Context, clientID string) (types.OAuthClientProfile, error)
+	GetAuthSpec(ctx context.Context, clientID string) (types.OAuthClientAuthSpec, error)
+	VerifyRegistrationAccessToken(ctx context.Context, clientID, registrationAccessToken string) (bool, error)
+	DynamicUpdate(
+		ctx context.Context, clientID string, input types.OAuthClientDynamicRegistrationInput,
+	) (types.OAuthClient, error)
+	Delete(ctx context.Context, clientID string) error
+	RegisterConfidential(
+		ctx context.Context, clientID string, input types.OAuthConfidentialClientInput,
+	) (types.OAuthClient, error)
+	UpdateConfidential(
+		ctx context.Context, clientID string, input types.OAuthConfidentialClientInput,
+	) (types.OAuthClient, error)
 }
 
 type oauthClientService struct {
 	manager dao.OAuthClientManager
+	// token managers are only used to revoke the tokens of a deleted client
+	accessTokenManager  dao.OAuthAccessTokenManager
+	refreshTokenManager dao.OAuthRefreshTokenManager
 }
 
 // NewOAuthClientService creates a new OAuthClientService
 func NewOAuthClientService() OAuthClientService {
 	return &oauthClientService{
-		manager: dao.NewOAuthClientManager(),
+		manager:             dao.NewOAuthClientManager(),
+		accessTokenManager:  dao.NewOAuthAccessTokenManager(),
+		refreshTokenManager: dao.NewOAuthRefreshTokenManager(),
 	}
 }
 
 // DynamicRegister registers a new OAuth client via Dynamic Client Registration (RFC 7591).
+// The returned client carries the registration access token (RFC 7592 §3) in plain text,
+// only its hash is stored.
 func (s *oauthClientService) DynamicRegister(
 	ctx context.Context,
 	input types.OAuthClientDynamicRegistrationInput,
@@ -65,25 +89,197 @@ func (s *oauthClientService) DynamicRegister(
 		return types.OAuthClient{}, errorWrapf(err, "GenerateDynamicClientID fail")
 	}
 
-	redirectURIsJSON, err := json.Marshal(input.RedirectURIs)
+	registrationAccessToken, err := oauth.GenerateToken(oauth.RegistrationAccessTokenPrefix)
+	if err != nil {
+		return types.OAuthClient{}, errorWrapf(err, "GenerateToken fail")
+	}
+
+	daoClient, err := convertDynamicRegistrationInput(clientID, input)
+	if err != nil {
+		return types.OAuthClient{}, errorWrapf(err, "convertDynamicRegistrationInput fail")
+	}
+	daoClient.Type = oauth.ClientTypePublic
+	daoClient.RegistrationAccessTokenHash = oauth.HashToken(registrationAccessToken)
+
+	if err := s.manager.Create(ctx, daoClient); err != nil {
+		return types.OAuthClient{}, errorWrapf(err, "manager.Create fail")
+	}
+
+	client, err := s.Get(ctx, clientID)
+	if err != nil {
+		return types.OAuthClient{}, err
+	}
+	client.RegistrationAccessToken = registrationAccessToken
+	return client, nil
+}
+
+// VerifyRegistrationAccessToken reports whether the token is the registration access token
+// of the client (RFC 7592 §3). Clients not registered dynamically have none.
+func (s *oauthClientService) VerifyRegistrationAccessToken(
+	ctx context.Context, clientID, registrationAccessToken string,
+) (bool, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthClientSVC, "VerifyRegistrationAccessToken")
+
+	daoClient, err := s.manager.Get(ctx, clientID)
+	if err != nil {
+		return false, errorWrapf(err, "manager.Get clientID=`%s` fail", clientID)
+	}
+	if daoClient.ID == "" || daoClient.RegistrationAccessTokenHash == "" {
+		return false, nil
+	}
+
+	tokenHash := oauth.HashToken(registrationAccessToken)
+	return subtle.ConstantTimeCompare([]byte(tokenHash), []byte(daoClient.RegistrationAccessTokenHash)) == 1, nil
+}
+
+// DynamicUpdate replaces the metadata of a dynamically registered client (RFC 7592 §2.2).
+// Fields omitted from the input are reset, the client_id and registration access token are kept.
+func (s *oauthClientService) DynamicUpdate(
+	ctx context.Context, clientID string, input types.OAuthClientDynamicRegistrationInput,
+) (types.OAuthClient, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthClientSVC, "DynamicUpdate")
+
+	daoClient, err := convertDynamicRegistrationInput(clientID, input)
+	if err != nil {
+		return types.OAuthClient{}, errorWrapf(err, "convertDynamicRegistrationInput fail")
+	}
+
+	if _, err := s.manager.Update(ctx, daoClient); err != nil {
+		return types.OAuthClient{}, errorWrapf(err, "manager.Update clientID=`%s` fail", clientID)
+	}
+
+	return s.Get(ctx, clientID)
+}
+
+// Delete deletes a client and revokes all of its tokens in the same transaction,
+// for both dynamically registered clients (RFC 7592 §2.3) and the confidential clients of apps.
+//
+// Lock ordering: refresh_token table first, then access_token table (see oauthTokenService).
+func (s *oauthClientService) Delete(ctx context.Context, clientID string) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthClientSVC, "Delete")
+
+	tx, err := database.GenerateDefaultDBTx(ctx)
+	if err != nil {
+		return errorWrapf(err, "database.GenerateDefaultDBTx fail")
+	}
+	defer database.RollBackWithLog(tx)
+
+	revokedRefreshTokens, err := s.refreshTokenManager.RevokeByClientIDWithTx(ctx, tx, clientID)
+	if err != nil {
+		return errorWrapf(err, "refreshTokenManager.RevokeByClientIDWithTx clientID=`%s` fail", clientID)
+	}
+	revokedAccessTokens, err := s.accessTokenManager.RevokeByClientIDWithTx(ctx, tx, clientID)
+	if err != nil {
+		return errorWrapf(err, "accessTokenManager.RevokeByClientIDWithTx clientID=`%s` fail", clientID)
+	}
+	if _, err := s.manager.DeleteWithTx(ctx, tx, clientID); err != nil {
+		return errorWrapf(err, "manager.DeleteWithTx clientID=`%s` fail", clientID)
+	}
+	if err := tx.Commit(); err != nil {
+		return errorWrapf(err, "tx.Commit fail")
+	}
+	invalidation.Invalidate(ctx, invalidation.KindAccessToken, dao.RevokedTokenHashes(revokedAccessTokens))
+	publishRevocations(ctx, revocation.ReasonClientDeleted, revokedAccessTokens, revokedRefreshTokens)
+
+	return nil
+}
+
+// RegisterConfidential registers the confidential client of an app, whose client_id is the app_code.
+// The client authenticates with the secrets of the app, so no credential is returned.
+func (s *oauthClientService) RegisterConfidential(
+	ctx context.Context, clientID string, input types.OAuthConfidentialClientInput,
+) (types.OAuthClient, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthClientSVC, "RegisterConfidential")
+
+	daoClient, err := convertConfidentialClientInput(clientID, input)
+	if err != nil {
+		return types.OAuthClient{}, errorWrapf(err, "convertConfidentialClientInput fail")
+	}
+	daoClient.Type = oauth.ClientTypeConfidential
+
+	if err := s.manager.Create(ctx, daoClient); err != nil {
+		return types.OAuthClient{}, errorWrapf(err, "manager.Create clientID=`%s` fail", clientID)
+	}
+
+	return s.Get(ctx, clientID)
+}
+
+// UpdateConfidential replaces the metadata, including the token endpoint authentication,
+// of the confidential client of an app.
+func (s *oauthClientService) UpdateConfidential(
+	ctx context.Context, clientID string, input types.OAuthConfidentialClientInput,
+) (types.OAuthClient, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthClientSVC, "UpdateConfidential")
+
+	daoClient, err := convertConfidentialClientInput(clientID, input)
+	if err != nil {
+		return types.OAuthClient{}, errorWrapf(err, "convertConfidentialClientInput fail")
+	}
+
+	if _, err := s.manager.UpdateMetadata(ctx, daoClient); err != nil {
+		return types.OAuthClient{}, errorWrapf(err, "manager.UpdateMetadata clientID=`%s` fail", clientID)
+	}
+
+	return s.Get(ctx, clientID)
+}
+
+func convertConfidentialClientInput(
+	clientID string, input types.OAuthConfidentialClientInput,
+) (dao.OAuthClient, error) {
+	redirectURIs := input.RedirectURIs
+	if redirectURIs == nil {
+		redirectURIs = []string{}
+	}
+	redirectURIsJSON, err := json.Marshal(redirectURIs)
 	if err != nil {
-		return types.OAuthClient{}, errorWrapf(err, "json.Marshal redirectURIs fail")
+		return dao.OAuthClient{}, err
 	}
 
 	daoClient := dao.OAuthClient{
 		ID:           clientID,
 		Name:         input.Name,
-		Type:         oauth.ClientTypePublic,
 		RedirectURIs: string(redirectURIsJSON),
 		GrantTypes:   strings.Join(input.GrantTypes, ","),
 		LogoURI:      input.LogoURI,
+
+		TokenEndpointAuthMethod: input.TokenEndpointAuthMethod,
+		TLSClientAuthSubjectDN:  input.TLSClientAuthSubjectDN,
+		TLSClientAuthSAN:        input.TLSClientAuthSAN,
+		JWKSURI:                 input.JWKSURI,
+
+		AllowedRealms:    strings.Join(input.AllowedRealms, ","),
+		AllowedResources: strings.Join(input.AllowedResources, ","),
+	}
+	if input.JWKS != "" {
+		daoClient.JWKS = &input.JWKS
 	}
+	return daoClient, nil
+}
 
-	if err := s.manager.Create(ctx, daoClient); err != nil {
-		return types.OAuthClient{}, errorWrapf(err, "manager.Create fail")
+// convertDynamicRegistrationInput converts the registration input to a DAO client,
+// the Type and registration access token are left to the caller.
+func convertDynamicRegistrationInput(
+	clientID string, input types.OAuthClientDynamicRegistrationInput,
+) (dao.OAuthClient, error) {
+	redirectURIsJSON, err := json.Marshal(input.RedirectURIs)
+	if err != nil {
+		return dao.OAuthClient{}, err
 	}
 
-	return s.Get(ctx, clientID)
+	daoClient := dao.OAuthClient{
+		ID:           clientID,
+		Name:         input.Name,
+		RedirectURIs: string(redirectURIsJSON),
+		GrantTypes:   strings.Join(input.GrantTypes, ","),
+		LogoURI:      input.LogoURI,
+
+		TokenEndpointAuthMethod: input.TokenEndpointAuthMethod,
+		JWKSURI:                 input.JWKSURI,
+	}
+	if input.JWKS != "" {
+		daoClient.JWKS = &input.JWKS
+	}
+	return daoClient, nil
 }
 
 // Get retrieves an OAuth client by client ID.
@@ -135,8 +331,13 @@ func (s *oauthClientService) GetFlowSpec(ctx context.Context, clientID string) (
 
 	return types.OAuthClientFlowSpec{
 		ID:           daoGrants.ID,
+		Type:         daoGrants.Type,
 		GrantTypes:   strings.Split(daoGrants.GrantTypes, ","),
 		RedirectURIs: redirectURIs,
+		Policy: oauth.ClientPolicy{
+			AllowedRealms:    util.SplitCommaList(daoGrants.AllowedRealms),
+			AllowedResources: util.SplitCommaList(daoGrants.AllowedResources),
+		},
 	}, nil
 }
 
@@ -162,6 +363,40 @@ func (s *oauthClientService) GetProfile(ctx context.Context, clientID string) (t
 	}, nil
 }
 
+// GetAuthSpec retrieves the registered token endpoint authentication of the client.
+// The auth method falls back to the one derived from the client type when not registered.
+// Returns a zero-value OAuthClientAuthSpec (ID == "") with nil error when the client does not exist.
+func (s *oauthClientService) GetAuthSpec(ctx context.Context, clientID string) (types.OAuthClientAuthSpec, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthClientSVC, "GetAuthSpec")
+
+	daoAuth, err := s.manager.GetAuthentication(ctx, clientID)
+	if err != nil {
+		return types.OAuthClientAuthSpec{}, errorWrapf(err, "manager.GetAuthentication clientID=`%s` fail", clientID)
+	}
+
+	if daoAuth.ID == "" {
+		return types.OAuthClientAuthSpec{}, nil
+	}
+
+	authMethod := daoAuth.TokenEndpointAuthMethod
+	if authMethod == "" {
+		authMethod = types.OAuthClient{Type: daoAuth.Type}.TokenEndpointAuthMethod()
+	}
+
+	spec := types.OAuthClientAuthSpec{
+		ID:                      daoAuth.ID,
+		Type:                    daoAuth.Type,
+		TokenEndpointAuthMethod: authMethod,
+		TLSClientAuthSubjectDN:  daoAuth.TLSClientAuthSubjectDN,
+		TLSClientAuthSAN:        daoAuth.TLSClientAuthSAN,
+		JWKSURI:                 daoAuth.JWKSURI,
+	}
+	if daoAuth.JWKS != nil {
+		spec.JWKS = *daoAuth.JWKS
+	}
+	return spec, nil
+}
+
 // convertToTypes converts a DAO client to a service types client
 func (s *oauthClientService) convertToTypes(daoClient dao.OAuthClient) (types.OAuthClient, error) {
 	var redirectURIs []string
@@ -169,7 +404,7 @@ func (s *oauthClientService) convertToTypes(daoClient dao.OAuthClient) (types.OA
 		return types.OAuthClient{}, err
 	}
 
-	return types.OAuthClient{
+	client := types.OAuthClient{
 		ID:           daoClient.ID,
 		Name:         daoClient.Name,
 		Type:         daoClient.Type,
@@ -177,5 +412,17 @@ func (s *oauthClientService) convertToTypes(daoClient dao.OAuthClient) (types.OA
 		GrantTypes:   strings.Split(daoClient.GrantTypes, ","),
 		LogoURI:      daoClient.LogoURI,
 		CreatedAt:    daoClient.CreatedAt.Unix(),
-	}, nil
+		AuthMethod:   daoClient.TokenEndpointAuthMethod,
+		JWKSURI:      daoClient.JWKSURI,
+
+		TLSClientAuthSubjectDN: daoClient.TLSClientAuthSubjectDN,
+		TLSClientAuthSAN:       daoClient.TLSClientAuthSAN,
+
+		AllowedRealms:    util.SplitCommaList(daoClient.AllowedRealms),
+		AllowedResources: util.SplitCommaList(daoClient.AllowedResources),
+	}
+	if daoClient.JWKS != nil {
+		client.JWKS = *daoClient.JWKS
+	}
+	return client, nil
 }
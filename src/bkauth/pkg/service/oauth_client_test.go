@@ -27,8 +27,10 @@ import (
 	. "github.com/onsi/gomega"
 	"go.uber.org/mock/gomock"
 
+	"bkauth/pkg/database"
 	"bkauth/pkg/database/dao"
 	"bkauth/pkg/database/dao/mock"
+	"bkauth/pkg/oauth"
 	"bkauth/pkg/service/types"
 )
 
@@ -223,6 +225,60 @@ var _ = Describe("oauthClientService", func() {
 		})
 	})
 
+	Describe("GetAuthSpec", func() {
+		It("should return zero-value when client does not exist", func() {
+			mockManager.EXPECT().GetAuthentication(gomock.Any(), "nonexistent").
+				Return(dao.OAuthClientAuthentication{}, nil)
+
+			spec, err := svc.GetAuthSpec(ctx, "nonexistent")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spec).To(Equal(types.OAuthClientAuthSpec{}))
+		})
+
+		It("should derive the auth method from the client type when not registered", func() {
+			mockManager.EXPECT().GetAuthentication(gomock.Any(), "my-app").
+				Return(dao.OAuthClientAuthentication{ID: "my-app", Type: "confidential"}, nil)
+
+			spec, err := svc.GetAuthSpec(ctx, "my-app")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spec.TokenEndpointAuthMethod).To(Equal(oauth.AuthMethodClientSecretBasic))
+			Expect(spec.IsTLSClientAuth()).To(BeFalse())
+		})
+
+		It("should map the registered mutual-TLS authentication", func() {
+			jwks := `{"keys":[]}`
+			mockManager.EXPECT().GetAuthentication(gomock.Any(), "my-app").
+				Return(dao.OAuthClientAuthentication{
+					ID:                      "my-app",
+					Type:                    "confidential",
+					TokenEndpointAuthMethod: oauth.AuthMethodSelfSignedTLSClientAuth,
+					JWKS:                    &jwks,
+				}, nil)
+
+			spec, err := svc.GetAuthSpec(ctx, "my-app")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spec).To(Equal(types.OAuthClientAuthSpec{
+				ID:                      "my-app",
+				Type:                    "confidential",
+				TokenEndpointAuthMethod: oauth.AuthMethodSelfSignedTLSClientAuth,
+				JWKS:                    jwks,
+			}))
+			Expect(spec.IsTLSClientAuth()).To(BeTrue())
+		})
+
+		It("should propagate manager errors", func() {
+			mockManager.EXPECT().GetAuthentication(gomock.Any(), "my-app").
+				Return(dao.OAuthClientAuthentication{}, errors.New("db error"))
+
+			_, err := svc.GetAuthSpec(ctx, "my-app")
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	Describe("DynamicRegister", func() {
 		It("should create client and return the registered client", func() {
 			now := time.Now()
@@ -231,6 +287,7 @@ var _ = Describe("oauthClientService", func() {
 				DoAndReturn(func(_ context.Context, client dao.OAuthClient) error {
 					Expect(client.Type).To(Equal("public"))
 					Expect(client.Name).To(Equal("Test App"))
+					Expect(client.RegistrationAccessTokenHash).NotTo(BeEmpty())
 					return nil
 				})
 			mockManager.EXPECT().Get(gomock.Any(), gomock.Any()).
@@ -251,6 +308,40 @@ var _ = Describe("oauthClientService", func() {
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(client.Name).To(Equal("Test App"))
+			Expect(client.RegistrationAccessToken).To(HavePrefix(oauth.RegistrationAccessTokenPrefix))
+		})
+
+		It("should store the registered private_key_jwt keys", func() {
+			mockManager.EXPECT().
+				Create(gomock.Any(), gomock.AssignableToTypeOf(dao.OAuthClient{})).
+				DoAndReturn(func(_ context.Context, client dao.OAuthClient) error {
+					Expect(client.TokenEndpointAuthMethod).To(Equal("private_key_jwt"))
+					Expect(client.JWKS).To(BeNil())
+					Expect(client.JWKSURI).To(Equal("https://example.com/jwks.json"))
+					return nil
+				})
+			mockManager.EXPECT().Get(gomock.Any(), gomock.Any()).
+				Return(dao.OAuthClient{
+					ID:                      "dcr_placeholder",
+					Name:                    "Test App",
+					Type:                    "public",
+					RedirectURIs:            `["https://example.com/cb"]`,
+					GrantTypes:              "authorization_code",
+					TokenEndpointAuthMethod: "private_key_jwt",
+					JWKSURI:                 "https://example.com/jwks.json",
+				}, nil)
+
+			client, err := svc.DynamicRegister(ctx, types.OAuthClientDynamicRegistrationInput{
+				Name:                    "Test App",
+				RedirectURIs:            []string{"https://example.com/cb"},
+				GrantTypes:              []string{"authorization_code"},
+				TokenEndpointAuthMethod: "private_key_jwt",
+				JWKSURI:                 "https://example.com/jwks.json",
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.TokenEndpointAuthMethod()).To(Equal("private_key_jwt"))
+			Expect(client.JWKSURI).To(Equal("https://example.com/jwks.json"))
 		})
 
 		It("should propagate create errors", func() {
@@ -268,4 +359,265 @@ var _ = Describe("oauthClientService", func() {
 			Expect(err.Error()).To(ContainSubstring("manager.Create fail"))
 		})
 	})
+
+	Describe("VerifyRegistrationAccessToken", func() {
+		It("should accept the registration access token of the client", func() {
+			mockManager.EXPECT().Get(gomock.Any(), "dcr_abc").Return(dao.OAuthClient{
+				ID:                          "dcr_abc",
+				RegistrationAccessTokenHash: oauth.HashToken("bkreg_token"),
+			}, nil)
+
+			ok, err := svc.VerifyRegistrationAccessToken(ctx, "dcr_abc", "bkreg_token")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should reject another token", func() {
+			mockManager.EXPECT().Get(gomock.Any(), "dcr_abc").Return(dao.OAuthClient{
+				ID:                          "dcr_abc",
+				RegistrationAccessTokenHash: oauth.HashToken("bkreg_token"),
+			}, nil)
+
+			ok, err := svc.VerifyRegistrationAccessToken(ctx, "dcr_abc", "bkreg_other")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should reject clients without a registration access token", func() {
+			mockManager.EXPECT().Get(gomock.Any(), "my-app").Return(dao.OAuthClient{ID: "my-app"}, nil)
+
+			ok, err := svc.VerifyRegistrationAccessToken(ctx, "my-app", "")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("DynamicUpdate", func() {
+		It("should overwrite the client metadata", func() {
+			mockManager.EXPECT().
+				Update(gomock.Any(), gomock.AssignableToTypeOf(dao.OAuthClient{})).
+				DoAndReturn(func(_ context.Context, client dao.OAuthClient) (int64, error) {
+					Expect(client.ID).To(Equal("dcr_abc"))
+					Expect(client.RedirectURIs).To(Equal(`["https://example.com/cb2"]`))
+					Expect(client.RegistrationAccessTokenHash).To(BeEmpty())
+					return 1, nil
+				})
+			mockManager.EXPECT().Get(gomock.Any(), "dcr_abc").Return(dao.OAuthClient{
+				ID:           "dcr_abc",
+				Name:         "Renamed App",
+				Type:         "public",
+				RedirectURIs: `["https://example.com/cb2"]`,
+				GrantTypes:   "authorization_code",
+			}, nil)
+
+			client, err := svc.DynamicUpdate(ctx, "dcr_abc", types.OAuthClientDynamicRegistrationInput{
+				Name:         "Renamed App",
+				RedirectURIs: []string{"https://example.com/cb2"},
+				GrantTypes:   []string{"authorization_code"},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.Name).To(Equal("Renamed App"))
+		})
+	})
+
+	Describe("RegisterConfidential", func() {
+		It("should create the confidential client of the app", func() {
+			mockManager.EXPECT().
+				Create(gomock.Any(), gomock.AssignableToTypeOf(dao.OAuthClient{})).
+				DoAndReturn(func(_ context.Context, client dao.OAuthClient) error {
+					Expect(client.ID).To(Equal("my-app"))
+					Expect(client.Type).To(Equal(oauth.ClientTypeConfidential))
+					Expect(client.RedirectURIs).To(Equal(`[]`))
+					Expect(client.GrantTypes).To(Equal("client_credentials"))
+					Expect(client.RegistrationAccessTokenHash).To(BeEmpty())
+					return nil
+				})
+			mockManager.EXPECT().Get(gomock.Any(), "my-app").Return(dao.OAuthClient{
+				ID:           "my-app",
+				Name:         "My App",
+				Type:         oauth.ClientTypeConfidential,
+				RedirectURIs: `[]`,
+				GrantTypes:   "client_credentials",
+			}, nil)
+
+			client, err := svc.RegisterConfidential(ctx, "my-app", types.OAuthConfidentialClientInput{
+				Name:       "My App",
+				GrantTypes: []string{oauth.GrantTypeClientCredentials},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.ID).To(Equal("my-app"))
+			Expect(client.Type).To(Equal(oauth.ClientTypeConfidential))
+		})
+
+		It("should write the registered token endpoint authentication", func() {
+			mockManager.EXPECT().
+				Create(gomock.Any(), gomock.AssignableToTypeOf(dao.OAuthClient{})).
+				DoAndReturn(func(_ context.Context, client dao.OAuthClient) error {
+					Expect(client.TokenEndpointAuthMethod).To(Equal(oauth.AuthMethodTLSClientAuth))
+					Expect(client.TLSClientAuthSubjectDN).To(BeEmpty())
+					Expect(client.TLSClientAuthSAN).To(Equal("my-app.example.com"))
+					Expect(client.JWKS).To(BeNil())
+					return nil
+				})
+			mockManager.EXPECT().Get(gomock.Any(), "my-app").Return(dao.OAuthClient{
+				ID:                      "my-app",
+				Name:                    "My App",
+				Type:                    oauth.ClientTypeConfidential,
+				RedirectURIs:            `[]`,
+				GrantTypes:              "client_credentials",
+				TokenEndpointAuthMethod: oauth.AuthMethodTLSClientAuth,
+				TLSClientAuthSAN:        "my-app.example.com",
+			}, nil)
+
+			client, err := svc.RegisterConfidential(ctx, "my-app", types.OAuthConfidentialClientInput{
+				Name:                    "My App",
+				GrantTypes:              []string{oauth.GrantTypeClientCredentials},
+				TokenEndpointAuthMethod: oauth.AuthMethodTLSClientAuth,
+				TLSClientAuthSAN:        "my-app.example.com",
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.TokenEndpointAuthMethod()).To(Equal(oauth.AuthMethodTLSClientAuth))
+			Expect(client.TLSClientAuthSAN).To(Equal("my-app.example.com"))
+		})
+
+		It("should propagate create errors", func() {
+			mockManager.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+
+			_, err := svc.RegisterConfidential(ctx, "my-app", types.OAuthConfidentialClientInput{
+				Name:       "My App",
+				GrantTypes: []string{oauth.GrantTypeClientCredentials},
+			})
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("UpdateConfidential", func() {
+		It("should update the client metadata", func() {
+			mockManager.EXPECT().
+				UpdateMetadata(gomock.Any(), gomock.AssignableToTypeOf(dao.OAuthClient{})).
+				DoAndReturn(func(_ context.Context, client dao.OAuthClient) (int64, error) {
+					Expect(client.ID).To(Equal("my-app"))
+					Expect(client.Name).To(Equal("Renamed App"))
+					Expect(client.RedirectURIs).To(Equal(`["https://example.com/cb"]`))
+					Expect(client.LogoURI).To(Equal("https://example.com/logo.png"))
+					return 1, nil
+				})
+			mockManager.EXPECT().Get(gomock.Any(), "my-app").Return(dao.OAuthClient{
+				ID:           "my-app",
+				Name:         "Renamed App",
+				Type:         oauth.ClientTypeConfidential,
+				RedirectURIs: `["https://example.com/cb"]`,
+				GrantTypes:   "authorization_code",
+				LogoURI:      "https://example.com/logo.png",
+			}, nil)
+
+			client, err := svc.UpdateConfidential(ctx, "my-app", types.OAuthConfidentialClientInput{
+				Name:         "Renamed App",
+				RedirectURIs: []string{"https://example.com/cb"},
+				GrantTypes:   []string{oauth.GrantTypeAuthorizationCode},
+				LogoURI:      "https://example.com/logo.png",
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.Name).To(Equal("Renamed App"))
+			Expect(client.LogoURI).To(Equal("https://example.com/logo.png"))
+		})
+
+		It("should replace the token endpoint authentication", func() {
+			jwks := `{"keys":[]}`
+			mockManager.EXPECT().
+				UpdateMetadata(gomock.Any(), gomock.AssignableToTypeOf(dao.OAuthClient{})).
+				DoAndReturn(func(_ context.Context, client dao.OAuthClient) (int64, error) {
+					Expect(client.TokenEndpointAuthMethod).To(Equal(oauth.AuthMethodSelfSignedTLSClientAuth))
+					Expect(client.TLSClientAuthSubjectDN).To(BeEmpty())
+					Expect(client.TLSClientAuthSAN).To(BeEmpty())
+					Expect(client.JWKS).To(HaveValue(Equal(jwks)))
+					Expect(client.JWKSURI).To(BeEmpty())
+					return 1, nil
+				})
+			mockManager.EXPECT().Get(gomock.Any(), "my-app").Return(dao.OAuthClient{
+				ID:                      "my-app",
+				Name:                    "My App",
+				Type:                    oauth.ClientTypeConfidential,
+				RedirectURIs:            `[]`,
+				GrantTypes:              "client_credentials",
+				TokenEndpointAuthMethod: oauth.AuthMethodSelfSignedTLSClientAuth,
+				JWKS:                    &jwks,
+			}, nil)
+
+			client, err := svc.UpdateConfidential(ctx, "my-app", types.OAuthConfidentialClientInput{
+				Name:                    "My App",
+				GrantTypes:              []string{oauth.GrantTypeClientCredentials},
+				TokenEndpointAuthMethod: oauth.AuthMethodSelfSignedTLSClientAuth,
+				JWKS:                    jwks,
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.TokenEndpointAuthMethod()).To(Equal(oauth.AuthMethodSelfSignedTLSClientAuth))
+			Expect(client.JWKS).To(Equal(jwks))
+		})
+	})
+
+	Describe("Delete", func() {
+		var (
+			mockAccessManager  *mock.MockOAuthAccessTokenManager
+			mockRefreshManager *mock.MockOAuthRefreshTokenManager
+		)
+
+		BeforeEach(func() {
+			mockAccessManager = mock.NewMockOAuthAccessTokenManager(ctl)
+			mockRefreshManager = mock.NewMockOAuthRefreshTokenManager(ctl)
+			svc.accessTokenManager = mockAccessManager
+			svc.refreshTokenManager = mockRefreshManager
+		})
+
+		It("should revoke the tokens of the client before deleting it", func() {
+			first := mockRefreshManager.EXPECT().
+				RevokeByClientIDWithTx(gomock.Any(), gomock.Any(), "dcr_abc").
+				Return([]dao.RevokedToken{{TokenHash: "rt-hash-1"}}, nil)
+			second := mockAccessManager.EXPECT().
+				RevokeByClientIDWithTx(gomock.Any(), gomock.Any(), "dcr_abc").
+				Return([]dao.RevokedToken{{TokenHash: "hash-1"}, {TokenHash: "hash-2"}}, nil).
+				After(first)
+			mockManager.EXPECT().
+				DeleteWithTx(gomock.Any(), gomock.Any(), "dcr_abc").
+				Return(int64(1), nil).
+				After(second)
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+			restore := useMockDefaultDB(db)
+			defer restore()
+
+			err := svc.Delete(ctx, "dcr_abc")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dbMock.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("should not delete the client when revoking fails", func() {
+			mockRefreshManager.EXPECT().
+				RevokeByClientIDWithTx(gomock.Any(), gomock.Any(), "dcr_abc").
+				Return(nil, errors.New("lock wait timeout"))
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectRollback()
+			restore := useMockDefaultDB(db)
+			defer restore()
+
+			err := svc.Delete(ctx, "dcr_abc")
+
+			Expect(err).To(HaveOccurred())
+			Expect(dbMock.ExpectationsWereMet()).To(Succeed())
+		})
+	})
 })
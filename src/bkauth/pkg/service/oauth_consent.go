@@ -0,0 +1,144 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+//go:generate mockgen -source=$GOFILE -destination=./mock/$GOFILE -package=mock
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"bkauth/pkg/database/dao"
+	"bkauth/pkg/errorx"
+	"bkauth/pkg/oauth"
+	"bkauth/pkg/service/types"
+	"bkauth/pkg/util"
+)
+
+const OAuthConsentSVC = "OAuthConsentSVC"
+
+// OAuthConsentService defines the interface for persisted user consent operations.
+type OAuthConsentService interface {
+	IsGranted(ctx context.Context, grant types.ConsentGrant) (bool, error)
+	Grant(ctx context.Context, grant types.ConsentGrant, ttl int64) error
+	Revoke(ctx context.Context, grant types.ConsentGrant) error
+}
+
+type oauthConsentService struct {
+	manager dao.OAuthConsentManager
+}
+
+// NewOAuthConsentService creates a new OAuthConsentService.
+func NewOAuthConsentService() OAuthConsentService {
+	return &oauthConsentService{
+		manager: dao.NewOAuthConsentManager(),
+	}
+}
+
+// getValid returns the unexpired consent of the user for the client, or nil.
+func (s *oauthConsentService) getValid(
+	ctx context.Context, grant types.ConsentGrant,
+) (*dao.OAuthConsent, []string, error) {
+	consent, err := s.manager.Get(ctx, grant.TenantID, grant.Sub, grant.RealmName, grant.ClientID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if consent.ID == 0 || time.Now().After(consent.ExpiresAt) {
+		return nil, nil, nil
+	}
+
+	var audience []string
+	if err := json.Unmarshal([]byte(consent.Audience), &audience); err != nil {
+		return nil, nil, err
+	}
+	return &consent, audience, nil
+}
+
+// IsGranted reports whether the user has an unexpired consent for the client
+// that covers every requested audience and scope.
+func (s *oauthConsentService) IsGranted(ctx context.Context, grant types.ConsentGrant) (bool, error) {
+	consent, audience, err := s.getValid(ctx, grant)
+	if err != nil {
+		return false, errorx.Wrapf(err, OAuthConsentSVC, "IsGranted",
+			"getValid sub=`%s` clientID=`%s` fail", grant.Sub, grant.ClientID)
+	}
+	if consent == nil {
+		return false, nil
+	}
+
+	return oauth.ConsentCovers(audience, consent.Scope, grant.Audience, grant.Scope), nil
+}
+
+// Grant records that the user approved the audiences and scope for the client, for ttl seconds.
+// An unexpired consent is extended with the new audiences and scopes rather than replaced,
+// so approving a second resource does not make the client ask again for the first one.
+func (s *oauthConsentService) Grant(ctx context.Context, grant types.ConsentGrant, ttl int64) error {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthConsentSVC, "Grant")
+
+	consent, audience, err := s.getValid(ctx, grant)
+	if err != nil {
+		return errorWrapf(err, "getValid sub=`%s` clientID=`%s` fail", grant.Sub, grant.ClientID)
+	}
+
+	scopes := oauth.ParseScope(grant.Scope)
+	if consent != nil {
+		audience = util.Deduplicate(append(audience, grant.Audience...))
+		scopes = util.Deduplicate(append(oauth.ParseScope(consent.Scope), scopes...))
+	} else {
+		audience = grant.Audience
+	}
+	if audience == nil {
+		audience = []string{}
+	}
+
+	audienceJSON, err := json.Marshal(audience)
+	if err != nil {
+		return errorWrapf(err, "json.Marshal audience fail")
+	}
+
+	now := time.Now()
+	err = s.manager.Upsert(ctx, dao.OAuthConsent{
+		TenantID:  grant.TenantID,
+		Sub:       grant.Sub,
+		RealmName: grant.RealmName,
+		ClientID:  grant.ClientID,
+		Audience:  string(audienceJSON),
+		Scope:     strings.Join(scopes, " "),
+		GrantedAt: now,
+		ExpiresAt: now.Add(time.Duration(ttl) * time.Second),
+	})
+	if err != nil {
+		return errorWrapf(err, "manager.Upsert fail")
+	}
+
+	return nil
+}
+
+// Revoke forgets the consent of the user for the client, so that the consent page
+// is shown again on the next authorization request. Audience and Scope are ignored.
+func (s *oauthConsentService) Revoke(ctx context.Context, grant types.ConsentGrant) error {
+	_, err := s.manager.Delete(ctx, grant.TenantID, grant.Sub, grant.RealmName, grant.ClientID)
+	if err != nil {
+		return errorx.Wrapf(err, OAuthConsentSVC, "Revoke",
+			"manager.Delete sub=`%s` clientID=`%s` fail", grant.Sub, grant.ClientID)
+	}
+	return nil
+}
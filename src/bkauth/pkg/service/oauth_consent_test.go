@@ -0,0 +1,185 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"bkauth/pkg/database/dao"
+	"bkauth/pkg/database/dao/mock"
+	"bkauth/pkg/service/types"
+)
+
+var _ = Describe("oauthConsentService", func() {
+	var (
+		ctx         context.Context
+		ctl         *gomock.Controller
+		mockManager *mock.MockOAuthConsentManager
+		svc         oauthConsentService
+		grant       types.ConsentGrant
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctl = gomock.NewController(GinkgoT())
+		mockManager = mock.NewMockOAuthConsentManager(ctl)
+		svc = oauthConsentService{manager: mockManager}
+		grant = types.ConsentGrant{
+			TenantID:  "default",
+			Sub:       "user1",
+			RealmName: "blueking",
+			ClientID:  "dcr_abc",
+			Audience:  []string{"mcp:foo"},
+			Scope:     "openid",
+		}
+	})
+
+	AfterEach(func() {
+		ctl.Finish()
+	})
+
+	storedConsent := func(audience, scope string, expiresAt time.Time) dao.OAuthConsent {
+		return dao.OAuthConsent{
+			ID:        1,
+			TenantID:  "default",
+			Sub:       "user1",
+			RealmName: "blueking",
+			ClientID:  "dcr_abc",
+			Audience:  audience,
+			Scope:     scope,
+			ExpiresAt: expiresAt,
+		}
+	}
+
+	Describe("IsGranted", func() {
+		It("should be granted when a valid consent covers the request", func() {
+			mockManager.EXPECT().Get(gomock.Any(), "default", "user1", "blueking", "dcr_abc").
+				Return(storedConsent(`["mcp:foo","mcp:bar"]`, "openid profile", time.Now().Add(time.Hour)), nil)
+
+			ok, err := svc.IsGranted(ctx, grant)
+
+			assert.NoError(GinkgoT(), err)
+			assert.True(GinkgoT(), ok)
+		})
+
+		It("should not be granted for a new resource", func() {
+			mockManager.EXPECT().Get(gomock.Any(), "default", "user1", "blueking", "dcr_abc").
+				Return(storedConsent(`["mcp:bar"]`, "openid", time.Now().Add(time.Hour)), nil)
+
+			ok, err := svc.IsGranted(ctx, grant)
+
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), ok)
+		})
+
+		It("should not be granted when the consent has expired", func() {
+			mockManager.EXPECT().Get(gomock.Any(), "default", "user1", "blueking", "dcr_abc").
+				Return(storedConsent(`["mcp:foo"]`, "openid", time.Now().Add(-time.Minute)), nil)
+
+			ok, err := svc.IsGranted(ctx, grant)
+
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), ok)
+		})
+
+		It("should not be granted without a consent", func() {
+			mockManager.EXPECT().Get(gomock.Any(), "default", "user1", "blueking", "dcr_abc").
+				Return(dao.OAuthConsent{}, nil)
+
+			ok, err := svc.IsGranted(ctx, grant)
+
+			assert.NoError(GinkgoT(), err)
+			assert.False(GinkgoT(), ok)
+		})
+
+		It("should propagate the manager error", func() {
+			mockManager.EXPECT().Get(gomock.Any(), "default", "user1", "blueking", "dcr_abc").
+				Return(dao.OAuthConsent{}, errors.New("db error"))
+
+			_, err := svc.IsGranted(ctx, grant)
+
+			assert.Error(GinkgoT(), err)
+		})
+	})
+
+	Describe("Grant", func() {
+		It("should create a consent expiring after ttl", func() {
+			mockManager.EXPECT().Get(gomock.Any(), "default", "user1", "blueking", "dcr_abc").
+				Return(dao.OAuthConsent{}, nil)
+			mockManager.EXPECT().Upsert(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, consent dao.OAuthConsent) error {
+					assert.Equal(GinkgoT(), `["mcp:foo"]`, consent.Audience)
+					assert.Equal(GinkgoT(), "openid", consent.Scope)
+					assert.WithinDuration(GinkgoT(), time.Now().Add(time.Hour), consent.ExpiresAt, 5*time.Second)
+					return nil
+				})
+
+			err := svc.Grant(ctx, grant, 3600)
+
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("should extend a valid consent with the new audiences and scopes", func() {
+			mockManager.EXPECT().Get(gomock.Any(), "default", "user1", "blueking", "dcr_abc").
+				Return(storedConsent(`["mcp:bar"]`, "profile", time.Now().Add(time.Hour)), nil)
+			mockManager.EXPECT().Upsert(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, consent dao.OAuthConsent) error {
+					assert.Equal(GinkgoT(), `["mcp:bar","mcp:foo"]`, consent.Audience)
+					assert.Equal(GinkgoT(), "profile openid", consent.Scope)
+					return nil
+				})
+
+			err := svc.Grant(ctx, grant, 3600)
+
+			assert.NoError(GinkgoT(), err)
+		})
+
+		It("should replace an expired consent", func() {
+			mockManager.EXPECT().Get(gomock.Any(), "default", "user1", "blueking", "dcr_abc").
+				Return(storedConsent(`["mcp:bar"]`, "profile", time.Now().Add(-time.Minute)), nil)
+			mockManager.EXPECT().Upsert(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, consent dao.OAuthConsent) error {
+					assert.Equal(GinkgoT(), `["mcp:foo"]`, consent.Audience)
+					assert.Equal(GinkgoT(), "openid", consent.Scope)
+					return nil
+				})
+
+			err := svc.Grant(ctx, grant, 3600)
+
+			assert.NoError(GinkgoT(), err)
+		})
+	})
+
+	Describe("Revoke", func() {
+		It("should delete the consent of the user for the client", func() {
+			mockManager.EXPECT().Delete(gomock.Any(), "default", "user1", "blueking", "dcr_abc").
+				Return(int64(1), nil)
+
+			err := svc.Revoke(ctx, grant)
+
+			assert.NoError(GinkgoT(), err)
+		})
+	})
+})
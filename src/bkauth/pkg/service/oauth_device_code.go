@@ -35,7 +35,10 @@ const OAuthDeviceCodeSVC = "OAuthDeviceCodeSVC"
 
 // OAuthDeviceCodeService defines the interface for device code operations
 type OAuthDeviceCodeService interface {
-	CreateDeviceCode(ctx context.Context, realmName, clientID, resource string) (types.CreatedDeviceCode, error)
+	CreateDeviceCode(
+		ctx context.Context, realmName, clientID, resource, scope string,
+		authorizationDetails []oauth.AuthorizationDetail,
+	) (types.CreatedDeviceCode, error)
 	GetByUserCode(ctx context.Context, userCode string) (types.PendingDeviceCode, error)
 	ApproveByUserCode(ctx context.Context, tenantID, userCode, sub, username string, audience []string) error
 	DenyByUserCode(ctx context.Context, userCode string) error
@@ -55,7 +58,8 @@ func NewOAuthDeviceCodeService() OAuthDeviceCodeService {
 
 func (s *oauthDeviceCodeService) CreateDeviceCode(
 	ctx context.Context,
-	realmName, clientID, resource string,
+	realmName, clientID, resource, scope string,
+	authorizationDetails []oauth.AuthorizationDetail,
 ) (types.CreatedDeviceCode, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthDeviceCodeSVC, "CreateDeviceCode")
 
@@ -71,15 +75,19 @@ func (s *oauthDeviceCodeService) CreateDeviceCode(
 
 	expiresAt := time.Now().Add(time.Duration(oauth.DeviceCodeTTL) * time.Second)
 
+	deviceCodeHash := oauth.HashTokenForStorage(deviceCode)
 	daoDeviceCode := dao.OAuthDeviceCode{
-		DeviceCode:   deviceCode,
-		UserCode:     userCode,
-		ClientID:     clientID,
-		Resource:     resource,
-		RealmName:    realmName,
-		Status:       oauth.DeviceCodeStatusPending,
-		PollInterval: oauth.DeviceCodeInterval,
-		ExpiresAt:    expiresAt,
+		DeviceCodeHash:       deviceCodeHash.Hash,
+		DeviceCodeHashKeyID:  deviceCodeHash.KeyID,
+		UserCode:             userCode,
+		ClientID:             clientID,
+		Resource:             resource,
+		AuthorizationDetails: oauth.FormatAuthorizationDetails(authorizationDetails),
+		Scope:                scope,
+		RealmName:            realmName,
+		Status:               oauth.DeviceCodeStatusPending,
+		PollInterval:         oauth.DeviceCodeInterval,
+		ExpiresAt:            expiresAt,
 	}
 
 	if _, err := s.deviceCodeManager.Create(ctx, daoDeviceCode); err != nil {
@@ -115,10 +123,17 @@ func (s *oauthDeviceCodeService) GetByUserCode(ctx context.Context, userCode str
 		return types.PendingDeviceCode{}, oauth.ErrUserCodeAlreadyUsed
 	}
 
+	authorizationDetails, err := oauth.ParseAuthorizationDetails(dc.AuthorizationDetails)
+	if err != nil {
+		return types.PendingDeviceCode{}, errorWrapf(err, "oauth.ParseAuthorizationDetails fail")
+	}
+
 	return types.PendingDeviceCode{
-		ClientID:  dc.ClientID,
-		RealmName: dc.RealmName,
-		Resource:  dc.Resource,
+		ClientID:             dc.ClientID,
+		RealmName:            dc.RealmName,
+		Resource:             dc.Resource,
+		AuthorizationDetails: authorizationDetails,
+		Scope:                dc.Scope,
 	}, nil
 }
 
@@ -196,9 +211,11 @@ func (s *oauthDeviceCodeService) PollAndConsumeDeviceCode(
 ) (types.ApprovedDeviceCode, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthDeviceCodeSVC, "PollAndConsumeDeviceCode")
 
-	dc, err := s.deviceCodeManager.GetByDeviceCode(ctx, deviceCode)
+	// device codes created before they were hashed are stored as is
+	deviceCodeHashes := append(oauth.TokenHashValues(oauth.TokenHashCandidates(deviceCode)), deviceCode)
+	dc, err := s.deviceCodeManager.GetByDeviceCodeHashes(ctx, deviceCodeHashes)
 	if err != nil {
-		return types.ApprovedDeviceCode{}, errorWrapf(err, "deviceCodeManager.GetByDeviceCode fail")
+		return types.ApprovedDeviceCode{}, errorWrapf(err, "deviceCodeManager.GetByDeviceCodeHashes fail")
 	}
 
 	if dc.ID == 0 {
@@ -253,9 +270,9 @@ func (s *oauthDeviceCodeService) PollAndConsumeDeviceCode(
 	//
 	// Other theoretically possible causes (code deleted by TTL cleanup, or code
 	// expired between Get and UPDATE) are not handled here because:
-	//   - TTL cleanup runs at coarse intervals (minutes/hours), never races with in-flight requests.
+	//   - TTL cleanup (pkg/janitor) only deletes codes expired for longer than the retention.
 	//   - Expiry was already validated above; the sub-millisecond window is negligible.
-	rowsAffected, err := s.deviceCodeManager.ConsumeApproved(ctx, deviceCode, clientID)
+	rowsAffected, err := s.deviceCodeManager.ConsumeApproved(ctx, dc.DeviceCodeHash, clientID)
 	if err != nil {
 		return types.ApprovedDeviceCode{}, errorWrapf(err, "deviceCodeManager.ConsumeApproved fail")
 	}
@@ -267,11 +284,17 @@ func (s *oauthDeviceCodeService) PollAndConsumeDeviceCode(
 		TenantID: dc.TenantID,
 		Sub:      dc.Sub,
 		Username: dc.Username,
+		Resource: dc.Resource,
+		Scope:    dc.Scope,
 	}
 	if dc.Audience != nil {
 		if err := json.Unmarshal([]byte(*dc.Audience), &approved.Audience); err != nil {
 			return types.ApprovedDeviceCode{}, errorWrapf(err, "json.Unmarshal audience fail")
 		}
 	}
+	approved.AuthorizationDetails, err = oauth.ParseAuthorizationDetails(dc.AuthorizationDetails)
+	if err != nil {
+		return types.ApprovedDeviceCode{}, errorWrapf(err, "oauth.ParseAuthorizationDetails fail")
+	}
 	return approved, nil
 }
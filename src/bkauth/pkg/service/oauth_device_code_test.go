@@ -34,18 +34,23 @@ import (
 
 func newPendingDeviceCode() dao.OAuthDeviceCode {
 	return dao.OAuthDeviceCode{
-		ID:           1,
-		DeviceCode:   "device-1",
-		UserCode:     "ABCD-EFGH",
-		ClientID:     "client-1",
-		RealmName:    "blueking",
-		Resource:     "bk_paas",
-		Status:       oauth.DeviceCodeStatusPending,
-		PollInterval: oauth.DeviceCodeInterval,
-		ExpiresAt:    time.Now().Add(time.Minute),
+		ID:             1,
+		DeviceCodeHash: oauth.HashToken("device-1"),
+		UserCode:       "ABCD-EFGH",
+		ClientID:       "client-1",
+		RealmName:      "blueking",
+		Resource:       "bk_paas",
+		Status:         oauth.DeviceCodeStatusPending,
+		PollInterval:   oauth.DeviceCodeInterval,
+		ExpiresAt:      time.Now().Add(time.Minute),
 	}
 }
 
+// deviceCodeLookupHashes returns the hashes a device code is looked up by when no hash key is registered
+func deviceCodeLookupHashes(deviceCode string) []string {
+	return []string{oauth.HashToken(deviceCode), deviceCode}
+}
+
 var _ = Describe("oauthDeviceCodeService", func() {
 	var (
 		ctl         *gomock.Controller
@@ -103,6 +108,20 @@ var _ = Describe("oauthDeviceCodeService", func() {
 			assert.Equal(GinkgoT(), "client-1", result.ClientID)
 			assert.Equal(GinkgoT(), "blueking", result.RealmName)
 			assert.Equal(GinkgoT(), "bk_paas", result.Resource)
+			assert.Nil(GinkgoT(), result.AuthorizationDetails)
+		})
+
+		It("should return the requested authorization details", func() {
+			dc := newPendingDeviceCode()
+			dc.AuthorizationDetails = `[{"type":"bk_mcp_server","identifier":"bk-log"}]`
+			mockManager.EXPECT().GetByUserCode(gomock.Any(), gomock.Any()).Return(dc, nil)
+
+			result, err := svc.GetByUserCode(context.Background(), "ABCD-EFGH")
+
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), []oauth.AuthorizationDetail{
+				{Type: "bk_mcp_server", Identifier: "bk-log"},
+			}, result.AuthorizationDetails)
 		})
 	})
 
@@ -194,7 +213,7 @@ var _ = Describe("oauthDeviceCodeService", func() {
 
 	Describe("PollAndConsumeDeviceCode", func() {
 		It("should reject when device code does not exist", func() {
-			mockManager.EXPECT().GetByDeviceCode(gomock.Any(), "nonexistent").
+			mockManager.EXPECT().GetByDeviceCodeHashes(gomock.Any(), deviceCodeLookupHashes("nonexistent")).
 				Return(dao.OAuthDeviceCode{}, nil)
 
 			_, err := svc.PollAndConsumeDeviceCode(context.Background(), "blueking", "nonexistent", "client-1")
@@ -205,7 +224,7 @@ var _ = Describe("oauthDeviceCodeService", func() {
 		It("should reject when realm does not match", func() {
 			dc := newPendingDeviceCode()
 			dc.Status = oauth.DeviceCodeStatusApproved
-			mockManager.EXPECT().GetByDeviceCode(gomock.Any(), "device-1").Return(dc, nil)
+			mockManager.EXPECT().GetByDeviceCodeHashes(gomock.Any(), deviceCodeLookupHashes("device-1")).Return(dc, nil)
 
 			_, err := svc.PollAndConsumeDeviceCode(context.Background(), "bk-devops", "device-1", "client-1")
 
@@ -215,7 +234,7 @@ var _ = Describe("oauthDeviceCodeService", func() {
 		It("should reject when client_id does not match", func() {
 			dc := newPendingDeviceCode()
 			dc.Status = oauth.DeviceCodeStatusApproved
-			mockManager.EXPECT().GetByDeviceCode(gomock.Any(), "device-1").Return(dc, nil)
+			mockManager.EXPECT().GetByDeviceCodeHashes(gomock.Any(), deviceCodeLookupHashes("device-1")).Return(dc, nil)
 
 			_, err := svc.PollAndConsumeDeviceCode(context.Background(), "blueking", "device-1", "wrong-client")
 
@@ -225,7 +244,7 @@ var _ = Describe("oauthDeviceCodeService", func() {
 		It("should reject when device code is expired", func() {
 			dc := newPendingDeviceCode()
 			dc.ExpiresAt = time.Now().Add(-time.Second)
-			mockManager.EXPECT().GetByDeviceCode(gomock.Any(), "device-1").Return(dc, nil)
+			mockManager.EXPECT().GetByDeviceCodeHashes(gomock.Any(), deviceCodeLookupHashes("device-1")).Return(dc, nil)
 
 			_, err := svc.PollAndConsumeDeviceCode(context.Background(), "blueking", "device-1", "client-1")
 
@@ -236,7 +255,7 @@ var _ = Describe("oauthDeviceCodeService", func() {
 			dc := newPendingDeviceCode()
 			now := time.Now()
 			dc.LastPolledAt = &now
-			mockManager.EXPECT().GetByDeviceCode(gomock.Any(), "device-1").Return(dc, nil)
+			mockManager.EXPECT().GetByDeviceCodeHashes(gomock.Any(), deviceCodeLookupHashes("device-1")).Return(dc, nil)
 			mockManager.EXPECT().SlowDown(gomock.Any(), int64(1), int64(oauth.SlowDownIncrement)).
 				Return(int64(1), nil)
 
@@ -247,7 +266,7 @@ var _ = Describe("oauthDeviceCodeService", func() {
 
 		It("should return authorization_pending when status is pending", func() {
 			dc := newPendingDeviceCode()
-			mockManager.EXPECT().GetByDeviceCode(gomock.Any(), "device-1").Return(dc, nil)
+			mockManager.EXPECT().GetByDeviceCodeHashes(gomock.Any(), deviceCodeLookupHashes("device-1")).Return(dc, nil)
 			mockManager.EXPECT().UpdateLastPolledAt(gomock.Any(), int64(1)).Return(int64(1), nil)
 
 			_, err := svc.PollAndConsumeDeviceCode(context.Background(), "blueking", "device-1", "client-1")
@@ -258,7 +277,7 @@ var _ = Describe("oauthDeviceCodeService", func() {
 		It("should return denied when status is denied", func() {
 			dc := newPendingDeviceCode()
 			dc.Status = oauth.DeviceCodeStatusDenied
-			mockManager.EXPECT().GetByDeviceCode(gomock.Any(), "device-1").Return(dc, nil)
+			mockManager.EXPECT().GetByDeviceCodeHashes(gomock.Any(), deviceCodeLookupHashes("device-1")).Return(dc, nil)
 			mockManager.EXPECT().UpdateLastPolledAt(gomock.Any(), int64(1)).Return(int64(1), nil)
 
 			_, err := svc.PollAndConsumeDeviceCode(context.Background(), "blueking", "device-1", "client-1")
@@ -269,7 +288,7 @@ var _ = Describe("oauthDeviceCodeService", func() {
 		It("should return consumed when status is already consumed", func() {
 			dc := newPendingDeviceCode()
 			dc.Status = oauth.DeviceCodeStatusConsumed
-			mockManager.EXPECT().GetByDeviceCode(gomock.Any(), "device-1").Return(dc, nil)
+			mockManager.EXPECT().GetByDeviceCodeHashes(gomock.Any(), deviceCodeLookupHashes("device-1")).Return(dc, nil)
 			mockManager.EXPECT().UpdateLastPolledAt(gomock.Any(), int64(1)).Return(int64(1), nil)
 
 			_, err := svc.PollAndConsumeDeviceCode(context.Background(), "blueking", "device-1", "client-1")
@@ -280,9 +299,9 @@ var _ = Describe("oauthDeviceCodeService", func() {
 		It("should return consumed when the atomic consume loses the race", func() {
 			dc := newPendingDeviceCode()
 			dc.Status = oauth.DeviceCodeStatusApproved
-			mockManager.EXPECT().GetByDeviceCode(gomock.Any(), "device-1").Return(dc, nil)
+			mockManager.EXPECT().GetByDeviceCodeHashes(gomock.Any(), deviceCodeLookupHashes("device-1")).Return(dc, nil)
 			mockManager.EXPECT().UpdateLastPolledAt(gomock.Any(), int64(1)).Return(int64(1), nil)
-			mockManager.EXPECT().ConsumeApproved(gomock.Any(), "device-1", "client-1").
+			mockManager.EXPECT().ConsumeApproved(gomock.Any(), oauth.HashToken("device-1"), "client-1").
 				Return(int64(0), nil)
 
 			_, err := svc.PollAndConsumeDeviceCode(context.Background(), "blueking", "device-1", "client-1")
@@ -297,9 +316,9 @@ var _ = Describe("oauthDeviceCodeService", func() {
 			dc.Sub = "sub-1"
 			dc.Username = "admin"
 			dc.Audience = &audience
-			mockManager.EXPECT().GetByDeviceCode(gomock.Any(), "device-1").Return(dc, nil)
+			mockManager.EXPECT().GetByDeviceCodeHashes(gomock.Any(), deviceCodeLookupHashes("device-1")).Return(dc, nil)
 			mockManager.EXPECT().UpdateLastPolledAt(gomock.Any(), int64(1)).Return(int64(1), nil)
-			mockManager.EXPECT().ConsumeApproved(gomock.Any(), "device-1", "client-1").
+			mockManager.EXPECT().ConsumeApproved(gomock.Any(), oauth.HashToken("device-1"), "client-1").
 				Return(int64(1), nil)
 
 			result, err := svc.PollAndConsumeDeviceCode(context.Background(), "blueking", "device-1", "client-1")
@@ -309,6 +328,20 @@ var _ = Describe("oauthDeviceCodeService", func() {
 			assert.Equal(GinkgoT(), "admin", result.Username)
 			assert.Equal(GinkgoT(), []string{"aud-1", "aud-2"}, result.Audience)
 		})
+
+		It("should consume a device code stored before device codes were hashed", func() {
+			dc := newPendingDeviceCode()
+			dc.DeviceCodeHash = "device-1"
+			dc.Status = oauth.DeviceCodeStatusApproved
+			mockManager.EXPECT().GetByDeviceCodeHashes(gomock.Any(), deviceCodeLookupHashes("device-1")).Return(dc, nil)
+			mockManager.EXPECT().UpdateLastPolledAt(gomock.Any(), int64(1)).Return(int64(1), nil)
+			mockManager.EXPECT().ConsumeApproved(gomock.Any(), "device-1", "client-1").
+				Return(int64(1), nil)
+
+			_, err := svc.PollAndConsumeDeviceCode(context.Background(), "blueking", "device-1", "client-1")
+
+			assert.NoError(GinkgoT(), err)
+		})
 	})
 })
 
@@ -331,10 +364,11 @@ var _ = Describe("oauthDeviceCodeService.CreateDeviceCode", func() {
 
 	It("ok", func() {
 		start := time.Now()
+		var stored dao.OAuthDeviceCode
 		mockManager.EXPECT().
 			Create(gomock.Any(), gomock.AssignableToTypeOf(dao.OAuthDeviceCode{})).
 			DoAndReturn(func(_ context.Context, dc dao.OAuthDeviceCode) (int64, error) {
-				assert.NotEmpty(GinkgoT(), dc.DeviceCode)
+				stored = dc
 				assert.NotEmpty(GinkgoT(), dc.UserCode)
 				assert.Equal(GinkgoT(), "client-1", dc.ClientID)
 				assert.Equal(GinkgoT(), "blueking", dc.RealmName)
@@ -347,12 +381,15 @@ var _ = Describe("oauthDeviceCodeService.CreateDeviceCode", func() {
 				return int64(1), nil
 			})
 
-		result, err := svc.CreateDeviceCode(context.Background(), "blueking", "client-1", "bk_paas")
+		result, err := svc.CreateDeviceCode(context.Background(), "blueking", "client-1", "bk_paas", "", nil)
 
 		assert.NoError(GinkgoT(), err)
 		assert.NotEmpty(GinkgoT(), result.DeviceCode)
 		assert.NotEmpty(GinkgoT(), result.UserCode)
 		assert.Equal(GinkgoT(), int64(oauth.DeviceCodeInterval), result.PollInterval)
+		// only the hash of the device code is stored
+		assert.Equal(GinkgoT(), oauth.HashToken(result.DeviceCode), stored.DeviceCodeHash)
+		assert.Equal(GinkgoT(), oauth.LegacyTokenHashKeyID, stored.DeviceCodeHashKeyID)
 	})
 
 	It("create error", func() {
@@ -360,7 +397,7 @@ var _ = Describe("oauthDeviceCodeService.CreateDeviceCode", func() {
 			Create(gomock.Any(), gomock.AssignableToTypeOf(dao.OAuthDeviceCode{})).
 			Return(int64(0), errors.New("db connection lost"))
 
-		_, err := svc.CreateDeviceCode(context.Background(), "blueking", "client-1", "bk_paas")
+		_, err := svc.CreateDeviceCode(context.Background(), "blueking", "client-1", "bk_paas", "", nil)
 
 		assert.Error(GinkgoT(), err)
 		assert.Contains(GinkgoT(), err.Error(), "deviceCodeManager.Create fail")
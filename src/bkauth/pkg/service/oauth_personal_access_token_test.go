@@ -0,0 +1,196 @@
+/*
+ * TencentBlueKing is pleased to support the open source community by making
+ * 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+ * Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ *     http://opensource.org/licenses/MIT
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"bkauth/pkg/cache/invalidation"
+	"bkauth/pkg/database"
+	"bkauth/pkg/database/dao"
+	"bkauth/pkg/database/dao/mock"
+	"bkauth/pkg/oauth"
+	"bkauth/pkg/service/types"
+)
+
+var _ = Describe("oauthPersonalAccessTokenService", func() {
+	var (
+		ctx               context.Context
+		ctl               *gomock.Controller
+		mockManager       *mock.MockOAuthPersonalAccessTokenManager
+		mockAccessManager *mock.MockOAuthAccessTokenManager
+		svc               oauthPersonalAccessTokenService
+		patOfUser1        dao.OAuthPersonalAccessToken
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctl = gomock.NewController(GinkgoT())
+		mockManager = mock.NewMockOAuthPersonalAccessTokenManager(ctl)
+		mockAccessManager = mock.NewMockOAuthAccessTokenManager(ctl)
+		svc = oauthPersonalAccessTokenService{
+			manager:            mockManager,
+			accessTokenManager: mockAccessManager,
+		}
+		patOfUser1 = dao.OAuthPersonalAccessToken{
+			ID:       1,
+			GrantID:  "grant-1",
+			TenantID: "default",
+			Sub:      "user1",
+		}
+	})
+
+	AfterEach(func() {
+		ctl.Finish()
+	})
+
+	Describe("Create", func() {
+		It("should issue an access token to the private app with the user's grant", func() {
+			var accessToken dao.OAuthAccessToken
+			var pat dao.OAuthPersonalAccessToken
+			mockAccessManager.EXPECT().CreateWithTx(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ *sqlx.Tx, token dao.OAuthAccessToken) (int64, error) {
+					accessToken = token
+					return 1, nil
+				})
+			mockManager.EXPECT().CreateWithTx(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ *sqlx.Tx, token dao.OAuthPersonalAccessToken) (int64, error) {
+					pat = token
+					return 7, nil
+				})
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+			restore := useMockDefaultDB(db)
+			defer restore()
+
+			created, err := svc.Create(ctx, types.PersonalAccessTokenInput{
+				TenantID:  "default",
+				Sub:       "user1",
+				Username:  "admin",
+				RealmName: "blueking",
+				Name:      "ci-script",
+				Resource:  "mcp:foo",
+				Audience:  []string{"mcp:foo"},
+				Prefix:    "bk_",
+				TTL:       3600,
+			})
+
+			assert.NoError(GinkgoT(), err)
+			assert.Equal(GinkgoT(), int64(7), created.ID)
+			assert.True(GinkgoT(), strings.HasPrefix(created.Token, "bk_"))
+			assert.Equal(GinkgoT(), oauth.MaskToken(created.Token), created.TokenMask)
+			assert.InDelta(GinkgoT(), time.Now().Add(time.Hour).Unix(), created.ExpiresAt, 5)
+
+			assert.Equal(GinkgoT(), oauth.PrivateAppCode, accessToken.ClientID)
+			assert.Equal(GinkgoT(), oauth.GrantTypePersonalAccessToken, accessToken.GrantType)
+			assert.Equal(GinkgoT(), oauth.HashToken(created.Token), accessToken.TokenHash)
+			assert.Equal(GinkgoT(), `["mcp:foo"]`, accessToken.Audience)
+			assert.Equal(GinkgoT(), accessToken.GrantID, pat.GrantID)
+			assert.Equal(GinkgoT(), "ci-script", pat.Name)
+			assert.NoError(GinkgoT(), dbMock.ExpectationsWereMet())
+		})
+
+		It("should not persist the token when the access token insert fails", func() {
+			mockAccessManager.EXPECT().CreateWithTx(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(int64(0), errors.New("duplicate entry"))
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectRollback()
+			restore := useMockDefaultDB(db)
+			defer restore()
+
+			_, err := svc.Create(ctx, types.PersonalAccessTokenInput{Prefix: "bk_", TTL: 3600})
+
+			assert.Error(GinkgoT(), err)
+			assert.NoError(GinkgoT(), dbMock.ExpectationsWereMet())
+		})
+	})
+
+	Describe("ListBySub", func() {
+		It("should convert the tokens of the user", func() {
+			now := time.Now()
+			mockManager.EXPECT().ListBySub(gomock.Any(), "default", "user1").Return([]dao.OAuthPersonalAccessToken{
+				{ID: 1, Name: "ci-script", RealmName: "blueking", Resource: "mcp:foo", ExpiresAt: now, CreatedAt: now},
+			}, nil)
+
+			tokens, err := svc.ListBySub(ctx, "default", "user1")
+
+			assert.NoError(GinkgoT(), err)
+			assert.Len(GinkgoT(), tokens, 1)
+			assert.Equal(GinkgoT(), "ci-script", tokens[0].Name)
+			assert.Equal(GinkgoT(), now.Unix(), tokens[0].ExpiresAt)
+			assert.Empty(GinkgoT(), tokens[0].Token)
+		})
+	})
+
+	Describe("Revoke", func() {
+		It("should revoke the access token and delete the personal access token", func() {
+			var evicted []string
+			invalidation.RegisterEvictor(invalidation.KindAccessToken, func(_ context.Context, keys []string) {
+				evicted = keys
+			})
+			defer invalidation.RegisterEvictor(invalidation.KindAccessToken, func(context.Context, []string) {})
+
+			mockManager.EXPECT().Get(gomock.Any(), int64(1)).Return(patOfUser1, nil)
+			mockAccessManager.EXPECT().RevokeByGrantIDWithTx(gomock.Any(), gomock.Any(), "grant-1").
+				Return([]dao.RevokedToken{{TokenHash: "hash-2"}}, nil)
+			mockManager.EXPECT().DeleteWithTx(gomock.Any(), gomock.Any(), int64(1)).Return(int64(1), nil)
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+			restore := useMockDefaultDB(db)
+			defer restore()
+
+			err := svc.Revoke(ctx, "default", "user1", 1)
+
+			assert.NoError(GinkgoT(), err)
+			// the hash of the access token, which differs from the copy once re-hashed with a new key
+			assert.Equal(GinkgoT(), []string{"hash-2"}, evicted)
+			assert.NoError(GinkgoT(), dbMock.ExpectationsWereMet())
+		})
+
+		It("should not revoke a token of another user", func() {
+			mockManager.EXPECT().Get(gomock.Any(), int64(1)).Return(patOfUser1, nil)
+
+			err := svc.Revoke(ctx, "default", "user2", 1)
+
+			assert.ErrorIs(GinkgoT(), err, oauth.ErrPersonalAccessTokenNotFound)
+		})
+
+		It("should return not found for a missing token", func() {
+			mockManager.EXPECT().Get(gomock.Any(), int64(404)).Return(dao.OAuthPersonalAccessToken{}, nil)
+
+			err := svc.Revoke(ctx, "default", "user1", 404)
+
+			assert.ErrorIs(GinkgoT(), err, oauth.ErrPersonalAccessTokenNotFound)
+		})
+	})
+})
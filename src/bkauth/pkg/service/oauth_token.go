@@ -27,10 +27,12 @@ import (
 
 	"github.com/jmoiron/sqlx"
 
+	"bkauth/pkg/cache/invalidation"
 	"bkauth/pkg/database"
 	"bkauth/pkg/database/dao"
 	"bkauth/pkg/errorx"
 	"bkauth/pkg/oauth"
+	"bkauth/pkg/revocation"
 	"bkauth/pkg/service/types"
 )
 
@@ -39,20 +41,31 @@ const OAuthTokenSVC = "OAuthTokenSVC"
 // OAuthTokenService defines the interface for OAuth token operations.
 type OAuthTokenService interface {
 	IssueTokensForAuthorizationCode(
-		ctx context.Context, realmName, clientID, tenantID, sub, username string,
-		audience []string, policy types.TokenIssuancePolicy,
+		ctx context.Context, realmName, clientID string,
+		grant types.TokenGrant, policy types.TokenIssuancePolicy,
 	) (types.TokenPair, error)
 	IssueTokensForDeviceCode(
-		ctx context.Context, realmName, clientID, tenantID, sub, username string,
-		audience []string, policy types.TokenIssuancePolicy,
+		ctx context.Context, realmName, clientID string,
+		grant types.TokenGrant, policy types.TokenIssuancePolicy,
+	) (types.TokenPair, error)
+	IssueAccessTokenForClientCredentials(
+		ctx context.Context, realmName, clientID string,
+		grant types.TokenGrant, policy types.TokenIssuancePolicy,
+	) (types.TokenPair, error)
+	IssueAccessTokenForTokenExchange(
+		ctx context.Context, realmName, grantID, clientID string,
+		grant types.TokenGrant, policy types.TokenIssuancePolicy,
 	) (types.TokenPair, error)
 	RefreshAccessToken(
 		ctx context.Context, realmName, refreshToken, clientID string,
-		policy types.TokenIssuancePolicy,
+		cnf oauth.Confirmation, policy types.TokenIssuancePolicy,
 	) (types.TokenPair, error)
-	GetAccessTokenByTokenHash(ctx context.Context, tokenHash string) (types.ResolvedAccessToken, error)
-	RevokeToken(ctx context.Context, tokenHash, clientID string) error
-	RevokeByGrantID(ctx context.Context, grantID string) error
+	GetAccessTokenByTokenHashes(ctx context.Context, tokenHashes []oauth.TokenHash) (types.ResolvedAccessToken, error)
+	GetRefreshTokenByTokenHashes(ctx context.Context, tokenHashes []oauth.TokenHash) (types.ResolvedRefreshToken, error)
+	RevokeToken(ctx context.Context, tokenHashes []oauth.TokenHash, clientID string) error
+	RevokeByGrantID(ctx context.Context, grantID, reason string) error
+	ListActiveGrants(ctx context.Context, tenantID, sub string) ([]types.UserGrant, error)
+	RevokeGrantOfUser(ctx context.Context, tenantID, sub, grantID string) (types.UserGrant, error)
 }
 
 // oauthTokenService implements OAuthTokenService.
@@ -83,11 +96,91 @@ type preparedTokenPair struct {
 	accessToken  string
 	refreshToken string
 	expiresIn    int64
+	scope        string
+	// authorizationDetails is echoed in the token response (RFC 9396 §7)
+	authorizationDetails []oauth.AuthorizationDetail
 
 	daoAccessToken  dao.OAuthAccessToken
 	daoRefreshToken dao.OAuthRefreshToken
 }
 
+// prepareAccessToken generates an access token (opaque or JWT, per policy)
+// and builds its DAO struct. No database access.
+func (s *oauthTokenService) prepareAccessToken(
+	realmName, grantID, clientID string,
+	grant types.TokenGrant, audienceJSON string,
+	policy types.TokenIssuancePolicy,
+) (string, dao.OAuthAccessToken, error) {
+	now := time.Now()
+	jti := oauth.GenerateJTI()
+	accessTokenExpiresAt := now.Add(time.Duration(policy.AccessTokenTTL) * time.Second)
+
+	var accessToken string
+	var err error
+	if policy.AccessTokenFormat == oauth.AccessTokenFormatJWT {
+		var cnf *oauth.Confirmation
+		if !grant.Cnf.IsZero() {
+			cnf = &grant.Cnf
+		}
+		// a client_credentials grant has no user, the client is the subject (RFC 9068 §2.2)
+		subject := grant.Sub
+		if subject == "" {
+			subject = clientID
+		}
+		accessToken, err = oauth.SignJWT(oauth.JWTTypeAccessToken, oauth.AccessTokenClaims{
+			Issuer:               policy.Issuer,
+			Subject:              subject,
+			Audience:             grant.Audience,
+			ClientID:             clientID,
+			TenantID:             grant.TenantID,
+			Scope:                grant.Scope,
+			Act:                  grant.Act,
+			AuthorizationDetails: grant.AuthorizationDetails,
+			Cnf:                  cnf,
+			JTI:                  jti,
+			ExpiresAt:            accessTokenExpiresAt.Unix(),
+			IssuedAt:             now.Unix(),
+		})
+	} else {
+		accessToken, err = oauth.GenerateToken(policy.Prefix)
+	}
+	if err != nil {
+		return "", dao.OAuthAccessToken{}, err
+	}
+
+	var act string
+	if grant.Act != nil {
+		actJSON, err := json.Marshal(grant.Act)
+		if err != nil {
+			return "", dao.OAuthAccessToken{}, err
+		}
+		act = string(actJSON)
+	}
+
+	tokenHash := oauth.HashTokenForStorage(accessToken)
+	return accessToken, dao.OAuthAccessToken{
+		JTI:                  jti,
+		TokenHash:            tokenHash.Hash,
+		TokenHashKeyID:       tokenHash.KeyID,
+		TokenMask:            oauth.MaskToken(accessToken),
+		GrantID:              grantID,
+		ClientID:             clientID,
+		RealmName:            realmName,
+		TenantID:             grant.TenantID,
+		Sub:                  grant.Sub,
+		Username:             grant.Username,
+		Audience:             audienceJSON,
+		Scope:                grant.Scope,
+		AuthorizationDetails: oauth.FormatAuthorizationDetails(grant.AuthorizationDetails),
+		GrantType:            grant.GrantType,
+		Act:                  act,
+		CnfJKT:               grant.Cnf.JKT,
+		CnfX5T:               grant.Cnf.X5TS256,
+		ExpiresAt:            accessTokenExpiresAt,
+		Revoked:              false,
+	}, nil
+}
+
 // prepareTokenPair generates all random material and builds DAO structs for
 // a token pair. This is pure computation with no database access, so it can
 // safely run outside a transaction to minimize lock hold time.
@@ -97,21 +190,19 @@ type preparedTokenPair struct {
 // For rotation, pass the previous token's ExpiresAt to preserve the
 // absolute lifetime (the grant family expires at the originally issued time).
 func (s *oauthTokenService) prepareTokenPair(
-	realmName, grantID, clientID, tenantID, sub, username string,
-	audience []string, rotationCount int64,
+	realmName, grantID, clientID string,
+	grant types.TokenGrant, rotationCount int64,
 	refreshTokenExpiresAt time.Time,
 	policy types.TokenIssuancePolicy,
 ) (preparedTokenPair, error) {
-	now := time.Now()
-
-	accessToken, err := oauth.GenerateToken(policy.Prefix)
+	audienceJSON, err := json.Marshal(grant.Audience)
 	if err != nil {
 		return preparedTokenPair{}, err
 	}
 
-	jti := oauth.GenerateJTI()
-
-	audienceJSON, err := json.Marshal(audience)
+	accessToken, daoAccessToken, err := s.prepareAccessToken(
+		realmName, grantID, clientID, grant, string(audienceJSON), policy,
+	)
 	if err != nil {
 		return preparedTokenPair{}, err
 	}
@@ -121,37 +212,42 @@ func (s *oauthTokenService) prepareTokenPair(
 		return preparedTokenPair{}, err
 	}
 
+	// RFC 9449 §5, RFC 8705 §4: refresh tokens of public clients are bound to the DPoP key
+	// or the client certificate, those of confidential clients are already bound to the
+	// client authentication.
+	var refreshCnf oauth.Confirmation
+	if policy.PublicClient {
+		refreshCnf = grant.Cnf
+	}
+	refreshTokenHash := oauth.HashTokenForStorage(refreshToken)
+
 	return preparedTokenPair{
-		accessToken:  accessToken,
-		refreshToken: refreshToken,
-		expiresIn:    policy.AccessTokenTTL,
-		daoAccessToken: dao.OAuthAccessToken{
-			JTI:       jti,
-			TokenHash: oauth.HashToken(accessToken),
-			TokenMask: oauth.MaskToken(accessToken),
-			GrantID:   grantID,
-			ClientID:  clientID,
-			RealmName: realmName,
-			TenantID:  tenantID,
-			Sub:       sub,
-			Username:  username,
-			Audience:  string(audienceJSON),
-			ExpiresAt: now.Add(time.Duration(policy.AccessTokenTTL) * time.Second),
-			Revoked:   false,
-		},
+		accessToken:          accessToken,
+		refreshToken:         refreshToken,
+		expiresIn:            policy.AccessTokenTTL,
+		scope:                grant.Scope,
+		authorizationDetails: grant.AuthorizationDetails,
+
+		daoAccessToken: daoAccessToken,
 		daoRefreshToken: dao.OAuthRefreshToken{
-			TokenHash:     oauth.HashToken(refreshToken),
-			TokenMask:     oauth.MaskToken(refreshToken),
-			GrantID:       grantID,
-			ClientID:      clientID,
-			RealmName:     realmName,
-			TenantID:      tenantID,
-			Sub:           sub,
-			Username:      username,
-			Audience:      string(audienceJSON),
-			ExpiresAt:     refreshTokenExpiresAt,
-			Revoked:       false,
-			RotationCount: rotationCount,
+			TokenHash:            refreshTokenHash.Hash,
+			TokenHashKeyID:       refreshTokenHash.KeyID,
+			TokenMask:            oauth.MaskToken(refreshToken),
+			GrantID:              grantID,
+			ClientID:             clientID,
+			RealmName:            realmName,
+			TenantID:             grant.TenantID,
+			Sub:                  grant.Sub,
+			Username:             grant.Username,
+			Audience:             string(audienceJSON),
+			Resource:             grant.Resource,
+			AuthorizationDetails: daoAccessToken.AuthorizationDetails,
+			Scope:                grant.Scope,
+			CnfJKT:               refreshCnf.JKT,
+			CnfX5T:               refreshCnf.X5TS256,
+			ExpiresAt:            refreshTokenExpiresAt,
+			Revoked:              false,
+			RotationCount:        rotationCount,
 		},
 	}, nil
 }
@@ -178,21 +274,86 @@ func (s *oauthTokenService) persistTokenPairTx(
 // IssueTokensForAuthorizationCode issues tokens for a validated and consumed authorization code.
 func (s *oauthTokenService) IssueTokensForAuthorizationCode(
 	ctx context.Context,
-	realmName, clientID, tenantID, sub, username string,
-	audience []string, policy types.TokenIssuancePolicy,
+	realmName, clientID string,
+	grant types.TokenGrant, policy types.TokenIssuancePolicy,
 ) (types.TokenPair, error) {
 	grantID := oauth.GenerateGrantID()
-	return s.generateTokenPair(ctx, realmName, tenantID, grantID, clientID, sub, username, audience, policy)
+	return s.generateTokenPair(ctx, realmName, grantID, clientID, grant, policy)
 }
 
 // IssueTokensForDeviceCode issues tokens after a device code has been approved (RFC 8628).
 func (s *oauthTokenService) IssueTokensForDeviceCode(
 	ctx context.Context,
-	realmName, clientID, tenantID, sub, username string,
-	audience []string, policy types.TokenIssuancePolicy,
+	realmName, clientID string,
+	grant types.TokenGrant, policy types.TokenIssuancePolicy,
 ) (types.TokenPair, error) {
 	grantID := oauth.GenerateGrantID()
-	return s.generateTokenPair(ctx, realmName, tenantID, grantID, clientID, sub, username, audience, policy)
+	return s.generateTokenPair(ctx, realmName, grantID, clientID, grant, policy)
+}
+
+// IssueAccessTokenForClientCredentials issues an access token for the
+// client_credentials grant (RFC 6749 §4.4). No refresh token is issued
+// (RFC 6749 §4.4.3), so the token is a grant of its own and expires with it.
+func (s *oauthTokenService) IssueAccessTokenForClientCredentials(
+	ctx context.Context,
+	realmName, clientID string,
+	grant types.TokenGrant, policy types.TokenIssuancePolicy,
+) (types.TokenPair, error) {
+	grantID := oauth.GenerateGrantID()
+	return s.issueAccessToken(ctx, realmName, grantID, clientID, grant, policy)
+}
+
+// IssueAccessTokenForTokenExchange issues an access token for the token
+// exchange grant (RFC 8693). grantID is the subject token's grant, so the
+// exchanged token joins its family and is revoked together with it
+// (RevokeByGrantID). No refresh token is issued.
+func (s *oauthTokenService) IssueAccessTokenForTokenExchange(
+	ctx context.Context,
+	realmName, grantID, clientID string,
+	grant types.TokenGrant, policy types.TokenIssuancePolicy,
+) (types.TokenPair, error) {
+	return s.issueAccessToken(ctx, realmName, grantID, clientID, grant, policy)
+}
+
+// issueAccessToken persists a standalone access token (no refresh token) for grantID.
+func (s *oauthTokenService) issueAccessToken(
+	ctx context.Context,
+	realmName, grantID, clientID string,
+	grant types.TokenGrant, policy types.TokenIssuancePolicy,
+) (types.TokenPair, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthTokenSVC, "issueAccessToken")
+
+	audienceJSON, err := json.Marshal(grant.Audience)
+	if err != nil {
+		return types.TokenPair{}, errorWrapf(err, "json.Marshal audience fail")
+	}
+
+	accessToken, daoAccessToken, err := s.prepareAccessToken(
+		realmName, grantID, clientID, grant, string(audienceJSON), policy,
+	)
+	if err != nil {
+		return types.TokenPair{}, errorWrapf(err, "prepareAccessToken fail")
+	}
+
+	tx, err := database.GenerateDefaultDBTx(ctx)
+	if err != nil {
+		return types.TokenPair{}, errorWrapf(err, "database.GenerateDefaultDBTx fail")
+	}
+	defer database.RollBackWithLog(tx)
+
+	if _, err := s.accessTokenManager.CreateWithTx(ctx, tx, daoAccessToken); err != nil {
+		return types.TokenPair{}, errorWrapf(err, "accessTokenManager.CreateWithTx fail")
+	}
+	if err := tx.Commit(); err != nil {
+		return types.TokenPair{}, errorWrapf(err, "tx.Commit fail")
+	}
+
+	return types.TokenPair{
+		AccessToken:          accessToken,
+		ExpiresIn:            policy.AccessTokenTTL,
+		Scope:                grant.Scope,
+		AuthorizationDetails: grant.AuthorizationDetails,
+	}, nil
 }
 
 // generateTokenPair generates an access token and refresh token pair atomically.
@@ -201,14 +362,14 @@ func (s *oauthTokenService) IssueTokensForDeviceCode(
 // forward a rotation count, use prepareTokenPair + persistTokenPairTx directly.
 func (s *oauthTokenService) generateTokenPair(
 	ctx context.Context,
-	realmName, tenantID, grantID, clientID, sub, username string,
-	audience []string, policy types.TokenIssuancePolicy,
+	realmName, grantID, clientID string,
+	grant types.TokenGrant, policy types.TokenIssuancePolicy,
 ) (types.TokenPair, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthTokenSVC, "generateTokenPair")
 
 	refreshTokenExpiresAt := time.Now().Add(time.Duration(policy.RefreshTokenTTL) * time.Second)
 	prepared, err := s.prepareTokenPair(
-		realmName, grantID, clientID, tenantID, sub, username, audience,
+		realmName, grantID, clientID, grant,
 		oauth.InitialRotationCount, refreshTokenExpiresAt, policy,
 	)
 	if err != nil {
@@ -229,26 +390,30 @@ func (s *oauthTokenService) generateTokenPair(
 	}
 
 	return types.TokenPair{
-		AccessToken:  prepared.accessToken,
-		ExpiresIn:    prepared.expiresIn,
-		RefreshToken: prepared.refreshToken,
+		AccessToken:          prepared.accessToken,
+		ExpiresIn:            prepared.expiresIn,
+		RefreshToken:         prepared.refreshToken,
+		Scope:                prepared.scope,
+		AuthorizationDetails: prepared.authorizationDetails,
 	}, nil
 }
 
-// GetAccessTokenByTokenHash retrieves an access token record by its token hash.
-// Callers are responsible for hashing the raw token via oauth.HashToken before calling this method,
+// GetAccessTokenByTokenHashes retrieves an access token record by its token hashes.
+// Callers are responsible for hashing the raw token via oauth.TokenHashCandidates before calling this method,
 // so that the raw token never enters the service/cache layer.
+// A token found under a hash other than the first (current) one is re-hashed with the current key,
+// so that the stored hash always matches the cache key, which revocation uses to evict the token.
 // Returns a zero-value ResolvedAccessToken (ClientID == "") when the token does not exist;
 // callers should check this to distinguish "not found" from a valid record.
 // Revoked/expired checks are NOT performed here — callers decide how to interpret the token state.
-func (s *oauthTokenService) GetAccessTokenByTokenHash(
-	ctx context.Context, tokenHash string,
+func (s *oauthTokenService) GetAccessTokenByTokenHashes(
+	ctx context.Context, tokenHashes []oauth.TokenHash,
 ) (types.ResolvedAccessToken, error) {
-	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthTokenSVC, "GetAccessTokenByTokenHash")
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthTokenSVC, "GetAccessTokenByTokenHashes")
 
-	daoToken, err := s.accessTokenManager.GetByTokenHash(ctx, tokenHash)
+	daoToken, err := s.accessTokenManager.GetByTokenHashes(ctx, oauth.TokenHashValues(tokenHashes))
 	if err != nil {
-		return types.ResolvedAccessToken{}, errorWrapf(err, "accessTokenManager.GetByTokenHash fail")
+		return types.ResolvedAccessToken{}, errorWrapf(err, "accessTokenManager.GetByTokenHashes fail")
 	}
 
 	// not found — return zero-value struct so the result is cacheable as a negative entry
@@ -256,24 +421,82 @@ func (s *oauthTokenService) GetAccessTokenByTokenHash(
 		return types.ResolvedAccessToken{}, nil
 	}
 
+	current := tokenHashes[0]
+	if daoToken.TokenHash != current.Hash {
+		if _, err := s.accessTokenManager.UpdateTokenHash(ctx, daoToken.ID, current.Hash, current.KeyID); err != nil {
+			return types.ResolvedAccessToken{}, errorWrapf(err, "accessTokenManager.UpdateTokenHash fail")
+		}
+	}
+
 	var audience []string
 	if err := json.Unmarshal([]byte(daoToken.Audience), &audience); err != nil {
 		return types.ResolvedAccessToken{}, errorWrapf(err, "json.Unmarshal audience fail")
 	}
 
+	var act *oauth.Actor
+	if daoToken.Act != "" {
+		if err := json.Unmarshal([]byte(daoToken.Act), &act); err != nil {
+			return types.ResolvedAccessToken{}, errorWrapf(err, "json.Unmarshal act fail")
+		}
+	}
+
+	authorizationDetails, err := oauth.ParseAuthorizationDetails(daoToken.AuthorizationDetails)
+	if err != nil {
+		return types.ResolvedAccessToken{}, errorWrapf(err, "oauth.ParseAuthorizationDetails fail")
+	}
+
 	return types.ResolvedAccessToken{
-		ClientID:  daoToken.ClientID,
-		RealmName: daoToken.RealmName,
-		TenantID:  daoToken.TenantID,
-		Sub:       daoToken.Sub,
-		Username:  daoToken.Username,
-		Audience:  audience,
+		JTI:                  daoToken.JTI,
+		GrantID:              daoToken.GrantID,
+		ClientID:             daoToken.ClientID,
+		RealmName:            daoToken.RealmName,
+		TenantID:             daoToken.TenantID,
+		Sub:                  daoToken.Sub,
+		Username:             daoToken.Username,
+		Audience:             audience,
+		Scope:                daoToken.Scope,
+		AuthorizationDetails: authorizationDetails,
+		GrantType:            daoToken.GrantType,
+		Act:                  act,
+		Cnf:                  oauth.Confirmation{JKT: daoToken.CnfJKT, X5TS256: daoToken.CnfX5T},
 
 		ExpiresAt: daoToken.ExpiresAt.Unix(),
 		Revoked:   daoToken.Revoked,
 	}, nil
 }
 
+// GetRefreshTokenByTokenHashes retrieves a refresh token record by its token hashes, for introspection.
+// Returns a zero-value ResolvedRefreshToken (ClientID == "") when the token does not exist.
+// Revoked/expired/idle checks are NOT performed here — callers decide how to interpret the token state.
+func (s *oauthTokenService) GetRefreshTokenByTokenHashes(
+	ctx context.Context, tokenHashes []oauth.TokenHash,
+) (types.ResolvedRefreshToken, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthTokenSVC, "GetRefreshTokenByTokenHashes")
+
+	daoToken, err := s.refreshTokenManager.GetByTokenHashes(ctx, oauth.TokenHashValues(tokenHashes))
+	if err != nil {
+		return types.ResolvedRefreshToken{}, errorWrapf(err, "refreshTokenManager.GetByTokenHashes fail")
+	}
+	if daoToken.ID == 0 {
+		return types.ResolvedRefreshToken{}, nil
+	}
+
+	return types.ResolvedRefreshToken{
+		GrantID:       daoToken.GrantID,
+		ClientID:      daoToken.ClientID,
+		TenantID:      daoToken.TenantID,
+		RealmName:     daoToken.RealmName,
+		Sub:           daoToken.Sub,
+		Username:      daoToken.Username,
+		Scope:         daoToken.Scope,
+		RotationCount: daoToken.RotationCount,
+
+		ExpiresAt:       daoToken.ExpiresAt.Unix(),
+		LastRefreshedAt: daoToken.CreatedAt.Unix(),
+		Revoked:         daoToken.Revoked,
+	}, nil
+}
+
 // RefreshAccessToken rotates a refresh token: validates the presented token,
 // revokes it together with its associated access token, and issues a fresh pair.
 //
@@ -306,7 +529,7 @@ func (s *oauthTokenService) GetAccessTokenByTokenHash(
 // # Concurrency strategy — two-phase CAS
 //
 // Phase 1 (outside tx): read the refresh token and validate immutable
-// attributes (existence, client ownership, expiry). These fields never change
+// attributes (existence, client ownership, DPoP key binding, expiry). These fields never change
 // after creation, so the snapshot is reliable regardless of concurrent access.
 // A token that is already revoked at this stage indicates a replay of a
 // previously consumed token; the entire grant family is revoked defensively.
@@ -330,16 +553,17 @@ func (s *oauthTokenService) GetAccessTokenByTokenHash(
 //     grant-family revocation only for the former.
 func (s *oauthTokenService) RefreshAccessToken(
 	ctx context.Context,
-	realmName, refreshToken, clientID string, policy types.TokenIssuancePolicy,
+	realmName, refreshToken, clientID string,
+	cnf oauth.Confirmation, policy types.TokenIssuancePolicy,
 ) (types.TokenPair, error) {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthTokenSVC, "RefreshAccessToken")
 
 	// ---- Phase 1: read + immutable-attribute validation (no tx, no lock) ----
 
-	tokenHash := oauth.HashToken(refreshToken)
-	daoRefreshToken, err := s.refreshTokenManager.GetByTokenHash(ctx, tokenHash)
+	tokenHashes := oauth.TokenHashValues(oauth.TokenHashCandidates(refreshToken))
+	daoRefreshToken, err := s.refreshTokenManager.GetByTokenHashes(ctx, tokenHashes)
 	if err != nil {
-		return types.TokenPair{}, errorWrapf(err, "refreshTokenManager.GetByTokenHash fail")
+		return types.TokenPair{}, errorWrapf(err, "refreshTokenManager.GetByTokenHashes fail")
 	}
 
 	if daoRefreshToken.ID == 0 {
@@ -358,6 +582,16 @@ func (s *oauthTokenService) RefreshAccessToken(
 		return types.TokenPair{}, oauth.ErrClientMismatch
 	}
 
+	// A DPoP-bound refresh token is only usable with a proof signed by the same key (RFC 9449 §5).
+	if daoRefreshToken.CnfJKT != "" && daoRefreshToken.CnfJKT != cnf.JKT {
+		return types.TokenPair{}, oauth.ErrDPoPKeyMismatch
+	}
+
+	// A certificate-bound refresh token is only usable with the same client certificate (RFC 8705 §4).
+	if daoRefreshToken.CnfX5T != "" && daoRefreshToken.CnfX5T != cnf.X5TS256 {
+		return types.TokenPair{}, oauth.ErrClientCertificateMismatch
+	}
+
 	if daoRefreshToken.Revoked {
 		// The token was already consumed before this request arrived.
 		// This could be either (a) a legitimate client racing (e.g. timeout
@@ -369,7 +603,7 @@ func (s *oauthTokenService) RefreshAccessToken(
 		// family-wide revocation. See oauth.ReplayDetectionGracePeriod for
 		// the full rationale and trade-off analysis.
 		if time.Since(daoRefreshToken.UpdatedAt) > oauth.ReplayDetectionGracePeriod {
-			_ = s.RevokeByGrantID(ctx, daoRefreshToken.GrantID)
+			_ = s.RevokeByGrantID(ctx, daoRefreshToken.GrantID, revocation.ReasonReplayDetected)
 		}
 		return types.TokenPair{}, oauth.ErrRefreshTokenRevoked
 	}
@@ -382,19 +616,46 @@ func (s *oauthTokenService) RefreshAccessToken(
 		return types.TokenPair{}, oauth.ErrRefreshTokenExpired
 	}
 
+	// Sliding idle timeout: each rotation creates a new refresh token, so its creation time
+	// is the last time the family was used.
+	if policy.RefreshTokenIdleTimeout > 0 &&
+		time.Since(daoRefreshToken.CreatedAt) > time.Duration(policy.RefreshTokenIdleTimeout)*time.Second {
+		return types.TokenPair{}, oauth.ErrRefreshTokenIdleExpired
+	}
+
+	// the client may no longer be allowed the realm or resource of the grant
+	if err := policy.ClientPolicy.Check(realmName, daoRefreshToken.Resource); err != nil {
+		return types.TokenPair{}, err
+	}
+
 	var audience []string
 	if err := json.Unmarshal([]byte(daoRefreshToken.Audience), &audience); err != nil {
 		return types.TokenPair{}, errorWrapf(err, "json.Unmarshal audience fail")
 	}
 
+	authorizationDetails, err := oauth.ParseAuthorizationDetails(daoRefreshToken.AuthorizationDetails)
+	if err != nil {
+		return types.TokenPair{}, errorWrapf(err, "oauth.ParseAuthorizationDetails fail")
+	}
+
 	// Pre-generate all random material outside the transaction to minimize
 	// the time the transaction holds locks.
 	// Carry forward the original ExpiresAt so the grant family has a fixed
 	// absolute lifetime from initial issuance — rotation does not extend it.
+	grant := types.TokenGrant{
+		TenantID:             daoRefreshToken.TenantID,
+		Sub:                  daoRefreshToken.Sub,
+		Username:             daoRefreshToken.Username,
+		Audience:             audience,
+		Resource:             daoRefreshToken.Resource,
+		Scope:                daoRefreshToken.Scope,
+		AuthorizationDetails: authorizationDetails,
+		GrantType:            oauth.GrantTypeRefreshToken,
+		Cnf:                  cnf,
+	}
 	prepared, err := s.prepareTokenPair(
-		realmName, daoRefreshToken.GrantID, clientID, daoRefreshToken.TenantID,
-		daoRefreshToken.Sub, daoRefreshToken.Username,
-		audience, daoRefreshToken.RotationCount+1,
+		realmName, daoRefreshToken.GrantID, clientID, grant,
+		daoRefreshToken.RotationCount+1,
 		daoRefreshToken.ExpiresAt,
 		policy,
 	)
@@ -423,7 +684,8 @@ func (s *oauthTokenService) RefreshAccessToken(
 		return types.TokenPair{}, oauth.ErrRefreshTokenRevoked
 	}
 
-	if _, err := s.accessTokenManager.RevokeWithTx(ctx, tx, daoRefreshToken.AccessTokenID); err != nil {
+	revokedAccessTokens, err := s.accessTokenManager.RevokeWithTx(ctx, tx, daoRefreshToken.AccessTokenID)
+	if err != nil {
 		return types.TokenPair{}, errorWrapf(err, "accessTokenManager.RevokeWithTx fail")
 	}
 
@@ -434,33 +696,36 @@ func (s *oauthTokenService) RefreshAccessToken(
 	if err := tx.Commit(); err != nil {
 		return types.TokenPair{}, errorWrapf(err, "tx.Commit fail")
 	}
+	invalidation.Invalidate(ctx, invalidation.KindAccessToken, dao.RevokedTokenHashes(revokedAccessTokens))
+	publishRevocations(ctx, revocation.ReasonRotated, revokedAccessTokens,
+		[]dao.RevokedToken{newRevokedRefreshToken(daoRefreshToken)})
 
 	return types.TokenPair{
-		AccessToken:  prepared.accessToken,
-		ExpiresIn:    prepared.expiresIn,
-		RefreshToken: prepared.refreshToken,
+		AccessToken:          prepared.accessToken,
+		ExpiresIn:            prepared.expiresIn,
+		RefreshToken:         prepared.refreshToken,
+		Scope:                prepared.scope,
+		AuthorizationDetails: prepared.authorizationDetails,
 	}, nil
 }
 
 // revokeRefreshTokenWithCascadeTx revokes a refresh token and its associated
-// access token within a caller-provided transaction.
+// access token within a caller-provided transaction, returning the revoked access
+// tokens for the caller to evict from the cache and publish once committed.
 func (s *oauthTokenService) revokeRefreshTokenWithCascadeTx(
 	ctx context.Context, tx *sqlx.Tx,
 	refreshTokenID, accessTokenID int64,
-) error {
+) ([]dao.RevokedToken, error) {
 	if _, err := s.refreshTokenManager.RevokeWithTx(ctx, tx, refreshTokenID); err != nil {
-		return err
+		return nil, err
 	}
-	if _, err := s.accessTokenManager.RevokeWithTx(ctx, tx, accessTokenID); err != nil {
-		return err
-	}
-	return nil
+	return s.accessTokenManager.RevokeWithTx(ctx, tx, accessTokenID)
 }
 
 // revokeRefreshTokenWithCascade atomically revokes a refresh token and its
 // associated access token. It manages its own transaction; used by RevokeToken.
 func (s *oauthTokenService) revokeRefreshTokenWithCascade(
-	ctx context.Context, refreshTokenID, accessTokenID int64,
+	ctx context.Context, refreshToken dao.OAuthRefreshToken,
 ) error {
 	tx, err := database.GenerateDefaultDBTx(ctx)
 	if err != nil {
@@ -468,10 +733,19 @@ func (s *oauthTokenService) revokeRefreshTokenWithCascade(
 	}
 	defer database.RollBackWithLog(tx)
 
-	if err := s.revokeRefreshTokenWithCascadeTx(ctx, tx, refreshTokenID, accessTokenID); err != nil {
+	revokedAccessTokens, err := s.revokeRefreshTokenWithCascadeTx(
+		ctx, tx, refreshToken.ID, refreshToken.AccessTokenID,
+	)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
 		return err
 	}
-	return tx.Commit()
+	invalidation.Invalidate(ctx, invalidation.KindAccessToken, dao.RevokedTokenHashes(revokedAccessTokens))
+	publishRevocations(ctx, revocation.ReasonRevoked, revokedAccessTokens,
+		[]dao.RevokedToken{newRevokedRefreshToken(refreshToken)})
+	return nil
 }
 
 // RevokeToken revokes an access token or refresh token (RFC 7009).
@@ -482,12 +756,13 @@ func (s *oauthTokenService) revokeRefreshTokenWithCascade(
 // Per RFC 7009 Section 2.1, the method always returns nil (success) for non-infrastructure errors
 // — including token-not-found, client mismatch, and already-revoked cases —
 // to prevent callers from probing token existence.
-func (s *oauthTokenService) RevokeToken(ctx context.Context, tokenHash, clientID string) error {
+func (s *oauthTokenService) RevokeToken(ctx context.Context, tokenHashes []oauth.TokenHash, clientID string) error {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthTokenSVC, "RevokeToken")
 
-	accessToken, err := s.accessTokenManager.GetByTokenHash(ctx, tokenHash)
+	hashValues := oauth.TokenHashValues(tokenHashes)
+	accessToken, err := s.accessTokenManager.GetByTokenHashes(ctx, hashValues)
 	if err != nil {
-		return errorWrapf(err, "accessTokenManager.GetByTokenHash fail")
+		return errorWrapf(err, "accessTokenManager.GetByTokenHashes fail")
 	}
 	if accessToken.ID != 0 {
 		if clientID != "" && accessToken.ClientID != clientID {
@@ -499,12 +774,22 @@ func (s *oauthTokenService) RevokeToken(ctx context.Context, tokenHash, clientID
 		if _, err := s.accessTokenManager.Revoke(ctx, accessToken.ID); err != nil {
 			return errorWrapf(err, "accessTokenManager.Revoke fail")
 		}
+		// the token may be cached under the hash of a previous key too, during a key rotation
+		invalidation.Invalidate(ctx, invalidation.KindAccessToken, hashValues)
+		publishRevocations(ctx, revocation.ReasonRevoked, []dao.RevokedToken{{
+			TokenHash: accessToken.TokenHash,
+			JTI:       accessToken.JTI,
+			GrantID:   accessToken.GrantID,
+			ClientID:  accessToken.ClientID,
+			RealmName: accessToken.RealmName,
+			Sub:       accessToken.Sub,
+		}}, nil)
 		return nil
 	}
 
-	refreshToken, err := s.refreshTokenManager.GetByTokenHash(ctx, tokenHash)
+	refreshToken, err := s.refreshTokenManager.GetByTokenHashes(ctx, hashValues)
 	if err != nil {
-		return errorWrapf(err, "refreshTokenManager.GetByTokenHash fail")
+		return errorWrapf(err, "refreshTokenManager.GetByTokenHashes fail")
 	}
 	if refreshToken.ID != 0 {
 		if clientID != "" && refreshToken.ClientID != clientID {
@@ -514,7 +799,7 @@ func (s *oauthTokenService) RevokeToken(ctx context.Context, tokenHash, clientID
 			return nil
 		}
 
-		if err := s.revokeRefreshTokenWithCascade(ctx, refreshToken.ID, refreshToken.AccessTokenID); err != nil {
+		if err := s.revokeRefreshTokenWithCascade(ctx, refreshToken); err != nil {
 			return errorWrapf(err, "revokeRefreshTokenWithCascade fail")
 		}
 		return nil
@@ -523,13 +808,14 @@ func (s *oauthTokenService) RevokeToken(ctx context.Context, tokenHash, clientID
 	return nil
 }
 
-// RevokeByGrantID revokes all tokens in a token family (same grant).
+// RevokeByGrantID revokes all tokens in a token family (same grant),
+// publishing their revocation with the given reason.
 //
 // Lock ordering: refresh_token table first, then access_token table.
 // This matches the order used by RefreshAccessToken and
 // revokeRefreshTokenWithCascadeTx to prevent deadlocks when concurrent
 // requests operate on tokens within the same grant family.
-func (s *oauthTokenService) RevokeByGrantID(ctx context.Context, grantID string) error {
+func (s *oauthTokenService) RevokeByGrantID(ctx context.Context, grantID, reason string) error {
 	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthTokenSVC, "RevokeByGrantID")
 
 	tx, err := database.GenerateDefaultDBTx(ctx)
@@ -538,15 +824,114 @@ func (s *oauthTokenService) RevokeByGrantID(ctx context.Context, grantID string)
 	}
 	defer database.RollBackWithLog(tx)
 
-	if _, err := s.refreshTokenManager.RevokeByGrantIDWithTx(ctx, tx, grantID); err != nil {
+	revokedRefreshTokens, err := s.refreshTokenManager.RevokeByGrantIDWithTx(ctx, tx, grantID)
+	if err != nil {
 		return errorWrapf(err, "refreshTokenManager.RevokeByGrantIDWithTx fail")
 	}
-	if _, err := s.accessTokenManager.RevokeByGrantIDWithTx(ctx, tx, grantID); err != nil {
+	revokedAccessTokens, err := s.accessTokenManager.RevokeByGrantIDWithTx(ctx, tx, grantID)
+	if err != nil {
 		return errorWrapf(err, "accessTokenManager.RevokeByGrantIDWithTx fail")
 	}
 	if err := tx.Commit(); err != nil {
 		return errorWrapf(err, "tx.Commit fail")
 	}
+	invalidation.Invalidate(ctx, invalidation.KindAccessToken, dao.RevokedTokenHashes(revokedAccessTokens))
+	publishRevocations(ctx, reason, revokedAccessTokens, revokedRefreshTokens)
 
 	return nil
 }
+
+// ListActiveGrants lists the grant families of a user that can still be refreshed,
+// i.e. those holding an unrevoked, unexpired refresh token.
+func (s *oauthTokenService) ListActiveGrants(
+	ctx context.Context, tenantID, sub string,
+) ([]types.UserGrant, error) {
+	daoGrants, err := s.refreshTokenManager.ListActiveGrantsBySub(ctx, tenantID, sub, time.Now())
+	if err != nil {
+		return nil, errorx.Wrapf(err, OAuthTokenSVC, "ListActiveGrants",
+			"refreshTokenManager.ListActiveGrantsBySub sub=`%s` fail", sub)
+	}
+
+	grants := make([]types.UserGrant, 0, len(daoGrants))
+	for _, g := range daoGrants {
+		grants = append(grants, types.UserGrant{
+			GrantID:         g.GrantID,
+			ClientID:        g.ClientID,
+			RealmName:       g.RealmName,
+			Resource:        g.Resource,
+			Scope:           g.Scope,
+			FirstIssuedAt:   g.FirstIssuedAt.Unix(),
+			LastRefreshedAt: g.LastRefreshedAt.Unix(),
+			ExpiresAt:       g.ExpiresAt.Unix(),
+		})
+	}
+	return grants, nil
+}
+
+// RevokeGrantOfUser revokes a grant family on behalf of the user it was issued to and
+// returns it. oauth.ErrGrantNotFound is returned when the grant is not an active grant
+// of the user, so that a user cannot revoke the grants of another one.
+func (s *oauthTokenService) RevokeGrantOfUser(
+	ctx context.Context, tenantID, sub, grantID string,
+) (types.UserGrant, error) {
+	errorWrapf := errorx.NewLayerFunctionErrorWrapf(OAuthTokenSVC, "RevokeGrantOfUser")
+
+	grants, err := s.ListActiveGrants(ctx, tenantID, sub)
+	if err != nil {
+		return types.UserGrant{}, errorWrapf(err, "ListActiveGrants fail")
+	}
+
+	for _, grant := range grants {
+		if grant.GrantID != grantID {
+			continue
+		}
+		if err := s.RevokeByGrantID(ctx, grantID, revocation.ReasonGrantRevoked); err != nil {
+			return types.UserGrant{}, errorWrapf(err, "RevokeByGrantID grantID=`%s` fail", grantID)
+		}
+		return grant, nil
+	}
+
+	return types.UserGrant{}, oauth.ErrGrantNotFound
+}
+
+// newRevokedRefreshToken returns the revoked token of a refresh token revoked by id
+func newRevokedRefreshToken(token dao.OAuthRefreshToken) dao.RevokedToken {
+	return dao.RevokedToken{
+		TokenHash: token.TokenHash,
+		GrantID:   token.GrantID,
+		ClientID:  token.ClientID,
+		RealmName: token.RealmName,
+		Sub:       token.Sub,
+	}
+}
+
+// publishRevocations publishes the revocation of the tokens for the resource servers,
+// once the revocation is committed.
+func publishRevocations(ctx context.Context, reason string, accessTokens, refreshTokens []dao.RevokedToken) {
+	revocation.Publish(ctx, newRevocationEvents(reason, time.Now(), accessTokens, refreshTokens))
+}
+
+// newRevocationEvents returns the revocation events of the tokens, the refresh tokens first
+// as they are revoked first.
+func newRevocationEvents(
+	reason string, revokedAt time.Time, accessTokens, refreshTokens []dao.RevokedToken,
+) []revocation.Event {
+	events := make([]revocation.Event, 0, len(accessTokens)+len(refreshTokens))
+	appendEvents := func(tokenType string, tokens []dao.RevokedToken) {
+		for _, t := range tokens {
+			events = append(events, revocation.Event{
+				TokenType: tokenType,
+				JTI:       t.JTI,
+				GrantID:   t.GrantID,
+				ClientID:  t.ClientID,
+				Sub:       t.Sub,
+				RealmName: t.RealmName,
+				Reason:    reason,
+				RevokedAt: revokedAt.Unix(),
+			})
+		}
+	}
+	appendEvents(oauth.TokenTypeRefreshToken, refreshTokens)
+	appendEvents(oauth.TokenTypeAccessToken, accessTokens)
+	return events
+}
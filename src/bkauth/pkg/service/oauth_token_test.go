@@ -20,18 +20,25 @@ package service
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"errors"
 	"time"
 
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
 	"github.com/jmoiron/sqlx"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.uber.org/mock/gomock"
 
+	"bkauth/pkg/cache/invalidation"
 	"bkauth/pkg/database"
 	"bkauth/pkg/database/dao"
 	"bkauth/pkg/database/dao/mock"
 	"bkauth/pkg/oauth"
+	"bkauth/pkg/revocation"
 	"bkauth/pkg/service/types"
 )
 
@@ -43,6 +50,9 @@ func useMockDefaultDB(db *sqlx.DB) func() {
 	}
 }
 
+// testTokenHashes are the hash candidates of a token, hashed with the key "k1" and legacy SHA-256
+var testTokenHashes = []oauth.TokenHash{{KeyID: "k1", Hash: "hash-1"}, {Hash: "legacy-1"}}
+
 func newValidRefreshTokenDAO() dao.OAuthRefreshToken {
 	return dao.OAuthRefreshToken{
 		ID:            1,
@@ -53,6 +63,7 @@ func newValidRefreshTokenDAO() dao.OAuthRefreshToken {
 		Sub:           "sub-1",
 		Username:      "user-1",
 		Audience:      `["aud-1","aud-2"]`,
+		Resource:      "https://example.com/api",
 		ExpiresAt:     time.Now().Add(time.Hour),
 		UpdatedAt:     time.Now(),
 		RotationCount: 0,
@@ -80,10 +91,13 @@ var _ = Describe("oauthTokenService", func() {
 				"blueking",
 				"grant-1",
 				"client-1",
-				"default",
-				"sub-1",
-				"user-1",
-				[]string{"aud-1", "aud-2"},
+				types.TokenGrant{
+					TenantID: "default",
+					Sub:      "sub-1",
+					Username: "user-1",
+					Audience: []string{"aud-1", "aud-2"},
+					Scope:    "read write",
+				},
 				3,
 				refreshExpiresAt,
 				policy,
@@ -121,10 +135,94 @@ var _ = Describe("oauthTokenService", func() {
 
 			Expect(prepared.daoAccessToken.Audience).To(Equal(`["aud-1","aud-2"]`))
 			Expect(prepared.daoRefreshToken.Audience).To(Equal(`["aud-1","aud-2"]`))
+
+			Expect(prepared.scope).To(Equal("read write"))
+			Expect(prepared.daoAccessToken.Scope).To(Equal("read write"))
+			Expect(prepared.daoRefreshToken.Scope).To(Equal("read write"))
+		})
+
+		It("should sign a JWT access token bound to the stored jti when the policy asks for JWT", func() {
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(oauth.RegisterSigningKey(oauth.SigningKey{KID: "service-test", PrivateKey: key})).To(Succeed())
+			DeferCleanup(oauth.ResetSigningKeys)
+
+			policy.AccessTokenFormat = oauth.AccessTokenFormatJWT
+			policy.Issuer = "https://bkauth.example.com/realms/blueking/oauth2"
+
+			svc := oauthTokenService{}
+			grant := types.TokenGrant{TenantID: "default", Sub: "sub-1", Username: "user-1", Audience: []string{"aud-1"}}
+			prepared, err := svc.prepareTokenPair(
+				"blueking", "grant-1", "client-1", grant, 0, time.Now().Add(time.Hour), policy,
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			parsed, err := jwt.ParseSigned(prepared.accessToken, []jose.SignatureAlgorithm{jose.ES256})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed.Headers[0].ExtraHeaders[jose.HeaderType]).To(Equal(oauth.JWTTypeAccessToken))
+
+			var claims oauth.AccessTokenClaims
+			Expect(parsed.Claims(key.Public(), &claims)).To(Succeed())
+			Expect(claims.Issuer).To(Equal(policy.Issuer))
+			Expect(claims.Subject).To(Equal("sub-1"))
+			Expect(claims.Audience).To(Equal([]string{"aud-1"}))
+			Expect(claims.ClientID).To(Equal("client-1"))
+			Expect(claims.TenantID).To(Equal("default"))
+			Expect(claims.JTI).To(Equal(prepared.daoAccessToken.JTI))
+			Expect(claims.ExpiresAt).To(Equal(prepared.daoAccessToken.ExpiresAt.Unix()))
+
+			Expect(prepared.daoAccessToken.TokenHash).To(Equal(oauth.HashToken(prepared.accessToken)))
+			Expect(prepared.refreshToken).To(HavePrefix(policy.Prefix))
+
+			// client_credentials: the client is the subject of the JWT, the stored sub stays empty
+			prepared, err = svc.prepareTokenPair(
+				"blueking", "grant-2", "client-1", types.TokenGrant{Audience: []string{"aud-1"}},
+				0, time.Now().Add(time.Hour), policy,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			parsed, err = jwt.ParseSigned(prepared.accessToken, []jose.SignatureAlgorithm{jose.ES256})
+			Expect(err).NotTo(HaveOccurred())
+			claims = oauth.AccessTokenClaims{}
+			Expect(parsed.Claims(key.Public(), &claims)).To(Succeed())
+			Expect(claims.Subject).To(Equal("client-1"))
+			Expect(prepared.daoAccessToken.Sub).To(BeEmpty())
+		})
+
+		It("should bind the refresh token to the DPoP key for public clients only", func() {
+			svc := oauthTokenService{}
+			grant := types.TokenGrant{Audience: []string{"aud-1"}, Cnf: oauth.Confirmation{JKT: "jkt-1"}}
+
+			publicPolicy := policy
+			publicPolicy.PublicClient = true
+			prepared, err := svc.prepareTokenPair(
+				"blueking", "grant-1", "dcr_abc", grant, 0, time.Now().Add(time.Hour), publicPolicy,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(prepared.daoAccessToken.CnfJKT).To(Equal("jkt-1"))
+			Expect(prepared.daoRefreshToken.CnfJKT).To(Equal("jkt-1"))
+
+			prepared, err = svc.prepareTokenPair(
+				"blueking", "grant-1", "client-1", grant, 0, time.Now().Add(time.Hour), policy,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(prepared.daoAccessToken.CnfJKT).To(Equal("jkt-1"))
+			Expect(prepared.daoRefreshToken.CnfJKT).To(BeEmpty())
+		})
+
+		It("should bind the access token to the client certificate", func() {
+			svc := oauthTokenService{}
+			grant := types.TokenGrant{Audience: []string{"aud-1"}, Cnf: oauth.Confirmation{X5TS256: "x5t-1"}}
+
+			prepared, err := svc.prepareTokenPair(
+				"blueking", "grant-1", "client-1", grant, 0, time.Now().Add(time.Hour), policy,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(prepared.daoAccessToken.CnfX5T).To(Equal("x5t-1"))
+			Expect(prepared.daoRefreshToken.CnfX5T).To(BeEmpty())
 		})
 	})
 
-	Describe("GetAccessTokenByTokenHash", func() {
+	Describe("GetAccessTokenByTokenHashes", func() {
 		var (
 			ctl                *gomock.Controller
 			mockAccessManager  *mock.MockOAuthAccessTokenManager
@@ -142,11 +240,11 @@ var _ = Describe("oauthTokenService", func() {
 		})
 
 		It("should return zero value when token does not exist", func() {
-			mockAccessManager.EXPECT().GetByTokenHash(gomock.Any(), "hash-1").
+			mockAccessManager.EXPECT().GetByTokenHashes(gomock.Any(), []string{"hash-1", "legacy-1"}).
 				Return(dao.OAuthAccessToken{}, nil)
 			svc := oauthTokenService{accessTokenManager: mockAccessManager}
 
-			token, err := svc.GetAccessTokenByTokenHash(context.Background(), "hash-1")
+			token, err := svc.GetAccessTokenByTokenHashes(context.Background(), testTokenHashes)
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(token).To(Equal(types.ResolvedAccessToken{}))
@@ -154,47 +252,171 @@ var _ = Describe("oauthTokenService", func() {
 
 		It("should decode audience and map fields", func() {
 			expiresAt := time.Now().Add(10 * time.Minute)
-			mockAccessManager.EXPECT().GetByTokenHash(gomock.Any(), "hash-1").
+			mockAccessManager.EXPECT().GetByTokenHashes(gomock.Any(), []string{"hash-1", "legacy-1"}).
 				Return(dao.OAuthAccessToken{
 					ID:        1,
+					JTI:       "jti-1",
+					TokenHash: "hash-1",
 					ClientID:  "client-1",
 					RealmName: "blueking",
 					Sub:       "sub-1",
 					Username:  "user-1",
 					Audience:  `["aud-1","aud-2"]`,
+					CnfJKT:    "jkt-1",
+					CnfX5T:    "x5t-1",
 					ExpiresAt: expiresAt,
 					Revoked:   true,
 				}, nil)
 			svc := oauthTokenService{accessTokenManager: mockAccessManager}
 
-			token, err := svc.GetAccessTokenByTokenHash(context.Background(), "hash-1")
+			token, err := svc.GetAccessTokenByTokenHashes(context.Background(), testTokenHashes)
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(token).To(Equal(types.ResolvedAccessToken{
+				JTI:       "jti-1",
 				ClientID:  "client-1",
 				RealmName: "blueking",
 				Sub:       "sub-1",
 				Username:  "user-1",
 				Audience:  []string{"aud-1", "aud-2"},
+				Cnf:       oauth.Confirmation{JKT: "jkt-1", X5TS256: "x5t-1"},
 				ExpiresAt: expiresAt.Unix(),
 				Revoked:   true,
 			}))
 		})
 
+		It("should map the grant id and decode the actor chain", func() {
+			mockAccessManager.EXPECT().GetByTokenHashes(gomock.Any(), []string{"hash-1", "legacy-1"}).
+				Return(dao.OAuthAccessToken{
+					ID:        1,
+					TokenHash: "hash-1",
+					GrantID:   "grant-1",
+					ClientID:  "mcp-1",
+					Audience:  `["aud-1"]`,
+					GrantType: oauth.GrantTypeTokenExchange,
+					Act:       `{"client_id":"mcp-1","act":{"client_id":"mcp-0"}}`,
+				}, nil)
+			svc := oauthTokenService{accessTokenManager: mockAccessManager}
+
+			token, err := svc.GetAccessTokenByTokenHashes(context.Background(), testTokenHashes)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token.GrantID).To(Equal("grant-1"))
+			Expect(token.GrantType).To(Equal(oauth.GrantTypeTokenExchange))
+			Expect(token.Act).To(Equal(oauth.NewActor("mcp-1", oauth.NewActor("mcp-0", nil))))
+		})
+
 		It("should return wrapped error when audience is invalid json", func() {
-			mockAccessManager.EXPECT().GetByTokenHash(gomock.Any(), "hash-1").
-				Return(dao.OAuthAccessToken{ID: 1, Audience: "{invalid-json}"}, nil)
+			mockAccessManager.EXPECT().GetByTokenHashes(gomock.Any(), []string{"hash-1", "legacy-1"}).
+				Return(dao.OAuthAccessToken{ID: 1, TokenHash: "hash-1", Audience: "{invalid-json}"}, nil)
 			svc := oauthTokenService{accessTokenManager: mockAccessManager}
 
-			_, err := svc.GetAccessTokenByTokenHash(context.Background(), "hash-1")
+			_, err := svc.GetAccessTokenByTokenHashes(context.Background(), testTokenHashes)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("json.Unmarshal audience fail"))
 		})
 
+		It("should re-hash a token found under a previous key with the current key", func() {
+			mockAccessManager.EXPECT().GetByTokenHashes(gomock.Any(), []string{"hash-1", "legacy-1"}).
+				Return(dao.OAuthAccessToken{ID: 1, TokenHash: "legacy-1", ClientID: "client-1", Audience: `[]`}, nil)
+			mockAccessManager.EXPECT().UpdateTokenHash(gomock.Any(), int64(1), "hash-1", "k1").Return(int64(1), nil)
+			svc := oauthTokenService{accessTokenManager: mockAccessManager}
+
+			token, err := svc.GetAccessTokenByTokenHashes(context.Background(), testTokenHashes)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token.ClientID).To(Equal("client-1"))
+		})
+
+		It("should fail when the token cannot be re-hashed", func() {
+			mockAccessManager.EXPECT().GetByTokenHashes(gomock.Any(), []string{"hash-1", "legacy-1"}).
+				Return(dao.OAuthAccessToken{ID: 1, TokenHash: "legacy-1", ClientID: "client-1", Audience: `[]`}, nil)
+			mockAccessManager.EXPECT().UpdateTokenHash(gomock.Any(), int64(1), "hash-1", "k1").
+				Return(int64(0), errors.New("db error"))
+			svc := oauthTokenService{accessTokenManager: mockAccessManager}
+
+			_, err := svc.GetAccessTokenByTokenHashes(context.Background(), testTokenHashes)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("accessTokenManager.UpdateTokenHash fail"))
+		})
+
 		_ = mockRefreshManager
 	})
 
+	Describe("GetRefreshTokenByTokenHashes", func() {
+		var (
+			ctl                *gomock.Controller
+			mockRefreshManager *mock.MockOAuthRefreshTokenManager
+		)
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			mockRefreshManager = mock.NewMockOAuthRefreshTokenManager(ctl)
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("should return zero value when token does not exist", func() {
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), []string{"hash-1", "legacy-1"}).
+				Return(dao.OAuthRefreshToken{}, nil)
+			svc := oauthTokenService{refreshTokenManager: mockRefreshManager}
+
+			token, err := svc.GetRefreshTokenByTokenHashes(context.Background(), testTokenHashes)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal(types.ResolvedRefreshToken{}))
+		})
+
+		It("should map fields", func() {
+			expiresAt := time.Now().Add(time.Hour)
+			createdAt := time.Now().Add(-time.Minute)
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), []string{"hash-1", "legacy-1"}).
+				Return(dao.OAuthRefreshToken{
+					ID:            1,
+					GrantID:       "grant-1",
+					ClientID:      "client-1",
+					RealmName:     "blueking",
+					Sub:           "sub-1",
+					Username:      "user-1",
+					Scope:         "read",
+					RotationCount: 3,
+					ExpiresAt:     expiresAt,
+					CreatedAt:     createdAt,
+				}, nil)
+			svc := oauthTokenService{refreshTokenManager: mockRefreshManager}
+
+			token, err := svc.GetRefreshTokenByTokenHashes(context.Background(), testTokenHashes)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token).To(Equal(types.ResolvedRefreshToken{
+				GrantID:         "grant-1",
+				ClientID:        "client-1",
+				RealmName:       "blueking",
+				Sub:             "sub-1",
+				Username:        "user-1",
+				Scope:           "read",
+				RotationCount:   3,
+				ExpiresAt:       expiresAt.Unix(),
+				LastRefreshedAt: createdAt.Unix(),
+			}))
+		})
+
+		It("should return error when the lookup fails", func() {
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), gomock.Any()).
+				Return(dao.OAuthRefreshToken{}, errors.New("db error"))
+			svc := oauthTokenService{refreshTokenManager: mockRefreshManager}
+
+			_, err := svc.GetRefreshTokenByTokenHashes(context.Background(), testTokenHashes)
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("refreshTokenManager.GetByTokenHashes fail"))
+		})
+	})
+
 	Describe("RefreshAccessToken", func() {
 		var (
 			ctl                *gomock.Controller
@@ -218,19 +440,23 @@ var _ = Describe("oauthTokenService", func() {
 		})
 
 		It("should reject unknown refresh tokens", func() {
-			mockRefreshManager.EXPECT().GetByTokenHash(gomock.Any(), gomock.Any()).
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), gomock.Any()).
 				Return(dao.OAuthRefreshToken{}, nil)
 
-			_, err := svc.RefreshAccessToken(context.Background(), "blueking", "refresh-1", "client-1", policy)
+			_, err := svc.RefreshAccessToken(
+				context.Background(), "blueking", "refresh-1", "client-1", oauth.Confirmation{}, policy,
+			)
 
 			Expect(err).To(MatchError(oauth.ErrInvalidRefreshToken))
 		})
 
 		It("should reject refresh tokens from a different realm", func() {
-			mockRefreshManager.EXPECT().GetByTokenHash(gomock.Any(), gomock.Any()).
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), gomock.Any()).
 				Return(newValidRefreshTokenDAO(), nil)
 
-			_, err := svc.RefreshAccessToken(context.Background(), "bk-devops", "refresh-1", "client-1", policy)
+			_, err := svc.RefreshAccessToken(
+				context.Background(), "bk-devops", "refresh-1", "client-1", oauth.Confirmation{}, policy,
+			)
 
 			Expect(err).To(MatchError(oauth.ErrRealmMismatch))
 		})
@@ -238,20 +464,51 @@ var _ = Describe("oauthTokenService", func() {
 		It("should reject refresh tokens owned by another client", func() {
 			rt := newValidRefreshTokenDAO()
 			rt.ClientID = "another-client"
-			mockRefreshManager.EXPECT().GetByTokenHash(gomock.Any(), gomock.Any()).Return(rt, nil)
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), gomock.Any()).Return(rt, nil)
 
-			_, err := svc.RefreshAccessToken(context.Background(), "blueking", "refresh-1", "client-1", policy)
+			_, err := svc.RefreshAccessToken(
+				context.Background(), "blueking", "refresh-1", "client-1", oauth.Confirmation{}, policy,
+			)
 
 			Expect(err).To(MatchError(oauth.ErrClientMismatch))
 		})
 
+		It("should reject DPoP-bound refresh tokens presented without the bound key", func() {
+			rt := newValidRefreshTokenDAO()
+			rt.CnfJKT = "jkt-1"
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), gomock.Any()).Return(rt, nil).Times(2)
+
+			_, err := svc.RefreshAccessToken(
+				context.Background(), "blueking", "refresh-1", "client-1", oauth.Confirmation{}, policy,
+			)
+			Expect(err).To(MatchError(oauth.ErrDPoPKeyMismatch))
+
+			_, err = svc.RefreshAccessToken(
+				context.Background(), "blueking", "refresh-1", "client-1", oauth.Confirmation{JKT: "jkt-2"}, policy,
+			)
+			Expect(err).To(MatchError(oauth.ErrDPoPKeyMismatch))
+		})
+
+		It("should reject certificate-bound refresh tokens presented with another certificate", func() {
+			rt := newValidRefreshTokenDAO()
+			rt.CnfX5T = "x5t-1"
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), gomock.Any()).Return(rt, nil)
+
+			_, err := svc.RefreshAccessToken(
+				context.Background(), "blueking", "refresh-1", "client-1", oauth.Confirmation{X5TS256: "x5t-2"}, policy,
+			)
+			Expect(err).To(MatchError(oauth.ErrClientCertificateMismatch))
+		})
+
 		It("should reject revoked refresh tokens inside grace period without revoking the family", func() {
 			rt := newValidRefreshTokenDAO()
 			rt.Revoked = true
 			rt.UpdatedAt = time.Now()
-			mockRefreshManager.EXPECT().GetByTokenHash(gomock.Any(), gomock.Any()).Return(rt, nil)
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), gomock.Any()).Return(rt, nil)
 
-			_, err := svc.RefreshAccessToken(context.Background(), "blueking", "refresh-1", "client-1", policy)
+			_, err := svc.RefreshAccessToken(
+				context.Background(), "blueking", "refresh-1", "client-1", oauth.Confirmation{}, policy,
+			)
 
 			Expect(err).To(MatchError(oauth.ErrRefreshTokenRevoked))
 		})
@@ -260,14 +517,14 @@ var _ = Describe("oauthTokenService", func() {
 			rt := newValidRefreshTokenDAO()
 			rt.Revoked = true
 			rt.UpdatedAt = time.Now().Add(-oauth.ReplayDetectionGracePeriod - time.Second)
-			mockRefreshManager.EXPECT().GetByTokenHash(gomock.Any(), gomock.Any()).Return(rt, nil)
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), gomock.Any()).Return(rt, nil)
 
 			mockRefreshManager.EXPECT().
 				RevokeByGrantIDWithTx(gomock.Any(), gomock.Any(), "grant-1").
-				Return(int64(1), nil)
+				Return([]dao.RevokedToken{{TokenHash: "rt-hash-1"}}, nil)
 			mockAccessManager.EXPECT().
 				RevokeByGrantIDWithTx(gomock.Any(), gomock.Any(), "grant-1").
-				Return(int64(1), nil)
+				Return([]dao.RevokedToken{{TokenHash: "hash-1"}}, nil)
 
 			db, dbMock := database.NewMockSqlxDB()
 			dbMock.ExpectBegin()
@@ -275,7 +532,9 @@ var _ = Describe("oauthTokenService", func() {
 			restore := useMockDefaultDB(db)
 			defer restore()
 
-			_, err := svc.RefreshAccessToken(context.Background(), "blueking", "refresh-1", "client-1", policy)
+			_, err := svc.RefreshAccessToken(
+				context.Background(), "blueking", "refresh-1", "client-1", oauth.Confirmation{}, policy,
+			)
 
 			Expect(err).To(MatchError(oauth.ErrRefreshTokenRevoked))
 			Expect(dbMock.ExpectationsWereMet()).To(Succeed())
@@ -284,26 +543,57 @@ var _ = Describe("oauthTokenService", func() {
 		It("should reject expired refresh tokens", func() {
 			rt := newValidRefreshTokenDAO()
 			rt.ExpiresAt = time.Now().Add(-time.Second)
-			mockRefreshManager.EXPECT().GetByTokenHash(gomock.Any(), gomock.Any()).Return(rt, nil)
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), gomock.Any()).Return(rt, nil)
 
-			_, err := svc.RefreshAccessToken(context.Background(), "blueking", "refresh-1", "client-1", policy)
+			_, err := svc.RefreshAccessToken(
+				context.Background(), "blueking", "refresh-1", "client-1", oauth.Confirmation{}, policy,
+			)
 
 			Expect(err).To(MatchError(oauth.ErrRefreshTokenExpired))
 		})
 
+		It("should reject refresh tokens not rotated within the idle timeout", func() {
+			rt := newValidRefreshTokenDAO()
+			rt.CreatedAt = time.Now().Add(-2 * time.Hour)
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), gomock.Any()).Return(rt, nil)
+
+			idlePolicy := policy
+			idlePolicy.RefreshTokenIdleTimeout = 3600
+			_, err := svc.RefreshAccessToken(
+				context.Background(), "blueking", "refresh-1", "client-1", oauth.Confirmation{}, idlePolicy,
+			)
+
+			Expect(err).To(MatchError(oauth.ErrRefreshTokenIdleExpired))
+		})
+
+		It("should reject refresh tokens no longer allowed by the client policy", func() {
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), gomock.Any()).
+				Return(newValidRefreshTokenDAO(), nil)
+
+			restricted := policy
+			restricted.ClientPolicy = oauth.ClientPolicy{AllowedRealms: []string{"bk-devops"}}
+			_, err := svc.RefreshAccessToken(
+				context.Background(), "blueking", "refresh-1", "client-1", oauth.Confirmation{}, restricted,
+			)
+
+			Expect(errors.Is(err, oauth.ErrRealmNotAllowed)).To(BeTrue())
+		})
+
 		It("should return wrapped error when stored audience is invalid", func() {
 			rt := newValidRefreshTokenDAO()
 			rt.Audience = "{invalid-json}"
-			mockRefreshManager.EXPECT().GetByTokenHash(gomock.Any(), gomock.Any()).Return(rt, nil)
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), gomock.Any()).Return(rt, nil)
 
-			_, err := svc.RefreshAccessToken(context.Background(), "blueking", "refresh-1", "client-1", policy)
+			_, err := svc.RefreshAccessToken(
+				context.Background(), "blueking", "refresh-1", "client-1", oauth.Confirmation{}, policy,
+			)
 
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("json.Unmarshal audience fail"))
 		})
 
 		It("should return refresh token revoked when the CAS revoke loses the race", func() {
-			mockRefreshManager.EXPECT().GetByTokenHash(gomock.Any(), gomock.Any()).
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), gomock.Any()).
 				Return(newValidRefreshTokenDAO(), nil)
 			mockRefreshManager.EXPECT().
 				RevokeIfNotRevokedWithTx(gomock.Any(), gomock.Any(), int64(1)).
@@ -315,7 +605,9 @@ var _ = Describe("oauthTokenService", func() {
 			restore := useMockDefaultDB(db)
 			defer restore()
 
-			_, err := svc.RefreshAccessToken(context.Background(), "blueking", "refresh-1", "client-1", policy)
+			_, err := svc.RefreshAccessToken(
+				context.Background(), "blueking", "refresh-1", "client-1", oauth.Confirmation{}, policy,
+			)
 
 			Expect(err).To(MatchError(oauth.ErrRefreshTokenRevoked))
 			Expect(dbMock.ExpectationsWereMet()).To(Succeed())
@@ -325,14 +617,14 @@ var _ = Describe("oauthTokenService", func() {
 			rt := newValidRefreshTokenDAO()
 			rt.RotationCount = 7
 			originalExpiresAt := rt.ExpiresAt
-			mockRefreshManager.EXPECT().GetByTokenHash(gomock.Any(), gomock.Any()).Return(rt, nil)
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), gomock.Any()).Return(rt, nil)
 
 			mockRefreshManager.EXPECT().
 				RevokeIfNotRevokedWithTx(gomock.Any(), gomock.Any(), int64(1)).
 				Return(int64(1), nil)
 			mockAccessManager.EXPECT().
 				RevokeWithTx(gomock.Any(), gomock.Any(), int64(101)).
-				Return(int64(1), nil)
+				Return([]dao.RevokedToken{{TokenHash: "hash-1"}}, nil)
 			mockAccessManager.EXPECT().
 				CreateWithTx(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(dao.OAuthAccessToken{})).
 				DoAndReturn(func(_ context.Context, _ *sqlx.Tx, token dao.OAuthAccessToken) (int64, error) {
@@ -349,6 +641,7 @@ var _ = Describe("oauthTokenService", func() {
 					Expect(token.RealmName).To(Equal("blueking"))
 					Expect(token.RotationCount).To(Equal(int64(8)))
 					Expect(token.ExpiresAt).To(Equal(originalExpiresAt))
+					Expect(token.Resource).To(Equal("https://example.com/api"))
 					return int64(2002), nil
 				})
 
@@ -358,7 +651,9 @@ var _ = Describe("oauthTokenService", func() {
 			restore := useMockDefaultDB(db)
 			defer restore()
 
-			pair, err := svc.RefreshAccessToken(context.Background(), "blueking", "refresh-1", "client-1", policy)
+			pair, err := svc.RefreshAccessToken(
+				context.Background(), "blueking", "refresh-1", "client-1", oauth.Confirmation{}, policy,
+			)
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(pair.AccessToken).To(HavePrefix(policy.Prefix))
@@ -391,25 +686,32 @@ var _ = Describe("oauthTokenService", func() {
 		})
 
 		It("should revoke an access token when it exists", func() {
-			mockAccessManager.EXPECT().GetByTokenHash(gomock.Any(), "hash-1").
+			mockAccessManager.EXPECT().GetByTokenHashes(gomock.Any(), []string{"hash-1", "legacy-1"}).
 				Return(dao.OAuthAccessToken{ID: 101, ClientID: "client-1"}, nil)
 			mockAccessManager.EXPECT().Revoke(gomock.Any(), int64(101)).Return(int64(1), nil)
 
-			err := svc.RevokeToken(context.Background(), "hash-1", "client-1")
+			err := svc.RevokeToken(context.Background(), testTokenHashes, "client-1")
 
 			Expect(err).NotTo(HaveOccurred())
 		})
 
 		It("should revoke refresh token and linked access token in one transaction", func() {
-			mockAccessManager.EXPECT().GetByTokenHash(gomock.Any(), "hash-1").
+			var evicted []string
+			invalidation.RegisterEvictor(invalidation.KindAccessToken, func(_ context.Context, keys []string) {
+				evicted = keys
+			})
+			defer invalidation.RegisterEvictor(invalidation.KindAccessToken, func(context.Context, []string) {})
+
+			mockAccessManager.EXPECT().GetByTokenHashes(gomock.Any(), []string{"hash-1", "legacy-1"}).
 				Return(dao.OAuthAccessToken{}, nil)
-			mockRefreshManager.EXPECT().GetByTokenHash(gomock.Any(), "hash-1").
+			mockRefreshManager.EXPECT().GetByTokenHashes(gomock.Any(), []string{"hash-1", "legacy-1"}).
 				Return(dao.OAuthRefreshToken{ID: 1, AccessTokenID: 101, ClientID: "client-1"}, nil)
 
 			mockRefreshManager.EXPECT().
 				RevokeWithTx(gomock.Any(), gomock.Any(), int64(1)).Return(int64(1), nil)
 			mockAccessManager.EXPECT().
-				RevokeWithTx(gomock.Any(), gomock.Any(), int64(101)).Return(int64(1), nil)
+				RevokeWithTx(gomock.Any(), gomock.Any(), int64(101)).
+				Return([]dao.RevokedToken{{TokenHash: "hash-1"}}, nil)
 
 			db, dbMock := database.NewMockSqlxDB()
 			dbMock.ExpectBegin()
@@ -417,10 +719,12 @@ var _ = Describe("oauthTokenService", func() {
 			restore := useMockDefaultDB(db)
 			defer restore()
 
-			err := svc.RevokeToken(context.Background(), "hash-1", "client-1")
+			err := svc.RevokeToken(context.Background(), testTokenHashes, "client-1")
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(dbMock.ExpectationsWereMet()).To(Succeed())
+			// the linked access token is evicted from the cache too
+			Expect(evicted).To(Equal([]string{"hash-1"}))
 		})
 	})
 
@@ -449,10 +753,10 @@ var _ = Describe("oauthTokenService", func() {
 		It("should revoke refresh tokens before access tokens", func() {
 			first := mockRefreshManager.EXPECT().
 				RevokeByGrantIDWithTx(gomock.Any(), gomock.Any(), "grant-1").
-				Return(int64(1), nil)
+				Return([]dao.RevokedToken{{TokenHash: "rt-hash-1"}}, nil)
 			mockAccessManager.EXPECT().
 				RevokeByGrantIDWithTx(gomock.Any(), gomock.Any(), "grant-1").
-				Return(int64(1), nil).
+				Return([]dao.RevokedToken{{TokenHash: "hash-1"}}, nil).
 				After(first)
 
 			db, dbMock := database.NewMockSqlxDB()
@@ -461,12 +765,72 @@ var _ = Describe("oauthTokenService", func() {
 			restore := useMockDefaultDB(db)
 			defer restore()
 
-			err := svc.RevokeByGrantID(context.Background(), "grant-1")
+			err := svc.RevokeByGrantID(context.Background(), "grant-1", revocation.ReasonGrantRevoked)
 
 			Expect(err).NotTo(HaveOccurred())
 			Expect(dbMock.ExpectationsWereMet()).To(Succeed())
 		})
 	})
+
+	Describe("RevokeGrantOfUser", func() {
+		var (
+			ctl                *gomock.Controller
+			mockAccessManager  *mock.MockOAuthAccessTokenManager
+			mockRefreshManager *mock.MockOAuthRefreshTokenManager
+			svc                oauthTokenService
+		)
+
+		BeforeEach(func() {
+			ctl = gomock.NewController(GinkgoT())
+			mockAccessManager = mock.NewMockOAuthAccessTokenManager(ctl)
+			mockRefreshManager = mock.NewMockOAuthRefreshTokenManager(ctl)
+			svc = oauthTokenService{
+				accessTokenManager:  mockAccessManager,
+				refreshTokenManager: mockRefreshManager,
+			}
+
+			mockRefreshManager.EXPECT().
+				ListActiveGrantsBySub(gomock.Any(), "default", "sub-1", gomock.Any()).
+				Return([]dao.OAuthRefreshTokenGrant{{
+					GrantID:   "grant-1",
+					ClientID:  "client-1",
+					RealmName: "blueking",
+					ExpiresAt: time.Now().Add(time.Hour),
+				}}, nil)
+		})
+
+		AfterEach(func() {
+			ctl.Finish()
+		})
+
+		It("should reject grants that are not active grants of the user", func() {
+			_, err := svc.RevokeGrantOfUser(context.Background(), "default", "sub-1", "grant-2")
+
+			Expect(err).To(MatchError(oauth.ErrGrantNotFound))
+		})
+
+		It("should revoke the grant family", func() {
+			mockRefreshManager.EXPECT().
+				RevokeByGrantIDWithTx(gomock.Any(), gomock.Any(), "grant-1").
+				Return([]dao.RevokedToken{{TokenHash: "rt-hash-1"}}, nil)
+			mockAccessManager.EXPECT().
+				RevokeByGrantIDWithTx(gomock.Any(), gomock.Any(), "grant-1").
+				Return([]dao.RevokedToken{{TokenHash: "hash-1"}}, nil)
+
+			db, dbMock := database.NewMockSqlxDB()
+			dbMock.ExpectBegin()
+			dbMock.ExpectCommit()
+			restore := useMockDefaultDB(db)
+			defer restore()
+
+			grant, err := svc.RevokeGrantOfUser(context.Background(), "default", "sub-1", "grant-1")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(grant.ClientID).To(Equal("client-1"))
+			Expect(grant.RealmName).To(Equal("blueking"))
+			Expect(dbMock.ExpectationsWereMet()).To(Succeed())
+		})
+	})
 })
 
 var _ = Describe("oauthTokenService.IssueTokensForAuthorizationCode", func() {
@@ -527,8 +891,9 @@ var _ = Describe("oauthTokenService.IssueTokensForAuthorizationCode", func() {
 		defer restore()
 
 		pair, err := svc.IssueTokensForAuthorizationCode(
-			context.Background(), "blueking", "client-1", "default", "sub-1", "user-1",
-			[]string{"aud-1", "aud-2"}, policy,
+			context.Background(), "blueking", "client-1", types.TokenGrant{
+				TenantID: "default", Sub: "sub-1", Username: "user-1", Audience: []string{"aud-1", "aud-2"},
+			}, policy,
 		)
 
 		Expect(err).NotTo(HaveOccurred())
@@ -545,8 +910,9 @@ var _ = Describe("oauthTokenService.IssueTokensForAuthorizationCode", func() {
 		defer restore()
 
 		_, err := svc.IssueTokensForAuthorizationCode(
-			context.Background(), "blueking", "client-1", "default", "sub-1", "user-1",
-			[]string{"aud-1"}, policy,
+			context.Background(), "blueking", "client-1", types.TokenGrant{
+				TenantID: "default", Sub: "sub-1", Username: "user-1", Audience: []string{"aud-1"},
+			}, policy,
 		)
 
 		Expect(err).To(HaveOccurred())
@@ -612,8 +978,9 @@ var _ = Describe("oauthTokenService.IssueTokensForDeviceCode", func() {
 		defer restore()
 
 		pair, err := svc.IssueTokensForDeviceCode(
-			context.Background(), "blueking", "client-1", "default", "sub-1", "user-1",
-			[]string{"aud-1"}, policy,
+			context.Background(), "blueking", "client-1", types.TokenGrant{
+				TenantID: "default", Sub: "sub-1", Username: "user-1", Audience: []string{"aud-1"},
+			}, policy,
 		)
 
 		Expect(err).NotTo(HaveOccurred())
@@ -630,11 +997,200 @@ var _ = Describe("oauthTokenService.IssueTokensForDeviceCode", func() {
 		defer restore()
 
 		_, err := svc.IssueTokensForDeviceCode(
-			context.Background(), "blueking", "client-1", "default", "sub-1", "user-1",
-			[]string{"aud-1"}, policy,
+			context.Background(), "blueking", "client-1", types.TokenGrant{
+				TenantID: "default", Sub: "sub-1", Username: "user-1", Audience: []string{"aud-1"},
+			}, policy,
 		)
 
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(ContainSubstring("GenerateDefaultDBTx fail"))
 	})
 })
+
+var _ = Describe("oauthTokenService.IssueAccessTokenForClientCredentials", func() {
+	var (
+		ctl                *gomock.Controller
+		mockAccessManager  *mock.MockOAuthAccessTokenManager
+		mockRefreshManager *mock.MockOAuthRefreshTokenManager
+		svc                oauthTokenService
+		policy             types.TokenIssuancePolicy
+		grant              types.TokenGrant
+	)
+
+	BeforeEach(func() {
+		ctl = gomock.NewController(GinkgoT())
+		mockAccessManager = mock.NewMockOAuthAccessTokenManager(ctl)
+		mockRefreshManager = mock.NewMockOAuthRefreshTokenManager(ctl)
+		svc = oauthTokenService{
+			accessTokenManager:  mockAccessManager,
+			refreshTokenManager: mockRefreshManager,
+		}
+		policy = types.TokenIssuancePolicy{
+			Prefix:          "bk_",
+			AccessTokenTTL:  300,
+			RefreshTokenTTL: 3600,
+		}
+		grant = types.TokenGrant{
+			TenantID:  "default",
+			Audience:  []string{"aud-1"},
+			Scope:     "read",
+			GrantType: oauth.GrantTypeClientCredentials,
+		}
+	})
+
+	AfterEach(func() {
+		ctl.Finish()
+	})
+
+	It("ok", func() {
+		mockAccessManager.EXPECT().
+			CreateWithTx(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(dao.OAuthAccessToken{})).
+			DoAndReturn(func(_ context.Context, _ *sqlx.Tx, token dao.OAuthAccessToken) (int64, error) {
+				Expect(token.ClientID).To(Equal("client-1"))
+				Expect(token.RealmName).To(Equal("blueking"))
+				Expect(token.TenantID).To(Equal("default"))
+				Expect(token.Sub).To(BeEmpty())
+				Expect(token.Username).To(BeEmpty())
+				Expect(token.Audience).To(Equal(`["aud-1"]`))
+				Expect(token.Scope).To(Equal("read"))
+				Expect(token.GrantType).To(Equal(oauth.GrantTypeClientCredentials))
+				Expect(token.GrantID).NotTo(BeEmpty())
+				return int64(1001), nil
+			})
+
+		db, dbMock := database.NewMockSqlxDB()
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+		restore := useMockDefaultDB(db)
+		defer restore()
+
+		pair, err := svc.IssueAccessTokenForClientCredentials(
+			context.Background(), "blueking", "client-1", grant, policy,
+		)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pair.AccessToken).To(HavePrefix(policy.Prefix))
+		Expect(pair.RefreshToken).To(BeEmpty())
+		Expect(pair.ExpiresIn).To(Equal(policy.AccessTokenTTL))
+		Expect(pair.Scope).To(Equal("read"))
+		Expect(dbMock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("create error", func() {
+		mockAccessManager.EXPECT().
+			CreateWithTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(int64(0), errors.New("insert failed"))
+
+		db, dbMock := database.NewMockSqlxDB()
+		dbMock.ExpectBegin()
+		dbMock.ExpectRollback()
+		restore := useMockDefaultDB(db)
+		defer restore()
+
+		_, err := svc.IssueAccessTokenForClientCredentials(
+			context.Background(), "blueking", "client-1", grant, policy,
+		)
+
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("accessTokenManager.CreateWithTx fail"))
+	})
+})
+
+var _ = Describe("oauthTokenService.IssueAccessTokenForTokenExchange", func() {
+	var (
+		ctl               *gomock.Controller
+		mockAccessManager *mock.MockOAuthAccessTokenManager
+		svc               oauthTokenService
+		policy            types.TokenIssuancePolicy
+	)
+
+	BeforeEach(func() {
+		ctl = gomock.NewController(GinkgoT())
+		mockAccessManager = mock.NewMockOAuthAccessTokenManager(ctl)
+		svc = oauthTokenService{accessTokenManager: mockAccessManager}
+		policy = types.TokenIssuancePolicy{
+			Prefix:         "bk_",
+			AccessTokenTTL: 300,
+		}
+	})
+
+	AfterEach(func() {
+		ctl.Finish()
+	})
+
+	It("should join the subject's grant family and persist the actor chain", func() {
+		mockAccessManager.EXPECT().
+			CreateWithTx(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(dao.OAuthAccessToken{})).
+			DoAndReturn(func(_ context.Context, _ *sqlx.Tx, token dao.OAuthAccessToken) (int64, error) {
+				Expect(token.GrantID).To(Equal("grant-subject"))
+				Expect(token.ClientID).To(Equal("mcp-1"))
+				Expect(token.Sub).To(Equal("sub-1"))
+				Expect(token.GrantType).To(Equal(oauth.GrantTypeTokenExchange))
+				Expect(token.Act).To(Equal(`{"client_id":"mcp-1"}`))
+				return int64(1001), nil
+			})
+
+		db, dbMock := database.NewMockSqlxDB()
+		dbMock.ExpectBegin()
+		dbMock.ExpectCommit()
+		restore := useMockDefaultDB(db)
+		defer restore()
+
+		pair, err := svc.IssueAccessTokenForTokenExchange(
+			context.Background(), "blueking", "grant-subject", "mcp-1", types.TokenGrant{
+				TenantID:  "default",
+				Sub:       "sub-1",
+				Username:  "user-1",
+				Audience:  []string{"aud-1"},
+				GrantType: oauth.GrantTypeTokenExchange,
+				Act:       oauth.NewActor("mcp-1", nil),
+			}, policy,
+		)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pair.AccessToken).To(HavePrefix(policy.Prefix))
+		Expect(pair.RefreshToken).To(BeEmpty())
+		Expect(dbMock.ExpectationsWereMet()).To(Succeed())
+	})
+})
+
+var _ = Describe("newRevocationEvents", func() {
+	It("should convert the refresh tokens first, then the access tokens", func() {
+		revokedAt := time.Unix(1700000000, 0)
+		events := newRevocationEvents(revocation.ReasonRotated, revokedAt,
+			[]dao.RevokedToken{{
+				TokenHash: "hash-1", JTI: "jti-1", GrantID: "grant-1", ClientID: "client-1", RealmName: "blueking",
+				Sub: "sub-1",
+			}},
+			[]dao.RevokedToken{{
+				TokenHash: "rt-hash-1", GrantID: "grant-1", ClientID: "client-1", RealmName: "blueking", Sub: "sub-1",
+			}},
+		)
+
+		Expect(events).To(Equal([]revocation.Event{
+			{
+				TokenType: oauth.TokenTypeRefreshToken,
+				GrantID:   "grant-1",
+				ClientID:  "client-1",
+				Sub:       "sub-1",
+				RealmName: "blueking",
+				Reason:    revocation.ReasonRotated,
+				RevokedAt: 1700000000,
+			},
+			{
+				TokenType: oauth.TokenTypeAccessToken,
+				JTI:       "jti-1",
+				GrantID:   "grant-1",
+				ClientID:  "client-1",
+				Sub:       "sub-1",
+				RealmName: "blueking",
+				Reason:    revocation.ReasonRotated,
+				RevokedAt: 1700000000,
+			},
+		}))
+	})
+
+	It("should return no events when nothing was revoked", func() {
+		Expect(newRevocationEvents(revocation.ReasonRevoked, time.Now(), nil, nil)).To(BeEmpty())
+	})
+})
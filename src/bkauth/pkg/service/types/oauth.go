@@ -31,10 +31,34 @@ type OAuthClientDynamicRegistrationInput struct {
 	RedirectURIs []string
 	GrantTypes   []string
 	LogoURI      string
+	// empty means "none"; private_key_jwt requires one of JWKS / JWKSURI
+	TokenEndpointAuthMethod string
+	JWKS                    string
+	JWKSURI                 string
+}
+
+// OAuthConfidentialClientInput carries the fields of the confidential client of an app,
+// managed through the app API; its client_id is the app_code.
+type OAuthConfidentialClientInput struct {
+	Name         string
+	RedirectURIs []string
+	GrantTypes   []string
+	LogoURI      string
+	// empty means client_secret_basic with the app secrets; tls_client_auth requires one of
+	// TLSClientAuthSubjectDN / TLSClientAuthSAN, self_signed_tls_client_auth requires JWKS
+	// and private_key_jwt requires one of JWKS / JWKSURI
+	TokenEndpointAuthMethod string
+	TLSClientAuthSubjectDN  string
+	TLSClientAuthSAN        string
+	JWKS                    string
+	JWKSURI                 string
+	// empty means not restricted
+	AllowedRealms    []string
+	AllowedResources []string
 }
 
 // OAuthClient represents the full OAuth client entity, used only by DynamicRegister.
-// token_endpoint_auth_method is derived from Type at runtime:
+// token_endpoint_auth_method, unless registered, is derived from Type at runtime:
 //
 //	public -> "none", confidential -> "client_secret_basic"
 type OAuthClient struct {
@@ -45,10 +69,25 @@ type OAuthClient struct {
 	GrantTypes   []string `json:"grant_types"`
 	LogoURI      string   `json:"logo_uri,omitempty"`
 	CreatedAt    int64    `json:"client_id_issued_at,omitempty"`
+
+	// AuthMethod is the registered token_endpoint_auth_method, empty when derived from Type.
+	AuthMethod             string `json:"-"`
+	TLSClientAuthSubjectDN string `json:"-"`
+	TLSClientAuthSAN       string `json:"-"`
+	JWKS                   string `json:"-"`
+	JWKSURI                string `json:"-"`
+	// RegistrationAccessToken is only set on registration (RFC 7592 §3), it cannot be retrieved later.
+	RegistrationAccessToken string `json:"-"`
+	// AllowedRealms / AllowedResources are the client policy, only managed through the app API.
+	AllowedRealms    []string `json:"-"`
+	AllowedResources []string `json:"-"`
 }
 
-// TokenEndpointAuthMethod returns the auth method derived from client type.
+// TokenEndpointAuthMethod returns the registered auth method, or the one derived from client type.
 func (c OAuthClient) TokenEndpointAuthMethod() string {
+	if c.AuthMethod != "" {
+		return c.AuthMethod
+	}
 	if c.Type == oauth.ClientTypeConfidential {
 		return oauth.AuthMethodClientSecretBasic
 	}
@@ -59,8 +98,25 @@ func (c OAuthClient) TokenEndpointAuthMethod() string {
 // Used by token, authorize and device_authorize handlers to validate grant types and redirect URIs.
 type OAuthClientFlowSpec struct {
 	ID           string
+	Type         string
 	GrantTypes   []string
 	RedirectURIs []string
+	// Policy is the client's own policy, empty when none is registered.
+	Policy oauth.ClientPolicy
+}
+
+// IsPublic reports whether the client is registered as a public client.
+func (s OAuthClientFlowSpec) IsPublic() bool {
+	return s.Type == oauth.ClientTypePublic
+}
+
+// ResolvePolicy returns the effective policy of the client on the realm: its own policy,
+// or the realm default for public clients that have none.
+func (s OAuthClientFlowSpec) ResolvePolicy(realmName string) oauth.ClientPolicy {
+	if s.Policy.IsEmpty() && s.IsPublic() {
+		return oauth.DefaultPublicClientPolicy(realmName)
+	}
+	return s.Policy
 }
 
 // SupportsGrantType reports whether the client was registered with the given grant type.
@@ -81,42 +137,87 @@ type OAuthClientProfile struct {
 	LogoURI string
 }
 
+// OAuthClientAuthSpec holds the registered token endpoint authentication of the client,
+// used by the client authentication middleware.
+type OAuthClientAuthSpec struct {
+	ID                      string
+	Type                    string
+	TokenEndpointAuthMethod string
+	// RFC 8705 §2.1.2: exactly one of them is registered for tls_client_auth
+	TLSClientAuthSubjectDN string
+	TLSClientAuthSAN       string
+	// JWKS is the client's JSON Web Key Set, e.g. the certificates of self_signed_tls_client_auth
+	// or the keys of private_key_jwt; JWKSURI is where the client publishes it instead.
+	JWKS    string
+	JWKSURI string
+}
+
+// IsPublic reports whether the client is registered as a public client, which does not authenticate.
+func (s OAuthClientAuthSpec) IsPublic() bool {
+	return s.Type == oauth.ClientTypePublic
+}
+
+// IsTLSClientAuth reports whether the client authenticates with a client certificate (RFC 8705 §2).
+func (s OAuthClientAuthSpec) IsTLSClientAuth() bool {
+	return s.TokenEndpointAuthMethod == oauth.AuthMethodTLSClientAuth ||
+		s.TokenEndpointAuthMethod == oauth.AuthMethodSelfSignedTLSClientAuth
+}
+
 // CreateAuthorizationCodeInput carries the caller-provided fields needed
 // to persist a new authorization code.
 type CreateAuthorizationCodeInput struct {
-	Code                string
-	ClientID            string
-	TenantID            string
-	RealmName           string
-	Sub                 string
-	Username            string
-	RedirectURI         string
-	Audience            []string
-	CodeChallenge       string
-	CodeChallengeMethod string
+	Code                 string
+	ClientID             string
+	TenantID             string
+	RealmName            string
+	Sub                  string
+	Username             string
+	RedirectURI          string
+	Audience             []string
+	Resource             string
+	AuthorizationDetails []oauth.AuthorizationDetail
+	Scope                string
+	CodeChallenge        string
+	CodeChallengeMethod  string
+	Nonce                string
 }
 
 // ConsumedAuthorizationCode is the minimal data set returned after an
 // authorization code has been validated and consumed, used to issue tokens.
 type ConsumedAuthorizationCode struct {
-	TenantID string
-	Sub      string
-	Username string
-	Audience []string
+	TenantID             string
+	Sub                  string
+	Username             string
+	Audience             []string
+	Resource             string
+	AuthorizationDetails []oauth.AuthorizationDetail
+	Scope                string
+	Nonce                string
 }
 
 // ResolvedAccessToken contains the fields resolved from an opaque access token string,
 // suitable for introspection, validation, and caching.
 type ResolvedAccessToken struct {
 	// Standard OAuth 2.0 / RFC 7662 claims
-	// JTI      string
+	JTI       string
+	GrantID   string
 	ClientID  string
 	TenantID  string
 	RealmName string
 	Sub       string
 	Username  string
 	Audience  []string
-	// Scope    string
+	Scope     string
+	// AuthorizationDetails lets the resource server enforce permissions finer than the audience,
+	// e.g. per gateway API (RFC 9396 §9).
+	AuthorizationDetails []oauth.AuthorizationDetail
+	// GrantType is the grant the token was issued through; client_credentials
+	// tokens carry no Sub/Username because they act on behalf of the client itself.
+	GrantType string
+	// Act is the delegation chain of a token obtained via token exchange (RFC 8693).
+	Act *oauth.Actor
+	// Cnf is the proof-of-possession key the token is bound to; zero for bearer tokens.
+	Cnf oauth.Confirmation
 
 	// Token lifecycle
 	ExpiresAt int64
@@ -130,11 +231,81 @@ func (t ResolvedAccessToken) IsActive() bool {
 	return !t.Revoked && time.Now().Unix() < t.ExpiresAt
 }
 
+// ResolvedRefreshToken contains the fields resolved from a refresh token string,
+// suitable for introspection.
+type ResolvedRefreshToken struct {
+	GrantID   string
+	ClientID  string
+	TenantID  string
+	RealmName string
+	Sub       string
+	Username  string
+	Scope     string
+	// RotationCount is how many times the grant family has been rotated.
+	RotationCount int64
+
+	// Token lifecycle
+	ExpiresAt int64
+	// LastRefreshedAt is when the token was issued, i.e. when the family was last rotated;
+	// the idle timeout is measured from it.
+	LastRefreshedAt int64
+	Revoked         bool
+}
+
+// EffectiveExpiresAt returns when the token expires, the earlier of its absolute expiry and,
+// with an idle timeout in seconds (0 disables it), the end of the idle period.
+func (t ResolvedRefreshToken) EffectiveExpiresAt(idleTimeout int64) int64 {
+	if idleTimeout > 0 && t.LastRefreshedAt+idleTimeout < t.ExpiresAt {
+		return t.LastRefreshedAt + idleTimeout
+	}
+	return t.ExpiresAt
+}
+
+// IsActive reports whether the token can still be rotated (not revoked, expired or idle).
+func (t ResolvedRefreshToken) IsActive(idleTimeout int64) bool {
+	return !t.Revoked && time.Now().Unix() < t.EffectiveExpiresAt(idleTimeout)
+}
+
+// TokenGrant carries what the resource owner authorized; every token issued
+// for the grant, including those produced by refresh rotation, is bound to it.
+type TokenGrant struct {
+	TenantID string
+	Sub      string
+	Username string
+	Audience []string
+	// Resource is the resource parameter the audiences were extracted from, kept on the
+	// refresh token so that the grant can be displayed to the user.
+	Resource string
+	// AuthorizationDetails is the authorization_details the user approved (RFC 9396),
+	// kept on every token of the grant family.
+	AuthorizationDetails []oauth.AuthorizationDetail
+	Scope                string
+	// GrantType is the grant_type of the token request that issued the tokens.
+	GrantType string
+	// Act is set for token exchange (RFC 8693) and nil otherwise.
+	Act *oauth.Actor
+	// Cnf binds the issued tokens to the client's DPoP key (RFC 9449) or certificate (RFC 8705);
+	// zero for bearer tokens.
+	Cnf oauth.Confirmation
+}
+
 // TokenIssuancePolicy holds the realm-specific parameters that govern token generation.
 type TokenIssuancePolicy struct {
 	Prefix          string
 	AccessTokenTTL  int64
 	RefreshTokenTTL int64
+	// RefreshTokenIdleTimeout, in seconds, expires a refresh token that is not rotated in time;
+	// zero disables it.
+	RefreshTokenIdleTimeout int64
+	// AccessTokenFormat is oauth.AccessTokenFormatOpaque (default) or oauth.AccessTokenFormatJWT.
+	AccessTokenFormat string
+	// Issuer is the "iss" claim of JWT access tokens.
+	Issuer string
+	// PublicClient is set when the registered client type is public, whose refresh tokens
+	// are bound to the proof-of-possession key of the client.
+	PublicClient bool
+	// ClientPolicy is the effective policy of the client, re-checked against the grant on refresh.
+	ClientPolicy oauth.ClientPolicy
 }
 
 // TokenPair represents an access token and refresh token pair.
@@ -145,6 +316,8 @@ type TokenPair struct {
 	ExpiresIn    int64  `json:"expires_in"`
 	RefreshToken string `json:"refresh_token,omitempty"`
 	Scope        string `json:"scope,omitempty"`
+	// RFC 9396 §7: the authorization details the tokens were issued for
+	AuthorizationDetails []oauth.AuthorizationDetail `json:"authorization_details,omitempty"`
 }
 
 // CreatedDeviceCode is returned by CreateDeviceCode with the fields needed
@@ -158,16 +331,77 @@ type CreatedDeviceCode struct {
 // PendingDeviceCode is returned by GetByUserCode with the fields needed
 // to render the user-facing consent page.
 type PendingDeviceCode struct {
-	ClientID  string
-	RealmName string
-	Resource  string
+	ClientID             string
+	RealmName            string
+	Resource             string
+	AuthorizationDetails []oauth.AuthorizationDetail
+	Scope                string
 }
 
 // ApprovedDeviceCode is returned by PollAndConsumeDeviceCode when the device
 // code has been approved and consumed, carrying the identity claims needed to issue tokens.
 type ApprovedDeviceCode struct {
-	TenantID string
-	Sub      string
-	Username string
-	Audience []string
+	TenantID             string
+	Sub                  string
+	Username             string
+	Audience             []string
+	Resource             string
+	AuthorizationDetails []oauth.AuthorizationDetail
+	Scope                string
+}
+
+// PersonalAccessTokenInput carries what a user chose when creating a personal access token.
+type PersonalAccessTokenInput struct {
+	TenantID  string
+	Sub       string
+	Username  string
+	RealmName string
+	Name      string
+	Resource  string
+	Audience  []string
+	// Prefix is the token prefix of the realm, TTL the lifetime in seconds.
+	Prefix string
+	TTL    int64
+}
+
+// PersonalAccessToken is a personal access token as listed to its owner.
+type PersonalAccessToken struct {
+	ID        int64
+	Name      string
+	RealmName string
+	Resource  string
+	TokenMask string
+	ExpiresAt int64
+	CreatedAt int64
+	// Token is only set on creation, it cannot be retrieved later.
+	Token string
+}
+
+// ConsentGrant identifies what a user approved, or is asked to approve, for a client on a realm.
+type ConsentGrant struct {
+	TenantID  string
+	Sub       string
+	RealmName string
+	ClientID  string
+	Audience  []string
+	Scope     string
+}
+
+// UserGrant is an active grant family of a user, as listed on the "My authorizations" page.
+type UserGrant struct {
+	GrantID         string
+	ClientID        string
+	RealmName       string
+	Resource        string
+	Scope           string
+	FirstIssuedAt   int64
+	LastRefreshedAt int64
+	ExpiresAt       int64
+}
+
+// CleanupResult is the number of expired rows of a table deleted, or to be deleted in a dry run,
+// by the token cleanup.
+type CleanupResult struct {
+	Table string
+	Count int64
 }
@@ -57,6 +57,35 @@ var _ = Describe("ResolvedAccessToken", func() {
 	})
 })
 
+var _ = Describe("ResolvedRefreshToken", func() {
+	Describe("IsActive", func() {
+		DescribeTable("cases",
+			func(revoked bool, lastRefreshedAgo time.Duration, idleTimeout int64, want bool) {
+				t := ResolvedRefreshToken{
+					Revoked:         revoked,
+					ExpiresAt:       time.Now().Add(time.Hour).Unix(),
+					LastRefreshedAt: time.Now().Add(-lastRefreshedAgo).Unix(),
+				}
+				assert.Equal(GinkgoT(), want, t.IsActive(idleTimeout))
+			},
+			Entry("not revoked, no idle timeout", false, 2*time.Hour, int64(0), true),
+			Entry("revoked", true, time.Minute, int64(0), false),
+			Entry("refreshed within the idle timeout", false, time.Minute, int64(600), true),
+			Entry("idle for longer than the idle timeout", false, 20*time.Minute, int64(600), false),
+		)
+	})
+
+	Describe("EffectiveExpiresAt", func() {
+		It("should return the earlier of the absolute and the idle expiry", func() {
+			t := ResolvedRefreshToken{ExpiresAt: 10000, LastRefreshedAt: 1000}
+
+			assert.Equal(GinkgoT(), int64(10000), t.EffectiveExpiresAt(0))
+			assert.Equal(GinkgoT(), int64(1600), t.EffectiveExpiresAt(600))
+			assert.Equal(GinkgoT(), int64(10000), t.EffectiveExpiresAt(20000))
+		})
+	})
+})
+
 var _ = Describe("OAuthClientFlowSpec", func() {
 	Describe("SupportsGrantType", func() {
 		DescribeTable("cases",
@@ -78,4 +107,23 @@ var _ = Describe("OAuthClientFlowSpec", func() {
 				oauth.GrantTypeRefreshToken, false),
 		)
 	})
+
+	Describe("ResolvePolicy", func() {
+		policy := oauth.ClientPolicy{AllowedRealms: []string{"blueking"}, AllowedResources: []string{"mcp:foo"}}
+
+		It("should return the client's own policy", func() {
+			s := OAuthClientFlowSpec{Type: oauth.ClientTypePublic, Policy: policy}
+			assert.Equal(GinkgoT(), policy, s.ResolvePolicy("blueking"))
+		})
+
+		It("should not apply the public client default to confidential clients", func() {
+			s := OAuthClientFlowSpec{Type: oauth.ClientTypeConfidential}
+			assert.True(GinkgoT(), s.ResolvePolicy("blueking").IsEmpty())
+		})
+
+		It("should fall back to the realm default for public clients", func() {
+			s := OAuthClientFlowSpec{Type: oauth.ClientTypePublic}
+			assert.Equal(GinkgoT(), oauth.DefaultPublicClientPolicy("blueking"), s.ResolvePolicy("blueking"))
+		})
+	})
 })
@@ -33,6 +33,10 @@ const (
 	RealmNameKey = "realm_name"
 	ClientIDKey  = "client_id"
 
+	ClientCertThumbprintKey = "client_cert_thumbprint"
+	ClientTypeKey           = "client_type"
+	ClientAuthMethodKey     = "client_auth_method"
+
 	// TenantModeGlobal 应用在租户层的可用模式：全租户
 	TenantModeGlobal = "global"
 	// TenantModeSingle 应用在租户层的可用模式：单租户
@@ -118,3 +118,27 @@ func SetClientID(c *gin.Context, clientID string) {
 func GetClientID(c *gin.Context) string {
 	return c.GetString(ClientIDKey)
 }
+
+func SetClientCertThumbprint(c *gin.Context, thumbprint string) {
+	c.Set(ClientCertThumbprintKey, thumbprint)
+}
+
+func GetClientCertThumbprint(c *gin.Context) string {
+	return c.GetString(ClientCertThumbprintKey)
+}
+
+func SetClientType(c *gin.Context, clientType string) {
+	c.Set(ClientTypeKey, clientType)
+}
+
+func GetClientType(c *gin.Context) string {
+	return c.GetString(ClientTypeKey)
+}
+
+func SetClientAuthMethod(c *gin.Context, authMethod string) {
+	c.Set(ClientAuthMethodKey, authMethod)
+}
+
+func GetClientAuthMethod(c *gin.Context) string {
+	return c.GetString(ClientAuthMethodKey)
+}
@@ -0,0 +1,30 @@
+-- TencentBlueKing is pleased to support the open source community by making
+-- 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+-- Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+-- Licensed under the MIT License (the "License"); you may not use this file except
+-- in compliance with the License. You may obtain a copy of the License at
+--     http://opensource.org/licenses/MIT
+-- Unless required by applicable law or agreed to in writing, software distributed under
+-- the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+-- either express or implied. See the License for the specific language governing permissions and
+-- limitations under the License.
+-- We undertake not to change the open source license (MIT license) applicable
+-- to the current version of the project delivered to anyone in the future.
+
+-- Mutual-TLS client authentication (RFC 8705 §2) of confidential clients.
+-- token_endpoint_auth_method overrides the method derived from type when not empty:
+--   tls_client_auth matches tls_client_auth_subject_dn or tls_client_auth_san,
+--   self_signed_tls_client_auth matches an x5c certificate of jwks.
+ALTER TABLE `bkauth`.`oauth_client`
+    ADD COLUMN `token_endpoint_auth_method` VARCHAR(64) NOT NULL DEFAULT '' AFTER `grant_types`,
+    ADD COLUMN `tls_client_auth_subject_dn` VARCHAR(512) NOT NULL DEFAULT '' AFTER `token_endpoint_auth_method`,
+    ADD COLUMN `tls_client_auth_san` VARCHAR(512) NOT NULL DEFAULT '' AFTER `tls_client_auth_subject_dn`,
+    ADD COLUMN `jwks` TEXT NULL AFTER `tls_client_auth_san`;
+
+-- cnf_x5t_s256 is the SHA-256 thumbprint of the client certificate (RFC 8705 §3) the token is bound to;
+-- empty for tokens issued without a certificate. Refresh tokens are only bound for public clients.
+ALTER TABLE `bkauth`.`oauth_access_token`
+    ADD COLUMN `cnf_x5t_s256` VARCHAR(64) NOT NULL DEFAULT '' AFTER `cnf_jkt`;
+
+ALTER TABLE `bkauth`.`oauth_refresh_token`
+    ADD COLUMN `cnf_x5t_s256` VARCHAR(64) NOT NULL DEFAULT '' AFTER `cnf_jkt`;
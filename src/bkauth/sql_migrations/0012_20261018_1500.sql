@@ -0,0 +1,17 @@
+-- TencentBlueKing is pleased to support the open source community by making
+-- 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+-- Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+-- Licensed under the MIT License (the "License"); you may not use this file except
+-- in compliance with the License. You may obtain a copy of the License at
+--     http://opensource.org/licenses/MIT
+-- Unless required by applicable law or agreed to in writing, software distributed under
+-- the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+-- either express or implied. See the License for the specific language governing permissions and
+-- limitations under the License.
+-- We undertake not to change the open source license (MIT license) applicable
+-- to the current version of the project delivered to anyone in the future.
+
+-- private_key_jwt client authentication (RFC 7523 §2.2): the client assertion is verified
+-- against the keys registered inline in jwks or published at jwks_uri (RFC 7591 §2).
+ALTER TABLE `bkauth`.`oauth_client`
+    ADD COLUMN `jwks_uri` VARCHAR(512) NOT NULL DEFAULT '' AFTER `jwks`;
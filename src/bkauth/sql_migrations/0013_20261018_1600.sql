@@ -0,0 +1,24 @@
+-- TencentBlueKing is pleased to support the open source community by making
+-- 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+-- Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+-- Licensed under the MIT License (the "License"); you may not use this file except
+-- in compliance with the License. You may obtain a copy of the License at
+--     http://opensource.org/licenses/MIT
+-- Unless required by applicable law or agreed to in writing, software distributed under
+-- the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+-- either express or implied. See the License for the specific language governing permissions and
+-- limitations under the License.
+-- We undertake not to change the open source license (MIT license) applicable
+-- to the current version of the project delivered to anyone in the future.
+
+-- Dynamic Client Registration management (RFC 7592): a dynamically registered client is
+-- read, updated and deleted with the registration_access_token returned at registration.
+ALTER TABLE `bkauth`.`oauth_client`
+    ADD COLUMN `registration_access_token_hash` VARCHAR(64) NOT NULL DEFAULT '' AFTER `jwks_uri`;
+
+-- deleting a client revokes all of its tokens
+ALTER TABLE `bkauth`.`oauth_access_token`
+    ADD INDEX `idx_client_id` (`client_id`);
+
+ALTER TABLE `bkauth`.`oauth_refresh_token`
+    ADD INDEX `idx_client_id` (`client_id`);
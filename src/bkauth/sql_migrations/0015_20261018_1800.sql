@@ -0,0 +1,31 @@
+-- TencentBlueKing is pleased to support the open source community by making
+-- 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+-- Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+-- Licensed under the MIT License (the "License"); you may not use this file except
+-- in compliance with the License. You may obtain a copy of the License at
+--     http://opensource.org/licenses/MIT
+-- Unless required by applicable law or agreed to in writing, software distributed under
+-- the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+-- either express or implied. See the License for the specific language governing permissions and
+-- limitations under the License.
+-- We undertake not to change the open source license (MIT license) applicable
+-- to the current version of the project delivered to anyone in the future.
+
+-- Personal access tokens: the token itself is an oauth_access_token row
+-- (client_id = 'private', grant_type = 'personal_access_token') of the same grant_id,
+-- this table keeps the name and resource the user chose for it.
+CREATE TABLE IF NOT EXISTS `bkauth`.`oauth_personal_access_token` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `grant_id` VARCHAR(64) NOT NULL UNIQUE,
+    `name` VARCHAR(64) NOT NULL,
+    `tenant_id` VARCHAR(32) NOT NULL DEFAULT '',
+    `realm_name` VARCHAR(64) NOT NULL,
+    `sub` VARCHAR(64) NOT NULL,
+    `username` VARCHAR(64) NOT NULL DEFAULT '',
+    `resource` VARCHAR(2048) NOT NULL,
+    `token_hash` VARCHAR(64) NOT NULL,
+    `token_mask` VARCHAR(32) NOT NULL DEFAULT '',
+    `expires_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX `idx_tenant_sub` (`tenant_id`, `sub`)
+    ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
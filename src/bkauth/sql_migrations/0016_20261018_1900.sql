@@ -0,0 +1,27 @@
+-- TencentBlueKing is pleased to support the open source community by making
+-- 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+-- Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+-- Licensed under the MIT License (the "License"); you may not use this file except
+-- in compliance with the License. You may obtain a copy of the License at
+--     http://opensource.org/licenses/MIT
+-- Unless required by applicable law or agreed to in writing, software distributed under
+-- the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+-- either express or implied. See the License for the specific language governing permissions and
+-- limitations under the License.
+-- We undertake not to change the open source license (MIT license) applicable
+-- to the current version of the project delivered to anyone in the future.
+
+-- Approved consents, re-used by /authorize until expires_at so the user is not asked again
+-- for the same client, realm and resources; one row per (user, realm, client).
+CREATE TABLE IF NOT EXISTS `bkauth`.`oauth_consent` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `tenant_id` VARCHAR(32) NOT NULL DEFAULT '',
+    `sub` VARCHAR(64) NOT NULL,
+    `realm_name` VARCHAR(64) NOT NULL,
+    `client_id` VARCHAR(255) NOT NULL,
+    `audience` JSON NOT NULL,
+    `scope` VARCHAR(256) NOT NULL DEFAULT '',
+    `granted_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `expires_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_tenant_sub_realm_client` (`tenant_id`, `sub`, `realm_name`, `client_id`)
+    ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,21 @@
+-- TencentBlueKing is pleased to support the open source community by making
+-- 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+-- Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+-- Licensed under the MIT License (the "License"); you may not use this file except
+-- in compliance with the License. You may obtain a copy of the License at
+--     http://opensource.org/licenses/MIT
+-- Unless required by applicable law or agreed to in writing, software distributed under
+-- the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+-- either express or implied. See the License for the specific language governing permissions and
+-- limitations under the License.
+-- We undertake not to change the open source license (MIT license) applicable
+-- to the current version of the project delivered to anyone in the future.
+
+-- My authorizations: the resource the user approved is kept on the grant family so that it can be
+-- displayed back to the user, and the active grants of a user are listed by (tenant_id, sub).
+ALTER TABLE `bkauth`.`oauth_authorization_code`
+    ADD COLUMN `resource` VARCHAR(2048) NOT NULL DEFAULT '' AFTER `audience`;
+
+ALTER TABLE `bkauth`.`oauth_refresh_token`
+    ADD COLUMN `resource` VARCHAR(2048) NOT NULL DEFAULT '' AFTER `audience`,
+    ADD INDEX `idx_tenant_sub` (`tenant_id`, `sub`);
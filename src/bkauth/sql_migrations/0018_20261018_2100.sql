@@ -0,0 +1,18 @@
+-- TencentBlueKing is pleased to support the open source community by making
+-- 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+-- Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+-- Licensed under the MIT License (the "License"); you may not use this file except
+-- in compliance with the License. You may obtain a copy of the License at
+--     http://opensource.org/licenses/MIT
+-- Unless required by applicable law or agreed to in writing, software distributed under
+-- the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+-- either express or implied. See the License for the specific language governing permissions and
+-- limitations under the License.
+-- We undertake not to change the open source license (MIT license) applicable
+-- to the current version of the project delivered to anyone in the future.
+
+-- Per-client policy: the realms a client may use and the resource patterns it may request,
+-- both comma-separated; empty means not restricted (or the realm default of public clients).
+ALTER TABLE `bkauth`.`oauth_client`
+    ADD COLUMN `allowed_realms` VARCHAR(256) NOT NULL DEFAULT '' AFTER `grant_types`,
+    ADD COLUMN `allowed_resources` VARCHAR(2048) NOT NULL DEFAULT '' AFTER `allowed_realms`;
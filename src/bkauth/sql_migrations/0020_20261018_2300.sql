@@ -0,0 +1,25 @@
+-- TencentBlueKing is pleased to support the open source community by making
+-- 蓝鲸智云 - Auth 服务 (BlueKing - Auth) available.
+-- Copyright (C) 2017 THL A29 Limited, a Tencent company. All rights reserved.
+-- Licensed under the MIT License (the "License"); you may not use this file except
+-- in compliance with the License. You may obtain a copy of the License at
+--     http://opensource.org/licenses/MIT
+-- Unless required by applicable law or agreed to in writing, software distributed under
+-- the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+-- either express or implied. See the License for the specific language governing permissions and
+-- limitations under the License.
+-- We undertake not to change the open source license (MIT license) applicable
+-- to the current version of the project delivered to anyone in the future.
+
+-- Stored token hashes become an HMAC keyed by a server-side secret, the key id records which
+-- key computed each hash so that rows hashed with a previous key, or with the legacy plain
+-- SHA-256 (empty key id), stay readable during a rotation.
+ALTER TABLE `bkauth`.`oauth_access_token`
+    ADD COLUMN `token_hash_kid` VARCHAR(32) NOT NULL DEFAULT '' AFTER `token_hash`;
+
+ALTER TABLE `bkauth`.`oauth_refresh_token`
+    ADD COLUMN `token_hash_kid` VARCHAR(32) NOT NULL DEFAULT '' AFTER `token_hash`;
+
+-- device_code now holds the hash of the device code instead of the code itself
+ALTER TABLE `bkauth`.`oauth_device_code`
+    ADD COLUMN `device_code_hash_kid` VARCHAR(32) NOT NULL DEFAULT '' AFTER `device_code`;